@@ -12,4 +12,90 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// Package migrations upgrades the StorageDocument written by older releases of ksctl to the current layout.
+// Every change of the layout adds an Upgrade to the end of the registry, which moves the document
+// from its SchemaVersion to the next one
 package migrations
+
+import (
+	"fmt"
+
+	v1_2_8 "github.com/ksctl/ksctl/v2/migrations/releases/v1.2.8"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+)
+
+type Upgrade struct {
+	// From is the SchemaVersion the upgrade applies to, it leaves the document at From+1
+	From int
+
+	// Release of ksctl which introduced the upgrade
+	Release string
+
+	Description string
+
+	Apply func(*statefile.StorageDocument) error
+}
+
+func (u Upgrade) String() string {
+	return fmt.Sprintf("%d->%d (%s) %s", u.From, u.From+1, u.Release, u.Description)
+}
+
+// upgrades is ordered by From, the n-th upgrade moves the document from version n to n+1
+var upgrades = []Upgrade{
+	{
+		From:        0,
+		Release:     "v1.2.8",
+		Description: "pad the vm sizes to the number of vms",
+		Apply:       v1_2_8.AlignVMSizes,
+	},
+}
+
+// LatestSchemaVersion is the SchemaVersion of the documents written by this release
+func LatestSchemaVersion() int {
+	return len(upgrades)
+}
+
+// Upgrades returns the registered upgrades in the order they are applied
+func Upgrades() []Upgrade {
+	return append([]Upgrade(nil), upgrades...)
+}
+
+// UpgradeDocument applies the pending upgrades to the document in place and returns the ones applied.
+// A document written by a newer release is rejected, as this release cannot know its layout
+func UpgradeDocument(doc *statefile.StorageDocument) ([]Upgrade, error) {
+	if doc == nil {
+		return nil, nil
+	}
+	if doc.SchemaVersion > LatestSchemaVersion() {
+		return nil, fmt.Errorf("state schema version %d is newer than the supported version %d", doc.SchemaVersion, LatestSchemaVersion())
+	}
+	if doc.SchemaVersion < 0 {
+		return nil, fmt.Errorf("invalid state schema version %d", doc.SchemaVersion)
+	}
+
+	var applied []Upgrade
+	for _, u := range upgrades[doc.SchemaVersion:] {
+		if err := u.Apply(doc); err != nil {
+			return applied, fmt.Errorf("upgrade %s failed: %w", u, err)
+		}
+		doc.SchemaVersion = u.From + 1
+		applied = append(applied, u)
+	}
+	return applied, nil
+}
+
+// StampDocument marks a document which was never written before with the latest schema version.
+// The storages call it on write, so that new documents are not taken for ones from the older releases
+func StampDocument(doc *statefile.StorageDocument) {
+	if doc.Revision == 0 && doc.SchemaVersion == 0 {
+		doc.SchemaVersion = LatestSchemaVersion()
+	}
+}
+
+// Upgradable is implemented by the storages which upgrade the documents on read
+type Upgradable interface {
+	// WithoutSchemaUpgrade returns a copy of the storage reading the documents as stored,
+	// the storage itself keeps upgrading them
+	WithoutSchemaUpgrade() storage.Storage
+}
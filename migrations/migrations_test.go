@@ -0,0 +1,132 @@
+// Copyright 2024 ksctl
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrations_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/ksctl/ksctl/v2/migrations"
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	"github.com/ksctl/ksctl/v2/pkg/logger"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage/host"
+	"gotest.tools/v3/assert"
+)
+
+var (
+	parentCtx    = context.WithValue(context.TODO(), consts.KsctlTestFlagKey, "true")
+	parentLogger = logger.NewStructuredLogger(-1, os.Stdout)
+)
+
+// legacyDocument is laid out the way the releases before the schema version wrote it
+func legacyDocument(name string) *statefile.StorageDocument {
+	return &statefile.StorageDocument{
+		InfraProvider: consts.CloudAws,
+		ClusterName:   name,
+		Region:        "us-east-1",
+		ClusterType:   string(consts.ClusterTypeSelfMang),
+		CloudInfra: &statefile.InfrastructureState{
+			Aws: &statefile.StateConfigurationAws{
+				InfoWorkerPlanes: statefile.AWSStateVms{
+					HostNames:   []string{"wp-0", "wp-1"},
+					InstanceIds: []string{"i-0", "i-1"},
+					VMSizes:     []string{"t2.micro"},
+				},
+			},
+		},
+	}
+}
+
+func TestUpgradesOrdered(t *testing.T) {
+	for i, u := range migrations.Upgrades() {
+		assert.Equal(t, u.From, i, "upgrade %s is out of order", u)
+		assert.Assert(t, u.Apply != nil)
+	}
+	assert.Equal(t, len(migrations.Upgrades()), migrations.LatestSchemaVersion())
+}
+
+func TestUpgradeDocument(t *testing.T) {
+	doc := legacyDocument("demo")
+
+	applied, err := migrations.UpgradeDocument(doc)
+	assert.NilError(t, err)
+	assert.Equal(t, len(applied), migrations.LatestSchemaVersion())
+	assert.Equal(t, doc.SchemaVersion, migrations.LatestSchemaVersion())
+	assert.DeepEqual(t, doc.CloudInfra.Aws.InfoWorkerPlanes.VMSizes, []string{"t2.micro", ""})
+
+	applied, err = migrations.UpgradeDocument(doc)
+	assert.NilError(t, err)
+	assert.Equal(t, len(applied), 0)
+
+	doc.SchemaVersion = migrations.LatestSchemaVersion() + 1
+	_, err = migrations.UpgradeDocument(doc)
+	assert.ErrorContains(t, err, "newer than the supported version")
+}
+
+func TestStampDocument(t *testing.T) {
+	doc := &statefile.StorageDocument{}
+	migrations.StampDocument(doc)
+	assert.Equal(t, doc.SchemaVersion, migrations.LatestSchemaVersion())
+
+	doc = &statefile.StorageDocument{Revision: 3}
+	migrations.StampDocument(doc)
+	assert.Equal(t, doc.SchemaVersion, 0)
+}
+
+func TestUpgradeAll(t *testing.T) {
+	store := host.NewClient(context.WithValue(parentCtx, consts.KsctlCustomDirLoc, t.TempDir()), parentLogger)
+
+	// the documents are written as an older release would, without the schema version
+	raw := store.WithoutSchemaUpgrade()
+	assert.NilError(t, raw.Setup(consts.CloudAws, "us-east-1", "demo", consts.ClusterTypeSelfMang))
+	doc := legacyDocument("demo")
+	assert.NilError(t, raw.Write(doc))
+	// the first write stamps the new document, the second one puts back the version of the older releases
+	doc.SchemaVersion = 0
+	assert.NilError(t, raw.Write(doc))
+
+	reports, err := migrations.UpgradeAll(parentCtx, parentLogger, store, migrations.Options{DryRun: true})
+	assert.NilError(t, err)
+	assert.Equal(t, len(reports), 1)
+	assert.Equal(t, reports[0].FromVersion, 0)
+	assert.Equal(t, reports[0].ToVersion, migrations.LatestSchemaVersion())
+	assert.Assert(t, len(reports[0].Changes) != 0)
+
+	got, err := raw.Read()
+	assert.NilError(t, err)
+	assert.Equal(t, got.SchemaVersion, 0, "dry run must not write")
+
+	assert.NilError(t, store.Setup(consts.CloudAws, "us-east-1", "demo", consts.ClusterTypeSelfMang))
+	got, err = store.Read()
+	assert.NilError(t, err)
+	assert.Equal(t, got.SchemaVersion, migrations.LatestSchemaVersion(), "documents are upgraded on read")
+	assert.DeepEqual(t, got.CloudInfra.Aws.InfoWorkerPlanes.VMSizes, []string{"t2.micro", ""})
+
+	reports, err = migrations.UpgradeAll(parentCtx, parentLogger, store, migrations.Options{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(reports), 1)
+	assert.Equal(t, len(reports[0].Applied), migrations.LatestSchemaVersion())
+
+	got, err = raw.Read()
+	assert.NilError(t, err)
+	assert.Equal(t, got.SchemaVersion, migrations.LatestSchemaVersion())
+	assert.Equal(t, got.Revision, int64(3))
+
+	reports, err = migrations.UpgradeAll(parentCtx, parentLogger, store, migrations.Options{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(reports[0].Applied), 0)
+}
@@ -13,3 +13,34 @@
 // limitations under the License.
 
 package v1_2_8
+
+import "github.com/ksctl/ksctl/v2/pkg/statefile"
+
+// AlignVMSizes pads the vm sizes of every group of vms to the number of vms in it.
+// The providers index the sizes along with the instances, states which missed
+// the sizes of some of the vms made them go out of range
+func AlignVMSizes(doc *statefile.StorageDocument) error {
+	if doc.CloudInfra == nil {
+		return nil
+	}
+
+	if aws := doc.CloudInfra.Aws; aws != nil {
+		for _, vms := range []*statefile.AWSStateVms{&aws.InfoControlPlanes, &aws.InfoWorkerPlanes, &aws.InfoDatabase} {
+			vms.VMSizes = padTo(vms.VMSizes, max(len(vms.HostNames), len(vms.InstanceIds)))
+		}
+	}
+
+	if azure := doc.CloudInfra.Azure; azure != nil {
+		for _, vms := range []*statefile.AzureStateVMs{&azure.InfoControlPlanes, &azure.InfoWorkerPlanes, &azure.InfoDatabase} {
+			vms.VMSizes = padTo(vms.VMSizes, max(len(vms.Names), len(vms.Hostnames)))
+		}
+	}
+	return nil
+}
+
+func padTo(v []string, n int) []string {
+	for len(v) < n {
+		v = append(v, "")
+	}
+	return v
+}
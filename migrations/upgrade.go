@@ -0,0 +1,144 @@
+// Copyright 2024 ksctl
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrations
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/logger"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+)
+
+type Options struct {
+	// DryRun only reports what would change, nothing is written back to the storage
+	DryRun bool
+
+	// Filters selects the clusters to upgrade, same as storage.Storage.GetOneOrMoreClusters
+	Filters map[consts.KsctlSearchFilter]string
+}
+
+type Report struct {
+	InfraProvider consts.KsctlCloud
+	ClusterType   consts.KsctlClusterType
+	ClusterName   string
+	Region        string
+
+	FromVersion int
+	ToVersion   int
+
+	Applied []Upgrade
+	Changes []storage.StateChange
+}
+
+// UpgradeAll upgrades the stored state of every cluster to the latest schema version and writes it back.
+// Clusters already at the latest version are reported with no upgrades applied.
+// The reports are returned for the clusters handled till the first error
+func UpgradeAll(ctx context.Context, l logger.Logger, store storage.Storage, opts Options) ([]Report, error) {
+	ctx = context.WithValue(ctx, consts.KsctlModuleNameKey, "storage-upgrade")
+
+	u, ok := store.(Upgradable)
+	if !ok {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidStorageProvider,
+			l.NewError(ctx, "storage doesn't support reading the documents without upgrading them"),
+		)
+	}
+	// the documents are needed as stored to know what the upgrades change, the copy
+	// keeps the store shared with the other callers upgrading the documents it reads
+	store = u.WithoutSchemaUpgrade()
+
+	filters := opts.Filters
+	if filters == nil {
+		filters = map[consts.KsctlSearchFilter]string{}
+	}
+
+	clusters, err := store.GetOneOrMoreClusters(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []Report
+	for clusterType, docs := range clusters {
+		for _, doc := range docs {
+			if doc == nil {
+				continue
+			}
+			rep := Report{
+				InfraProvider: doc.InfraProvider,
+				ClusterType:   clusterType,
+				ClusterName:   doc.ClusterName,
+				Region:        doc.Region,
+				FromVersion:   doc.SchemaVersion,
+			}
+
+			before, err := copyDocument(doc)
+			if err != nil {
+				return reports, ksctlErrors.WrapError(
+					ksctlErrors.ErrInternal,
+					l.NewError(ctx, "failed to copy the state", "Reason", err),
+				)
+			}
+
+			rep.Applied, err = UpgradeDocument(doc)
+			if err != nil {
+				return reports, ksctlErrors.WrapError(
+					ksctlErrors.ErrInvalidVersion,
+					l.NewError(ctx, "failed to upgrade the state", "cluster", doc.ClusterName, "region", doc.Region, "Reason", err),
+				)
+			}
+			rep.ToVersion = doc.SchemaVersion
+
+			rep.Changes, err = storage.DiffDocuments(before, doc)
+			if err != nil {
+				return reports, ksctlErrors.WrapError(
+					ksctlErrors.ErrInternal,
+					l.NewError(ctx, "failed to diff the state", "Reason", err),
+				)
+			}
+			l.Debug(ctx, "migrations.UpgradeAll", "cluster", rep.ClusterName, "region", rep.Region, "from", rep.FromVersion, "to", rep.ToVersion, "changes", len(rep.Changes))
+
+			if opts.DryRun || len(rep.Applied) == 0 {
+				reports = append(reports, rep)
+				continue
+			}
+
+			if err := store.Setup(doc.InfraProvider, doc.Region, doc.ClusterName, clusterType); err != nil {
+				return reports, err
+			}
+			if err := store.Write(doc); err != nil {
+				return reports, err
+			}
+			reports = append(reports, rep)
+		}
+	}
+
+	return reports, nil
+}
+
+func copyDocument(doc *statefile.StorageDocument) (*statefile.StorageDocument, error) {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var cp *statefile.StorageDocument
+	if err := json.Unmarshal(raw, &cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
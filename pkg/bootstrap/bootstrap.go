@@ -17,6 +17,7 @@ package bootstrap
 import (
 	"github.com/ksctl/ksctl/v2/pkg/consts"
 	"github.com/ksctl/ksctl/v2/pkg/provider"
+	"github.com/ksctl/ksctl/v2/pkg/ssh"
 )
 
 type Bootstrap interface {
@@ -24,5 +25,22 @@ type Bootstrap interface {
 
 	ConfigureDataStore(noOfNodes int, version string) error
 
+	// AddDataStoreMember joins a datastore to the running etcd cluster of the members
+	AddDataStoreMember(no int, members []int, ca, cert, key string) error
+
+	// RemoveDataStoreMember removes a datastore from the etcd cluster through another member
+	RemoveDataStoreMember(no, via int) error
+
+	// RollDataStore restarts the etcd of a datastore with new certificates
+	RollDataStore(no int, ca, cert, key string) error
+
 	ConfigureLoadbalancer() error
+
+	ReconfigureLoadbalancer(noOfControlPlanes int) error
+
+	PlanSetup(*provider.CloudResourceState, consts.KsctlOperation)
+
+	PlanDataStore(no int, version string) ([]ssh.Script, error)
+
+	PlanLoadbalancer() ([]ssh.Script, error)
 }
@@ -86,6 +86,186 @@ func (p *PreBootstrap) ConfigureDataStore(no int, version string) error {
 	return nil
 }
 
+// AddDataStoreMember announces the datastore no to the etcd cluster of the members through the first of them
+// and configures it to join with the given certificates, the etcd version stays the one of the members
+func (p *PreBootstrap) AddDataStoreMember(no int, members []int, ca, cert, key string) error {
+	p.mu.Lock()
+	sshExecutor := ssh.NewSSHExecutor(p.ctx, p.l, p.state) //making sure that a new obj gets initialized for an every run thus eliminating possible problems with concurrency
+	p.mu.Unlock()
+
+	if len(members) == 0 {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidNoOfDatastore,
+			p.l.NewError(p.ctx, "no member of the etcd cluster left to add the datastore", "number", no),
+		)
+	}
+
+	p.l.Note(p.ctx, "adding Datastore to the etcd cluster", "number", strconv.Itoa(no))
+
+	ver := ""
+	if p.state.Versions.Etcd != nil {
+		ver = *p.state.Versions.Etcd
+	}
+	etcdVer, err := p.verifyVersion(ver)
+	if err != nil {
+		return err
+	}
+
+	privIPs := p.state.K8sBootstrap.B.PrivateIPs.DataStores
+
+	if err := sshExecutor.Flag(consts.UtilExecWithoutOutput).
+		Script(scriptAddDataStoreMember(no, privIPs[no])).
+		IPv4(p.state.K8sBootstrap.B.PublicIPs.DataStores[members[0]]).
+		FastMode(true).SSHExecute(); err != nil {
+		return err
+	}
+
+	if err := sshExecutor.Flag(consts.UtilExecWithoutOutput).
+		Script(scriptDBWithMembers(
+			etcdVer,
+			ca, cert, key,
+			privIPs,
+			no,
+			getEtcdMemberIPFieldForMembers(privIPs, append(utilities.DeepCopySlice(members), no)),
+			"existing")).
+		IPv4(p.state.K8sBootstrap.B.PublicIPs.DataStores[no]).
+		FastMode(true).SSHExecute(); err != nil {
+		return err
+	}
+
+	p.state.Versions.Etcd = utilities.Ptr(etcdVer)
+	if err := p.store.Write(p.state); err != nil {
+		return err
+	}
+
+	p.l.Success(p.ctx, "added Datastore to the etcd cluster", "number", strconv.Itoa(no))
+	return nil
+}
+
+// RemoveDataStoreMember removes the datastore no from the etcd cluster through the member via,
+// the datastore itself is never reached so that a dead one can be removed as well
+func (p *PreBootstrap) RemoveDataStoreMember(no, via int) error {
+	p.mu.Lock()
+	sshExecutor := ssh.NewSSHExecutor(p.ctx, p.l, p.state) //making sure that a new obj gets initialized for an every run thus eliminating possible problems with concurrency
+	p.mu.Unlock()
+
+	p.l.Note(p.ctx, "removing Datastore from the etcd cluster", "number", strconv.Itoa(no))
+
+	if err := sshExecutor.Flag(consts.UtilExecWithoutOutput).
+		Script(scriptRemoveDataStoreMember(no, p.state.K8sBootstrap.B.PrivateIPs.DataStores[no])).
+		IPv4(p.state.K8sBootstrap.B.PublicIPs.DataStores[via]).
+		FastMode(true).SSHExecute(); err != nil {
+		return err
+	}
+
+	p.l.Success(p.ctx, "removed Datastore from the etcd cluster", "number", strconv.Itoa(no))
+	return nil
+}
+
+// RollDataStore replaces the certificates of the datastore no and restarts its etcd,
+// it waits for the member to be healthy again so that rolling one datastore at a time keeps the quorum
+func (p *PreBootstrap) RollDataStore(no int, ca, cert, key string) error {
+	p.mu.Lock()
+	sshExecutor := ssh.NewSSHExecutor(p.ctx, p.l, p.state) //making sure that a new obj gets initialized for an every run thus eliminating possible problems with concurrency
+	p.mu.Unlock()
+
+	p.l.Note(p.ctx, "rolling Datastore", "number", strconv.Itoa(no))
+
+	if err := sshExecutor.Flag(consts.UtilExecWithoutOutput).
+		Script(scriptRollDataStore(ca, cert, key)).
+		IPv4(p.state.K8sBootstrap.B.PublicIPs.DataStores[no]).
+		FastMode(true).SSHExecute(); err != nil {
+		return err
+	}
+
+	p.l.Success(p.ctx, "rolled Datastore", "number", strconv.Itoa(no))
+	return nil
+}
+
+// getEtcdMemberIPFieldForMembers is the initial cluster of only the members, which keep the name of their index
+func getEtcdMemberIPFieldForMembers(ips []string, members []int) string {
+	var tempDS []string
+	for _, idx := range members {
+		tempDS = append(tempDS, fmt.Sprintf("infra%d=https://%s:2380", idx, ips[idx]))
+	}
+
+	return strings.Join(tempDS, ",")
+}
+
+const etcdctlLocal = "sudo etcdctl --endpoints=https://127.0.0.1:2379 --cacert=/var/lib/etcd/ca.pem --cert=/var/lib/etcd/etcd.pem --key=/var/lib/etcd/etcd-key.pem"
+
+func scriptAddDataStoreMember(no int, privIP string) ssh.ExecutionPipeline {
+	collection := ssh.NewExecutionPipeline()
+
+	// the member which got added by a previous attempt without starting is listed by its peer url only
+	collection.Append(ssh.Script{
+		Name:           "add the etcd member",
+		ScriptExecutor: consts.LinuxBash,
+		CanRetry:       true,
+		MaxRetries:     3,
+		ShellScript: fmt.Sprintf(`
+ETCDCTL="%s"
+
+if ${ETCDCTL} member list | grep -q "https://%s:2380,"; then
+	echo "infra%d is already a member"
+else
+	${ETCDCTL} member add infra%d --peer-urls=https://%s:2380
+fi
+`, etcdctlLocal, privIP, no, no, privIP),
+	})
+
+	return collection
+}
+
+func scriptRemoveDataStoreMember(no int, privIP string) ssh.ExecutionPipeline {
+	collection := ssh.NewExecutionPipeline()
+
+	collection.Append(ssh.Script{
+		Name:           "remove the etcd member",
+		ScriptExecutor: consts.LinuxBash,
+		CanRetry:       true,
+		MaxRetries:     3,
+		ShellScript: fmt.Sprintf(`
+ETCDCTL="%s"
+
+MEMBER_ID=$(${ETCDCTL} member list | grep -e ", infra%d," -e "https://%s:2380," | cut -d',' -f1)
+if [ -n "${MEMBER_ID}" ]; then
+	${ETCDCTL} member remove ${MEMBER_ID}
+else
+	echo "infra%d is not a member"
+fi
+`, etcdctlLocal, no, privIP, no),
+	})
+
+	return collection
+}
+
+func scriptRollDataStore(ca, etcd, key string) ssh.ExecutionPipeline {
+	collection := ssh.NewExecutionPipeline()
+
+	collection.Append(scriptDataStoreCerts(ca, etcd, key))
+
+	collection.Append(ssh.Script{
+		Name:           "restart etcd and wait for it to be healthy",
+		ScriptExecutor: consts.LinuxBash,
+		CanRetry:       true,
+		MaxRetries:     3,
+		ShellScript: fmt.Sprintf(`
+sudo systemctl restart etcd
+
+for i in $(seq 1 30); do
+	if %s endpoint health; then
+		exit 0
+	fi
+	sleep 2
+done
+exit 1
+`, etcdctlLocal),
+	})
+
+	return collection
+}
+
 func getEtcdMemberIPFieldForDatastore(ips []string) string {
 	var tempDS []string
 	for idx, ip := range ips {
@@ -97,6 +277,13 @@ func getEtcdMemberIPFieldForDatastore(ips []string) string {
 }
 
 func scriptDB(etcdLatestVer, ca, etcd, key string, privIPs []string, currIdx int) ssh.ExecutionPipeline {
+	return scriptDBWithMembers(etcdLatestVer, ca, etcd, key, privIPs, currIdx,
+		getEtcdMemberIPFieldForDatastore(privIPs), "new")
+}
+
+// scriptDBWithMembers configures the etcd of the datastore currIdx for the clusterMembers,
+// the clusterState is new when bootstrapping the etcd cluster and existing when joining a running one
+func scriptDBWithMembers(etcdLatestVer, ca, etcd, key string, privIPs []string, currIdx int, clusterMembers, clusterState string) ssh.ExecutionPipeline {
 	collection := ssh.NewExecutionPipeline()
 
 	collection.Append(ssh.Script{
@@ -167,30 +354,7 @@ sudo rm -rf /tmp/etcd-download-test
 `, etcdLatestVer),
 	})
 
-	collection.Append(ssh.Script{
-		Name:           "store the certificate files",
-		ScriptExecutor: consts.LinuxBash,
-		CanRetry:       false,
-		ShellScript: fmt.Sprintf(`
-sudo mkdir -p /var/lib/etcd
-
-cat <<EOF > ca.pem
-%s
-EOF
-
-cat <<EOF > etcd.pem
-%s
-EOF
-
-cat <<EOF > etcd-key.pem
-%s
-EOF
-
-sudo mv -v ca.pem etcd.pem etcd-key.pem /var/lib/etcd
-`, ca, etcd, key),
-	})
-
-	clusterMembers := getEtcdMemberIPFieldForDatastore(privIPs)
+	collection.Append(scriptDataStoreCerts(ca, etcd, key))
 
 	collection.Append(ssh.Script{
 		Name:           "configure etcd configuration file and systemd",
@@ -214,7 +378,7 @@ ExecStart=/usr/local/bin/etcd \\
   --initial-cluster-token etcd-cluster-1 \\
   --initial-cluster %s \\
   --log-outputs=/var/lib/etcd/etcd.log \\
-  --initial-cluster-state new \\
+  --initial-cluster-state %s \\
   --peer-auto-tls \\
   --snapshot-count '10000' \\
   --wal-dir=/var/lib/etcd/wal \\
@@ -232,7 +396,7 @@ WantedBy=multi-user.target
 EOF
 
 sudo mv -v etcd.service /etc/systemd/system
-`, currIdx, privIPs[currIdx], privIPs[currIdx], privIPs[currIdx], privIPs[currIdx], clusterMembers),
+`, currIdx, privIPs[currIdx], privIPs[currIdx], privIPs[currIdx], privIPs[currIdx], clusterMembers, clusterState),
 	})
 
 	collection.Append(ssh.Script{
@@ -250,3 +414,28 @@ sudo systemctl start etcd
 
 	return collection
 }
+
+func scriptDataStoreCerts(ca, etcd, key string) ssh.Script {
+	return ssh.Script{
+		Name:           "store the certificate files",
+		ScriptExecutor: consts.LinuxBash,
+		CanRetry:       false,
+		ShellScript: fmt.Sprintf(`
+sudo mkdir -p /var/lib/etcd
+
+cat <<EOF > ca.pem
+%s
+EOF
+
+cat <<EOF > etcd.pem
+%s
+EOF
+
+cat <<EOF > etcd-key.pem
+%s
+EOF
+
+sudo mv -v ca.pem etcd.pem etcd-key.pem /var/lib/etcd
+`, ca, etcd, key),
+	}
+}
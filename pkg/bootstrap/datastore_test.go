@@ -198,3 +198,104 @@ func TestGetEtcdMemberIPFieldForDatastore(t *testing.T) {
 
 	assert.Equal(t, "", getEtcdMemberIPFieldForDatastore([]string{}), "it should be equal")
 }
+
+func TestGetEtcdMemberIPFieldForMembers(t *testing.T) {
+	ips := []string{"9.9.9.9", "1.1.1.1", "2.2.2.2"}
+	assert.Equal(t, "infra0=https://9.9.9.9:2380,infra2=https://2.2.2.2:2380", getEtcdMemberIPFieldForMembers(ips, []int{0, 2}), "it should be equal")
+	assert.Equal(t, getEtcdMemberIPFieldForDatastore(ips), getEtcdMemberIPFieldForMembers(ips, []int{0, 1, 2}), "it should be equal")
+}
+
+func TestScriptsDataStoreMembership(t *testing.T) {
+	ca, etcd, key := "-- CA_CERT --", "-- ETCD_CERT --", "-- ETCD_KEY --"
+
+	t.Run("add member", func(t *testing.T) {
+		testHelper.HelperTestTemplate(
+			t,
+			[]ssh.Script{
+				{
+					Name:           "add the etcd member",
+					ScriptExecutor: consts.LinuxBash,
+					CanRetry:       true,
+					MaxRetries:     3,
+					ShellScript: `
+ETCDCTL="sudo etcdctl --endpoints=https://127.0.0.1:2379 --cacert=/var/lib/etcd/ca.pem --cert=/var/lib/etcd/etcd.pem --key=/var/lib/etcd/etcd-key.pem"
+
+if ${ETCDCTL} member list | grep -q "https://9.9.9.9:2380,"; then
+	echo "infra3 is already a member"
+else
+	${ETCDCTL} member add infra3 --peer-urls=https://9.9.9.9:2380
+fi
+`,
+				},
+			},
+			func() ssh.ExecutionPipeline {
+				return scriptAddDataStoreMember(3, "9.9.9.9")
+			},
+		)
+	})
+
+	t.Run("remove member", func(t *testing.T) {
+		testHelper.HelperTestTemplate(
+			t,
+			[]ssh.Script{
+				{
+					Name:           "remove the etcd member",
+					ScriptExecutor: consts.LinuxBash,
+					CanRetry:       true,
+					MaxRetries:     3,
+					ShellScript: `
+ETCDCTL="sudo etcdctl --endpoints=https://127.0.0.1:2379 --cacert=/var/lib/etcd/ca.pem --cert=/var/lib/etcd/etcd.pem --key=/var/lib/etcd/etcd-key.pem"
+
+MEMBER_ID=$(${ETCDCTL} member list | grep -e ", infra3," -e "https://9.9.9.9:2380," | cut -d',' -f1)
+if [ -n "${MEMBER_ID}" ]; then
+	${ETCDCTL} member remove ${MEMBER_ID}
+else
+	echo "infra3 is not a member"
+fi
+`,
+				},
+			},
+			func() ssh.ExecutionPipeline {
+				return scriptRemoveDataStoreMember(3, "9.9.9.9")
+			},
+		)
+	})
+
+	t.Run("roll member", func(t *testing.T) {
+		testHelper.HelperTestTemplate(
+			t,
+			[]ssh.Script{
+				scriptDataStoreCerts(ca, etcd, key),
+				{
+					Name:           "restart etcd and wait for it to be healthy",
+					ScriptExecutor: consts.LinuxBash,
+					CanRetry:       true,
+					MaxRetries:     3,
+					ShellScript: `
+sudo systemctl restart etcd
+
+for i in $(seq 1 30); do
+	if sudo etcdctl --endpoints=https://127.0.0.1:2379 --cacert=/var/lib/etcd/ca.pem --cert=/var/lib/etcd/etcd.pem --key=/var/lib/etcd/etcd-key.pem endpoint health; then
+		exit 0
+	fi
+	sleep 2
+done
+exit 1
+`,
+				},
+			},
+			func() ssh.ExecutionPipeline {
+				return scriptRollDataStore(ca, etcd, key)
+			},
+		)
+	})
+
+	t.Run("join existing cluster", func(t *testing.T) {
+		privIPs := []string{"9.9.9.9", "1.1.1.1"}
+		scripts := scriptDBWithMembers("v3.5.15", ca, etcd, key, privIPs, 1,
+			getEtcdMemberIPFieldForMembers(privIPs, []int{0, 1}), "existing").List()
+
+		assert.Equal(t, len(scripts), 5, "it should be equal")
+		assert.Contains(t, scripts[3].ShellScript, "--initial-cluster-state existing", "it should join the running cluster")
+	})
+}
@@ -17,6 +17,7 @@ package distributions
 import (
 	"github.com/ksctl/ksctl/v2/pkg/addons"
 	"github.com/ksctl/ksctl/v2/pkg/consts"
+	"github.com/ksctl/ksctl/v2/pkg/ssh"
 )
 
 type KubernetesDistribution interface {
@@ -24,9 +25,22 @@ type KubernetesDistribution interface {
 
 	ConfigureControlPlane(int) error
 
+	// JoinControlPlane joins a controlplane to the running cluster when scaling up
+	JoinControlPlane(int) error
+
+	// RemoveControlPlane stops the distribution on a controlplane drained and deleted from the cluster when scaling down
+	RemoveControlPlane(int) error
+
+	// ReconfigureDataStore restarts the distribution on a controlplane with the etcd certificates and the private ips of the datastores given
+	ReconfigureDataStore(idx int, ca, cert, key string, privateEtcdIps []string) error
+
 	JoinWorkerplane(int) error
 
 	K8sVersion(string) KubernetesDistribution
 
 	CNI(addons.ClusterAddons) (externalCNI bool)
+
+	PlanControlPlane(int) []ssh.Script
+
+	PlanWorkerplane(int) []ssh.Script
 }
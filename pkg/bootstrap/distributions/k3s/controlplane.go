@@ -97,41 +97,14 @@ func (p *K3s) ConfigureControlPlane(noOfCP int) error {
 		}
 	} else {
 
-		var script ssh.ExecutionPipeline
-
-		if consts.KsctlValidCNIPlugin(p.Cni) == consts.CNINone {
-			script = scriptCP_NWithoutCNI(
-				p.state.K8sBootstrap.B.CACert,
-				p.state.K8sBootstrap.B.EtcdCert,
-				p.state.K8sBootstrap.B.EtcdKey,
-				*p.state.Versions.K3s,
-				p.state.K8sBootstrap.B.PrivateIPs.DataStores,
-				p.state.K8sBootstrap.B.PublicIPs.LoadBalancer,
-				p.state.K8sBootstrap.B.PrivateIPs.LoadBalancer,
-				p.state.K8sBootstrap.K3s.K3sToken)
-		} else {
-			script = scriptCP_N(
-				p.state.K8sBootstrap.B.CACert,
-				p.state.K8sBootstrap.B.EtcdCert,
-				p.state.K8sBootstrap.B.EtcdKey,
-				*p.state.Versions.K3s,
-				p.state.K8sBootstrap.B.PrivateIPs.DataStores,
-				p.state.K8sBootstrap.B.PublicIPs.LoadBalancer,
-				p.state.K8sBootstrap.B.PrivateIPs.LoadBalancer,
-				p.state.K8sBootstrap.K3s.K3sToken)
-		}
-
-		err := sshExecutor.Flag(consts.UtilExecWithoutOutput).Script(script).
-			IPv4(p.state.K8sBootstrap.B.PublicIPs.ControlPlanes[idx]).
-			FastMode(true).SSHExecute()
-		if err != nil {
+		if err := p.joinControlPlane(sshExecutor, idx); err != nil {
 			return err
 		}
 
 		if idx+1 == len(p.state.K8sBootstrap.B.PublicIPs.ControlPlanes) {
 
 			p.l.Debug(p.ctx, "fetching kubeconfig")
-			err = sshExecutor.Flag(consts.UtilExecWithOutput).Script(scriptKUBECONFIG()).
+			err := sshExecutor.Flag(consts.UtilExecWithOutput).Script(scriptKUBECONFIG()).
 				IPv4(p.state.K8sBootstrap.B.PublicIPs.ControlPlanes[0]).
 				FastMode(true).SSHExecute()
 			if err != nil {
@@ -159,6 +132,150 @@ func (p *K3s) ConfigureControlPlane(noOfCP int) error {
 	return nil
 }
 
+func (p *K3s) joinControlPlane(sshExecutor ssh.RemoteConnection, idx int) error {
+	var script ssh.ExecutionPipeline
+
+	if consts.KsctlValidCNIPlugin(p.Cni) == consts.CNINone {
+		script = scriptCP_NWithoutCNI(
+			p.state.K8sBootstrap.B.CACert,
+			p.state.K8sBootstrap.B.EtcdCert,
+			p.state.K8sBootstrap.B.EtcdKey,
+			*p.state.Versions.K3s,
+			p.state.K8sBootstrap.B.PrivateIPs.DataStores,
+			p.state.K8sBootstrap.B.PublicIPs.LoadBalancer,
+			p.state.K8sBootstrap.B.PrivateIPs.LoadBalancer,
+			p.state.K8sBootstrap.K3s.K3sToken)
+	} else {
+		script = scriptCP_N(
+			p.state.K8sBootstrap.B.CACert,
+			p.state.K8sBootstrap.B.EtcdCert,
+			p.state.K8sBootstrap.B.EtcdKey,
+			*p.state.Versions.K3s,
+			p.state.K8sBootstrap.B.PrivateIPs.DataStores,
+			p.state.K8sBootstrap.B.PublicIPs.LoadBalancer,
+			p.state.K8sBootstrap.B.PrivateIPs.LoadBalancer,
+			p.state.K8sBootstrap.K3s.K3sToken)
+	}
+
+	err := sshExecutor.Flag(consts.UtilExecWithoutOutput).Script(script).
+		IPv4(p.state.K8sBootstrap.B.PublicIPs.ControlPlanes[idx]).
+		FastMode(true).SSHExecute()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// JoinControlPlane joins the controlplane idx to the running cluster with the server token of the controlplane 0
+func (p *K3s) JoinControlPlane(idx int) error {
+	p.mu.Lock()
+	sshExecutor := ssh.NewSSHExecutor(p.ctx, p.l, p.state) //making sure that a new obj gets initialized for a every run thus eleminating possible problems with concurrency
+	if len(p.Cni) == 0 {
+		// the flannel bundled with k3s is recorded as the cni of the cluster, any other got installed after it
+		p.Cni = string(consts.CNINone)
+		if len(p.state.ProvisionerAddons.Cni.Name) != 0 {
+			p.Cni = p.state.ProvisionerAddons.Cni.Name
+		}
+	}
+	p.mu.Unlock()
+
+	p.l.Note(p.ctx, "joining ControlPlane", "number", strconv.Itoa(idx))
+
+	if err := p.joinControlPlane(sshExecutor, idx); err != nil {
+		return err
+	}
+
+	p.l.Success(p.ctx, "joined ControlPlane", "number", strconv.Itoa(idx))
+	return nil
+}
+
+// RemoveControlPlane uninstalls the k3s server of the controlplane idx, the node has to be drained and deleted from the cluster before
+func (p *K3s) RemoveControlPlane(idx int) error {
+	p.mu.Lock()
+	sshExecutor := ssh.NewSSHExecutor(p.ctx, p.l, p.state) //making sure that a new obj gets initialized for a every run thus eleminating possible problems with concurrency
+	p.mu.Unlock()
+
+	p.l.Note(p.ctx, "removing ControlPlane", "number", strconv.Itoa(idx))
+
+	err := sshExecutor.Flag(consts.UtilExecWithoutOutput).Script(scriptUninstallCP()).
+		IPv4(p.state.K8sBootstrap.B.PublicIPs.ControlPlanes[idx]).
+		FastMode(true).SSHExecute()
+	if err != nil {
+		return err
+	}
+
+	p.l.Success(p.ctx, "removed ControlPlane", "number", strconv.Itoa(idx))
+	return nil
+}
+
+// ReconfigureDataStore restarts the k3s server of the controlplane idx with the etcd certificates and the datastore endpoints given,
+// it waits for the api server to be ready again so that reconfiguring one controlplane at a time keeps the cluster reachable
+func (p *K3s) ReconfigureDataStore(idx int, ca, cert, key string, privateEtcdIps []string) error {
+	p.mu.Lock()
+	sshExecutor := ssh.NewSSHExecutor(p.ctx, p.l, p.state) //making sure that a new obj gets initialized for a every run thus eleminating possible problems with concurrency
+	p.mu.Unlock()
+
+	p.l.Note(p.ctx, "reconfiguring datastore of ControlPlane", "number", strconv.Itoa(idx))
+
+	err := sshExecutor.Flag(consts.UtilExecWithoutOutput).Script(scriptReconfigureDataStore(ca, cert, key, privateEtcdIps)).
+		IPv4(p.state.K8sBootstrap.B.PublicIPs.ControlPlanes[idx]).
+		FastMode(true).SSHExecute()
+	if err != nil {
+		return err
+	}
+
+	p.l.Success(p.ctx, "reconfigured datastore of ControlPlane", "number", strconv.Itoa(idx))
+	return nil
+}
+
+func scriptReconfigureDataStore(ca, etcd, key string, privateEtcdIps []string) ssh.ExecutionPipeline {
+
+	collection := ssh.NewExecutionPipeline()
+
+	collection.Append(getScriptForEtcdCerts(ca, etcd, key))
+
+	dbEndpoint := getEtcdMemberIPFieldForControlplane(privateEtcdIps)
+
+	// the installer writes every argument of the server quoted on its own line
+	collection.Append(ssh.Script{
+		Name:           "update datastore endpoint and restart k3s",
+		CanRetry:       true,
+		MaxRetries:     3,
+		ScriptExecutor: consts.LinuxBash,
+		ShellScript: fmt.Sprintf(`
+sudo sed -i "/'--datastore-endpoint'/{n;s#'.*'#'%s'#}" /etc/systemd/system/k3s.service
+sudo systemctl daemon-reload
+sudo systemctl restart k3s
+
+for i in $(seq 1 60); do
+	if sudo k3s kubectl get --raw /readyz &> /dev/null; then
+		exit 0
+	fi
+	sleep 5
+done
+exit 1
+`, dbEndpoint),
+	})
+
+	return collection
+}
+
+func scriptUninstallCP() ssh.ExecutionPipeline {
+
+	collection := ssh.NewExecutionPipeline()
+	collection.Append(ssh.Script{
+		Name:           "Uninstall K3s Controlplane",
+		CanRetry:       true,
+		MaxRetries:     3,
+		ScriptExecutor: consts.LinuxBash,
+		ShellScript: `
+/bin/bash /usr/local/bin/k3s-uninstall.sh &>> ksctl.log || echo "already deleted"
+`,
+	})
+
+	return collection
+}
+
 func getScriptForEtcdCerts(ca, etcd, key string) ssh.Script {
 	return ssh.Script{
 		Name:           "store etcd certificates",
@@ -16,6 +16,7 @@ package k3s
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 
@@ -320,6 +321,37 @@ sudo cat /etc/rancher/k3s/k3s.yaml
 		)
 	})
 
+	t.Run("reconfigure datastore", func(t *testing.T) {
+		testHelper.HelperTestTemplate(
+			t,
+			[]ssh.Script{
+				getScriptForEtcdCerts(ca, etcd, key),
+				{
+					Name:           "update datastore endpoint and restart k3s",
+					CanRetry:       true,
+					MaxRetries:     3,
+					ScriptExecutor: consts.LinuxBash,
+					ShellScript: `
+sudo sed -i "/'--datastore-endpoint'/{n;s#'.*'#'https://9.9.9.9:2379,https://1.1.1.1:2379'#}" /etc/systemd/system/k3s.service
+sudo systemctl daemon-reload
+sudo systemctl restart k3s
+
+for i in $(seq 1 60); do
+	if sudo k3s kubectl get --raw /readyz &> /dev/null; then
+		exit 0
+	fi
+	sleep 5
+done
+exit 1
+`,
+				},
+			},
+			func() ssh.ExecutionPipeline {
+				return scriptReconfigureDataStore(ca, etcd, key, privIP)
+			},
+		)
+	})
+
 }
 
 func TestSciprWorkerplane(t *testing.T) {
@@ -353,7 +385,40 @@ sudo ./worker-setup.sh &>> ksctl.log
 				},
 			},
 			func() ssh.ExecutionPipeline { // Adjust the signature to match your needs
-				return scriptWP(ver, private, token)
+				return scriptWP(ver, private, token, nil, nil)
+			},
+		)
+	})
+
+	t.Run("worker pool labels and taints", func(t *testing.T) {
+		testHelper.HelperTestTemplate(
+			t,
+			[]ssh.Script{
+				{
+					Name:           "Join the workerplane-[0..M]",
+					CanRetry:       true,
+					MaxRetries:     3,
+					ScriptExecutor: consts.LinuxBash,
+					ShellScript: fmt.Sprintf(`%s
+cat <<EOF > worker-setup.sh
+#!/bin/bash
+/bin/bash /usr/local/bin/k3s-agent-uninstall.sh || echo "already deleted"
+export K3S_DEBUG=true
+curl -sfL https://get.k3s.io | INSTALL_K3S_CHANNEL="%s" sh -s - agent --token %s --server https://%s:6443 \
+	--kubelet-arg="kube-reserved=cpu=${KUBE_CPU}m,memory=${KUBE_MEM}Mi" \
+	--kubelet-arg="system-reserved=cpu=100m,memory=200Mi" \
+	--kubelet-arg="eviction-hard=memory.available<100Mi,nodefs.available<10%%,nodefs.inodesFree<5%%,imagefs.available<15%%" \
+	--node-label="ksctl.com/worker-pool=gpu" \
+	--node-taint="gpu=true:NoSchedule"
+EOF
+
+sudo chmod +x worker-setup.sh
+sudo ./worker-setup.sh &>> ksctl.log
+`, distributions.KubeletReservationScript, ver, token, private),
+				},
+			},
+			func() ssh.ExecutionPipeline {
+				return scriptWP(ver, private, token, []string{"ksctl.com/worker-pool=gpu"}, []string{"gpu=true:NoSchedule"})
 			},
 		)
 	})
@@ -395,6 +460,21 @@ func TestOverallScriptsCreation(t *testing.T) {
 		}
 	}
 
+	// scaling the last controlplane out and back in
+	if err := fakeClient.RemoveControlPlane(noCP - 1); err != nil {
+		t.Fatalf("Remove Controlplane unable to operate %v", err)
+	}
+	if err := fakeClient.JoinControlPlane(noCP - 1); err != nil {
+		t.Fatalf("Join Controlplane unable to operate %v", err)
+	}
+
+	// rolling the datastores of every controlplane
+	for no := 0; no < noCP; no++ {
+		if err := fakeClient.ReconfigureDataStore(no, "ca", "cert", "key", fakeStateFromCloud.PrivateIPv4DataStores); err != nil {
+			t.Fatalf("Reconfigure Datastore unable to operate %v", err)
+		}
+	}
+
 }
 
 func TestCNI(t *testing.T) {
@@ -448,3 +528,33 @@ func TestGetEtcdMemberIPFieldForControlplane(t *testing.T) {
 
 	assert.Equal(t, "", getEtcdMemberIPFieldForControlplane([]string{}), "it should be equal")
 }
+
+func TestPlanScripts(t *testing.T) {
+	fakeClient.K8sVersion("")
+	fakeClient.CNI(addons.ClusterAddons{{Label: "k3s", Name: "flannel", IsCNI: true}})
+
+	names := func(scripts []ssh.Script) (out []string) {
+		for _, s := range scripts {
+			out = append(out, s.Name)
+		}
+		return
+	}
+
+	cp0 := fakeClient.PlanControlPlane(0)
+	assert.DeepEqual(t, names(cp0), append(
+		names(scriptCP_1(
+			fakeClient.state.K8sBootstrap.B.CACert,
+			fakeClient.state.K8sBootstrap.B.EtcdCert,
+			fakeClient.state.K8sBootstrap.B.EtcdKey,
+			*fakeClient.state.Versions.K3s,
+			fakeClient.state.K8sBootstrap.B.PrivateIPs.DataStores,
+			fakeClient.state.K8sBootstrap.B.PublicIPs.LoadBalancer,
+			fakeClient.state.K8sBootstrap.B.PrivateIPs.LoadBalancer).List()),
+		"Get k3s server token", "k3s kubeconfig"))
+
+	for _, scripts := range [][]ssh.Script{fakeClient.PlanControlPlane(1), fakeClient.PlanWorkerplane(0)} {
+		assert.Assert(t, len(scripts) > 0)
+		assert.Assert(t, strings.Contains(scripts[len(scripts)-1].ShellScript, planK3sToken),
+			"the token of the first controlplane should be a placeholder")
+	}
+}
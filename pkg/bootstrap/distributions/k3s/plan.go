@@ -0,0 +1,66 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k3s
+
+import (
+	"github.com/ksctl/ksctl/v2/pkg/bootstrap/distributions"
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	"github.com/ksctl/ksctl/v2/pkg/ssh"
+)
+
+// planK3sToken stands in for the token which only exists once the first controlplane is configured
+const planK3sToken = "<k3s-token>"
+
+// PlanControlPlane returns the scripts ConfigureControlPlane would run on the controlplane no
+func (p *K3s) PlanControlPlane(no int) []ssh.Script {
+	b := p.state.K8sBootstrap.B
+	withoutCNI := consts.KsctlValidCNIPlugin(p.Cni) == consts.CNINone
+
+	if no == 0 {
+		var script ssh.ExecutionPipeline
+		if withoutCNI {
+			script = scriptCP_1WithoutCNI(b.CACert, b.EtcdCert, b.EtcdKey, *p.state.Versions.K3s,
+				b.PrivateIPs.DataStores, b.PublicIPs.LoadBalancer, b.PrivateIPs.LoadBalancer)
+		} else {
+			script = scriptCP_1(b.CACert, b.EtcdCert, b.EtcdKey, *p.state.Versions.K3s,
+				b.PrivateIPs.DataStores, b.PublicIPs.LoadBalancer, b.PrivateIPs.LoadBalancer)
+		}
+
+		scripts := append(script.List(), scriptForK3sToken().List()...)
+		if len(b.PublicIPs.ControlPlanes) > 1 {
+			// the kubeconfig is read from the first controlplane once the last one has joined
+			scripts = append(scripts, scriptKUBECONFIG().List()...)
+		}
+		return scripts
+	}
+
+	if withoutCNI {
+		return scriptCP_NWithoutCNI(b.CACert, b.EtcdCert, b.EtcdKey, *p.state.Versions.K3s,
+			b.PrivateIPs.DataStores, b.PublicIPs.LoadBalancer, b.PrivateIPs.LoadBalancer, planK3sToken).List()
+	}
+	return scriptCP_N(b.CACert, b.EtcdCert, b.EtcdKey, *p.state.Versions.K3s,
+		b.PrivateIPs.DataStores, b.PublicIPs.LoadBalancer, b.PrivateIPs.LoadBalancer, planK3sToken).List()
+}
+
+// PlanWorkerplane returns the scripts JoinWorkerplane would run on the workerplane no
+func (p *K3s) PlanWorkerplane(no int) []ssh.Script {
+	return scriptWP(
+		*p.state.Versions.K3s,
+		p.state.K8sBootstrap.B.PrivateIPs.LoadBalancer,
+		planK3sToken,
+		distributions.WorkerPlaneLabels(p.state, no),
+		distributions.WorkerPlaneTaints(p.state, no),
+	).List()
+}
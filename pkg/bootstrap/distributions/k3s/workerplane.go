@@ -37,6 +37,8 @@ func (p *K3s) JoinWorkerplane(no int) error {
 			*p.state.Versions.K3s,
 			p.state.K8sBootstrap.B.PrivateIPs.LoadBalancer,
 			p.state.K8sBootstrap.K3s.K3sToken,
+			distributions.WorkerPlaneLabels(p.state, idx),
+			distributions.WorkerPlaneTaints(p.state, idx),
 		)).
 		IPv4(p.state.K8sBootstrap.B.PublicIPs.WorkerPlanes[idx]).
 		FastMode(true).SSHExecute()
@@ -49,10 +51,18 @@ func (p *K3s) JoinWorkerplane(no int) error {
 	return nil
 }
 
-func scriptWP(ver string, privateIPlb, token string) ssh.ExecutionPipeline {
+func scriptWP(ver string, privateIPlb, token string, labels, taints []string) ssh.ExecutionPipeline {
 
 	collection := ssh.NewExecutionPipeline()
 
+	nodeArgs := ""
+	for _, label := range labels {
+		nodeArgs += fmt.Sprintf(" \\\n\t--node-label=\"%s\"", label)
+	}
+	for _, taint := range taints {
+		nodeArgs += fmt.Sprintf(" \\\n\t--node-taint=\"%s\"", taint)
+	}
+
 	collection.Append(ssh.Script{
 		Name:           "Join the workerplane-[0..M]",
 		CanRetry:       true,
@@ -66,12 +76,12 @@ export K3S_DEBUG=true
 curl -sfL https://get.k3s.io | INSTALL_K3S_CHANNEL="%s" sh -s - agent --token %s --server https://%s:6443 \
 	--kubelet-arg="kube-reserved=cpu=${KUBE_CPU}m,memory=${KUBE_MEM}Mi" \
 	--kubelet-arg="system-reserved=cpu=100m,memory=200Mi" \
-	--kubelet-arg="eviction-hard=memory.available<100Mi,nodefs.available<10%%,nodefs.inodesFree<5%%,imagefs.available<15%%"
+	--kubelet-arg="eviction-hard=memory.available<100Mi,nodefs.available<10%%,nodefs.inodesFree<5%%,imagefs.available<15%%"%s
 EOF
 
 sudo chmod +x worker-setup.sh
 sudo ./worker-setup.sh &>> ksctl.log
-`, distributions.KubeletReservationScript, ver, token, privateIPlb),
+`, distributions.KubeletReservationScript, ver, token, privateIPlb, nodeArgs),
 	})
 
 	return collection
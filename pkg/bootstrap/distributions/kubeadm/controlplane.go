@@ -115,34 +115,7 @@ func (p *Kubeadm) ConfigureControlPlane(noOfCP int) error {
 		}
 	} else {
 
-		installKubeadmTools := scriptTransferEtcdCerts(
-			scriptInstallKubeadmAndOtherTools(*p.state.Versions.Kubeadm),
-			p.state.K8sBootstrap.B.CACert,
-			p.state.K8sBootstrap.B.EtcdCert,
-			p.state.K8sBootstrap.B.EtcdKey)
-
-		p.l.Print(p.ctx, "Installing Kubeadm and copying etcd certificates")
-
-		if err := sshExecutor.Flag(consts.UtilExecWithoutOutput).
-			Script(installKubeadmTools).
-			IPv4(p.state.K8sBootstrap.B.PublicIPs.ControlPlanes[idx]).
-			FastMode(true).
-			SSHExecute(); err != nil {
-			return err
-		}
-
-		p.l.Print(p.ctx, "Joining controlplane to existing cluster")
-		if err := sshExecutor.Flag(consts.UtilExecWithoutOutput).
-			Script(scriptJoinControlplane(
-				distributions.ScriptKubeletDropIn(ssh.NewExecutionPipeline()),
-				p.state.K8sBootstrap.B.PrivateIPs.LoadBalancer,
-				p.state.K8sBootstrap.Kubeadm.BootstrapToken,
-				p.state.K8sBootstrap.Kubeadm.DiscoveryTokenCACertHash,
-				p.state.K8sBootstrap.Kubeadm.CertificateKey,
-			)).
-			IPv4(p.state.K8sBootstrap.B.PublicIPs.ControlPlanes[idx]).
-			FastMode(true).
-			SSHExecute(); err != nil {
+		if err := p.joinControlPlane(sshExecutor, idx); err != nil {
 			return err
 		}
 
@@ -178,6 +151,196 @@ func (p *Kubeadm) ConfigureControlPlane(noOfCP int) error {
 	return nil
 }
 
+func (p *Kubeadm) joinControlPlane(sshExecutor ssh.RemoteConnection, idx int) error {
+	installKubeadmTools := scriptTransferEtcdCerts(
+		scriptInstallKubeadmAndOtherTools(*p.state.Versions.Kubeadm),
+		p.state.K8sBootstrap.B.CACert,
+		p.state.K8sBootstrap.B.EtcdCert,
+		p.state.K8sBootstrap.B.EtcdKey)
+
+	p.l.Print(p.ctx, "Installing Kubeadm and copying etcd certificates")
+
+	if err := sshExecutor.Flag(consts.UtilExecWithoutOutput).
+		Script(installKubeadmTools).
+		IPv4(p.state.K8sBootstrap.B.PublicIPs.ControlPlanes[idx]).
+		FastMode(true).
+		SSHExecute(); err != nil {
+		return err
+	}
+
+	p.l.Print(p.ctx, "Joining controlplane to existing cluster")
+	if err := sshExecutor.Flag(consts.UtilExecWithoutOutput).
+		Script(scriptJoinControlplane(
+			distributions.ScriptKubeletDropIn(ssh.NewExecutionPipeline()),
+			p.state.K8sBootstrap.B.PrivateIPs.LoadBalancer,
+			p.state.K8sBootstrap.Kubeadm.BootstrapToken,
+			p.state.K8sBootstrap.Kubeadm.DiscoveryTokenCACertHash,
+			p.state.K8sBootstrap.Kubeadm.CertificateKey,
+		)).
+		IPv4(p.state.K8sBootstrap.B.PublicIPs.ControlPlanes[idx]).
+		FastMode(true).
+		SSHExecute(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// JoinControlPlane joins the controlplane idx to the running cluster, the bootstrap token and
+// the certificates uploaded by the controlplane 0 are renewed first as both expire long before a scale
+func (p *Kubeadm) JoinControlPlane(idx int) error {
+	p.mu.Lock()
+	sshExecutor := ssh.NewSSHExecutor(p.ctx, p.l, p.state) //making sure that a new obj gets initialized for a every run thus eleminating possible problems with concurrency
+	p.mu.Unlock()
+
+	p.l.Note(p.ctx, "joining ControlPlane", "number", strconv.Itoa(idx))
+
+	if err := p.renewBootstrapToken(sshExecutor); err != nil {
+		return err
+	}
+
+	if err := func() error {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		p.l.Print(p.ctx, "Uploading the controlplane certificates for the certificate key")
+		return sshExecutor.Flag(consts.UtilExecWithoutOutput).
+			Script(scriptUploadCerts(p.state.K8sBootstrap.Kubeadm.CertificateKey)).
+			IPv4(p.state.K8sBootstrap.B.PublicIPs.ControlPlanes[0]).
+			SSHExecute()
+	}(); err != nil {
+		return err
+	}
+
+	if err := p.store.Write(p.state); err != nil {
+		return err
+	}
+
+	if err := p.joinControlPlane(sshExecutor, idx); err != nil {
+		return err
+	}
+
+	p.l.Success(p.ctx, "joined ControlPlane", "number", strconv.Itoa(idx))
+	return nil
+}
+
+// RemoveControlPlane resets the kubeadm of the controlplane idx, the node has to be drained and deleted from the cluster before
+func (p *Kubeadm) RemoveControlPlane(idx int) error {
+	p.mu.Lock()
+	sshExecutor := ssh.NewSSHExecutor(p.ctx, p.l, p.state) //making sure that a new obj gets initialized for a every run thus eleminating possible problems with concurrency
+	p.mu.Unlock()
+
+	p.l.Note(p.ctx, "removing ControlPlane", "number", strconv.Itoa(idx))
+
+	if err := sshExecutor.Flag(consts.UtilExecWithoutOutput).
+		Script(scriptResetControlplane()).
+		IPv4(p.state.K8sBootstrap.B.PublicIPs.ControlPlanes[idx]).
+		FastMode(true).
+		SSHExecute(); err != nil {
+		return err
+	}
+
+	p.l.Success(p.ctx, "removed ControlPlane", "number", strconv.Itoa(idx))
+	return nil
+}
+
+// ReconfigureDataStore restarts the api server of the controlplane idx with the etcd certificates and the datastore endpoints given,
+// it waits for the api server to be ready again so that reconfiguring one controlplane at a time keeps the cluster reachable.
+// The controlplane 0 updates the cluster configuration as well for the controlplanes joining later
+func (p *Kubeadm) ReconfigureDataStore(idx int, ca, cert, key string, privateEtcdIps []string) error {
+	p.mu.Lock()
+	sshExecutor := ssh.NewSSHExecutor(p.ctx, p.l, p.state) //making sure that a new obj gets initialized for a every run thus eleminating possible problems with concurrency
+	p.mu.Unlock()
+
+	p.l.Note(p.ctx, "reconfiguring datastore of ControlPlane", "number", strconv.Itoa(idx))
+
+	if err := sshExecutor.Flag(consts.UtilExecWithoutOutput).
+		Script(scriptReconfigureDataStore(idx == 0, ca, cert, key, privateEtcdIps)).
+		IPv4(p.state.K8sBootstrap.B.PublicIPs.ControlPlanes[idx]).
+		FastMode(true).
+		SSHExecute(); err != nil {
+		return err
+	}
+
+	p.l.Success(p.ctx, "reconfigured datastore of ControlPlane", "number", strconv.Itoa(idx))
+	return nil
+}
+
+func getEtcdServers(ips []string) string {
+	servers := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		servers = append(servers, fmt.Sprintf("https://%s:2379", ip))
+	}
+	return strings.Join(servers, ",")
+}
+
+func scriptReconfigureDataStore(updateClusterConfiguration bool, ca, etcd, key string, privateIPDs []string) ssh.ExecutionPipeline {
+	collection := scriptTransferEtcdCerts(ssh.NewExecutionPipeline(), ca, etcd, key)
+
+	etcdServers := getEtcdServers(privateIPDs)
+
+	// the kubelet recreates the api server only for a changed manifest, while the certificates are read once at its start
+	collection.Append(ssh.Script{
+		Name:           "update etcd servers and restart api server",
+		CanRetry:       true,
+		MaxRetries:     3,
+		ScriptExecutor: consts.LinuxBash,
+		ShellScript: fmt.Sprintf(`
+MANIFEST=/etc/kubernetes/manifests/kube-apiserver.yaml
+
+if sudo grep -q -- "--etcd-servers=%s$" ${MANIFEST}; then
+	sudo crictl --runtime-endpoint unix:///var/run/containerd/containerd.sock ps --name kube-apiserver -q | \
+		xargs -r sudo crictl --runtime-endpoint unix:///var/run/containerd/containerd.sock stop
+else
+	sudo sed -i "s#--etcd-servers=.*#--etcd-servers=%s#" ${MANIFEST}
+fi
+
+sleep 30
+for i in $(seq 1 60); do
+	if sudo kubectl --kubeconfig=/etc/kubernetes/admin.conf --server=https://127.0.0.1:6443 get --raw /readyz &> /dev/null; then
+		exit 0
+	fi
+	sleep 5
+done
+exit 1
+`, etcdServers, etcdServers),
+	})
+
+	if updateClusterConfiguration {
+		collection.Append(ssh.Script{
+			Name:           "update etcd endpoints of the cluster configuration",
+			CanRetry:       true,
+			MaxRetries:     3,
+			ScriptExecutor: consts.LinuxBash,
+			ShellScript: fmt.Sprintf(`
+KUBECTL="sudo kubectl --kubeconfig=/etc/kubernetes/admin.conf --server=https://127.0.0.1:6443"
+
+${KUBECTL} -n kube-system get configmap kubeadm-config -o jsonpath='{.data.ClusterConfiguration}' > cluster-configuration.yml
+
+awk -v endpoints="%s" '
+/^ *endpoints:/ {
+	print
+	indent = $0
+	sub(/endpoints:.*/, "", indent)
+	n = split(endpoints, e, ",")
+	for (i = 1; i <= n; i++) print indent "- " e[i]
+	skip = 1
+	next
+}
+skip && /^ *- / { next }
+{ skip = 0; print }
+' cluster-configuration.yml > cluster-configuration.new.yml
+
+${KUBECTL} -n kube-system create configmap kubeadm-config --from-file=ClusterConfiguration=cluster-configuration.new.yml --dry-run=client -o yaml | \
+	${KUBECTL} apply -f -
+
+rm -f cluster-configuration.yml cluster-configuration.new.yml
+`, etcdServers),
+		})
+	}
+
+	return collection
+}
+
 func generateExternalEtcdConfig(ips []string) string {
 	var ret strings.Builder
 	for _, ip := range ips {
@@ -386,3 +549,32 @@ sudo kubeadm join %s:6443 --token %s --discovery-token-ca-cert-hash sha256:%s --
 	})
 	return collection
 }
+
+func scriptUploadCerts(certKey string) ssh.ExecutionPipeline {
+	collection := ssh.NewExecutionPipeline()
+	collection.Append(ssh.Script{
+		Name:           "upload controlplane certificates",
+		CanRetry:       true,
+		MaxRetries:     3,
+		ScriptExecutor: consts.LinuxBash,
+		ShellScript: fmt.Sprintf(`
+sudo kubeadm init phase upload-certs --upload-certs --certificate-key %s &>> ksctl.log
+`, certKey),
+	})
+	return collection
+}
+
+func scriptResetControlplane() ssh.ExecutionPipeline {
+	collection := ssh.NewExecutionPipeline()
+	collection.Append(ssh.Script{
+		Name:           "reset controlplane",
+		CanRetry:       true,
+		MaxRetries:     3,
+		ScriptExecutor: consts.LinuxBash,
+		ShellScript: `
+sudo kubeadm reset --force &>> ksctl.log
+sudo rm -rf /etc/cni/net.d $HOME/.kube
+`,
+	})
+	return collection
+}
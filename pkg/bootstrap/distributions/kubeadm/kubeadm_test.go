@@ -16,6 +16,8 @@ package kubeadm
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/ksctl/ksctl/v2/pkg/addons"
@@ -95,6 +97,115 @@ kubeadm token create --ttl 20m --description "ksctl bootstrap token"
 	})
 }
 
+func TestScriptsScaleControlplane(t *testing.T) {
+
+	t.Run("scriptUploadCerts", func(t *testing.T) {
+		testHelper.HelperTestTemplate(
+			t,
+			[]ssh.Script{
+				{
+					Name:           "upload controlplane certificates",
+					CanRetry:       true,
+					MaxRetries:     3,
+					ScriptExecutor: consts.LinuxBash,
+					ShellScript: `
+sudo kubeadm init phase upload-certs --upload-certs --certificate-key fake-key &>> ksctl.log
+`,
+				},
+			},
+			func() ssh.ExecutionPipeline {
+				return scriptUploadCerts("fake-key")
+			},
+		)
+	})
+
+	t.Run("scriptResetControlplane", func(t *testing.T) {
+		testHelper.HelperTestTemplate(
+			t,
+			[]ssh.Script{
+				{
+					Name:           "reset controlplane",
+					CanRetry:       true,
+					MaxRetries:     3,
+					ScriptExecutor: consts.LinuxBash,
+					ShellScript: `
+sudo kubeadm reset --force &>> ksctl.log
+sudo rm -rf /etc/cni/net.d $HOME/.kube
+`,
+				},
+			},
+			func() ssh.ExecutionPipeline {
+				return scriptResetControlplane()
+			},
+		)
+	})
+
+	t.Run("scriptReconfigureDataStore", func(t *testing.T) {
+		ca, etcd, key := "-- CA_CERT --", "-- ETCD_CERT --", "-- ETCD_KEY --"
+		restart := ssh.Script{
+			Name:           "update etcd servers and restart api server",
+			CanRetry:       true,
+			MaxRetries:     3,
+			ScriptExecutor: consts.LinuxBash,
+			ShellScript: `
+MANIFEST=/etc/kubernetes/manifests/kube-apiserver.yaml
+
+if sudo grep -q -- "--etcd-servers=https://9.9.9.9:2379,https://1.1.1.1:2379$" ${MANIFEST}; then
+	sudo crictl --runtime-endpoint unix:///var/run/containerd/containerd.sock ps --name kube-apiserver -q | \
+		xargs -r sudo crictl --runtime-endpoint unix:///var/run/containerd/containerd.sock stop
+else
+	sudo sed -i "s#--etcd-servers=.*#--etcd-servers=https://9.9.9.9:2379,https://1.1.1.1:2379#" ${MANIFEST}
+fi
+
+sleep 30
+for i in $(seq 1 60); do
+	if sudo kubectl --kubeconfig=/etc/kubernetes/admin.conf --server=https://127.0.0.1:6443 get --raw /readyz &> /dev/null; then
+		exit 0
+	fi
+	sleep 5
+done
+exit 1
+`,
+		}
+
+		testHelper.HelperTestTemplate(
+			t,
+			[]ssh.Script{
+				{
+					Name:           "save etcd certificate",
+					CanRetry:       false,
+					ScriptExecutor: consts.LinuxBash,
+					ShellScript: fmt.Sprintf(`
+sudo mkdir -vp /etcd/kubernetes/pki/etcd/
+
+cat <<EOF > ca.pem
+%s
+EOF
+
+cat <<EOF > etcd.pem
+%s
+EOF
+
+cat <<EOF > etcd-key.pem
+%s
+EOF
+
+sudo mv -v ca.pem etcd.pem etcd-key.pem /etcd/kubernetes/pki/etcd
+`, ca, etcd, key),
+				},
+				restart,
+			},
+			func() ssh.ExecutionPipeline {
+				return scriptReconfigureDataStore(false, ca, etcd, key, []string{"9.9.9.9", "1.1.1.1"})
+			},
+		)
+
+		pipeline := scriptReconfigureDataStore(true, ca, etcd, key, []string{"9.9.9.9", "1.1.1.1"})
+		last := pipeline.List()
+		assert.Equal(t, last[len(last)-1].Name, "update etcd endpoints of the cluster configuration")
+	})
+}
+
 func TestScriptInstallKubeadmAndOtherTools(t *testing.T) {
 	ver := "v1.31"
 
@@ -498,6 +609,21 @@ func TestOverallScriptsCreation(t *testing.T) {
 		}
 	}
 
+	// scaling the last controlplane out and back in
+	if err := fakeClient.RemoveControlPlane(noCP - 1); err != nil {
+		t.Fatalf("Remove Controlplane unable to operate %v", err)
+	}
+	if err := fakeClient.JoinControlPlane(noCP - 1); err != nil {
+		t.Fatalf("Join Controlplane unable to operate %v", err)
+	}
+
+	// rolling the datastores of every controlplane
+	for no := 0; no < noCP; no++ {
+		if err := fakeClient.ReconfigureDataStore(no, "ca", "cert", "key", fakeStateFromCloud.PrivateIPv4DataStores); err != nil {
+			t.Fatalf("Reconfigure Datastore unable to operate %v", err)
+		}
+	}
+
 }
 
 func TestCNI(t *testing.T) {
@@ -532,3 +658,29 @@ func TestCNI(t *testing.T) {
 		assert.Equal(t, got, v.Valid, "missmatch in return value")
 	}
 }
+
+func TestPlanScripts(t *testing.T) {
+	fakeClient.K8sVersion("")
+
+	names := func(scripts []ssh.Script) (out []string) {
+		for _, s := range scripts {
+			out = append(out, s.Name)
+		}
+		return
+	}
+
+	cp0 := names(fakeClient.PlanControlPlane(0))
+	assert.Equal(t, cp0[len(cp0)-1], "fetch kubeconfig")
+	assert.Assert(t, slices.Contains(cp0, "kubeadm init"))
+	assert.Assert(t, slices.Contains(cp0, "fetch bootstrap certificate key"))
+
+	cpN := fakeClient.PlanControlPlane(1)
+	assert.Equal(t, cpN[len(cpN)-1].Name, "Join Controlplane [1..N]")
+	for _, placeholder := range []string{planBootstrapToken, planCertificateKey, planDiscoveryTokenCACertHash} {
+		assert.Assert(t, strings.Contains(cpN[len(cpN)-1].ShellScript, placeholder))
+	}
+
+	wp := fakeClient.PlanWorkerplane(0)
+	assert.Assert(t, strings.Contains(wp[len(wp)-1].ShellScript, planBootstrapToken))
+	assert.Assert(t, strings.Contains(wp[len(wp)-1].ShellScript, planDiscoveryTokenCACertHash))
+}
@@ -0,0 +1,82 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubeadm
+
+import (
+	"github.com/ksctl/ksctl/v2/pkg/bootstrap/distributions"
+	"github.com/ksctl/ksctl/v2/pkg/ssh"
+)
+
+// placeholders for the values which only exist once the first controlplane is configured
+const (
+	planBootstrapToken           = "<kubeadm-bootstrap-token>"
+	planCertificateKey           = "<kubeadm-certificate-key>"
+	planDiscoveryTokenCACertHash = "<kubeadm-discovery-token-ca-cert-hash>"
+)
+
+// PlanControlPlane returns the scripts ConfigureControlPlane would run on the controlplane no
+func (p *Kubeadm) PlanControlPlane(no int) []ssh.Script {
+	b := p.state.K8sBootstrap.B
+
+	scripts := scriptTransferEtcdCerts(
+		scriptInstallKubeadmAndOtherTools(*p.state.Versions.Kubeadm),
+		b.CACert,
+		b.EtcdCert,
+		b.EtcdKey).List()
+
+	if no != 0 {
+		return append(scripts, scriptJoinControlplane(
+			distributions.ScriptKubeletDropIn(ssh.NewExecutionPipeline()),
+			b.PrivateIPs.LoadBalancer,
+			planBootstrapToken,
+			planDiscoveryTokenCACertHash,
+			planCertificateKey,
+		).List()...)
+	}
+
+	scripts = append(scripts, scriptGetCertificateKey().List()...)
+	scripts = append(scripts, scriptToGenerateBootStrapToken().List()...)
+	scripts = append(scripts, scriptAddKubeadmControlplane0(
+		distributions.ScriptKubeletDropIn(ssh.NewExecutionPipeline()),
+		*p.state.Versions.Kubeadm,
+		planBootstrapToken,
+		planCertificateKey,
+		b.PrivateIPs.LoadBalancer,
+		b.PublicIPs.LoadBalancer,
+		b.PrivateIPs.DataStores,
+	).List()...)
+	scripts = append(scripts, scriptDiscoveryTokenCACertHash().List()...)
+
+	if len(b.PublicIPs.ControlPlanes) > 1 {
+		// the kubeconfig is read from the first controlplane once the last one has joined
+		scripts = append(scripts, scriptGetKubeconfig().List()...)
+	}
+	return scripts
+}
+
+// PlanWorkerplane returns the scripts JoinWorkerplane would run on the workerplane no
+func (p *Kubeadm) PlanWorkerplane(no int) []ssh.Script {
+	return scriptJoinWorkerplane(
+		distributions.ScriptKubeletNodeLabels(
+			distributions.ScriptKubeletDropIn(
+				scriptInstallKubeadmAndOtherTools(*p.state.Versions.Kubeadm)),
+			distributions.WorkerPlaneLabels(p.state, no),
+			distributions.WorkerPlaneTaints(p.state, no),
+		),
+		p.state.K8sBootstrap.B.PrivateIPs.LoadBalancer,
+		planBootstrapToken,
+		planDiscoveryTokenCACertHash,
+	).List()
+}
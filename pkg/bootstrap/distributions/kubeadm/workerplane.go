@@ -34,36 +34,7 @@ func (p *Kubeadm) JoinWorkerplane(noOfWP int) error {
 
 	p.l.Note(p.ctx, "configuring Workerplane", "number", strconv.Itoa(idx))
 
-	if err := func() error {
-		p.mu.Lock()
-		defer p.mu.Unlock()
-		p.l.Print(p.ctx, "Checking validity Kubeadm Bootstrap Token")
-
-		tN := time.Now().UTC()
-		tM := p.state.K8sBootstrap.Kubeadm.BootstrapTokenExpireTimeUtc
-		tDiff := tM.Sub(tN)
-
-		p.l.Debug(p.ctx, "printing debug", "tNow", tN, "tExpire", tM, "tDiff", tDiff)
-
-		// time.After means expire time is after the current time
-		if tM.After(tN) && tDiff.Minutes() > 10 {
-			p.l.Success(p.ctx, "Valid Kubeadm Bootstrap Token")
-			return nil
-		} else {
-			p.l.Note(p.ctx, "Regenerating Kubeadm Bootstrap Token ttl is near")
-			timeCreationBootStrapToken := time.Now().UTC()
-			if err := sshExecutor.Flag(consts.UtilExecWithOutput).
-				Script(scriptToRenewBootStrapToken()).
-				IPv4(p.state.K8sBootstrap.B.PublicIPs.ControlPlanes[0]).
-				SSHExecute(); err != nil {
-				return err
-			}
-			p.state.K8sBootstrap.Kubeadm.BootstrapToken = strings.Trim(sshExecutor.GetOutput()[0], "\n")
-			p.state.K8sBootstrap.Kubeadm.BootstrapTokenExpireTimeUtc = timeCreationBootStrapToken
-		}
-
-		return nil
-	}(); err != nil {
+	if err := p.renewBootstrapToken(sshExecutor); err != nil {
 		return err
 	}
 
@@ -72,8 +43,12 @@ func (p *Kubeadm) JoinWorkerplane(noOfWP int) error {
 	}
 
 	script := scriptJoinWorkerplane(
-		distributions.ScriptKubeletDropIn(
-			scriptInstallKubeadmAndOtherTools(*p.state.Versions.Kubeadm)),
+		distributions.ScriptKubeletNodeLabels(
+			distributions.ScriptKubeletDropIn(
+				scriptInstallKubeadmAndOtherTools(*p.state.Versions.Kubeadm)),
+			distributions.WorkerPlaneLabels(p.state, idx),
+			distributions.WorkerPlaneTaints(p.state, idx),
+		),
 		p.state.K8sBootstrap.B.PrivateIPs.LoadBalancer,
 		p.state.K8sBootstrap.Kubeadm.BootstrapToken,
 		p.state.K8sBootstrap.Kubeadm.DiscoveryTokenCACertHash,
@@ -107,3 +82,36 @@ sudo kubeadm join %s:6443 --token %s --discovery-token-ca-cert-hash sha256:%s &>
 
 	return collection
 }
+
+// renewBootstrapToken creates a new bootstrap token on the controlplane 0 once the one in the state is about to expire
+func (p *Kubeadm) renewBootstrapToken(sshExecutor ssh.RemoteConnection) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.l.Print(p.ctx, "Checking validity Kubeadm Bootstrap Token")
+
+	tN := time.Now().UTC()
+	tM := p.state.K8sBootstrap.Kubeadm.BootstrapTokenExpireTimeUtc
+	tDiff := tM.Sub(tN)
+
+	p.l.Debug(p.ctx, "printing debug", "tNow", tN, "tExpire", tM, "tDiff", tDiff)
+
+	// time.After means expire time is after the current time
+	if tM.After(tN) && tDiff.Minutes() > 10 {
+		p.l.Success(p.ctx, "Valid Kubeadm Bootstrap Token")
+		return nil
+	}
+
+	p.l.Note(p.ctx, "Regenerating Kubeadm Bootstrap Token ttl is near")
+	timeCreationBootStrapToken := time.Now().UTC()
+	if err := sshExecutor.Flag(consts.UtilExecWithOutput).
+		Script(scriptToRenewBootStrapToken()).
+		IPv4(p.state.K8sBootstrap.B.PublicIPs.ControlPlanes[0]).
+		SSHExecute(); err != nil {
+		return err
+	}
+	p.state.K8sBootstrap.Kubeadm.BootstrapToken = strings.Trim(sshExecutor.GetOutput()[0], "\n")
+	// same as the ttl of the token in scriptToRenewBootStrapToken
+	p.state.K8sBootstrap.Kubeadm.BootstrapTokenExpireTimeUtc = timeCreationBootStrapToken.Add(20 * time.Minute)
+
+	return nil
+}
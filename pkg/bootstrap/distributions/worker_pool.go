@@ -0,0 +1,81 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distributions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	"github.com/ksctl/ksctl/v2/pkg/ssh"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+)
+
+// LabelWorkerPool is the node label holding the name of the worker pool of a workerplane
+const LabelWorkerPool = "ksctl.com/worker-pool"
+
+// WorkerPlaneLabels returns the node labels of the workerplane at the index in the key=value form sorted by key,
+// the workerplanes without a pool have none
+func WorkerPlaneLabels(state *statefile.StorageDocument, no int) []string {
+	pool := state.WorkerPoolOf(no)
+	if pool == nil {
+		return nil
+	}
+
+	labels := []string{LabelWorkerPool + "=" + pool.Name}
+	for k, v := range pool.Labels {
+		labels = append(labels, k+"="+v)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// WorkerPlaneTaints returns the taints of the workerplane at the index in the key[=value]:Effect form
+func WorkerPlaneTaints(state *statefile.StorageDocument, no int) []string {
+	pool := state.WorkerPoolOf(no)
+	if pool == nil {
+		return nil
+	}
+	return pool.Taints
+}
+
+// ScriptKubeletNodeLabels appends the step registering the node with the labels and taints to the given
+// execution pipeline, the kubelet args are added to the ones written by ScriptKubeletDropIn.
+// Nothing is appended when there are neither labels nor taints
+func ScriptKubeletNodeLabels(collection ssh.ExecutionPipeline, labels, taints []string) ssh.ExecutionPipeline {
+	if len(labels) == 0 && len(taints) == 0 {
+		return collection
+	}
+
+	args := "--config-dir=/etc/kubernetes/kubelet.conf.d"
+	if len(labels) != 0 {
+		args += " --node-labels=" + strings.Join(labels, ",")
+	}
+	if len(taints) != 0 {
+		args += " --register-with-taints=" + strings.Join(taints, ",")
+	}
+
+	collection.Append(ssh.Script{
+		Name:           "register the node with the labels and taints of its worker pool",
+		CanRetry:       false,
+		ScriptExecutor: consts.LinuxBash,
+		ShellScript: fmt.Sprintf(`
+echo 'KUBELET_EXTRA_ARGS=%s' | sudo tee /etc/default/kubelet > /dev/null
+`, args),
+	})
+
+	return collection
+}
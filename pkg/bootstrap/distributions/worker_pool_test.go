@@ -0,0 +1,89 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distributions
+
+import (
+	"testing"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	"github.com/ksctl/ksctl/v2/pkg/ssh"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"gotest.tools/v3/assert"
+)
+
+func TestWorkerPlaneLabelsAndTaints(t *testing.T) {
+	state := &statefile.StorageDocument{
+		WorkerPools: []statefile.WorkerPool{
+			{Name: statefile.DefaultWorkerPool, VMSize: "small", Count: 1},
+			{
+				Name:   "gpu",
+				VMSize: "large",
+				Count:  1,
+				Labels: map[string]string{"accelerator": "nvidia", "app.example.com/tier": "ml"},
+				Taints: []string{"gpu=true:NoSchedule"},
+			},
+		},
+		WorkerPlanePools: []string{statefile.DefaultWorkerPool, "gpu"},
+	}
+
+	assert.DeepEqual(t, WorkerPlaneLabels(state, 0), []string{"ksctl.com/worker-pool=default"})
+	assert.Equal(t, len(WorkerPlaneTaints(state, 0)), 0)
+
+	assert.DeepEqual(t, WorkerPlaneLabels(state, 1), []string{
+		"accelerator=nvidia",
+		"app.example.com/tier=ml",
+		"ksctl.com/worker-pool=gpu",
+	})
+	assert.DeepEqual(t, WorkerPlaneTaints(state, 1), []string{"gpu=true:NoSchedule"})
+
+	// the workerplanes of the clusters created before the worker pools
+	assert.Equal(t, len(WorkerPlaneLabels(state, 2)), 0)
+	assert.Equal(t, len(WorkerPlaneLabels(&statefile.StorageDocument{}, 0)), 0)
+}
+
+func TestScriptKubeletNodeLabels(t *testing.T) {
+	t.Run("without labels and taints", func(t *testing.T) {
+		ssh.HelperTestTemplate(
+			t,
+			[]ssh.Script{},
+			func() ssh.ExecutionPipeline {
+				return ScriptKubeletNodeLabels(ssh.NewExecutionPipeline(), nil, nil)
+			},
+		)
+	})
+
+	t.Run("with labels and taints", func(t *testing.T) {
+		ssh.HelperTestTemplate(
+			t,
+			[]ssh.Script{
+				{
+					Name:           "register the node with the labels and taints of its worker pool",
+					CanRetry:       false,
+					ScriptExecutor: consts.LinuxBash,
+					ShellScript: `
+echo 'KUBELET_EXTRA_ARGS=--config-dir=/etc/kubernetes/kubelet.conf.d --node-labels=a=b,ksctl.com/worker-pool=gpu --register-with-taints=gpu=true:NoSchedule,spot:NoExecute' | sudo tee /etc/default/kubelet > /dev/null
+`,
+				},
+			},
+			func() ssh.ExecutionPipeline {
+				return ScriptKubeletNodeLabels(
+					ssh.NewExecutionPipeline(),
+					[]string{"a=b", "ksctl.com/worker-pool=gpu"},
+					[]string{"gpu=true:NoSchedule", "spot:NoExecute"},
+				)
+			},
+		)
+	})
+}
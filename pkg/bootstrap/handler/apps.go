@@ -0,0 +1,106 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"encoding/json"
+
+	"github.com/ksctl/ksctl/v2/pkg/addons"
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/events"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+)
+
+const (
+	// KcmSku is the ksctl cluster manager, the app which can be asked for along the creation of a cluster
+	KcmSku = "kcm"
+
+	KcmNamespace     = "ksctl-system"
+	KcmConfigMapName = "cluster-config"
+)
+
+// AppsOf returns the apps among the ksctl addons, the cni and the addons ksctl has no app for are left out
+func AppsOf(ca addons.ClusterAddons) addons.ClusterAddons {
+	var apps addons.ClusterAddons
+	for _, addon := range ca.GetAddons(string(consts.K8sKsctl)) {
+		if !addon.IsCNI && addon.Name == KcmSku {
+			apps = append(apps, addon)
+		}
+	}
+	return apps
+}
+
+// appVersion returns the version the app is asked for, the config of an app is like {"version": "v0.1.0"}
+func (kc *Controller) appVersion(app addons.ClusterAddon) (string, error) {
+	var config struct {
+		Version string `json:"version"`
+	}
+	if app.Config != nil {
+		if err := json.Unmarshal([]byte(*app.Config), &config); err != nil {
+			return "", ksctlErrors.WrapError(
+				ksctlErrors.ErrInvalidKsctlClusterAddons,
+				kc.l.NewError(kc.ctx, "failed to deserialize the app config", "app", app.Name, "Reason", err),
+			)
+		}
+	}
+	if len(config.Version) == 0 {
+		return "", ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidKsctlClusterAddons,
+			kc.l.NewError(kc.ctx, "the version of the app is missing in its config", "app", app.Name),
+		)
+	}
+	return config.Version, nil
+}
+
+// installApps installs the apps asked for along the creation once the cni is up, every app is a step of the apps phase
+func (kc *Controller) installApps() error {
+	for _, app := range AppsOf(kc.p.Metadata.Addons) {
+		if kc.cp.Done(statefile.PhaseApps, app.Name) {
+			continue
+		}
+
+		done := events.Step(kc.ctx, events.Event{
+			Phase:    string(statefile.PhaseApps),
+			Resource: "addon",
+			Name:     app.Name,
+		})
+		err := kc.installApp(app)
+		done(err)
+		if err := kc.cp.Checkpoint(statefile.PhaseApps, app.Name, err); err != nil {
+			return err
+		}
+		kc.l.Success(kc.ctx, "Done with installing the app", "app", app.Name)
+	}
+	return nil
+}
+
+func (kc *Controller) installApp(app addons.ClusterAddon) error {
+	for _, installed := range kc.s.ProvisionerAddons.Apps {
+		if installed.Name == app.Name && installed.For == consts.K8sKsctl {
+			return nil
+		}
+	}
+
+	version, err := kc.appVersion(app)
+	if err != nil {
+		return err
+	}
+	return kc.InstallKcm(&kc.s.ClusterKubeConfig, KcmNamespace, KcmConfigMapName, statefile.SlimProvisionerAddon{
+		Name:    KcmSku,
+		For:     consts.K8sKsctl,
+		Version: &version,
+	})
+}
@@ -18,13 +18,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"sync"
 
 	"github.com/ksctl/ksctl/v2/pkg/addons"
 	"github.com/ksctl/ksctl/v2/pkg/apps/stack"
+	"github.com/ksctl/ksctl/v2/pkg/events"
 	"github.com/ksctl/ksctl/v2/pkg/k8s"
 	"github.com/ksctl/ksctl/v2/pkg/provider"
 	"github.com/ksctl/ksctl/v2/pkg/utilities"
+	"github.com/ksctl/ksctl/v2/pkg/waiter"
 
 	"github.com/ksctl/ksctl/v2/pkg/bootstrap"
 	"github.com/ksctl/ksctl/v2/pkg/config"
@@ -46,6 +49,11 @@ type Controller struct {
 	p   *controller.Client
 	b   *controller.Controller
 	s   *statefile.StorageDocument
+
+	// plan is set when the controller only plans the scripts without connecting to the nodes
+	plan bool
+
+	cp *controller.Checkpoints
 }
 
 func NewController(
@@ -64,6 +72,7 @@ func NewController(
 	cc.b = baseController
 	cc.p = controllerPayload
 	cc.s = state
+	cc.cp = controller.NewCheckpoints(state, controllerPayload.Storage)
 
 	if controllerPayload.Metadata.ClusterType == consts.ClusterTypeSelfMang {
 		err := cc.setupInterfaces(operation, transferableInfraState)
@@ -114,6 +123,21 @@ func (kc *Controller) setupInterfaces(
 		return kc.l.NewError(kc.ctx, "Invalid k8s provider")
 	}
 
+	if kc.plan {
+		kc.p.PreBootstrap.PlanSetup(transferableInfraState, operation)
+		return nil
+	}
+
+	// the etcd certificates are kept once a datastore got configured with them by a previous attempt
+	if operation == consts.OperationCreate && kc.s.K8sBootstrap != nil {
+		for no := range transferableInfraState.PrivateIPv4DataStores {
+			if kc.cp.Done(statefile.PhaseDataStore, strconv.Itoa(no)) {
+				operation = consts.OperationGet
+				break
+			}
+		}
+	}
+
 	if errTransfer := kc.p.PreBootstrap.Setup(transferableInfraState, operation); errTransfer != nil {
 		kc.l.Error("handled error", "catch", errTransfer)
 		return errTransfer
@@ -121,28 +145,54 @@ func (kc *Controller) setupInterfaces(
 	return nil
 }
 
+// track runs fn as the configuration of a node, reporting its start and its outcome to the events
+func (kc *Controller) track(phase statefile.CreationPhase, role consts.KsctlRole, no int, fn func() error) error {
+	done := events.Step(kc.ctx, events.Event{
+		Phase:    string(phase),
+		Resource: "node",
+		Role:     role,
+		Index:    no,
+	})
+	err := fn()
+	done(err)
+	return err
+}
+
 func (kc *Controller) ConfigureCluster() (bool, error) {
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+		return false, err
+	}
+
 	waitForPre := &sync.WaitGroup{}
 
 	errChanLB := make(chan error, 1)
 	errChanDS := make(chan error, kc.p.Metadata.NoDS)
 
-	waitForPre.Add(1 + kc.p.Metadata.NoDS)
-
-	go func() {
-		defer waitForPre.Done()
+	if !kc.cp.Done(statefile.PhaseLoadBalancer, "") {
+		waitForPre.Add(1)
+		go func() {
+			defer waitForPre.Done()
 
-		err := kc.p.PreBootstrap.ConfigureLoadbalancer()
-		if err != nil {
-			errChanLB <- err
-		}
-	}()
+			err := kc.track(statefile.PhaseLoadBalancer, consts.RoleLb, 0, kc.p.PreBootstrap.ConfigureLoadbalancer)
+			kc.cp.Record(statefile.PhaseLoadBalancer, "", err)
+			if err != nil {
+				errChanLB <- err
+			}
+		}()
+	}
 
 	for no := 0; no < kc.p.Metadata.NoDS; no++ {
+		if kc.cp.Done(statefile.PhaseDataStore, strconv.Itoa(no)) {
+			continue
+		}
+		waitForPre.Add(1)
 		go func(i int) {
 			defer waitForPre.Done()
 
-			err := kc.p.PreBootstrap.ConfigureDataStore(i, kc.p.Metadata.EtcdVersion)
+			err := kc.track(statefile.PhaseDataStore, consts.RoleDs, i, func() error {
+				return kc.p.PreBootstrap.ConfigureDataStore(i, kc.p.Metadata.EtcdVersion)
+			})
+			kc.cp.Record(statefile.PhaseDataStore, strconv.Itoa(i), err)
 			if err != nil {
 				errChanDS <- err
 			}
@@ -152,6 +202,8 @@ func (kc *Controller) ConfigureCluster() (bool, error) {
 	close(errChanLB)
 	close(errChanDS)
 
+	errFlush := kc.cp.Flush()
+
 	for err := range errChanLB {
 		if err != nil {
 			return false, err
@@ -163,8 +215,16 @@ func (kc *Controller) ConfigureCluster() (bool, error) {
 			return false, err
 		}
 	}
+	if errFlush != nil {
+		return false, errFlush
+	}
 
-	if err := kc.p.Bootstrap.Setup(consts.OperationCreate); err != nil {
+	// the tokens of the distribution are kept once the first controlplane got configured
+	setupOperation := consts.OperationCreate
+	if kc.cp.Done(statefile.PhaseControlPlane0, "") {
+		setupOperation = consts.OperationGet
+	}
+	if err := kc.p.Bootstrap.Setup(setupOperation); err != nil {
 		return false, err
 	}
 
@@ -175,9 +235,21 @@ func (kc *Controller) ConfigureCluster() (bool, error) {
 		return false, kc.l.NewError(kc.ctx, "invalid version of self-managed k8s cluster")
 	}
 
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+		return false, err
+	}
+
 	// wp[0,N] depends on cp[0]
-	err := kc.p.Bootstrap.ConfigureControlPlane(0)
-	if err != nil {
+	if !kc.cp.Done(statefile.PhaseControlPlane0, "") {
+		err := kc.track(statefile.PhaseControlPlane0, consts.RoleCp, 0, func() error {
+			return kc.p.Bootstrap.ConfigureControlPlane(0)
+		})
+		if err := kc.cp.Checkpoint(statefile.PhaseControlPlane0, "", err); err != nil {
+			return false, err
+		}
+	}
+
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
 		return false, err
 	}
 
@@ -186,21 +258,33 @@ func (kc *Controller) ConfigureCluster() (bool, error) {
 
 	wg := &sync.WaitGroup{}
 
-	wg.Add(kc.p.Metadata.NoCP - 1 + kc.p.Metadata.NoWP)
-
 	for no := 1; no < kc.p.Metadata.NoCP; no++ {
+		if kc.cp.Done(statefile.PhaseControlPlaneN, strconv.Itoa(no)) {
+			continue
+		}
+		wg.Add(1)
 		go func(i int) {
 			defer wg.Done()
-			err := kc.p.Bootstrap.ConfigureControlPlane(i)
+			err := kc.track(statefile.PhaseControlPlaneN, consts.RoleCp, i, func() error {
+				return kc.p.Bootstrap.ConfigureControlPlane(i)
+			})
+			kc.cp.Record(statefile.PhaseControlPlaneN, strconv.Itoa(i), err)
 			if err != nil {
 				errChanCP <- err
 			}
 		}(no)
 	}
 	for no := 0; no < kc.p.Metadata.NoWP; no++ {
+		if kc.cp.Done(statefile.PhaseWorkerPlane, strconv.Itoa(no)) {
+			continue
+		}
+		wg.Add(1)
 		go func(i int) {
 			defer wg.Done()
-			err := kc.p.Bootstrap.JoinWorkerplane(i)
+			err := kc.track(statefile.PhaseWorkerPlane, consts.RoleWp, i, func() error {
+				return kc.p.Bootstrap.JoinWorkerplane(i)
+			})
+			kc.cp.Record(statefile.PhaseWorkerPlane, strconv.Itoa(i), err)
 			if err != nil {
 				errChanWP <- err
 			}
@@ -211,6 +295,8 @@ func (kc *Controller) ConfigureCluster() (bool, error) {
 	close(errChanCP)
 	close(errChanWP)
 
+	errFlush = kc.cp.Flush()
+
 	for err := range errChanCP {
 		if err != nil {
 			return false, err
@@ -222,10 +308,54 @@ func (kc *Controller) ConfigureCluster() (bool, error) {
 			return false, err
 		}
 	}
+	if errFlush != nil {
+		return false, errFlush
+	}
 	return externalCNI, nil
 }
 
 func (kc *Controller) JoinMoreWorkerPlanes(start, end int) error {
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+		return err
+	}
+
+	if err := kc.p.Bootstrap.Setup(consts.OperationGet); err != nil {
+		return err
+	}
+
+	wg := &sync.WaitGroup{}
+	errChan := make(chan error, end-start)
+
+	for no := start; no < end; no++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := kc.track(statefile.PhaseWorkerPlane, consts.RoleWp, i, func() error {
+				return kc.p.Bootstrap.JoinWorkerplane(i)
+			})
+			if err != nil {
+				errChan <- err
+			}
+		}(no)
+	}
+	wg.Wait()
+	close(errChan)
+
+	for err := range errChan {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// JoinMoreControlPlanes joins the controlplanes [start, end) to the cluster, the loadbalancer
+// sends them the traffic of the api server only once all of them joined
+func (kc *Controller) JoinMoreControlPlanes(start, end int) error {
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+		return err
+	}
 
 	if err := kc.p.Bootstrap.Setup(consts.OperationGet); err != nil {
 		return err
@@ -238,7 +368,9 @@ func (kc *Controller) JoinMoreWorkerPlanes(start, end int) error {
 		wg.Add(1)
 		go func(i int) {
 			defer wg.Done()
-			err := kc.p.Bootstrap.JoinWorkerplane(i)
+			err := kc.track(statefile.PhaseControlPlaneN, consts.RoleCp, i, func() error {
+				return kc.p.Bootstrap.JoinControlPlane(i)
+			})
 			if err != nil {
 				errChan <- err
 			}
@@ -253,9 +385,61 @@ func (kc *Controller) JoinMoreWorkerPlanes(start, end int) error {
 		}
 	}
 
+	return kc.track(statefile.PhaseLoadBalancer, consts.RoleLb, 0, func() error {
+		return kc.p.PreBootstrap.ReconfigureLoadbalancer(end)
+	})
+}
+
+// DelControlPlanes removes the controlplanes after the first noCP from the cluster, one at a time and the newest first.
+// The loadbalancer stops sending them the traffic of the api server before, and every removal needs noCP other ready controlplanes
+func (kc *Controller) DelControlPlanes(kubeconfig string, hostnames []string) error {
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+		return err
+	}
+
+	noCP := kc.p.Metadata.NoCP
+
+	if err := kc.track(statefile.PhaseLoadBalancer, consts.RoleLb, 0, func() error {
+		return kc.p.PreBootstrap.ReconfigureLoadbalancer(noCP)
+	}); err != nil {
+		return err
+	}
+
+	if err := kc.p.Bootstrap.Setup(consts.OperationGet); err != nil {
+		return err
+	}
+
+	k, err := NewClusterClient(
+		kc.ctx,
+		kc.l,
+		kc.p.Storage,
+		kubeconfig,
+	)
+	if err != nil {
+		return err
+	}
+
+	for i := len(hostnames) - 1; i >= 0; i-- {
+		if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+			return err
+		}
+		idx := noCP + i
+		if err := kc.track(statefile.PhaseControlPlaneN, consts.RoleCp, idx, func() error {
+			return k.DeleteControlPlaneNode(hostnames[i], noCP, func() error {
+				return kc.p.Bootstrap.RemoveControlPlane(idx)
+			})
+		}); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// SyncInfraState updates the ips of the nodes kept in the state of the bootstrap once the vms changed
+func (kc *Controller) SyncInfraState(transferableInfraState *provider.CloudResourceState) error {
+	return kc.p.PreBootstrap.Setup(transferableInfraState, consts.OperationGet)
+}
+
 func (kc *Controller) DelWorkerPlanes(kubeconfig string, hostnames []string) error {
 
 	k, err := NewClusterClient(
@@ -269,7 +453,18 @@ func (kc *Controller) DelWorkerPlanes(kubeconfig string, hostnames []string) err
 	}
 
 	for _, hostname := range hostnames {
-		if err := k.DeleteWorkerNodes(hostname); err != nil {
+		if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+			return err
+		}
+		done := events.Step(kc.ctx, events.Event{
+			Phase:    string(statefile.PhaseWorkerPlane),
+			Resource: "node",
+			Name:     hostname,
+			Role:     consts.RoleWp,
+		})
+		err := k.DeleteWorkerNodes(hostname)
+		done(err)
+		if err != nil {
 			return err
 		}
 	}
@@ -282,6 +477,10 @@ func (kc *Controller) InstallAdditionalTools(externalCNI bool) error {
 		return nil
 	}
 
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+		return err
+	}
+
 	k, err := NewClusterClient(
 		kc.ctx,
 		kc.l,
@@ -292,7 +491,7 @@ func (kc *Controller) InstallAdditionalTools(externalCNI bool) error {
 		return err
 	}
 
-	if externalCNI {
+	if externalCNI && !kc.cp.Done(statefile.PhaseCNI, "") {
 		kc.l.Print(kc.ctx, "Installing External CNI Plugin")
 		_addons := kc.p.Metadata.Addons.GetAddons("ksctl")
 		var _cni *addons.ClusterAddon
@@ -322,14 +521,21 @@ func (kc *Controller) InstallAdditionalTools(externalCNI bool) error {
 			}
 		}
 
-		if err := k.CNI(_c, kc.s, consts.OperationCreate); err != nil {
+		done := events.Step(kc.ctx, events.Event{
+			Phase:    string(statefile.PhaseCNI),
+			Resource: "addon",
+			Name:     _c.StackName,
+		})
+		err := k.CNI(_c, kc.s, consts.OperationCreate)
+		done(err)
+		if err := kc.cp.Checkpoint(statefile.PhaseCNI, "", err); err != nil {
 			return err
 		}
 
 		kc.l.Success(kc.ctx, "Done with installing k8s cni")
 	}
 
-	return nil
+	return kc.installApps()
 }
 
 func (kc *Controller) InvokeDestroyProcedure() error {
@@ -0,0 +1,187 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/ksctl/ksctl/v2/pkg/certs"
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/events"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/waiter"
+)
+
+// DetachDataStores removes the datastores from the etcd cluster, the controlplanes stop using them before.
+// The detached datastores are never reached so that dead ones can be detached as well
+func (kc *Controller) DetachDataStores(detach []int) error {
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+		return err
+	}
+
+	if err := kc.p.Bootstrap.Setup(consts.OperationGet); err != nil {
+		return err
+	}
+
+	b := kc.s.K8sBootstrap.B
+	members := dataStoreMembers(len(b.PrivateIPs.DataStores), detach)
+	if len(members) == 0 {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidNoOfDatastore,
+			kc.l.NewError(kc.ctx, "no member of the etcd cluster would be left", "detach", detach),
+		)
+	}
+
+	// the controlplanes and the removal of every datastore
+	events.Expect(kc.ctx, len(b.PrivateIPs.ControlPlanes)+len(detach))
+
+	if err := kc.reconfigureControlPlanes(b.CACert, b.EtcdCert, b.EtcdKey, dataStoreIPs(b.PrivateIPs.DataStores, members)); err != nil {
+		return err
+	}
+
+	for i := len(detach) - 1; i >= 0; i-- {
+		if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+			return err
+		}
+		no := detach[i]
+		if err := kc.track(statefile.PhaseDataStore, consts.RoleDs, no, func() error {
+			return kc.p.PreBootstrap.RemoveDataStoreMember(no, members[0])
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AttachDataStores adds the datastores to the etcd cluster of the others, one at a time.
+// The certificates get regenerated for the private ips of all the datastores and rolled without losing the quorum nor the api server,
+// the members and the controlplanes trust the old and the new certificate authority until all of them use the new certificates
+func (kc *Controller) AttachDataStores(attach []int) error {
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+		return err
+	}
+
+	if err := kc.p.Bootstrap.Setup(consts.OperationGet); err != nil {
+		return err
+	}
+
+	b := kc.s.K8sBootstrap.B
+	noDS := len(b.PrivateIPs.DataStores)
+	noCP := len(b.PrivateIPs.ControlPlanes)
+	members := dataStoreMembers(noDS, attach)
+	if len(members) == 0 {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidNoOfDatastore,
+			kc.l.NewError(kc.ctx, "no member of the etcd cluster to attach the datastores to", "attach", attach),
+		)
+	}
+
+	// two rolls of the members, one of all the datastores, two of the controlplanes and the join of every datastore
+	events.Expect(kc.ctx, 2*len(members)+noDS+2*noCP+len(attach))
+
+	ca, cert, key, err := certs.GenerateCerts(kc.ctx, kc.l, b.PrivateIPs.DataStores)
+	if err != nil {
+		return err
+	}
+	trust := strings.TrimSpace(b.CACert) + "\n" + strings.TrimSpace(ca)
+
+	if err := kc.rollDataStores(members, trust, b.EtcdCert, b.EtcdKey); err != nil {
+		return err
+	}
+
+	if err := kc.reconfigureControlPlanes(trust, b.EtcdCert, b.EtcdKey, dataStoreIPs(b.PrivateIPs.DataStores, members)); err != nil {
+		return err
+	}
+
+	if err := kc.rollDataStores(members, trust, cert, key); err != nil {
+		return err
+	}
+
+	for _, no := range attach {
+		if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+			return err
+		}
+		if err := kc.track(statefile.PhaseDataStore, consts.RoleDs, no, func() error {
+			return kc.p.PreBootstrap.AddDataStoreMember(no, members, trust, cert, key)
+		}); err != nil {
+			return err
+		}
+		members = append(members, no)
+	}
+
+	if err := kc.reconfigureControlPlanes(ca, cert, key, b.PrivateIPs.DataStores); err != nil {
+		return err
+	}
+
+	kc.s.K8sBootstrap.B.CACert = ca
+	kc.s.K8sBootstrap.B.EtcdCert = cert
+	kc.s.K8sBootstrap.B.EtcdKey = key
+	if err := kc.p.Storage.Write(kc.s); err != nil {
+		return err
+	}
+
+	return kc.rollDataStores(dataStoreMembers(noDS, nil), ca, cert, key)
+}
+
+// rollDataStores restarts the members with the certificates one at a time
+func (kc *Controller) rollDataStores(members []int, ca, cert, key string) error {
+	for _, no := range members {
+		if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+			return err
+		}
+		if err := kc.track(statefile.PhaseDataStore, consts.RoleDs, no, func() error {
+			return kc.p.PreBootstrap.RollDataStore(no, ca, cert, key)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconfigureControlPlanes restarts the controlplanes with the etcd certificates and endpoints one at a time
+func (kc *Controller) reconfigureControlPlanes(ca, cert, key string, privateEtcdIps []string) error {
+	for no := range kc.s.K8sBootstrap.B.PrivateIPs.ControlPlanes {
+		if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+			return err
+		}
+		if err := kc.track(statefile.PhaseControlPlaneN, consts.RoleCp, no, func() error {
+			return kc.p.Bootstrap.ReconfigureDataStore(no, ca, cert, key, privateEtcdIps)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dataStoreMembers is the index of every datastore out of noDS except the excluded
+func dataStoreMembers(noDS int, exclude []int) []int {
+	members := make([]int, 0, noDS)
+	for no := 0; no < noDS; no++ {
+		if !slices.Contains(exclude, no) {
+			members = append(members, no)
+		}
+	}
+	return members
+}
+
+func dataStoreIPs(ips []string, members []int) []string {
+	selected := make([]string, 0, len(members))
+	for _, no := range members {
+		selected = append(selected, ips[no])
+	}
+	return selected
+}
@@ -18,8 +18,12 @@ import (
 	"strings"
 
 	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 )
 
+// labelControlPlane is set by both k3s and kubeadm on the nodes of the controlplanes
+const labelControlPlane = "node-role.kubernetes.io/control-plane"
+
 func (k *K8sClusterClient) DeleteWorkerNodes(nodeName string) error {
 
 	// TODO: Need to added step to drain the node before deleting it!
@@ -51,3 +55,58 @@ func (k *K8sClusterClient) DeleteWorkerNodes(nodeName string) error {
 	k.l.Success(k.ctx, "Deleted Node", "name", kNodeName)
 	return nil
 }
+
+// DeleteControlPlaneNode drains the node of a controlplane, stops its distribution and deletes it, as long as minReady
+// other controlplanes are ready to take over. A controlplane which never joined the cluster has no node, so only stop runs
+func (k *K8sClusterClient) DeleteControlPlaneNode(nodeName string, minReady int, stop func() error) error {
+	nodes, err := k.k8sClient.NodesList()
+	if err != nil {
+		return err
+	}
+
+	kNodeName := ""
+	readyOthers := 0
+	for _, node := range nodes.Items {
+		if strings.HasPrefix(node.Name, nodeName) {
+			kNodeName = node.Name
+			continue
+		}
+		if _, ok := node.Labels[labelControlPlane]; !ok || node.DeletionTimestamp != nil {
+			continue
+		}
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
+				readyOthers++
+				break
+			}
+		}
+	}
+
+	if len(kNodeName) == 0 {
+		k.l.Warn(k.ctx, "controlplane node not found, it never joined the cluster", "name", nodeName)
+		return stop()
+	}
+
+	if readyOthers < minReady {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidNoOfControlplane,
+			k.l.NewError(k.ctx, "removing the controlplane would leave too few ready controlplanes", "name", kNodeName, "ready", readyOthers, "required", minReady),
+		)
+	}
+
+	if err := k.k8sClient.NodeDrain(kNodeName); err != nil {
+		return err
+	}
+
+	// the kubelet registers the node again when it is deleted while still running
+	if err := stop(); err != nil {
+		return err
+	}
+
+	// the node got drained above and its kubelet is stopped now, so it is not drained again
+	if err := k.k8sClient.NodeDeleteDrained(kNodeName); err != nil {
+		return err
+	}
+	k.l.Success(k.ctx, "Deleted Node", "name", kNodeName)
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	"github.com/ksctl/ksctl/v2/pkg/handler/cluster/controller"
+	"github.com/ksctl/ksctl/v2/pkg/logger"
+	"github.com/ksctl/ksctl/v2/pkg/provider"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+)
+
+// NewPlanController returns a Controller which only plans the scripts to run on the nodes.
+// state is a scratch document which receives the placeholders of the values only known
+// once the cluster is bootstrapped, it is never written to the storage
+func NewPlanController(
+	ctx context.Context,
+	log logger.Logger,
+	baseController *controller.Controller,
+	state *statefile.StorageDocument,
+	operation consts.KsctlOperation,
+	transferableInfraState *provider.CloudResourceState,
+	controllerPayload *controller.Client,
+) (*Controller, error) {
+
+	cc := new(Controller)
+	cc.ctx = context.WithValue(ctx, consts.KsctlModuleNameKey, "ksctl-bootstrap")
+	cc.l = log
+	cc.b = baseController
+	cc.p = controllerPayload
+	cc.s = state
+	cc.plan = true
+	// the scratch state must never reach the storage
+	cc.cp = controller.NewCheckpoints(state, nil)
+
+	if controllerPayload.Metadata.ClusterType == consts.ClusterTypeSelfMang {
+		err := cc.setupInterfaces(operation, transferableInfraState)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return cc, nil
+}
+
+// PlanConfigureCluster returns the scripts ConfigureCluster would run on every node
+func (kc *Controller) PlanConfigureCluster() ([]controller.PlannedNode, error) {
+	b := kc.s.K8sBootstrap.B
+
+	// the nodes a previous attempt already configured are skipped the same way ConfigureCluster does
+	var nodes []controller.PlannedNode
+
+	if !kc.cp.Done(statefile.PhaseLoadBalancer, "") {
+		scripts, err := kc.p.PreBootstrap.PlanLoadbalancer()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, controller.NewPlannedNode(consts.RoleLb, 0, b.PublicIPs.LoadBalancer, scripts))
+	}
+
+	for no := 0; no < kc.p.Metadata.NoDS; no++ {
+		if kc.cp.Done(statefile.PhaseDataStore, strconv.Itoa(no)) {
+			continue
+		}
+		scripts, err := kc.p.PreBootstrap.PlanDataStore(no, kc.p.Metadata.EtcdVersion)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, controller.NewPlannedNode(consts.RoleDs, no, b.PublicIPs.DataStores[no], scripts))
+	}
+
+	_ = kc.p.Bootstrap.CNI(kc.p.Metadata.Addons)
+
+	kc.p.Bootstrap = kc.p.Bootstrap.K8sVersion(kc.p.Metadata.K8sVersion)
+	if kc.p.Bootstrap == nil {
+		return nil, kc.l.NewError(kc.ctx, "invalid version of self-managed k8s cluster")
+	}
+
+	for no := 0; no < kc.p.Metadata.NoCP; no++ {
+		if no == 0 && kc.cp.Done(statefile.PhaseControlPlane0, "") ||
+			no > 0 && kc.cp.Done(statefile.PhaseControlPlaneN, strconv.Itoa(no)) {
+			continue
+		}
+		nodes = append(nodes, controller.NewPlannedNode(
+			consts.RoleCp, no, b.PublicIPs.ControlPlanes[no], kc.p.Bootstrap.PlanControlPlane(no)))
+	}
+
+	for no := 0; no < kc.p.Metadata.NoWP; no++ {
+		if kc.cp.Done(statefile.PhaseWorkerPlane, strconv.Itoa(no)) {
+			continue
+		}
+		nodes = append(nodes, controller.NewPlannedNode(
+			consts.RoleWp, no, b.PublicIPs.WorkerPlanes[no], kc.p.Bootstrap.PlanWorkerplane(no)))
+	}
+
+	return nodes, nil
+}
+
+// PlanJoinMoreWorkerPlanes returns the scripts JoinMoreWorkerPlanes would run on the workerplanes [start, end)
+func (kc *Controller) PlanJoinMoreWorkerPlanes(start, end int) []controller.PlannedNode {
+	b := kc.s.K8sBootstrap.B
+
+	nodes := make([]controller.PlannedNode, 0, end-start)
+	for no := start; no < end; no++ {
+		nodes = append(nodes, controller.NewPlannedNode(
+			consts.RoleWp, no, b.PublicIPs.WorkerPlanes[no], kc.p.Bootstrap.PlanWorkerplane(no)))
+	}
+
+	return nodes
+}
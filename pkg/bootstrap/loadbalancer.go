@@ -20,6 +20,7 @@ import (
 	"github.com/ksctl/ksctl/v2/pkg/ssh"
 
 	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
 	"github.com/ksctl/ksctl/v2/pkg/utilities"
 )
 
@@ -59,6 +60,34 @@ func (p *PreBootstrap) ConfigureLoadbalancer() error {
 	return nil
 }
 
+// ReconfigureLoadbalancer points the backends of the haproxy to the first noOfControlPlanes controlplanes,
+// so the controlplanes joined or about to be removed by a scale get the traffic of the api server or stop getting it
+func (p *PreBootstrap) ReconfigureLoadbalancer(noOfControlPlanes int) error {
+	p.l.Note(p.ctx, "reconfiguring Loadbalancer", "noOfControlPlanes", noOfControlPlanes)
+	p.mu.Lock()
+	sshExecutor := ssh.NewSSHExecutor(p.ctx, p.l, p.state) //making sure that a new obj gets initialized for a every run thus eleminating possible problems with concurrency
+	p.mu.Unlock()
+
+	controlPlaneIPs := utilities.DeepCopySlice[string](p.state.K8sBootstrap.B.PrivateIPs.ControlPlanes)
+	if noOfControlPlanes < 1 || noOfControlPlanes > len(controlPlaneIPs) {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidNoOfControlplane,
+			p.l.NewError(p.ctx, "no of controlplanes for the loadbalancer is out of range", "noOfControlPlanes", noOfControlPlanes, "available", len(controlPlaneIPs)),
+		)
+	}
+
+	err := sshExecutor.Flag(consts.UtilExecWithoutOutput).Script(
+		scriptReconfigureLoadbalancer(controlPlaneIPs[:noOfControlPlanes])).
+		IPv4(p.state.K8sBootstrap.B.PublicIPs.LoadBalancer).
+		FastMode(true).SSHExecute()
+	if err != nil {
+		return err
+	}
+
+	p.l.Success(p.ctx, "reconfigured LoadBalancer")
+	return nil
+}
+
 func scriptConfigureLoadbalancer(haProxyVer string, controlPlaneIPs []string) ssh.ExecutionPipeline {
 	collection := ssh.NewExecutionPipeline()
 	// HA proxy repo https://haproxy.debian.net/
@@ -86,6 +115,39 @@ sudo systemctl enable haproxy
 `,
 	})
 
+	scriptLoadbalancerBackends(collection, controlPlaneIPs)
+
+	collection.Append(ssh.Script{
+		Name:           "restarting haproxy",
+		CanRetry:       true,
+		MaxRetries:     3,
+		ScriptExecutor: consts.LinuxBash,
+		ShellScript: `
+sudo systemctl restart haproxy
+`,
+	})
+
+	return collection
+}
+
+// scriptReconfigureLoadbalancer rewrites the backends of an installed haproxy, the reload keeps the established connections
+func scriptReconfigureLoadbalancer(controlPlaneIPs []string) ssh.ExecutionPipeline {
+	collection := scriptLoadbalancerBackends(ssh.NewExecutionPipeline(), controlPlaneIPs)
+
+	collection.Append(ssh.Script{
+		Name:           "reloading haproxy",
+		CanRetry:       true,
+		MaxRetries:     3,
+		ScriptExecutor: consts.LinuxBash,
+		ShellScript: `
+sudo systemctl reload haproxy
+`,
+	})
+
+	return collection
+}
+
+func scriptLoadbalancerBackends(collection ssh.ExecutionPipeline, controlPlaneIPs []string) ssh.ExecutionPipeline {
 	serverScript := ""
 	for index, controlPlaneIP := range controlPlaneIPs {
 		serverScript += fmt.Sprintf(`  server k3sserver-%d %s:%d check
@@ -118,15 +180,5 @@ sudo mv haproxy.cfg /etc/haproxy/haproxy.cfg
 `, serverScript),
 	})
 
-	collection.Append(ssh.Script{
-		Name:           "restarting haproxy",
-		CanRetry:       true,
-		MaxRetries:     3,
-		ScriptExecutor: consts.LinuxBash,
-		ShellScript: `
-sudo systemctl restart haproxy
-`,
-	})
-
 	return collection
 }
@@ -95,3 +95,55 @@ sudo systemctl restart haproxy
 	)
 
 }
+
+func TestScriptsReconfigureLoadbalancer(t *testing.T) {
+	array := []string{"127.0.0.1", "127.0.0.2", "127.0.0.3", "127.0.0.4", "127.0.0.5"}
+
+	testHelper.HelperTestTemplate(
+		t,
+		[]ssh.Script{
+			{
+				Name:           "create haproxy configuration",
+				CanRetry:       false,
+				ScriptExecutor: consts.LinuxBash,
+				ShellScript: `
+cat <<EOF > haproxy.cfg
+frontend kubernetes-frontend
+  bind *:6443
+  mode tcp
+  option tcplog
+  timeout client 10s
+  default_backend kubernetes-backend
+
+backend kubernetes-backend
+  timeout connect 10s
+  timeout server 10s
+  mode tcp
+  option tcp-check
+  balance roundrobin
+  server k3sserver-1 127.0.0.1:6443 check
+  server k3sserver-2 127.0.0.2:6443 check
+  server k3sserver-3 127.0.0.3:6443 check
+  server k3sserver-4 127.0.0.4:6443 check
+  server k3sserver-5 127.0.0.5:6443 check
+
+EOF
+
+sudo mv haproxy.cfg /etc/haproxy/haproxy.cfg
+`,
+			},
+			{
+				Name:           "reloading haproxy",
+				CanRetry:       true,
+				MaxRetries:     3,
+				ScriptExecutor: consts.LinuxBash,
+				ShellScript: `
+sudo systemctl reload haproxy
+`,
+			},
+		},
+		func() ssh.ExecutionPipeline {
+			return scriptReconfigureLoadbalancer(array)
+		},
+	)
+}
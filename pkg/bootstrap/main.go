@@ -72,6 +72,17 @@ func (p *PreBootstrap) Setup(
 		}
 	}
 
+	p.setCloudState(cloudState)
+
+	if err := p.store.Write(p.state); err != nil {
+		return err
+	}
+
+	p.l.Success(p.ctx, "Initialized state from Cloud")
+	return nil
+}
+
+func (p *PreBootstrap) setCloudState(cloudState *provider.CloudResourceState) {
 	p.state.K8sBootstrap.B.PublicIPs.ControlPlanes =
 		utilities.DeepCopySlice[string](cloudState.IPv4ControlPlanes)
 
@@ -96,11 +107,4 @@ func (p *PreBootstrap) Setup(
 		PrivateKey: cloudState.SSHPrivateKey,
 		UserName:   cloudState.SSHUserName,
 	}
-
-	if err := p.store.Write(p.state); err != nil {
-		return err
-	}
-
-	p.l.Success(p.ctx, "Initialized state from Cloud")
-	return nil
 }
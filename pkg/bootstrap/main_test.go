@@ -134,4 +134,19 @@ func TestOverallScriptsCreation(t *testing.T) {
 	}
 
 	assert.Equal(t, *fakeClient.state.Versions.Etcd, "v3.5.15", "should be equal")
+
+	// replacing the last datastore through the first, and rolling the certificates of all of them
+	if err := fakeClient.RemoveDataStoreMember(noDS-1, 0); err != nil {
+		t.Fatalf("Remove Datastore member unable to operate %v", err)
+	}
+	for no := 0; no < noDS; no++ {
+		if err := fakeClient.RollDataStore(no, "ca", "cert", "key"); err != nil {
+			t.Fatalf("Roll Datastore unable to operate %v", err)
+		}
+	}
+	if err := fakeClient.AddDataStoreMember(noDS-1, []int{0}, "ca", "cert", "key"); err != nil {
+		t.Fatalf("Add Datastore member unable to operate %v", err)
+	}
+
+	assert.Equal(t, *fakeClient.state.Versions.Etcd, "v3.5.15", "should be equal")
 }
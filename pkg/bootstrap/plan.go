@@ -0,0 +1,69 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bootstrap
+
+import (
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	"github.com/ksctl/ksctl/v2/pkg/provider"
+	"github.com/ksctl/ksctl/v2/pkg/ssh"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+)
+
+// PlanSetup loads the cloud state the same way Setup does, without generating
+// certificates or writing to the storage. The certificates are replaced by placeholders
+func (p *PreBootstrap) PlanSetup(
+	cloudState *provider.CloudResourceState,
+	operation consts.KsctlOperation,
+) {
+	if operation == consts.OperationCreate || p.state.K8sBootstrap == nil {
+		p.state.K8sBootstrap = &statefile.KubernetesBootstrapState{}
+	}
+
+	p.state.K8sBootstrap.B.CACert = "<ca-cert>"
+	p.state.K8sBootstrap.B.EtcdCert = "<etcd-cert>"
+	p.state.K8sBootstrap.B.EtcdKey = "<etcd-key>"
+
+	p.setCloudState(cloudState)
+}
+
+// PlanLoadbalancer returns the scripts ConfigureLoadbalancer would run on the loadbalancer
+func (p *PreBootstrap) PlanLoadbalancer() ([]ssh.Script, error) {
+	haProxyVer, err := getLatestVersionHAProxy()
+	if err != nil {
+		return nil, err
+	}
+
+	return scriptConfigureLoadbalancer(
+		haProxyVer,
+		p.state.K8sBootstrap.B.PrivateIPs.ControlPlanes,
+	).List(), nil
+}
+
+// PlanDataStore returns the scripts ConfigureDataStore would run on the datastore no
+func (p *PreBootstrap) PlanDataStore(no int, version string) ([]ssh.Script, error) {
+	etcdVer, err := p.verifyVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	return scriptDB(
+		etcdVer,
+		p.state.K8sBootstrap.B.CACert,
+		p.state.K8sBootstrap.B.EtcdCert,
+		p.state.K8sBootstrap.B.EtcdKey,
+		p.state.K8sBootstrap.B.PrivateIPs.DataStores,
+		no,
+	).List(), nil
+}
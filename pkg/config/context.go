@@ -29,6 +29,8 @@ func IsContextPresent(ctx context.Context, key consts.KsctlContextKeyType) (val
 		consts.KsctlModuleNameKey:      `^[\w-]+$`,
 		consts.KsctlCustomDirLoc:       `^[\w-:~\\/\s]+$`,
 		consts.KsctlComponentOverrides: `^([\w]+=[\w-:\.~\\/\s]+)+(,[\w]+=[\w-:\.~\\/\s]+)*$`,
+
+		consts.KsctlKubernetesStoreNamespace: `^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`,
 	}
 	_val := ctx.Value(key)
 	if _val == nil {
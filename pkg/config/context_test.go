@@ -102,6 +102,16 @@ func TestIsContextPresent(t *testing.T) {
 			key:      consts.KsctlComponentOverrides,
 			expected: true,
 		},
+		{
+			ctx:      context.WithValue(ppCtx, consts.KsctlKubernetesStoreNamespace, "ksctl-system"),
+			key:      consts.KsctlKubernetesStoreNamespace,
+			expected: true,
+		},
+		{
+			ctx:      context.WithValue(ppCtx, consts.KsctlKubernetesStoreNamespace, "Ksctl_System"),
+			key:      consts.KsctlKubernetesStoreNamespace,
+			expected: false,
+		},
 	}
 
 	for _, tt := range testCases {
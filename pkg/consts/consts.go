@@ -64,6 +64,15 @@ const (
 	ClusterType KsctlSearchFilter = "clusterType"
 	Name        KsctlSearchFilter = "clusterName"
 	Region      KsctlSearchFilter = "region"
+
+	State             KsctlSearchFilter = "state"
+	Owner             KsctlSearchFilter = "owner"
+	Team              KsctlSearchFilter = "team"
+	BootstrapProvider KsctlSearchFilter = "bootstrapProvider"
+	// KubernetesVersion matches the version itself or its patch releases, 1.30 matches 1.30.4
+	KubernetesVersion KsctlSearchFilter = "kubernetesVersion"
+	// Labels is a comma separated list of key=value pairs or keys which have to be present
+	Labels KsctlSearchFilter = "labels"
 )
 
 const (
@@ -73,12 +82,23 @@ const (
 
 const (
 	DurationSSHPause time.Duration = 20 * time.Second
+
+	// DurationSSHDialTimeout bounds each attempt to connect to a node, the attempts are retried with a backoff
+	DurationSSHDialTimeout time.Duration = 5 * time.Minute
+
+	DurationClusterLockTTL time.Duration = 2 * time.Minute
 )
 
 const (
 	CounterMaxRetryCount          KsctlCounterConsts = 5
 	CounterMaxNetworkSessionRetry KsctlCounterConsts = 5
 	CounterMaxWatchRetryCount     KsctlCounterConsts = 3
+
+	// CounterMaxStateRevisions is the number of state revisions kept by the storage for every cluster
+	CounterMaxStateRevisions KsctlCounterConsts = 10
+
+	// CounterMaxClusterLabels keeps the labels along with the Name tag under the aws and azure limit of 50 tags
+	CounterMaxClusterLabels KsctlCounterConsts = 40
 )
 
 const (
@@ -116,6 +136,9 @@ const (
 	StoreLocal    KsctlStore = "store-local"
 	StoreK8s      KsctlStore = "store-kubernetes"
 	StoreExtMongo KsctlStore = "external-store-mongodb"
+	StoreExtRedis KsctlStore = "external-store-redis"
+	StoreExtS3    KsctlStore = "external-store-s3"
+	StoreExtSQL   KsctlStore = "external-store-sql"
 )
 
 const (
@@ -145,6 +168,8 @@ const (
 	KsctlAzureCredentials   KsctlContextKeyType = iota // the value to be the AwsCredentials struct
 	KsctlMongodbCredentials KsctlContextKeyType = iota // the value to be the MongodbCredentials struct
 	KsctlRedisCredentials   KsctlContextKeyType = iota // the value to be the RedisCredentials struct
+
+	KsctlKubernetesStoreNamespace KsctlContextKeyType = iota // namespace used by the kubernetes storage
 )
 
 const (
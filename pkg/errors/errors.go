@@ -55,6 +55,10 @@ const (
 	ErrFailedKsctlClusterOperation
 	ErrFailedGenerateCertificates
 	ErrFailedConnectingKubernetesCluster
+
+	ErrStaleStateWrite
+	ErrClusterLocked
+	ErrUnauthorized
 )
 
 // KsctlError is the error type for ksctl errors
@@ -98,6 +102,12 @@ func (e KsctlError) errorCodeToString() string {
 		return "DuplicateRecordsErr"
 	case ErrNoMatchingRecordsFound:
 		return "NoMatchingRecordsFoundErr"
+	case ErrStaleStateWrite:
+		return "StaleStateWriteErr"
+	case ErrClusterLocked:
+		return "ClusterLockedErr"
+	case ErrUnauthorized:
+		return "UnauthorizedErr"
 	case ErrInvalidOperation:
 		return "InvalidOperationErr"
 	case ErrInvalidKsctlRole:
@@ -211,6 +221,18 @@ func IsNoMatchingRecordsFound(err error) bool {
 	return codeForError(err) == ErrNoMatchingRecordsFound
 }
 
+func IsStaleStateWrite(err error) bool {
+	return codeForError(err) == ErrStaleStateWrite
+}
+
+func IsClusterLocked(err error) bool {
+	return codeForError(err) == ErrClusterLocked
+}
+
+func IsUnauthorized(err error) bool {
+	return codeForError(err) == ErrUnauthorized
+}
+
 func IsInvalidOperation(err error) bool {
 	return codeForError(err) == ErrInvalidOperation
 }
@@ -0,0 +1,191 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+)
+
+type Status string
+
+const (
+	StatusStarted   Status = "started"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusRetrying  Status = "retrying"
+)
+
+// the phases reported besides the creation phases of the statefile
+const (
+	PhaseOperation      = "operation"
+	PhaseSSH            = "ssh"
+	PhaseManagedCluster = "managed_cluster"
+)
+
+// Event is the progress of an operation on a cluster, like the creation of the vm of the controlplane 2
+type Event struct {
+	Time        time.Time             `json:"time"`
+	Operation   consts.KsctlOperation `json:"operation"`
+	ClusterName string                `json:"cluster_name"`
+
+	Phase    string           `json:"phase"`
+	Resource string           `json:"resource"`
+	Name     string           `json:"name,omitempty"`
+	Role     consts.KsctlRole `json:"role,omitempty"`
+	Index    int              `json:"index"`
+	Host     string           `json:"host,omitempty"`
+
+	Status  Status `json:"status"`
+	Attempt int    `json:"attempt"`
+	// Percent is an estimate of how much of the operation is done, it only reaches 100 once the operation succeeded
+	Percent int    `json:"percent"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// Handler receives the events of the operations. It is called from the goroutines of the concurrent steps,
+// so it has to be safe for concurrent use and return quickly
+type Handler func(Event)
+
+type stream struct {
+	handler     Handler
+	operation   consts.KsctlOperation
+	clusterName string
+
+	mu        sync.Mutex
+	total     int
+	done      int
+	succeeded bool
+}
+
+type streamKey struct{}
+
+// NewContext returns a ctx carrying the events of the operation to h,
+// the providers, the bootstrap and the ssh executor report through it. A nil h reports nothing
+func NewContext(ctx context.Context, h Handler, operation consts.KsctlOperation, clusterName string) context.Context {
+	if h == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, streamKey{}, &stream{
+		handler:     h,
+		operation:   operation,
+		clusterName: clusterName,
+	})
+}
+
+func fromContext(ctx context.Context) *stream {
+	s, _ := ctx.Value(streamKey{}).(*stream)
+	return s
+}
+
+// Expect adds the number of steps the operation is going to report, which the percent complete is estimated from
+func Expect(ctx context.Context, steps int) {
+	s := fromContext(ctx)
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.total += steps
+}
+
+// Emit reports an event which doesn't finish a step, like a script being retried on a node
+func Emit(ctx context.Context, e Event) {
+	if s := fromContext(ctx); s != nil {
+		s.emit(e, false)
+	}
+}
+
+// Step reports the start of a step of the operation and returns the func reporting its outcome,
+// every finished step moves the percent complete forward
+func Step(ctx context.Context, e Event) (done func(err error)) {
+	s := fromContext(ctx)
+	if s == nil {
+		return func(error) {}
+	}
+
+	e.Status = StatusStarted
+	s.emit(e, false)
+
+	return func(err error) {
+		e.Status = StatusSucceeded
+		e.Reason = ""
+		if err != nil {
+			e.Status = StatusFailed
+			e.Reason = err.Error()
+		}
+		s.emit(e, true)
+	}
+}
+
+// Finish reports the outcome of the whole operation
+func Finish(ctx context.Context, err error) {
+	s := fromContext(ctx)
+	if s == nil {
+		return
+	}
+
+	e := Event{
+		Phase:    PhaseOperation,
+		Resource: "cluster",
+		Name:     s.clusterName,
+		Status:   StatusSucceeded,
+	}
+	if err != nil {
+		e.Status = StatusFailed
+		e.Reason = err.Error()
+	}
+
+	s.mu.Lock()
+	s.succeeded = err == nil
+	s.mu.Unlock()
+
+	s.emit(e, false)
+}
+
+func (s *stream) emit(e Event, stepDone bool) {
+	s.mu.Lock()
+	if stepDone {
+		s.done++
+	}
+	e.Percent = s.percent()
+	s.mu.Unlock()
+
+	e.Time = time.Now().UTC()
+	e.Operation = s.operation
+	e.ClusterName = s.clusterName
+	if e.Attempt == 0 {
+		e.Attempt = 1
+	}
+
+	s.handler(e)
+}
+
+func (s *stream) percent() int {
+	if s.succeeded {
+		return 100
+	}
+	if s.total == 0 {
+		return 0
+	}
+	if s.done >= s.total {
+		// the steps can outnumber the estimate, only the outcome of the operation completes it
+		return 99
+	}
+	return s.done * 100 / s.total
+}
@@ -0,0 +1,107 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	"gotest.tools/v3/assert"
+)
+
+type recorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *recorder) handle(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+func TestNilHandler(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, NewContext(ctx, nil, consts.OperationCreate, "demo"), ctx)
+
+	// nothing to report to, so none of them panic
+	Expect(ctx, 2)
+	Emit(ctx, Event{Phase: PhaseSSH})
+	Step(ctx, Event{Phase: PhaseSSH})(nil)
+	Finish(ctx, nil)
+}
+
+func TestStepAndFinish(t *testing.T) {
+	r := &recorder{}
+	ctx := NewContext(context.Background(), r.handle, consts.OperationCreate, "demo")
+
+	Expect(ctx, 4)
+
+	done := Step(ctx, Event{Phase: "vm", Resource: "vm", Role: consts.RoleCp, Index: 1})
+	done(nil)
+	Emit(ctx, Event{Phase: PhaseSSH, Resource: "script", Status: StatusRetrying, Attempt: 2})
+	Step(ctx, Event{Phase: "vm", Resource: "vm", Role: consts.RoleWp})(errors.New("quota exceeded"))
+
+	r.mu.Lock()
+	got := append([]Event(nil), r.events...)
+	r.mu.Unlock()
+
+	assert.Equal(t, len(got), 5)
+	for _, e := range got {
+		assert.Equal(t, e.Operation, consts.OperationCreate)
+		assert.Equal(t, e.ClusterName, "demo")
+		assert.Assert(t, !e.Time.IsZero())
+	}
+
+	assert.Equal(t, got[0].Status, StatusStarted)
+	assert.Equal(t, got[0].Percent, 0)
+	assert.Equal(t, got[0].Attempt, 1)
+	assert.Equal(t, got[1].Status, StatusSucceeded)
+	assert.Equal(t, got[1].Role, consts.RoleCp)
+	assert.Equal(t, got[1].Index, 1)
+	assert.Equal(t, got[1].Percent, 25)
+
+	assert.Equal(t, got[2].Status, StatusRetrying)
+	assert.Equal(t, got[2].Attempt, 2)
+	assert.Equal(t, got[2].Percent, 25)
+
+	assert.Equal(t, got[4].Status, StatusFailed)
+	assert.Equal(t, got[4].Reason, "quota exceeded")
+	assert.Equal(t, got[4].Percent, 50)
+
+	Finish(ctx, nil)
+	last := r.events[len(r.events)-1]
+	assert.Equal(t, last.Phase, PhaseOperation)
+	assert.Equal(t, last.Status, StatusSucceeded)
+	assert.Equal(t, last.Percent, 100)
+}
+
+func TestPercentCapped(t *testing.T) {
+	r := &recorder{}
+	ctx := NewContext(context.Background(), r.handle, consts.OperationDelete, "demo")
+
+	Expect(ctx, 1)
+	Step(ctx, Event{Phase: "network"})(nil)
+	Step(ctx, Event{Phase: "network"})(nil)
+	assert.Equal(t, r.events[len(r.events)-1].Percent, 99)
+
+	Finish(ctx, errors.New("failed"))
+	last := r.events[len(r.events)-1]
+	assert.Equal(t, last.Status, StatusFailed)
+	assert.Equal(t, last.Percent, 99)
+}
@@ -34,7 +34,7 @@ import (
 )
 
 const (
-	Sku = "kcm"
+	Sku = bootstrapHandler.KcmSku
 )
 
 func GetAvailableVersions() ([]string, error) {
@@ -47,6 +47,8 @@ type Kcm struct {
 	p   *controller.Client
 	b   *controller.Controller
 	s   *statefile.StorageDocument
+
+	releaseLock func()
 }
 
 func NewKcm(
@@ -81,7 +83,22 @@ func (kc *Kcm) prepareClient() (_ *provider.CloudResourceState, errC error) {
 		return nil, err
 	}
 
-	var err error
+	_, releaseLock, err := kc.b.LockCluster(kc.ctx, kc.p.Storage)
+	if err != nil {
+		kc.l.Error("handled error", "catch", err)
+		return nil, err
+	}
+	kc.releaseLock = releaseLock
+
+	state, err := kc.p.Storage.Read()
+	if err != nil {
+		kc.l.Error("handled error", "catch", err)
+		return nil, err
+	}
+	if err := kc.b.Authorize(controller.ActionAddon, state); err != nil {
+		return nil, err
+	}
+
 	switch kc.p.Metadata.Provider {
 	case consts.CloudAzure:
 		kc.p.Cloud, err = azure.NewClient(kc.ctx, kc.l, kc.b.KsctlWorkloadConf.WorkerCtx, kc.p.Metadata, kc.s, kc.p.Storage, azure.ProvideClient)
@@ -125,6 +142,12 @@ func (kc *Kcm) prepareClient() (_ *provider.CloudResourceState, errC error) {
 }
 
 func (k *Kcm) Install(version string) (errC error) {
+	defer func() {
+		if k.releaseLock != nil {
+			k.releaseLock()
+			k.releaseLock = nil
+		}
+	}()
 
 	defer func() {
 		v := k.b.PanicHandler(k.l)
@@ -141,6 +164,12 @@ func (k *Kcm) Install(version string) (errC error) {
 		}
 	}()
 
+	transferableInfraState, err := k.prepareClient()
+	if err != nil {
+		return err
+	}
+
+	// the state is only known once the client is prepared
 	defer func() {
 		if errC != nil {
 			k.s.PlatformSpec.State = statefile.ConfiguringFailed
@@ -157,11 +186,6 @@ func (k *Kcm) Install(version string) (errC error) {
 		}
 	}()
 
-	transferableInfraState, err := k.prepareClient()
-	if err != nil {
-		return err
-	}
-
 	s := k.s.ProvisionerAddons.Apps
 	for _, addon := range s {
 		if addon.Name == Sku && addon.For == consts.K8sKsctl {
@@ -193,10 +217,17 @@ func (k *Kcm) Install(version string) (errC error) {
 		return err
 	}
 
-	return kbc.InstallKcm(kubeconfig, "ksctl-system", "cluster-config", ca)
+	return kbc.InstallKcm(kubeconfig, bootstrapHandler.KcmNamespace, bootstrapHandler.KcmConfigMapName, ca)
 }
 
 func (k *Kcm) Uninstall() (errC error) {
+	defer func() {
+		if k.releaseLock != nil {
+			k.releaseLock()
+			k.releaseLock = nil
+		}
+	}()
+
 	defer func() {
 		v := k.b.PanicHandler(k.l)
 		if v != nil {
@@ -212,6 +243,12 @@ func (k *Kcm) Uninstall() (errC error) {
 		}
 	}()
 
+	transferableInfraState, err := k.prepareClient()
+	if err != nil {
+		return err
+	}
+
+	// the state is only known once the client is prepared
 	defer func() {
 		if errC != nil {
 			k.s.PlatformSpec.State = statefile.ConfiguringFailed
@@ -228,11 +265,6 @@ func (k *Kcm) Uninstall() (errC error) {
 		}
 	}()
 
-	transferableInfraState, err := k.prepareClient()
-	if err != nil {
-		return err
-	}
-
 	s := k.s.ProvisionerAddons.Apps
 	var app statefile.SlimProvisionerAddon
 	found := false
@@ -269,5 +301,5 @@ func (k *Kcm) Uninstall() (errC error) {
 		return err
 	}
 
-	return kbc.UninstallKcm(kubeconfig, "ksctl-system", "cluster-config", app)
+	return kbc.UninstallKcm(kubeconfig, bootstrapHandler.KcmNamespace, bootstrapHandler.KcmConfigMapName, app)
 }
@@ -0,0 +1,119 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"errors"
+
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/handler/cluster/controller"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+)
+
+func (kc *Controller) auditHelper(query storage.AuditQuery) (storage.Auditor, error) {
+	a, ok := kc.p.Storage.(storage.Auditor)
+	if !ok {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidStorageProvider,
+			kc.l.NewError(kc.ctx, "storage doesn't keep the audit trail"),
+		)
+	}
+
+	if err := query.Validate(); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			kc.l.NewError(kc.ctx, "invalid audit query", "Reason", err),
+		)
+	}
+	return a, nil
+}
+
+// AuditTrail returns the audit entries of all the clusters matching the query, oldest first.
+// Setting the Actor of the query gives the trail of a single user
+func (kc *Controller) AuditTrail(query storage.AuditQuery) (_ []*storage.AuditEntry, errC error) {
+	defer func() {
+		if errC != nil {
+			v := kc.b.PanicHandler(kc.l)
+			if v != nil {
+				errC = errors.Join(errC, v)
+			}
+		}
+	}()
+
+	if err := kc.b.Authorize(controller.ActionRead, nil); err != nil {
+		return nil, err
+	}
+
+	a, err := kc.auditHelper(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.QueryAudit(query)
+}
+
+// ClusterAuditTrail returns the audit entries of the cluster of the metadata matching the query, oldest first.
+// The trail is kept after the cluster gets deleted
+func (kc *Controller) ClusterAuditTrail(query storage.AuditQuery) (_ []*storage.AuditEntry, errC error) {
+	defer func() {
+		if errC != nil {
+			v := kc.b.PanicHandler(kc.l)
+			if v != nil {
+				errC = errors.Join(errC, v)
+			}
+		}
+	}()
+
+	if err := kc.b.ValidateClusterType(kc.p.Metadata.ClusterType); err != nil {
+		return nil, err
+	}
+
+	if kc.b.IsLocalProvider(kc.p) {
+		kc.p.Metadata.Region = "LOCAL"
+	}
+
+	if err := kc.p.Storage.Setup(
+		kc.p.Metadata.Provider,
+		kc.p.Metadata.Region,
+		kc.p.Metadata.ClusterName,
+		kc.p.Metadata.ClusterType,
+	); err != nil {
+		return nil, err
+	}
+
+	// the trail of a deleted cluster is kept, its state is gone
+	state, err := kc.p.Storage.Read()
+	if err != nil {
+		if !ksctlErrors.IsNoMatchingRecordsFound(err) {
+			return nil, err
+		}
+		state = nil
+	}
+	if err := kc.b.Authorize(controller.ActionRead, state); err != nil {
+		return nil, err
+	}
+
+	query.Cloud = kc.p.Metadata.Provider
+	query.Region = kc.p.Metadata.Region
+	query.ClusterName = kc.p.Metadata.ClusterName
+	query.ClusterType = kc.p.Metadata.ClusterType
+
+	a, err := kc.auditHelper(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.QueryAudit(query)
+}
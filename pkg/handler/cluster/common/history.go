@@ -0,0 +1,179 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"errors"
+
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/handler/cluster/controller"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+)
+
+// historyHelper selects the cluster in the storage and returns its revision history
+// once the user is authorized to run the action on the cluster
+func (kc *Controller) historyHelper(action controller.Action) (storage.History, error) {
+	if err := kc.b.ValidateClusterType(kc.p.Metadata.ClusterType); err != nil {
+		return nil, err
+	}
+
+	if kc.b.IsLocalProvider(kc.p) {
+		kc.p.Metadata.Region = "LOCAL"
+	}
+
+	h, ok := kc.p.Storage.(storage.History)
+	if !ok {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidStorageProvider,
+			kc.l.NewError(kc.ctx, "storage doesn't keep the state revisions"),
+		)
+	}
+
+	if err := kc.p.Storage.Setup(
+		kc.p.Metadata.Provider,
+		kc.p.Metadata.Region,
+		kc.p.Metadata.ClusterName,
+		kc.p.Metadata.ClusterType,
+	); err != nil {
+		return nil, err
+	}
+
+	state, err := kc.p.Storage.Read()
+	if err != nil {
+		return nil, err
+	}
+	if err := kc.b.Authorize(action, state); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// ListStateRevisions returns the revisions kept for the cluster, newest first
+func (kc *Controller) ListStateRevisions() (_ []*storage.StateRevision, errC error) {
+	defer func() {
+		if errC != nil {
+			v := kc.b.PanicHandler(kc.l)
+			if v != nil {
+				errC = errors.Join(errC, v)
+			}
+		}
+	}()
+
+	h, err := kc.historyHelper(controller.ActionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.ListRevisions()
+}
+
+// DiffStateRevisions returns the changes made to the state going from one revision to the other.
+// The revisions hold the secrets of the cluster, so the user has to be allowed to switch to it
+func (kc *Controller) DiffStateRevisions(from, to int64) (_ []storage.StateChange, errC error) {
+	defer func() {
+		if errC != nil {
+			v := kc.b.PanicHandler(kc.l)
+			if v != nil {
+				errC = errors.Join(errC, v)
+			}
+		}
+	}()
+
+	h, err := kc.historyHelper(controller.ActionSwitch)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := h.ReadRevision(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := h.ReadRevision(to)
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.DiffDocuments(a.Document, b.Document)
+}
+
+// RollbackState restores the state of the cluster to the given revision. The changes it would make to the
+// current state, with the secrets redacted, are passed to confirm and nothing is written unless it returns true.
+// The restored state is written as a new revision so the current one stays in the history
+func (kc *Controller) RollbackState(revision int64, confirm func(changes []storage.StateChange) bool) (errC error) {
+	finishAudit := kc.b.StartAudit(kc.p.Storage, &kc.p.Metadata, storage.AuditOperationRollbackState, map[string]any{"revision": revision})
+	defer func() { finishAudit(errC) }()
+	defer func() {
+		if errC != nil {
+			v := kc.b.PanicHandler(kc.l)
+			if v != nil {
+				errC = errors.Join(errC, v)
+			}
+		}
+	}()
+
+	h, err := kc.historyHelper(controller.ActionUpdate)
+	if err != nil {
+		return err
+	}
+
+	_, releaseLock, err := kc.b.LockCluster(kc.ctx, kc.p.Storage)
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
+	current, err := kc.p.Storage.Read()
+	if err != nil {
+		return err
+	}
+	if err := kc.b.Authorize(controller.ActionUpdate, current); err != nil {
+		return err
+	}
+	if current.Revision == revision {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			kc.l.NewError(kc.ctx, "state is already at the revision", "revision", revision),
+		)
+	}
+
+	target, err := h.ReadRevision(revision)
+	if err != nil {
+		return err
+	}
+
+	changes, err := storage.DiffDocuments(current, target.Document)
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			kc.l.NewError(kc.ctx, "failed to diff the state", "Reason", err),
+		)
+	}
+
+	if confirm == nil || !confirm(changes) {
+		kc.l.Print(kc.ctx, "Rollback of the state was not confirmed", "revision", revision)
+		return nil
+	}
+
+	restored := *target.Document
+	restored.ID = current.ID
+	restored.Revision = current.Revision
+	if err := kc.p.Storage.Write(&restored); err != nil {
+		return err
+	}
+
+	kc.l.Success(kc.ctx, "Rolled back the state", "revision", revision, "newRevision", restored.Revision)
+	return nil
+}
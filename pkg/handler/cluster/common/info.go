@@ -19,6 +19,7 @@ import (
 
 	"github.com/ksctl/ksctl/v2/pkg/consts"
 	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/handler/cluster/controller"
 	"github.com/ksctl/ksctl/v2/pkg/logger"
 	"github.com/ksctl/ksctl/v2/pkg/provider"
 	"github.com/ksctl/ksctl/v2/pkg/provider/aws"
@@ -169,6 +170,10 @@ func (kc *Controller) ListClusters() (_ []provider.ClusterData, errC error) {
 		}
 	}()
 
+	if err := kc.b.Authorize(controller.ActionRead, nil); err != nil {
+		return nil, err
+	}
+
 	v, err := kc.clusterDataHelper(logger.LoggingGetClusters)
 	if err != nil {
 		return nil, err
@@ -219,6 +224,9 @@ func (kc *Controller) GetCluster() (_ *provider.ClusterData, errC error) {
 				errOp,
 			)
 		}
+		if err := kc.b.Authorize(controller.ActionRead, state); err != nil {
+			return nil, err
+		}
 	}
 
 	v, err := kc.clusterDataHelper(logger.LoggingInfoCluster)
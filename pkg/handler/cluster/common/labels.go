@@ -0,0 +1,93 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"errors"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/handler/cluster/controller"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"github.com/ksctl/ksctl/v2/pkg/validation"
+)
+
+// UpdateLabels replaces the labels of a running cluster, the tags of its cloud resources are updated to match.
+// Labels missing from the given ones are removed from the cluster and its resources
+func (kc *Controller) UpdateLabels(labels map[string]string) (errC error) {
+	finishAudit := kc.b.StartAudit(kc.p.Storage, &kc.p.Metadata, storage.AuditOperationUpdateLabels, map[string]any{"labels": labels})
+	defer func() { finishAudit(errC) }()
+	defer func() {
+		if errC != nil {
+			v := kc.b.PanicHandler(kc.l)
+			if v != nil {
+				errC = errors.Join(errC, v)
+			}
+		}
+	}()
+
+	if err := kc.b.ValidateClusterType(kc.p.Metadata.ClusterType); err != nil {
+		return err
+	}
+
+	if err := validation.IsValidLabels(kc.ctx, kc.l, labels); err != nil {
+		return err
+	}
+
+	if kc.b.IsLocalProvider(kc.p) {
+		kc.p.Metadata.Region = "LOCAL"
+	}
+
+	if err := kc.p.Storage.Setup(
+		kc.p.Metadata.Provider,
+		kc.p.Metadata.Region,
+		kc.p.Metadata.ClusterName,
+		kc.p.Metadata.ClusterType,
+	); err != nil {
+		return err
+	}
+
+	_, releaseLock, err := kc.b.LockCluster(kc.ctx, kc.p.Storage)
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
+	state, err := kc.p.Storage.Read()
+	if err != nil {
+		return err
+	}
+	if errOp := state.PlatformSpec.State.IsControllerOperationAllowed(consts.OperationConfigure); errOp != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			errOp,
+		)
+	}
+	if err := kc.b.Authorize(controller.ActionUpdate, state); err != nil {
+		return err
+	}
+
+	if err := kc.loadCloud(); err != nil {
+		return err
+	}
+
+	if err := kc.p.Cloud.UpdateLabels(labels); err != nil {
+		return err
+	}
+
+	kc.p.Metadata.Labels = labels
+	kc.l.Success(kc.ctx, "Updated the labels of the cluster", "labels", len(labels))
+	return nil
+}
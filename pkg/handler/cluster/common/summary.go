@@ -23,6 +23,8 @@ type SummaryOutput struct {
 	k8s.SummaryOutput
 }
 
+// ClusterSummary reports the health of the cluster, the kubeconfig comes from Switch
+// so the user has to be allowed to switch to the cluster
 func (kc *Controller) ClusterSummary() (_ *SummaryOutput, errC error) {
 
 	kubeconfig, err := kc.Switch()
@@ -19,6 +19,7 @@ import (
 
 	"github.com/ksctl/ksctl/v2/pkg/consts"
 	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/handler/cluster/controller"
 	"github.com/ksctl/ksctl/v2/pkg/provider/aws"
 	"github.com/ksctl/ksctl/v2/pkg/provider/azure"
 	"github.com/ksctl/ksctl/v2/pkg/provider/local"
@@ -69,33 +70,12 @@ func (kc *Controller) Switch() (_ *string, errC error) {
 				errOp,
 			)
 		}
-	}
-
-	var err error
-	switch kc.p.Metadata.Provider {
-	case consts.CloudAzure:
-		kc.p.Cloud, err = azure.NewClient(kc.ctx, kc.l, kc.b.KsctlWorkloadConf.WorkerCtx, kc.p.Metadata, kc.s, kc.p.Storage, azure.ProvideClient)
-
-	case consts.CloudAws:
-		kc.p.Cloud, err = aws.NewClient(kc.ctx, kc.l, kc.b.KsctlWorkloadConf.WorkerCtx, kc.p.Metadata, kc.s, kc.p.Storage, aws.ProvideClient)
-		if err != nil {
-			break
+		if err := kc.b.Authorize(controller.ActionSwitch, state); err != nil {
+			return nil, err
 		}
-
-	case consts.CloudLocal:
-		kc.p.Cloud, err = local.NewClient(kc.ctx, kc.l, kc.b.KsctlWorkloadConf.WorkerCtx, kc.p.Metadata, kc.s, kc.p.Storage, local.ProvideClient)
-
-	}
-
-	if err != nil {
-		return nil, err
-	}
-
-	if errInit := kc.p.Cloud.InitState(consts.OperationGet); errInit != nil {
-		return nil, errInit
 	}
 
-	if err := kc.p.Cloud.IsPresent(); err != nil {
+	if err := kc.loadCloud(); err != nil {
 		return nil, err
 	}
 
@@ -116,3 +96,29 @@ func (kc *Controller) Switch() (_ *string, errC error) {
 
 	return kubeconfig, nil
 }
+
+// loadCloud creates the cloud client for the cluster selected in the storage and loads its state
+func (kc *Controller) loadCloud() error {
+	var err error
+	switch kc.p.Metadata.Provider {
+	case consts.CloudAzure:
+		kc.p.Cloud, err = azure.NewClient(kc.ctx, kc.l, kc.b.KsctlWorkloadConf.WorkerCtx, kc.p.Metadata, kc.s, kc.p.Storage, azure.ProvideClient)
+
+	case consts.CloudAws:
+		kc.p.Cloud, err = aws.NewClient(kc.ctx, kc.l, kc.b.KsctlWorkloadConf.WorkerCtx, kc.p.Metadata, kc.s, kc.p.Storage, aws.ProvideClient)
+
+	case consts.CloudLocal:
+		kc.p.Cloud, err = local.NewClient(kc.ctx, kc.l, kc.b.KsctlWorkloadConf.WorkerCtx, kc.p.Metadata, kc.s, kc.p.Storage, local.ProvideClient)
+
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if errInit := kc.p.Cloud.InitState(consts.OperationGet); errInit != nil {
+		return errInit
+	}
+
+	return kc.p.Cloud.IsPresent()
+}
@@ -0,0 +1,60 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"github.com/ksctl/ksctl/v2/pkg/config"
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+)
+
+// StartAudit begins the audit entry of the operation on behalf of the user and group of the WorkerCtx.
+// The cluster is taken from meta once the returned func gets called with the outcome of the operation,
+// so it has to be deferred ahead of the panic handler to see the final error.
+// Storages which don't implement storage.Auditor are not audited, a failure to record the entry is only logged
+func (cc *Controller) StartAudit(store storage.Storage, meta *Metadata, op storage.AuditOperation, input any) (finish func(err error)) {
+	auditor, ok := store.(storage.Auditor)
+	if !ok {
+		cc.l.Debug(cc.ctx, "storage doesn't support auditing, skipping the audit entry")
+		return func(error) {}
+	}
+
+	actor := storage.WriterFromContext(cc.KsctlWorkloadConf.WorkerCtx)
+	group := ""
+	if v, ok := config.IsContextPresent(cc.KsctlWorkloadConf.WorkerCtx, consts.KsctlContextGroup); ok {
+		group = v
+	}
+
+	entry, err := storage.NewAuditEntry(meta.Provider, meta.Region, meta.ClusterName, meta.ClusterType, op, actor, group)
+	if err != nil {
+		cc.l.Warn(cc.ctx, "failed to start the audit entry", "Reason", err)
+		return func(error) {}
+	}
+	if entry.Input, err = storage.RedactInput(input); err != nil {
+		cc.l.Warn(cc.ctx, "failed to record the input of the operation", "Reason", err)
+	}
+
+	return func(err error) {
+		entry.Cloud = meta.Provider
+		entry.Region = meta.Region
+		entry.ClusterName = meta.ClusterName
+		entry.ClusterType = meta.ClusterType
+		entry.Finish(err)
+
+		if _err := auditor.RecordAudit(entry); _err != nil {
+			cc.l.Warn(cc.ctx, "failed to record the audit entry", "operation", op, "Reason", _err)
+		}
+	}
+}
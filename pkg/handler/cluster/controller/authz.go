@@ -0,0 +1,167 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"github.com/ksctl/ksctl/v2/pkg/config"
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+)
+
+// Role decides the operations a user can run on the clusters
+type Role string
+
+const (
+	// RoleViewer can only read the clusters
+	RoleViewer Role = "viewer"
+	// RoleOperator can create clusters and run the operations on the clusters
+	// it owns or which belong to its team
+	RoleOperator Role = "operator"
+	// RoleAdmin can run the operations on every cluster
+	RoleAdmin Role = "admin"
+)
+
+func (r Role) rank() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RoleOperator:
+		return 2
+	case RoleAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Action is the kind of operation being authorized
+type Action string
+
+const (
+	ActionRead   Action = "read"
+	ActionCreate Action = "create"
+	ActionDelete Action = "delete"
+	ActionScale  Action = "scale"
+	ActionSwitch Action = "switch"
+	ActionAddon  Action = "addon"
+	ActionUpdate Action = "update"
+)
+
+// AuthorizationPolicy maps the context user and group to their role,
+// a user gets the higher of the roles given to it and to its group
+type AuthorizationPolicy struct {
+	Users  map[string]Role `json:"users,omitempty"`
+	Groups map[string]Role `json:"groups,omitempty"`
+
+	// DefaultRole is given to the users which are not part of Users or Groups
+	DefaultRole Role `json:"default_role"`
+}
+
+// DefaultAuthorizationPolicy is used when KsctlWorkerConfiguration has no policy,
+// every user can run the operations on its own clusters and the ones of its team
+var DefaultAuthorizationPolicy = AuthorizationPolicy{
+	DefaultRole: RoleOperator,
+}
+
+func (p *AuthorizationPolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	if p.DefaultRole.rank() == 0 {
+		return ksctlErrors.WrapErrorf(ksctlErrors.ErrInvalidKsctlRole, "default role %q is not one of viewer, operator or admin", p.DefaultRole)
+	}
+	for u, r := range p.Users {
+		if r.rank() == 0 {
+			return ksctlErrors.WrapErrorf(ksctlErrors.ErrInvalidKsctlRole, "role %q of user %s is not one of viewer, operator or admin", r, u)
+		}
+	}
+	for g, r := range p.Groups {
+		if r.rank() == 0 {
+			return ksctlErrors.WrapErrorf(ksctlErrors.ErrInvalidKsctlRole, "role %q of group %s is not one of viewer, operator or admin", r, g)
+		}
+	}
+	return nil
+}
+
+// RoleOf returns the role of the user who is part of the group
+func (p *AuthorizationPolicy) RoleOf(user, group string) Role {
+	if p == nil {
+		p = &DefaultAuthorizationPolicy
+	}
+	var role Role
+	if r, ok := p.Users[user]; ok {
+		role = r
+	}
+	if r, ok := p.Groups[group]; ok && len(group) != 0 && r.rank() > role.rank() {
+		role = r
+	}
+	if role.rank() == 0 {
+		role = p.DefaultRole
+	}
+	return role
+}
+
+// isMember reports if the user is the owner of the cluster or part of its team.
+// The clusters created before the owner got recorded belong to everyone
+func isMember(state *statefile.StorageDocument, user, group string) bool {
+	owner, team := state.PlatformSpec.Owner, state.PlatformSpec.Team
+	if len(owner) == 0 && len(team) == 0 {
+		return true
+	}
+	return owner == user || (len(team) != 0 && team == group)
+}
+
+// Authorize checks that the user and group of the WorkerCtx can run the action on the cluster
+// with the given state, state is nil when the cluster is yet to be created.
+// The refusals are reported with the ErrUnauthorized code
+func (cc *Controller) Authorize(action Action, state *statefile.StorageDocument) error {
+	user, group := "", ""
+	if v, ok := config.IsContextPresent(cc.KsctlWorkloadConf.WorkerCtx, consts.KsctlContextUser); ok {
+		user = v
+	}
+	if v, ok := config.IsContextPresent(cc.KsctlWorkloadConf.WorkerCtx, consts.KsctlContextGroup); ok {
+		group = v
+	}
+
+	role := cc.KsctlWorkloadConf.Authorization.RoleOf(user, group)
+	cc.l.Debug(cc.ctx, "authorizing the operation", "user", user, "group", group, "role", role, "action", action)
+
+	allowed := false
+	switch {
+	case action == ActionRead:
+		allowed = role.rank() >= RoleViewer.rank()
+	case role == RoleAdmin:
+		allowed = true
+	case role == RoleOperator:
+		allowed = state == nil || isMember(state, user, group)
+	}
+	if allowed {
+		return nil
+	}
+
+	if state == nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrUnauthorized,
+			cc.l.NewError(cc.ctx, "not allowed to run the operation", "user", user, "role", role, "action", action),
+		)
+	}
+	return ksctlErrors.WrapError(
+		ksctlErrors.ErrUnauthorized,
+		cc.l.NewError(cc.ctx, "not allowed to run the operation on the cluster",
+			"user", user, "role", role, "action", action,
+			"cluster", state.ClusterName, "owner", state.PlatformSpec.Owner, "team", state.PlatformSpec.Team),
+	)
+}
@@ -0,0 +1,99 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/logger"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"gotest.tools/v3/assert"
+)
+
+func newAuthzController(user, group string, policy *AuthorizationPolicy) *Controller {
+	ctx := context.WithValue(context.Background(), consts.KsctlContextUser, user)
+	ctx = context.WithValue(ctx, consts.KsctlContextGroup, group)
+	return NewBaseController(
+		context.Background(),
+		logger.NewStructuredLogger(-1, os.Stdout),
+		KsctlWorkerConfiguration{WorkerCtx: ctx, Authorization: policy},
+	)
+}
+
+func clusterOwnedBy(owner, team string) *statefile.StorageDocument {
+	doc := &statefile.StorageDocument{ClusterName: "demo"}
+	doc.PlatformSpec.Owner = owner
+	doc.PlatformSpec.Team = team
+	return doc
+}
+
+func TestAuthorize(t *testing.T) {
+	policy := &AuthorizationPolicy{
+		Users:       map[string]Role{"root@ksctl.com": RoleAdmin},
+		Groups:      map[string]Role{"auditors": RoleViewer, "platform": RoleAdmin},
+		DefaultRole: RoleOperator,
+	}
+
+	testCases := []struct {
+		name    string
+		user    string
+		group   string
+		policy  *AuthorizationPolicy
+		action  Action
+		state   *statefile.StorageDocument
+		allowed bool
+	}{
+		{"owner deletes", "alice", "dev", policy, ActionDelete, clusterOwnedBy("alice", "ops"), true},
+		{"team member scales", "bob", "ops", policy, ActionScale, clusterOwnedBy("alice", "ops"), true},
+		{"stranger deletes", "carol", "dev", policy, ActionDelete, clusterOwnedBy("alice", "ops"), false},
+		{"stranger without team switches", "carol", "", policy, ActionSwitch, clusterOwnedBy("alice", ""), false},
+		{"operator creates", "carol", "dev", policy, ActionCreate, nil, true},
+		{"viewer reads", "dave", "auditors", policy, ActionRead, clusterOwnedBy("alice", "ops"), true},
+		{"viewer creates", "dave", "auditors", policy, ActionCreate, nil, false},
+		{"viewer of own team installs addon", "dave", "auditors", policy, ActionAddon, clusterOwnedBy("dave", "auditors"), false},
+		{"admin user deletes", "root@ksctl.com", "", policy, ActionDelete, clusterOwnedBy("alice", "ops"), true},
+		{"admin group deletes", "erin", "platform", policy, ActionDelete, clusterOwnedBy("alice", "ops"), true},
+		{"cluster without owner", "carol", "dev", policy, ActionDelete, clusterOwnedBy("", ""), true},
+		{"default policy owner", "alice", "", nil, ActionDelete, clusterOwnedBy("alice", ""), true},
+		{"default policy stranger", "carol", "", nil, ActionDelete, clusterOwnedBy("alice", "ops"), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := newAuthzController(tc.user, tc.group, tc.policy).Authorize(tc.action, tc.state)
+			if tc.allowed {
+				assert.NilError(t, err)
+			} else {
+				assert.Assert(t, ksctlErrors.IsUnauthorized(err), "expected unauthorized error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthorizationPolicy_Validate(t *testing.T) {
+	var nilPolicy *AuthorizationPolicy
+	assert.NilError(t, nilPolicy.Validate())
+	assert.NilError(t, DefaultAuthorizationPolicy.Validate())
+
+	err := (&AuthorizationPolicy{DefaultRole: "root"}).Validate()
+	assert.Assert(t, ksctlErrors.IsInvalidKsctlRole(err))
+
+	err = (&AuthorizationPolicy{DefaultRole: RoleViewer, Groups: map[string]Role{"ops": "owner"}}).Validate()
+	assert.Assert(t, ksctlErrors.IsInvalidKsctlRole(err))
+}
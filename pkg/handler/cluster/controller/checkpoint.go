@@ -0,0 +1,87 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"sync"
+
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+)
+
+// Checkpoints records the phases of a creation into the state.
+// The outcomes are kept aside until Flush, as the providers write the same state from the goroutines of the
+// concurrent steps. A nil store never writes, which is what the plan mode needs
+type Checkpoints struct {
+	mu      sync.Mutex
+	state   *statefile.StorageDocument
+	store   storage.Storage
+	pending []pendingCheckpoint
+}
+
+type pendingCheckpoint struct {
+	phase statefile.CreationPhase
+	step  string
+	err   error
+}
+
+func NewCheckpoints(state *statefile.StorageDocument, store storage.Storage) *Checkpoints {
+	return &Checkpoints{state: state, store: store}
+}
+
+// Done tells whether a previous attempt already completed the step of the phase, so it can be skipped
+func (c *Checkpoints) Done(phase statefile.CreationPhase, step string) bool {
+	return c.state.IsCheckpointCompleted(phase, step)
+}
+
+// Record keeps the outcome of the step of the phase until the next Flush, it is safe for concurrent use
+func (c *Checkpoints) Record(phase statefile.CreationPhase, step string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending = append(c.pending, pendingCheckpoint{phase: phase, step: step, err: err})
+}
+
+// Flush applies the recorded outcomes to the state and writes it.
+// It must not run while the steps it records are still in progress
+func (c *Checkpoints) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.pending) == 0 {
+		return nil
+	}
+
+	for _, p := range c.pending {
+		c.state.SetCheckpoint(p.phase, p.step, p.err)
+	}
+	c.pending = nil
+
+	if c.store == nil {
+		return nil
+	}
+	return c.store.Write(c.state)
+}
+
+// Checkpoint records the outcome of a sequential step and writes it right away,
+// the err of the step is returned as is when the write succeeds
+func (c *Checkpoints) Checkpoint(phase statefile.CreationPhase, step string, err error) error {
+	c.Record(phase, step, err)
+	if errFlush := c.Flush(); errFlush != nil {
+		if err != nil {
+			return err
+		}
+		return errFlush
+	}
+	return err
+}
@@ -0,0 +1,84 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"gotest.tools/v3/assert"
+)
+
+func TestCheckpoints(t *testing.T) {
+	state := &statefile.StorageDocument{ClusterName: "demo"}
+	cp := NewCheckpoints(state, nil)
+
+	assert.NilError(t, cp.Checkpoint(statefile.PhaseNetwork, "", nil))
+	assert.Assert(t, cp.Done(statefile.PhaseNetwork, ""))
+
+	errVM := errors.New("quota exceeded")
+	wg := &sync.WaitGroup{}
+	for no, err := range []error{nil, errVM, nil} {
+		wg.Add(1)
+		go func(no int, err error) {
+			defer wg.Done()
+			cp.Record(statefile.PhaseVM, strconv.Itoa(no), err)
+		}(no, err)
+	}
+	wg.Wait()
+
+	assert.Assert(t, !cp.Done(statefile.PhaseVM, "0"), "records are only applied on flush")
+	assert.NilError(t, cp.Flush())
+
+	assert.Assert(t, cp.Done(statefile.PhaseVM, "0"))
+	assert.Assert(t, !cp.Done(statefile.PhaseVM, "1"))
+	assert.Assert(t, cp.Done(statefile.PhaseVM, "2"))
+
+	failed := state.FailedCheckpoints()
+	assert.Equal(t, len(failed), 1)
+	assert.Equal(t, failed[0].Phase, statefile.PhaseVM)
+	assert.Equal(t, failed[0].Step, "1")
+	assert.Equal(t, failed[0].Reason, errVM.Error())
+
+	// the retry of the failed step replaces its checkpoint
+	assert.NilError(t, cp.Checkpoint(statefile.PhaseVM, "1", nil))
+	assert.Assert(t, cp.Done(statefile.PhaseVM, "1"))
+	assert.Equal(t, len(state.FailedCheckpoints()), 0)
+	assert.Equal(t, len(state.Checkpoints), 4)
+
+	assert.Equal(t, cp.Checkpoint(statefile.PhaseControlPlane0, "", errVM), errVM)
+	assert.Assert(t, !cp.Done(statefile.PhaseControlPlane0, ""))
+
+	// the cni got installed and the app failed, the retry resumes from the app
+	assert.NilError(t, cp.Checkpoint(statefile.PhaseControlPlane0, "", nil))
+	assert.NilError(t, cp.Checkpoint(statefile.PhaseCNI, "", nil))
+	errApp := errors.New("release not found")
+	assert.Equal(t, cp.Checkpoint(statefile.PhaseApps, "kcm", errApp), errApp)
+	assert.Assert(t, cp.Done(statefile.PhaseCNI, ""))
+	assert.Assert(t, !cp.Done(statefile.PhaseApps, "kcm"))
+
+	failed = state.FailedCheckpoints()
+	assert.Equal(t, len(failed), 1)
+	assert.Equal(t, failed[0].Phase, statefile.PhaseApps)
+	assert.Equal(t, failed[0].Step, "kcm")
+	assert.Equal(t, failed[0].Reason, errApp.Error())
+
+	assert.NilError(t, cp.Checkpoint(statefile.PhaseApps, "kcm", nil))
+	assert.Assert(t, cp.Done(statefile.PhaseApps, "kcm"))
+	assert.Equal(t, len(state.FailedCheckpoints()), 0)
+}
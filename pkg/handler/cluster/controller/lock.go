@@ -0,0 +1,85 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/ksctl/ksctl/v2/pkg/config"
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+)
+
+// LockCluster acquires the operation lock of the cluster which was selected with storage.Setup,
+// the lease is renewed in the background till the returned release func gets called.
+// The returned context is derived from ctx and gets cancelled once the lease is lost, the operation
+// run under the lock has to use it so it stops instead of racing the next holder of the lock.
+// Storages which don't implement storage.Locker are not locked
+func (cc *Controller) LockCluster(ctx context.Context, store storage.Storage) (lockCtx context.Context, release func(), err error) {
+	locker, ok := store.(storage.Locker)
+	if !ok {
+		cc.l.Debug(cc.ctx, "storage doesn't support locking, skipping the cluster lock")
+		return ctx, func() {}, nil
+	}
+
+	owner := "anonymous"
+	if v, ok := config.IsContextPresent(cc.KsctlWorkloadConf.WorkerCtx, consts.KsctlContextUser); ok {
+		owner = v
+	}
+
+	ttl := consts.DurationClusterLockTTL
+	lease, err := locker.Lock(owner, ttl)
+	if err != nil {
+		return nil, nil, err
+	}
+	cc.l.Debug(cc.ctx, "acquired the cluster lock", "owner", lease.Owner, "expiresAt", lease.ExpiresAt)
+
+	lockCtx, cancel := context.WithCancelCause(ctx)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := locker.Renew(lease, ttl); err != nil {
+					// someone else holds the lease now, a failure to reach the storage is retried
+					// on the next tick as long as the lease has not expired
+					if ksctlErrors.IsClusterLocked(err) || lease.IsExpired(time.Now()) {
+						cc.l.Error("lost the cluster lock, stopping the operation", "Reason", err)
+						cancel(err)
+						return
+					}
+					cc.l.Warn(cc.ctx, "failed to renew the cluster lock", "Reason", err)
+				}
+			}
+		}
+	}()
+
+	return lockCtx, func() {
+		close(stop)
+		<-done
+		cancel(nil)
+		if err := locker.Unlock(lease); err != nil {
+			cc.l.Warn(cc.ctx, "failed to release the cluster lock", "Reason", err)
+		}
+	}, nil
+}
@@ -17,6 +17,7 @@ package controller
 import (
 	"context"
 	"runtime/debug"
+	"time"
 
 	"github.com/ksctl/ksctl/v2/pkg/addons"
 	"github.com/ksctl/ksctl/v2/pkg/bootstrap"
@@ -25,8 +26,10 @@ import (
 	"github.com/ksctl/ksctl/v2/pkg/consts"
 	"github.com/ksctl/ksctl/v2/pkg/errors"
 	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/events"
 	"github.com/ksctl/ksctl/v2/pkg/logger"
 	"github.com/ksctl/ksctl/v2/pkg/provider"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
 	"github.com/ksctl/ksctl/v2/pkg/storage"
 )
 
@@ -47,6 +50,17 @@ type KsctlWorkerConfiguration struct {
 	WorkerCtx   context.Context
 	PollerCache cache.Cache
 	Storage     storage.Storage
+
+	// Authorization decides who can run the operations on the clusters,
+	// nil uses the DefaultAuthorizationPolicy
+	Authorization *AuthorizationPolicy
+
+	// OperationTimeouts bounds the create, delete and scale operations, the ones missing only end with their context.
+	// The Storage is expected to be built from a context which outlives them, so the failed state still gets written
+	OperationTimeouts map[consts.KsctlOperation]time.Duration
+
+	// Events receives the progress of the create, delete and scale operations, nil reports nothing
+	Events events.Handler
 }
 
 type Metadata struct {
@@ -70,11 +84,33 @@ type Metadata struct {
 	NoCP int `json:"desired_no_of_controlplane_nodes"` // No of Controlplane VMs
 	NoDS int `json:"desired_no_of_datastore_nodes"`    // No of DataStore VMs
 
+	// NoMPMin and NoMPMax are the autoscaling bounds of the managed nodes, both 0 keeps the autoscaling disabled
+	NoMPMin int `json:"min_no_of_managed_nodes,omitempty"`
+	NoMPMax int `json:"max_no_of_managed_nodes,omitempty"`
+
+	// WorkerPools are the named pools of workerplanes of a self-managed cluster,
+	// without any the workerplanes form the default pool of NoWP nodes of WorkerPlaneNodeType
+	WorkerPools []statefile.WorkerPool `json:"worker_pools,omitempty"`
+
 	EtcdVersion string `json:"etcd_version"`
 
 	// Addons Helps us with specifying cloud managed cluster addons (aks, eks, gke)
 	// to k3s, kubeadm specific as well
 	Addons addons.ClusterAddons `json:"addons,omitempty"`
+
+	// Labels are the user-defined key/value pairs stored with the cluster
+	// and applied as tags to the cloud resources it creates
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// DesiredWorkerPools returns the worker pools of the metadata, the default pool when it has none
+func (m Metadata) DesiredWorkerPools() []statefile.WorkerPool {
+	if len(m.WorkerPools) == 0 {
+		return []statefile.WorkerPool{
+			{Name: statefile.DefaultWorkerPool, VMSize: m.WorkerPlaneNodeType, Count: m.NoWP},
+		}
+	}
+	return m.WorkerPools
 }
 
 type Controller struct {
@@ -97,6 +133,23 @@ func NewBaseController(
 	return b
 }
 
+// OperationContext derives the context of a long-running operation from ctx, with the deadline of OperationTimeouts when set.
+// Every cloud call, ssh session and pause of the operation ends once it is done, and its progress is reported to the Events
+func (cc *Controller) OperationContext(ctx context.Context, op consts.KsctlOperation, clusterName string) (context.Context, context.CancelFunc) {
+	ctx = events.NewContext(ctx, cc.KsctlWorkloadConf.Events, op, clusterName)
+	events.Emit(ctx, events.Event{
+		Phase:    events.PhaseOperation,
+		Resource: "cluster",
+		Name:     clusterName,
+		Status:   events.StatusStarted,
+	})
+
+	if d, ok := cc.KsctlWorkloadConf.OperationTimeouts[op]; ok && d > 0 {
+		return context.WithTimeout(ctx, d)
+	}
+	return context.WithCancel(ctx)
+}
+
 func (cc *Controller) PanicHandler(log logger.Logger) error {
 	if r := recover(); r != nil {
 		return errors.WrapErrorf(ksctlErrors.ErrPanic, "Cause: {%v}\nTraceback\n%v", r, string(debug.Stack()))
@@ -0,0 +1,55 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	"github.com/ksctl/ksctl/v2/pkg/logger"
+	"gotest.tools/v3/assert"
+)
+
+func TestOperationContext(t *testing.T) {
+	b := NewBaseController(
+		context.Background(),
+		logger.NewStructuredLogger(-1, os.Stdout),
+		KsctlWorkerConfiguration{
+			WorkerCtx: context.Background(),
+			OperationTimeouts: map[consts.KsctlOperation]time.Duration{
+				consts.OperationCreate: time.Hour,
+			},
+		},
+	)
+
+	ctx, cancel := b.OperationContext(context.Background(), consts.OperationCreate, "demo")
+	deadline, ok := ctx.Deadline()
+	assert.Assert(t, ok, "create has a timeout")
+	assert.Assert(t, time.Until(deadline) <= time.Hour)
+	cancel()
+	assert.Equal(t, ctx.Err(), context.Canceled)
+
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel = b.OperationContext(parent, consts.OperationDelete, "demo")
+	defer cancel()
+	_, ok = ctx.Deadline()
+	assert.Assert(t, !ok, "delete has no timeout")
+
+	cancelParent()
+	assert.Equal(t, ctx.Err(), context.Canceled, "cancelling the caller ends the operation")
+}
@@ -0,0 +1,113 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	"github.com/ksctl/ksctl/v2/pkg/logger"
+	"github.com/ksctl/ksctl/v2/pkg/ssh"
+)
+
+type PlanAction string
+
+const (
+	PlanActionCreate PlanAction = "create"
+	PlanActionDelete PlanAction = "delete"
+)
+
+// PlannedResource is a cloud resource which the planned operation would create or delete
+type PlannedResource struct {
+	Action PlanAction       `json:"action"`
+	Kind   string           `json:"kind"`
+	Name   string           `json:"name"`
+	Role   consts.KsctlRole `json:"role,omitempty"`
+	VMType string           `json:"vm_type,omitempty"`
+	Count  int              `json:"count,omitempty"`
+}
+
+type PlannedScript struct {
+	Name        string `json:"name"`
+	ShellScript string `json:"shell_script"`
+}
+
+// PlannedNode holds the scripts which would run on a node, in execution order.
+// Host is the public ip of the node or a placeholder when the node doesn't exist yet
+type PlannedNode struct {
+	Role    consts.KsctlRole `json:"role"`
+	Index   int              `json:"index"`
+	Host    string           `json:"host"`
+	Scripts []PlannedScript  `json:"scripts"`
+}
+
+func NewPlannedNode(role consts.KsctlRole, index int, host string, scripts []ssh.Script) PlannedNode {
+	n := PlannedNode{Role: role, Index: index, Host: host, Scripts: make([]PlannedScript, 0, len(scripts))}
+	for _, s := range scripts {
+		n.Scripts = append(n.Scripts, PlannedScript{Name: s.Name, ShellScript: s.ShellScript})
+	}
+	return n
+}
+
+// Plan is the outcome of walking an operation without side effects.
+// MonthlyCost is the estimated monthly cost of the machines the operation would create
+type Plan struct {
+	Operation   consts.KsctlOperation   `json:"operation"`
+	Cloud       consts.KsctlCloud       `json:"cloud"`
+	Region      string                  `json:"region"`
+	ClusterName string                  `json:"cluster_name"`
+	ClusterType consts.KsctlClusterType `json:"cluster_type"`
+
+	Resources []PlannedResource `json:"resources"`
+	Nodes     []PlannedNode     `json:"nodes,omitempty"`
+
+	MonthlyCost float64 `json:"estimated_monthly_cost"`
+	Currency    string  `json:"currency,omitempty"`
+}
+
+func NewPlan(meta Metadata, op consts.KsctlOperation) *Plan {
+	return &Plan{
+		Operation:   op,
+		Cloud:       meta.Provider,
+		Region:      meta.Region,
+		ClusterName: meta.ClusterName,
+		ClusterType: meta.ClusterType,
+	}
+}
+
+// Print renders the resources and the scripts of every node as tables
+func (p *Plan) Print(ctx context.Context, l logger.Logger) {
+	rows := make([][]string, 0, len(p.Resources))
+	for i, r := range p.Resources {
+		rows = append(rows, []string{strconv.Itoa(i + 1), string(r.Action), r.Kind, r.Name, string(r.Role), r.VMType})
+	}
+	l.Table(ctx, []string{"Step", "Action", "Kind", "Name", "Role", "VMType"}, rows)
+
+	if len(p.Nodes) == 0 {
+		return
+	}
+
+	rows = make([][]string, 0, len(p.Nodes))
+	for _, n := range p.Nodes {
+		names := make([]string, 0, len(n.Scripts))
+		for _, s := range n.Scripts {
+			names = append(names, s.Name)
+		}
+		rows = append(rows, []string{string(n.Role), strconv.Itoa(n.Index), n.Host, strings.Join(names, ", ")})
+	}
+	l.Table(ctx, []string{"Role", "Index", "Host", "Scripts"}, rows)
+}
@@ -48,6 +48,9 @@ func (cc *Controller) ValidateMetadata(c *Client) error {
 			cc.l.NewError(cc.ctx, "invalid format for context value `USERID`", "Reason", "Make sure the value", "type", "string", "format", `email`, "team", "uuid"),
 		)
 	}
+	if err := cc.KsctlWorkloadConf.Authorization.Validate(); err != nil {
+		return err
+	}
 	if !validation.ValidateStorage(c.Metadata.StateLocation) {
 		return ksctlErrors.WrapError(
 			ksctlErrors.ErrInvalidStorageProvider,
@@ -66,5 +69,9 @@ func (cc *Controller) ValidateMetadata(c *Client) error {
 		)
 	}
 
+	if err := validation.IsValidLabels(cc.ctx, cc.l, meta.Labels); err != nil {
+		return err
+	}
+
 	return nil
 }
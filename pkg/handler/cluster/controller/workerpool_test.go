@@ -0,0 +1,55 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"gotest.tools/v3/assert"
+)
+
+func TestWorkerPools(t *testing.T) {
+	meta := Metadata{WorkerPlaneNodeType: "small", NoWP: 2}
+	assert.DeepEqual(t, meta.DesiredWorkerPools(), []statefile.WorkerPool{
+		{Name: statefile.DefaultWorkerPool, VMSize: "small", Count: 2},
+	})
+
+	meta.WorkerPools = []statefile.WorkerPool{{Name: "gpu", VMSize: "large", Count: 1}}
+	assert.DeepEqual(t, meta.DesiredWorkerPools(), meta.WorkerPools)
+
+	state := &statefile.StorageDocument{
+		WorkerPools: []statefile.WorkerPool{
+			{Name: statefile.DefaultWorkerPool, VMSize: "small"},
+			{Name: "gpu", VMSize: "large", DiskSize: 100},
+			{Name: "spot", VMSize: "small"},
+		},
+		WorkerPlanePools: []string{statefile.DefaultWorkerPool, "gpu", "gpu", "spot"},
+	}
+
+	assert.Equal(t, state.WorkerPoolOf(1).Name, "gpu")
+	assert.Equal(t, state.WorkerPoolOf(1).DiskSize, 100)
+	assert.Assert(t, state.WorkerPoolOf(4) == nil)
+	assert.Assert(t, state.WorkerPool("cpu") == nil)
+
+	// the spot pool loses its only workerplane
+	state.WorkerPlanePools = state.WorkerPlanePools[:3]
+	state.SyncWorkerPools()
+
+	assert.Equal(t, len(state.WorkerPools), 2)
+	assert.Equal(t, state.WorkerPool(statefile.DefaultWorkerPool).Count, 1)
+	assert.Equal(t, state.WorkerPool("gpu").Count, 2)
+	assert.Assert(t, state.WorkerPool("spot") == nil)
+}
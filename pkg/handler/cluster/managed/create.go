@@ -20,12 +20,17 @@ import (
 	bootstrapHandler "github.com/ksctl/ksctl/v2/pkg/bootstrap/handler"
 	"github.com/ksctl/ksctl/v2/pkg/consts"
 	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/events"
+	"github.com/ksctl/ksctl/v2/pkg/handler/cluster/controller"
 	providerHandler "github.com/ksctl/ksctl/v2/pkg/provider/handler"
 	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
 	"github.com/ksctl/ksctl/v2/pkg/validation"
 )
 
 func (kc *Controller) Create() (errC error) {
+	finishAudit := kc.b.StartAudit(kc.p.Storage, &kc.p.Metadata, storage.AuditOperationCreate, kc.p.Metadata)
+	defer func() { finishAudit(errC) }()
 	defer func() {
 		v := kc.b.PanicHandler(kc.l)
 		if v != nil {
@@ -62,6 +67,12 @@ func (kc *Controller) Create() (errC error) {
 		return err
 	}
 
+	lockCtx, releaseLock, err := kc.b.LockCluster(kc.ctx, kc.p.Storage)
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
 	if state, err := kc.p.Storage.Read(); err != nil {
 		if !ksctlErrors.IsNoMatchingRecordsFound(err) {
 			return err
@@ -75,6 +86,9 @@ func (kc *Controller) Create() (errC error) {
 				errOp,
 			)
 		}
+		if err := kc.b.Authorize(controller.ActionCreate, nil); err != nil {
+			return err
+		}
 	} else {
 		kc.l.Debug(kc.ctx, "Found previous state, using it")
 		if errOp := state.PlatformSpec.State.IsControllerOperationAllowed(consts.OperationCreate); errOp != nil {
@@ -83,6 +97,9 @@ func (kc *Controller) Create() (errC error) {
 				errOp,
 			)
 		}
+		if err := kc.b.Authorize(controller.ActionCreate, state); err != nil {
+			return err
+		}
 	}
 
 	if err := validation.IsValidKsctlClusterAddons(kc.ctx, kc.l, kc.p.Metadata.Addons); err != nil {
@@ -107,8 +124,20 @@ func (kc *Controller) Create() (errC error) {
 		}
 	}()
 
+	opCtx, cancel := kc.b.OperationContext(lockCtx, consts.OperationCreate, kc.p.Metadata.ClusterName)
+	defer cancel()
+	defer func() { events.Finish(opCtx, errC) }()
+
+	// the network, the managed cluster, the cni and the apps
+	apps := len(bootstrapHandler.AppsOf(kc.p.Metadata.Addons))
+	if kc.b.IsLocalProvider(kc.p) {
+		events.Expect(opCtx, 2+apps)
+	} else {
+		events.Expect(opCtx, 3+apps)
+	}
+
 	kpc, err := providerHandler.NewController(
-		kc.ctx,
+		opCtx,
 		kc.l,
 		kc.b,
 		kc.s,
@@ -125,7 +154,7 @@ func (kc *Controller) Create() (errC error) {
 	}
 
 	kbc, err := bootstrapHandler.NewController(
-		kc.ctx,
+		opCtx,
 		kc.l,
 		kc.b,
 		kc.s,
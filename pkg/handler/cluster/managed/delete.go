@@ -19,11 +19,16 @@ import (
 
 	"github.com/ksctl/ksctl/v2/pkg/consts"
 	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/events"
+	"github.com/ksctl/ksctl/v2/pkg/handler/cluster/controller"
 	providerHandler "github.com/ksctl/ksctl/v2/pkg/provider/handler"
 	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
 )
 
 func (kc *Controller) Delete() (errC error) {
+	finishAudit := kc.b.StartAudit(kc.p.Storage, &kc.p.Metadata, storage.AuditOperationDelete, kc.p.Metadata)
+	defer func() { finishAudit(errC) }()
 	defer func() {
 		v := kc.b.PanicHandler(kc.l)
 		if v != nil {
@@ -51,6 +56,12 @@ func (kc *Controller) Delete() (errC error) {
 		return err
 	}
 
+	lockCtx, releaseLock, err := kc.b.LockCluster(kc.ctx, kc.p.Storage)
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
 	if state, err := kc.p.Storage.Read(); err != nil {
 		if !ksctlErrors.IsNoMatchingRecordsFound(err) {
 			return err
@@ -73,6 +84,9 @@ func (kc *Controller) Delete() (errC error) {
 				errOp,
 			)
 		}
+		if err := kc.b.Authorize(controller.ActionDelete, state); err != nil {
+			return err
+		}
 	}
 
 	defer func() {
@@ -86,8 +100,12 @@ func (kc *Controller) Delete() (errC error) {
 		}
 	}()
 
+	opCtx, cancel := kc.b.OperationContext(lockCtx, consts.OperationDelete, kc.p.Metadata.ClusterName)
+	defer cancel()
+	defer func() { events.Finish(opCtx, errC) }()
+
 	kpc, err := providerHandler.NewController(
-		kc.ctx,
+		opCtx,
 		kc.l,
 		kc.b,
 		kc.s,
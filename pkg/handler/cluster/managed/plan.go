@@ -0,0 +1,106 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package managed
+
+import (
+	"errors"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/handler/cluster/controller"
+	"github.com/ksctl/ksctl/v2/pkg/handler/cluster/metadata"
+	providerHandler "github.com/ksctl/ksctl/v2/pkg/provider/handler"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/validation"
+)
+
+// PlanCreate walks the provisioning flow of Create without calling the cloud. It returns the
+// resources Create would make and the estimated monthly cost of the cluster.
+// Plans don't take the lock of the cluster as they never write to the storage
+func (kc *Controller) PlanCreate() (_ *controller.Plan, errC error) {
+	defer func() {
+		v := kc.b.PanicHandler(kc.l)
+		if v != nil {
+			errC = errors.Join(errC, v)
+		}
+	}()
+
+	if kc.b.IsLocalProvider(kc.p) {
+		kc.p.Metadata.Region = "LOCAL"
+	}
+
+	if !validation.ValidateDistro(kc.p.Metadata.K8sDistro) {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidBootstrapProvider,
+			kc.l.NewError(
+				kc.ctx, "Problem in validation", "bootstrap", kc.p.Metadata.K8sDistro,
+			),
+		)
+	}
+
+	if err := kc.p.Storage.Setup(
+		kc.p.Metadata.Provider,
+		kc.p.Metadata.Region,
+		kc.p.Metadata.ClusterName,
+		consts.ClusterTypeMang,
+	); err != nil {
+		return nil, err
+	}
+
+	state, err := kc.p.Storage.Read()
+	if err != nil {
+		if !ksctlErrors.IsNoMatchingRecordsFound(err) {
+			return nil, err
+		}
+
+		if errOp := statefile.Fresh.IsControllerOperationAllowed(consts.OperationCreate); errOp != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInvalidUserInput,
+				errOp,
+			)
+		}
+	} else if errOp := state.PlatformSpec.State.IsControllerOperationAllowed(consts.OperationCreate); errOp != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			errOp,
+		)
+	}
+	if err := kc.b.Authorize(controller.ActionCreate, state); err != nil {
+		return nil, err
+	}
+
+	if err := validation.IsValidKsctlClusterAddons(kc.ctx, kc.l, kc.p.Metadata.Addons); err != nil {
+		return nil, err
+	}
+
+	kpc := providerHandler.NewPlanController(kc.ctx, kc.l, kc.b, state, kc.p)
+
+	if _, err := kpc.CreateManagedCluster(); err != nil {
+		return nil, err
+	}
+
+	plan := controller.NewPlan(kc.p.Metadata, consts.OperationCreate)
+	plan.Resources = kpc.PlannedResources()
+
+	mc, err := metadata.NewController(kc.ctx, kc.l, kc.b.KsctlWorkloadConf, kc.p)
+	if err != nil {
+		return nil, err
+	}
+	if plan.MonthlyCost, plan.Currency, err = mc.EstimateMonthlyCost(kc.p.Metadata); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/ksctl/ksctl/v2/pkg/addons"
 	"github.com/ksctl/ksctl/v2/pkg/bootstrap/handler/cni"
 	"github.com/ksctl/ksctl/v2/pkg/consts"
+	"github.com/ksctl/ksctl/v2/pkg/handler/cluster/controller"
 	"golang.org/x/mod/semver"
 
 	"github.com/ksctl/ksctl/v2/pkg/provider"
@@ -79,6 +80,16 @@ type PriceCalculatorInput struct {
 	LoadBalancerMachine provider.InstanceRegionOutput
 
 	ManagedControlPlaneMachine provider.ManagedClusterOutput
+
+	// WorkerPools are the worker pools of a self managed cluster, when set they are priced in place of NoOfWorkerNodes and WorkerMachine
+	WorkerPools []WorkerPoolPrice
+}
+
+// WorkerPoolPrice is a worker pool along with the price of its vm size
+type WorkerPoolPrice struct {
+	Name    string
+	Count   int
+	Machine provider.InstanceRegionOutput
 }
 
 func (kc *Controller) PriceCalculator(inp PriceCalculatorInput) (float64, error) {
@@ -89,6 +100,103 @@ func (kc *Controller) PriceCalculator(inp PriceCalculatorInput) (float64, error)
 	}
 }
 
+// EstimateMonthlyCost looks up the prices of the node types of meta in its region and returns the
+// monthly cost computed by PriceCalculator. The node types left empty are not priced,
+// the worker pools of meta are priced each on its own vm size in place of its workerplane node type
+func (kc *Controller) EstimateMonthlyCost(meta controller.Metadata) (_ float64, currency string, errC error) {
+	defer func() {
+		if errC != nil {
+			v := kc.b.PanicHandler(kc.l)
+			if v != nil {
+				errC = errors.Join(errC, v)
+			}
+		}
+	}()
+
+	currency = "USD"
+	if kc.b.IsLocalProvider(kc.client) {
+		return 0.0, currency, nil
+	}
+
+	prices := map[string]provider.InstanceRegionOutput{}
+	priceOf := func(instanceType string) (provider.InstanceRegionOutput, error) {
+		if len(instanceType) == 0 {
+			return provider.InstanceRegionOutput{}, nil
+		}
+		if v, ok := prices[instanceType]; ok {
+			return v, nil
+		}
+		v, err := kc.cc.GetPriceInstanceType(meta.Region, instanceType)
+		if err != nil {
+			return provider.InstanceRegionOutput{}, err
+		}
+		if len(v.Price.Currency) != 0 {
+			currency = v.Price.Currency
+		}
+		prices[instanceType] = *v
+		return *v, nil
+	}
+
+	inp := PriceCalculatorInput{}
+	var err error
+	if meta.ClusterType == consts.ClusterTypeMang {
+		inp.NoOfWorkerNodes = meta.NoMP
+		if inp.WorkerMachine, err = priceOf(meta.ManagedNodeType); err != nil {
+			return 0.0, "", err
+		}
+
+		offerings, err := kc.cc.GetAvailableManagedK8sManagementOfferings(meta.Region, &meta.ManagedNodeType)
+		if err != nil {
+			return 0.0, "", err
+		}
+		if len(offerings) != 0 {
+			inp.ManagedControlPlaneMachine = offerings[0]
+		}
+	} else {
+		inp.NoOfWorkerNodes = meta.NoWP
+		inp.NoOfControlPlaneNodes = meta.NoCP
+		inp.NoOfEtcdNodes = meta.NoDS
+
+		workerPlaneNodeType := meta.WorkerPlaneNodeType
+		if len(meta.WorkerPools) != 0 {
+			workerPlaneNodeType = ""
+			inp.NoOfWorkerNodes = 0
+		}
+		for _, pool := range meta.WorkerPools {
+			machine, err := priceOf(pool.VMSize)
+			if err != nil {
+				return 0.0, "", err
+			}
+			inp.WorkerPools = append(inp.WorkerPools, WorkerPoolPrice{
+				Name:    pool.Name,
+				Count:   pool.Count,
+				Machine: machine,
+			})
+		}
+
+		for _, m := range []struct {
+			instanceType string
+			out          *provider.InstanceRegionOutput
+		}{
+			{workerPlaneNodeType, &inp.WorkerMachine},
+			{meta.ControlPlaneNodeType, &inp.ControlPlaneMachine},
+			{meta.DataStoreNodeType, &inp.EtcdMachine},
+			{meta.LoadBalancerNodeType, &inp.LoadBalancerMachine},
+		} {
+			if *m.out, err = priceOf(m.instanceType); err != nil {
+				return 0.0, "", err
+			}
+		}
+	}
+	inp.Currency = currency
+
+	total, err := kc.PriceCalculator(inp)
+	if err != nil {
+		return 0.0, "", err
+	}
+	return total, currency, nil
+}
+
 func convertToHumanReadable(price float64, currency string) string {
 	symbol := map[string]rune{
 		"USD": '$',
@@ -107,14 +215,11 @@ func convertToHumanReadable(price float64, currency string) string {
 }
 
 func (kc *Controller) priceCalculatorForSelfManagedCluster(inp PriceCalculatorInput) (float64, error) {
-	workerCost := float64(inp.NoOfWorkerNodes) * inp.WorkerMachine.GetCost()
 	controlPlaneCost := float64(inp.NoOfControlPlaneNodes) * inp.ControlPlaneMachine.GetCost()
 	etcdCost := float64(inp.NoOfEtcdNodes) * inp.EtcdMachine.GetCost()
 	lbCost := inp.LoadBalancerMachine.GetCost()
 	currency := inp.Currency
 
-	total := workerCost + controlPlaneCost + etcdCost + lbCost
-
 	headers := []string{"Resource", "UnitCost", "Quantity", "Cost"}
 	rows := [][]string{
 		{
@@ -123,12 +228,32 @@ func (kc *Controller) priceCalculatorForSelfManagedCluster(inp PriceCalculatorIn
 			strconv.Itoa(inp.NoOfControlPlaneNodes),
 			convertToHumanReadable(controlPlaneCost, currency),
 		},
-		{
+	}
+
+	workerCost := 0.0
+	if len(inp.WorkerPools) == 0 {
+		workerCost = float64(inp.NoOfWorkerNodes) * inp.WorkerMachine.GetCost()
+		rows = append(rows, []string{
 			"Worker Node(s)",
 			convertToHumanReadable(inp.WorkerMachine.GetCost(), currency),
 			strconv.Itoa(inp.NoOfWorkerNodes),
 			convertToHumanReadable(workerCost, currency),
-		},
+		})
+	}
+	for _, pool := range inp.WorkerPools {
+		poolCost := float64(pool.Count) * pool.Machine.GetCost()
+		workerCost += poolCost
+		rows = append(rows, []string{
+			"Worker Pool " + pool.Name,
+			convertToHumanReadable(pool.Machine.GetCost(), currency),
+			strconv.Itoa(pool.Count),
+			convertToHumanReadable(poolCost, currency),
+		})
+	}
+
+	total := workerCost + controlPlaneCost + etcdCost + lbCost
+
+	rows = append(rows, [][]string{
 		{
 			"Etcd Nodes",
 			convertToHumanReadable(inp.EtcdMachine.GetCost(), currency),
@@ -146,7 +271,7 @@ func (kc *Controller) priceCalculatorForSelfManagedCluster(inp PriceCalculatorIn
 			"Total", "", "",
 			convertToHumanReadable(total, currency),
 		},
-	}
+	}...)
 
 	kc.l.Table(kc.ctx, headers, rows)
 
@@ -18,16 +18,21 @@ import (
 	"errors"
 
 	"github.com/ksctl/ksctl/v2/pkg/consts"
+	"github.com/ksctl/ksctl/v2/pkg/events"
 	"github.com/ksctl/ksctl/v2/pkg/statefile"
 	"github.com/ksctl/ksctl/v2/pkg/validation"
 
 	bootstrapHandler "github.com/ksctl/ksctl/v2/pkg/bootstrap/handler"
 	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
 
+	"github.com/ksctl/ksctl/v2/pkg/handler/cluster/controller"
 	providerHandler "github.com/ksctl/ksctl/v2/pkg/provider/handler"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
 )
 
 func (kc *Controller) Create() (errC error) {
+	finishAudit := kc.b.StartAudit(kc.p.Storage, &kc.p.Metadata, storage.AuditOperationCreate, kc.p.Metadata)
+	defer func() { finishAudit(errC) }()
 	defer func() {
 		v := kc.b.PanicHandler(kc.l)
 		if v != nil {
@@ -51,6 +56,14 @@ func (kc *Controller) Create() (errC error) {
 		return err
 	}
 
+	lockCtx, releaseLock, err := kc.b.LockCluster(kc.ctx, kc.p.Storage)
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
+	// steps completed by an earlier attempt, kc.s is only loaded by the provider controller
+	completedSteps := 0
 	if state, err := kc.p.Storage.Read(); err != nil {
 		if !ksctlErrors.IsNoMatchingRecordsFound(err) {
 			return err
@@ -64,6 +77,9 @@ func (kc *Controller) Create() (errC error) {
 				errOp,
 			)
 		}
+		if err := kc.b.Authorize(controller.ActionCreate, nil); err != nil {
+			return err
+		}
 	} else {
 		kc.l.Debug(kc.ctx, "Found previous state, using it")
 		if errOp := state.PlatformSpec.State.IsControllerOperationAllowed(consts.OperationCreate); errOp != nil {
@@ -72,6 +88,13 @@ func (kc *Controller) Create() (errC error) {
 				errOp,
 			)
 		}
+		if err := kc.b.Authorize(controller.ActionCreate, state); err != nil {
+			return err
+		}
+		for _, c := range state.FailedCheckpoints() {
+			kc.l.Note(kc.ctx, "Resuming the creation which failed", "phase", c.Phase, "step", c.Step, "reason", c.Reason)
+		}
+		completedSteps = len(state.Checkpoints) - len(state.FailedCheckpoints())
 	}
 
 	if !validation.ValidateDistro(kc.p.Metadata.K8sDistro) {
@@ -87,6 +110,10 @@ func (kc *Controller) Create() (errC error) {
 		return err
 	}
 
+	if err := kc.layoutWorkerPools(); err != nil {
+		return err
+	}
+
 	defer func() {
 		if errC != nil {
 			// failed in cluster creation
@@ -105,8 +132,17 @@ func (kc *Controller) Create() (errC error) {
 		}
 	}()
 
+	opCtx, cancel := kc.b.OperationContext(lockCtx, consts.OperationCreate, kc.p.Metadata.ClusterName)
+	defer cancel()
+	defer func() { events.Finish(opCtx, errC) }()
+
+	// the network, the ssh key, the 4 firewalls, the cni, the apps and for every node its vm and its configuration,
+	// the steps already completed by an earlier attempt are skipped
+	events.Expect(opCtx, 7+len(bootstrapHandler.AppsOf(kc.p.Metadata.Addons))+
+		2*(1+kc.p.Metadata.NoDS+kc.p.Metadata.NoCP+kc.p.Metadata.NoWP)-completedSteps)
+
 	kpc, err := providerHandler.NewController(
-		kc.ctx,
+		opCtx,
 		kc.l,
 		kc.b,
 		kc.s,
@@ -123,7 +159,7 @@ func (kc *Controller) Create() (errC error) {
 	}
 
 	kbc, errBootstrapController := bootstrapHandler.NewController(
-		kc.ctx,
+		opCtx,
 		kc.l,
 		kc.b,
 		kc.s,
@@ -0,0 +1,156 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selfmanaged
+
+import (
+	"context"
+
+	bootstrapHandler "github.com/ksctl/ksctl/v2/pkg/bootstrap/handler"
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	"github.com/ksctl/ksctl/v2/pkg/events"
+	providerHandler "github.com/ksctl/ksctl/v2/pkg/provider/handler"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+)
+
+// AddDataStoreNodes scales the datastores up to the noDS of the metadata, which has to stay odd and at least 3.
+// The new datastores join the etcd cluster one at a time and the controlplanes use all of them afterwards
+func (kc *Controller) AddDataStoreNodes() error {
+	return kc.scale(storage.AuditOperationScaleUp, kc.p.Metadata, func(opCtx context.Context, kpc *providerHandler.Controller) error {
+		transferableInfraState, idxDSNotConfigured, errProvisioningDataStore := kpc.AddDataStoreNodes()
+		if errProvisioningDataStore != nil {
+			return errProvisioningDataStore
+		}
+
+		// the vm of every new datastore
+		events.Expect(opCtx, kc.p.Metadata.NoDS-idxDSNotConfigured)
+
+		kbc, err := bootstrapHandler.NewController(
+			opCtx,
+			kc.l,
+			kc.b,
+			kc.s,
+			consts.OperationGet,
+			transferableInfraState,
+			kc.p,
+		)
+		if err != nil {
+			return err
+		}
+
+		attach := make([]int, 0, kc.p.Metadata.NoDS-idxDSNotConfigured)
+		for no := idxDSNotConfigured; no < kc.p.Metadata.NoDS; no++ {
+			attach = append(attach, no)
+		}
+
+		if err := kbc.AttachDataStores(attach); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// DeleteDataStoreNodes scales the datastores down to the noDS of the metadata, which has to stay odd and at least 3.
+// The controlplanes stop using the datastores above it before they leave the etcd cluster and their vms get deleted
+func (kc *Controller) DeleteDataStoreNodes() error {
+	return kc.scale(storage.AuditOperationScaleDown, kc.p.Metadata, func(opCtx context.Context, kpc *providerHandler.Controller) error {
+		transferableInfraState, currDS, errRemoveDS := kpc.DataStoresToRemove()
+		if errRemoveDS != nil {
+			return errRemoveDS
+		}
+
+		// the vm of every removed datastore
+		events.Expect(opCtx, currDS-kc.p.Metadata.NoDS)
+
+		kbc, err := bootstrapHandler.NewController(
+			opCtx,
+			kc.l,
+			kc.b,
+			kc.s,
+			consts.OperationGet,
+			transferableInfraState,
+			kc.p,
+		)
+		if err != nil {
+			return err
+		}
+
+		detach := make([]int, 0, currDS-kc.p.Metadata.NoDS)
+		for no := kc.p.Metadata.NoDS; no < currDS; no++ {
+			detach = append(detach, no)
+		}
+
+		if err := kbc.DetachDataStores(detach); err != nil {
+			return err
+		}
+
+		transferableInfraState, errDelDS := kpc.DelDataStoreNodes()
+		if errDelDS != nil {
+			return errDelDS
+		}
+
+		if err := kbc.SyncInfraState(transferableInfraState); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// ReplaceDataStoreNode replaces the datastore no with a new vm, like a dead one, keeping the noDS.
+// It leaves the etcd cluster without being reached and its replacement joins with the certificates regenerated for the new ip
+func (kc *Controller) ReplaceDataStoreNode(no int) error {
+	return kc.scale(storage.AuditOperationReplaceNode, map[string]any{"datastore": no}, func(opCtx context.Context, kpc *providerHandler.Controller) error {
+		transferableInfraState, errReplaceDS := kpc.DataStoreToReplace(no)
+		if errReplaceDS != nil {
+			return errReplaceDS
+		}
+
+		// the deletion and the creation of the vm
+		events.Expect(opCtx, 2)
+
+		kbc, err := bootstrapHandler.NewController(
+			opCtx,
+			kc.l,
+			kc.b,
+			kc.s,
+			consts.OperationGet,
+			transferableInfraState,
+			kc.p,
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := kbc.DetachDataStores([]int{no}); err != nil {
+			return err
+		}
+
+		transferableInfraState, errReplaceDS = kpc.ReplaceDataStoreNode(no)
+		if errReplaceDS != nil {
+			return errReplaceDS
+		}
+
+		if err := kbc.SyncInfraState(transferableInfraState); err != nil {
+			return err
+		}
+
+		if err := kbc.AttachDataStores([]int{no}); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
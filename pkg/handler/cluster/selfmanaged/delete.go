@@ -20,11 +20,16 @@ import (
 	bootstrapHandler "github.com/ksctl/ksctl/v2/pkg/bootstrap/handler"
 	"github.com/ksctl/ksctl/v2/pkg/consts"
 	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/events"
+	"github.com/ksctl/ksctl/v2/pkg/handler/cluster/controller"
 	providerHandler "github.com/ksctl/ksctl/v2/pkg/provider/handler"
 	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
 )
 
 func (kc *Controller) Delete() (errC error) {
+	finishAudit := kc.b.StartAudit(kc.p.Storage, &kc.p.Metadata, storage.AuditOperationDelete, kc.p.Metadata)
+	defer func() { finishAudit(errC) }()
 	defer func() {
 		v := kc.b.PanicHandler(kc.l)
 		if v != nil {
@@ -48,6 +53,12 @@ func (kc *Controller) Delete() (errC error) {
 		return err
 	}
 
+	lockCtx, releaseLock, err := kc.b.LockCluster(kc.ctx, kc.p.Storage)
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
 	if state, err := kc.p.Storage.Read(); err != nil {
 		if !ksctlErrors.IsNoMatchingRecordsFound(err) {
 			return err
@@ -70,6 +81,9 @@ func (kc *Controller) Delete() (errC error) {
 				errOp,
 			)
 		}
+		if err := kc.b.Authorize(controller.ActionDelete, state); err != nil {
+			return err
+		}
 	}
 
 	defer func() {
@@ -83,13 +97,17 @@ func (kc *Controller) Delete() (errC error) {
 		}
 	}()
 
+	opCtx, cancel := kc.b.OperationContext(lockCtx, consts.OperationDelete, kc.p.Metadata.ClusterName)
+	defer cancel()
+	defer func() { events.Finish(opCtx, errC) }()
+
 	{
 		/*
 		  Note: This will remove infrastructure created by the ksctl agent and not ksctl cli
 		  CAUTION: WIP
 		*/
 		_, err := providerHandler.NewController(
-			kc.ctx,
+			opCtx,
 			kc.l,
 			kc.b,
 			kc.s,
@@ -110,7 +128,7 @@ func (kc *Controller) Delete() (errC error) {
 		}
 
 		kbc, errBootstrapController := bootstrapHandler.NewController(
-			kc.ctx,
+			opCtx,
 			kc.l,
 			kc.b,
 			kc.s,
@@ -130,7 +148,7 @@ func (kc *Controller) Delete() (errC error) {
 Extra:
 
 	kpc, err := providerHandler.NewController(
-		kc.ctx,
+		opCtx,
 		kc.l,
 		kc.b,
 		kc.s,
@@ -15,225 +15,93 @@
 package selfmanaged
 
 import (
-	"errors"
+	"context"
 	"strings"
 
 	bootstrapHandler "github.com/ksctl/ksctl/v2/pkg/bootstrap/handler"
 	"github.com/ksctl/ksctl/v2/pkg/config"
 	"github.com/ksctl/ksctl/v2/pkg/consts"
-	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/events"
 	providerHandler "github.com/ksctl/ksctl/v2/pkg/provider/handler"
-	"github.com/ksctl/ksctl/v2/pkg/statefile"
-	"github.com/ksctl/ksctl/v2/pkg/validation"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
 )
 
-func (kc *Controller) AddWorkerNodes() (errC error) {
-	defer func() {
-		v := kc.b.PanicHandler(kc.l)
-		if v != nil {
-			errC = errors.Join(errC, v)
-			if kc.s.PlatformSpec.State != statefile.ConfiguringFailed {
-				kc.s.PlatformSpec.State = statefile.ConfiguringFailed
-				if err := kc.p.Storage.Write(kc.s); err != nil {
-					errC = errors.Join(errC, err)
-					kc.l.Error("Failed to write state after error", "error", err)
-				}
-			}
-		}
-	}()
-
-	if err := kc.p.Storage.Setup(
-		kc.p.Metadata.Provider,
-		kc.p.Metadata.Region,
-		kc.p.Metadata.ClusterName,
-		consts.ClusterTypeSelfMang,
-	); err != nil {
-		return err
-	}
-
-	if state, err := kc.p.Storage.Read(); err != nil {
-		if !ksctlErrors.IsNoMatchingRecordsFound(err) {
-			return err
+func (kc *Controller) AddWorkerNodes() error {
+	return kc.scale(storage.AuditOperationScaleUp, kc.p.Metadata, func(opCtx context.Context, kpc *providerHandler.Controller) error {
+		transferableInfraState, idxWPNotConfigured, errProvisioningWorker := kpc.AddWorkerNodes()
+		if errProvisioningWorker != nil {
+			return errProvisioningWorker
 		}
 
-		kc.l.Debug(kc.ctx, "No previous state found, creating a new one")
-
-		return ksctlErrors.WrapError(
-			ksctlErrors.ErrInvalidUserInput,
-			kc.l.NewError(
-				kc.ctx, "No previous state found",
-			),
+		kbc, errBootstrapController := bootstrapHandler.NewController(
+			opCtx,
+			kc.l,
+			kc.b,
+			kc.s,
+			consts.OperationGet,
+			transferableInfraState,
+			kc.p,
 		)
-	} else {
-		kc.l.Debug(kc.ctx, "Found previous state, using it")
-		if errOp := state.PlatformSpec.State.IsControllerOperationAllowed(consts.OperationScale); errOp != nil {
-			return ksctlErrors.WrapError(
-				ksctlErrors.ErrInvalidUserInput,
-				errOp,
-			)
+		if errBootstrapController != nil {
+			return errBootstrapController
 		}
-	}
-
-	if !validation.ValidateDistro(kc.p.Metadata.K8sDistro) {
-		return ksctlErrors.WrapError(
-			ksctlErrors.ErrInvalidBootstrapProvider,
-			kc.l.NewError(
-				kc.ctx, "Problem in validation", "bootstrap", kc.p.Metadata.K8sDistro,
-			),
-		)
-	}
-
-	defer func() {
-		if errC != nil {
-			kc.s.PlatformSpec.State = statefile.ConfiguringFailed
-			if err := kc.p.Storage.Write(kc.s); err != nil {
-				errC = errors.Join(errC, err)
-				kc.l.Error("Failed to write state after error", "error", err)
-			}
-		} else {
-			kc.s.PlatformSpec.State = statefile.Running
-			if err := kc.p.Storage.Write(kc.s); err != nil {
-				errC = errors.Join(errC, err)
-				kc.l.Error("Failed to write state after success", "error", err)
-			}
+
+		if err := kbc.JoinMoreWorkerPlanes(idxWPNotConfigured, kc.p.Metadata.NoWP); err != nil {
+			return err
 		}
-	}()
-
-	kpc, err := providerHandler.NewController(
-		kc.ctx,
-		kc.l,
-		kc.b,
-		kc.s,
-		consts.OperationScale,
-		kc.p,
-	)
-	if err != nil {
-		return err
-	}
-
-	transferableInfraState, idxWPNotConfigured, errProvisioningWorker := kpc.AddWorkerNodes()
-	if errProvisioningWorker != nil {
-		return errProvisioningWorker
-	}
-
-	kbc, errBootstrapController := bootstrapHandler.NewController(
-		kc.ctx,
-		kc.l,
-		kc.b,
-		kc.s,
-		consts.OperationGet,
-		transferableInfraState,
-		kc.p,
-	)
-	if errBootstrapController != nil {
-		return errBootstrapController
-	}
-
-	if err := kbc.JoinMoreWorkerPlanes(idxWPNotConfigured, kc.p.Metadata.NoWP); err != nil {
-		return err
-	}
-
-	return nil
+
+		return nil
+	})
 }
 
-func (kc *Controller) DeleteWorkerNodes() (errC error) {
-	defer func() {
-		v := kc.b.PanicHandler(kc.l)
-		if v != nil {
-			errC = errors.Join(errC, v)
-			if kc.s.PlatformSpec.State != statefile.ConfiguringFailed {
-				kc.s.PlatformSpec.State = statefile.ConfiguringFailed
-				if err := kc.p.Storage.Write(kc.s); err != nil {
-					errC = errors.Join(errC, err)
-					kc.l.Error("Failed to write state after error", "error", err)
-				}
-			}
-		}
-	}()
-
-	if err := kc.p.Storage.Setup(
-		kc.p.Metadata.Provider,
-		kc.p.Metadata.Region,
-		kc.p.Metadata.ClusterName,
-		consts.ClusterTypeSelfMang,
-	); err != nil {
-		return err
-	}
-
-	if state, err := kc.p.Storage.Read(); err != nil {
-		if !ksctlErrors.IsNoMatchingRecordsFound(err) {
-			return err
+func (kc *Controller) DeleteWorkerNodes() error {
+	return kc.scale(storage.AuditOperationScaleDown, kc.p.Metadata, func(opCtx context.Context, kpc *providerHandler.Controller) error {
+		transferableInfraState, hostnames, errDelWP := kpc.DelWorkerNodes()
+		if errDelWP != nil {
+			return errDelWP
 		}
 
-		kc.l.Debug(kc.ctx, "No previous state found, creating a new one")
+		kc.l.Debug(kc.ctx, "K8s nodes to be deleted", "hostnames", strings.Join(hostnames, ";"))
 
-		return ksctlErrors.WrapError(
-			ksctlErrors.ErrInvalidUserInput,
-			kc.l.NewError(
-				kc.ctx, "No previous state found",
-			),
-		)
-	} else {
-		kc.l.Debug(kc.ctx, "Found previous state, using it")
-		if errOp := state.PlatformSpec.State.IsControllerOperationAllowed(consts.OperationScale); errOp != nil {
-			return ksctlErrors.WrapError(
-				ksctlErrors.ErrInvalidUserInput,
-				errOp,
-			)
+		fakeClient := false
+		if _, ok := config.IsContextPresent(kc.ctx, consts.KsctlTestFlagKey); ok {
+			fakeClient = true
 		}
-	}
-
-	if !validation.ValidateDistro(kc.p.Metadata.K8sDistro) {
-		return ksctlErrors.WrapError(
-			ksctlErrors.ErrInvalidBootstrapProvider,
-			kc.l.NewError(
-				kc.ctx, "Problem in validation", "bootstrap", kc.p.Metadata.K8sDistro,
-			),
-		)
-	}
-	defer func() {
-		if errC != nil {
-			kc.s.PlatformSpec.State = statefile.ConfiguringFailed
-			if err := kc.p.Storage.Write(kc.s); err != nil {
-				errC = errors.Join(errC, err)
-				kc.l.Error("Failed to write state after error", "error", err)
+
+		if !fakeClient {
+			kbc, err := bootstrapHandler.NewController(
+				opCtx,
+				kc.l,
+				kc.b,
+				kc.s,
+				consts.OperationGet,
+				transferableInfraState,
+				kc.p,
+			)
+			if err != nil {
+				return err
 			}
-		} else {
-			kc.s.PlatformSpec.State = statefile.Running
-			if err := kc.p.Storage.Write(kc.s); err != nil {
-				errC = errors.Join(errC, err)
-				kc.l.Error("Failed to write state after success", "error", err)
+
+			if err := kbc.DelWorkerPlanes(kc.s.ClusterKubeConfig, hostnames); err != nil {
+				return err
 			}
 		}
-	}()
-
-	kpc, err := providerHandler.NewController(
-		kc.ctx,
-		kc.l,
-		kc.b,
-		kc.s,
-		consts.OperationScale,
-		kc.p,
-	)
-	if err != nil {
-		return err
-	}
-
-	transferableInfraState, hostnames, errDelWP := kpc.DelWorkerNodes()
-	if errDelWP != nil {
-		return errDelWP
-	}
-
-	kc.l.Debug(kc.ctx, "K8s nodes to be deleted", "hostnames", strings.Join(hostnames, ";"))
-
-	fakeClient := false
-	if _, ok := config.IsContextPresent(kc.ctx, consts.KsctlTestFlagKey); ok {
-		fakeClient = true
-	}
-
-	if !fakeClient {
-		kbc, err := bootstrapHandler.NewController(
-			kc.ctx,
+
+		return nil
+	})
+}
+
+// AddControlPlaneNodes scales the controlplanes up to the noCP of the metadata, the count can be even as they hold no quorum.
+// The new controlplanes join the cluster before the loadbalancer sends them the traffic of the api server
+func (kc *Controller) AddControlPlaneNodes() error {
+	return kc.scale(storage.AuditOperationScaleUp, kc.p.Metadata, func(opCtx context.Context, kpc *providerHandler.Controller) error {
+		transferableInfraState, idxCPNotConfigured, errProvisioningControlPlane := kpc.AddControlPlaneNodes()
+		if errProvisioningControlPlane != nil {
+			return errProvisioningControlPlane
+		}
+
+		kbc, errBootstrapController := bootstrapHandler.NewController(
+			opCtx,
 			kc.l,
 			kc.b,
 			kc.s,
@@ -241,14 +109,69 @@ func (kc *Controller) DeleteWorkerNodes() (errC error) {
 			transferableInfraState,
 			kc.p,
 		)
-		if err != nil {
-			return err
+		if errBootstrapController != nil {
+			return errBootstrapController
 		}
 
-		if err := kbc.DelWorkerPlanes(kc.s.ClusterKubeConfig, hostnames); err != nil {
+		if err := kbc.JoinMoreControlPlanes(idxCPNotConfigured, kc.p.Metadata.NoCP); err != nil {
 			return err
 		}
-	}
 
-	return nil
+		return nil
+	})
+}
+
+// DeleteControlPlaneNodes scales the controlplanes down to the noCP of the metadata, which has to keep at least 2 of them for the HA.
+// The controlplanes are drained and removed from the cluster one at a time before their vms get deleted, the controlplane 0 is always kept
+func (kc *Controller) DeleteControlPlaneNodes() error {
+	return kc.scale(storage.AuditOperationScaleDown, kc.p.Metadata, func(opCtx context.Context, kpc *providerHandler.Controller) error {
+		transferableInfraState, hostnames, errRemoveCP := kpc.ControlPlanesToRemove()
+		if errRemoveCP != nil {
+			return errRemoveCP
+		}
+
+		kc.l.Debug(kc.ctx, "K8s controlplanes to be deleted", "hostnames", strings.Join(hostnames, ";"))
+
+		// the backends of the loadbalancer, the removal from the cluster and the vm of every controlplane
+		events.Expect(opCtx, 1+2*len(hostnames))
+
+		fakeClient := false
+		if _, ok := config.IsContextPresent(kc.ctx, consts.KsctlTestFlagKey); ok {
+			fakeClient = true
+		}
+
+		var kbc *bootstrapHandler.Controller
+		if !fakeClient {
+			var err error
+			kbc, err = bootstrapHandler.NewController(
+				opCtx,
+				kc.l,
+				kc.b,
+				kc.s,
+				consts.OperationGet,
+				transferableInfraState,
+				kc.p,
+			)
+			if err != nil {
+				return err
+			}
+
+			if err := kbc.DelControlPlanes(kc.s.ClusterKubeConfig, hostnames); err != nil {
+				return err
+			}
+		}
+
+		transferableInfraState, errDelCP := kpc.DelControlPlaneNodes()
+		if errDelCP != nil {
+			return errDelCP
+		}
+
+		if !fakeClient {
+			if err := kbc.SyncInfraState(transferableInfraState); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
@@ -0,0 +1,274 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selfmanaged
+
+import (
+	"encoding/json"
+	"errors"
+
+	bootstrapHandler "github.com/ksctl/ksctl/v2/pkg/bootstrap/handler"
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/handler/cluster/controller"
+	"github.com/ksctl/ksctl/v2/pkg/handler/cluster/metadata"
+	providerHandler "github.com/ksctl/ksctl/v2/pkg/provider/handler"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/validation"
+)
+
+// readPlanState returns the current state of the cluster, nil when it doesn't exist yet.
+// Plans don't take the lock of the cluster as they never write to the storage
+func (kc *Controller) readPlanState(op consts.KsctlOperation, action controller.Action) (*statefile.StorageDocument, error) {
+	if err := kc.p.Storage.Setup(
+		kc.p.Metadata.Provider,
+		kc.p.Metadata.Region,
+		kc.p.Metadata.ClusterName,
+		consts.ClusterTypeSelfMang,
+	); err != nil {
+		return nil, err
+	}
+
+	state, err := kc.p.Storage.Read()
+	if err != nil {
+		if !ksctlErrors.IsNoMatchingRecordsFound(err) {
+			return nil, err
+		}
+		if op != consts.OperationCreate {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInvalidUserInput,
+				kc.l.NewError(
+					kc.ctx, "No previous state found",
+				),
+			)
+		}
+		if errOp := statefile.Fresh.IsControllerOperationAllowed(op); errOp != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInvalidUserInput,
+				errOp,
+			)
+		}
+		return nil, kc.b.Authorize(action, nil)
+	}
+
+	if errOp := state.PlatformSpec.State.IsControllerOperationAllowed(op); errOp != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			errOp,
+		)
+	}
+	if err := kc.b.Authorize(action, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// scratchState returns a copy of state for the bootstrap to fill with placeholders
+func scratchState(state *statefile.StorageDocument) (*statefile.StorageDocument, error) {
+	scratch := new(statefile.StorageDocument)
+	if state == nil {
+		return scratch, nil
+	}
+
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return nil, ksctlErrors.WrapError(ksctlErrors.ErrInternal, err)
+	}
+	if err := json.Unmarshal(raw, scratch); err != nil {
+		return nil, ksctlErrors.WrapError(ksctlErrors.ErrInternal, err)
+	}
+	return scratch, nil
+}
+
+func (kc *Controller) estimateMonthlyCost(plan *controller.Plan, meta controller.Metadata) error {
+	mc, err := metadata.NewController(kc.ctx, kc.l, kc.b.KsctlWorkloadConf, kc.p)
+	if err != nil {
+		return err
+	}
+
+	// the worker pools are priced in the same table as the rest of the cluster
+	plan.MonthlyCost, plan.Currency, err = mc.EstimateMonthlyCost(meta)
+	return err
+}
+
+// PlanCreate walks the provisioning and bootstrap flow of Create without calling the cloud or
+// connecting to the nodes. It returns the resources Create would make, the scripts it would run
+// on every node and the estimated monthly cost of the cluster
+func (kc *Controller) PlanCreate() (_ *controller.Plan, errC error) {
+	defer func() {
+		v := kc.b.PanicHandler(kc.l)
+		if v != nil {
+			errC = errors.Join(errC, v)
+		}
+	}()
+
+	state, err := kc.readPlanState(consts.OperationCreate, controller.ActionCreate)
+	if err != nil {
+		return nil, err
+	}
+
+	if !validation.ValidateDistro(kc.p.Metadata.K8sDistro) {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidBootstrapProvider,
+			kc.l.NewError(
+				kc.ctx, "Problem in validation", "bootstrap", kc.p.Metadata.K8sDistro,
+			),
+		)
+	}
+
+	if err := validation.IsValidKsctlClusterAddons(kc.ctx, kc.l, kc.p.Metadata.Addons); err != nil {
+		return nil, err
+	}
+
+	if err := kc.layoutWorkerPools(); err != nil {
+		return nil, err
+	}
+
+	// the provisioning lays out the worker pools in the state
+	if state == nil {
+		state = new(statefile.StorageDocument)
+	}
+
+	kpc := providerHandler.NewPlanController(kc.ctx, kc.l, kc.b, state, kc.p)
+
+	transferableInfraState, err := kpc.CreateHACluster()
+	if err != nil {
+		return nil, err
+	}
+
+	scratch, err := scratchState(state)
+	if err != nil {
+		return nil, err
+	}
+
+	kbc, err := bootstrapHandler.NewPlanController(
+		kc.ctx,
+		kc.l,
+		kc.b,
+		scratch,
+		consts.OperationCreate,
+		transferableInfraState,
+		kc.p,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := kbc.PlanConfigureCluster()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := controller.NewPlan(kc.p.Metadata, consts.OperationCreate)
+	plan.Resources = kpc.PlannedResources()
+	plan.Nodes = nodes
+
+	if err := kc.estimateMonthlyCost(plan, kc.p.Metadata); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// PlanDelete walks the flow of Delete without calling the cloud and returns the resources it would delete
+func (kc *Controller) PlanDelete() (_ *controller.Plan, errC error) {
+	defer func() {
+		v := kc.b.PanicHandler(kc.l)
+		if v != nil {
+			errC = errors.Join(errC, v)
+		}
+	}()
+
+	state, err := kc.readPlanState(consts.OperationDelete, controller.ActionDelete)
+	if err != nil {
+		return nil, err
+	}
+
+	kpc := providerHandler.NewPlanController(kc.ctx, kc.l, kc.b, state, kc.p)
+
+	if err := kpc.DeleteHACluster(); err != nil {
+		return nil, err
+	}
+
+	plan := controller.NewPlan(kc.p.Metadata, consts.OperationDelete)
+	plan.Resources = kpc.PlannedResources()
+
+	return plan, nil
+}
+
+// PlanAddWorkerNodes walks the flow of AddWorkerNodes without calling the cloud or connecting to the nodes.
+// It returns the workerplanes it would add, the scripts it would run on them and their estimated monthly cost
+func (kc *Controller) PlanAddWorkerNodes() (_ *controller.Plan, errC error) {
+	defer func() {
+		v := kc.b.PanicHandler(kc.l)
+		if v != nil {
+			errC = errors.Join(errC, v)
+		}
+	}()
+
+	state, err := kc.readPlanState(consts.OperationScale, controller.ActionScale)
+	if err != nil {
+		return nil, err
+	}
+
+	if !validation.ValidateDistro(kc.p.Metadata.K8sDistro) {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidBootstrapProvider,
+			kc.l.NewError(
+				kc.ctx, "Problem in validation", "bootstrap", kc.p.Metadata.K8sDistro,
+			),
+		)
+	}
+
+	kpc := providerHandler.NewPlanController(kc.ctx, kc.l, kc.b, state, kc.p)
+
+	transferableInfraState, idxWPNotConfigured, err := kpc.AddWorkerNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	scratch, err := scratchState(state)
+	if err != nil {
+		return nil, err
+	}
+
+	kbc, err := bootstrapHandler.NewPlanController(
+		kc.ctx,
+		kc.l,
+		kc.b,
+		scratch,
+		consts.OperationGet,
+		transferableInfraState,
+		kc.p,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := controller.NewPlan(kc.p.Metadata, consts.OperationScale)
+	plan.Resources = kpc.PlannedResources()
+	plan.Nodes = kbc.PlanJoinMoreWorkerPlanes(idxWPNotConfigured, kc.p.Metadata.NoWP)
+
+	added := controller.Metadata{
+		Region:              kc.p.Metadata.Region,
+		ClusterType:         consts.ClusterTypeSelfMang,
+		WorkerPlaneNodeType: kc.p.Metadata.WorkerPlaneNodeType,
+		NoWP:                max(kc.p.Metadata.NoWP-idxWPNotConfigured, 0),
+	}
+	if err := kc.estimateMonthlyCost(plan, added); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
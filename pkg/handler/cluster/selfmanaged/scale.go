@@ -0,0 +1,135 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selfmanaged
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/events"
+	"github.com/ksctl/ksctl/v2/pkg/handler/cluster/controller"
+	providerHandler "github.com/ksctl/ksctl/v2/pkg/provider/handler"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"github.com/ksctl/ksctl/v2/pkg/validation"
+)
+
+// scale runs a scaling operation of an existing cluster, op and input are what gets audited.
+// The body runs with the cluster locked and the state read and authorized,
+// the state is written back as running or as configuring failed once the body returns
+func (kc *Controller) scale(
+	op storage.AuditOperation,
+	input any,
+	body func(opCtx context.Context, kpc *providerHandler.Controller) error,
+) (errC error) {
+	finishAudit := kc.b.StartAudit(kc.p.Storage, &kc.p.Metadata, op, input)
+	defer func() { finishAudit(errC) }()
+	defer func() {
+		v := kc.b.PanicHandler(kc.l)
+		if v != nil {
+			errC = errors.Join(errC, v)
+			if kc.s.PlatformSpec.State != statefile.ConfiguringFailed {
+				kc.s.PlatformSpec.State = statefile.ConfiguringFailed
+				if err := kc.p.Storage.Write(kc.s); err != nil {
+					errC = errors.Join(errC, err)
+					kc.l.Error("Failed to write state after error", "error", err)
+				}
+			}
+		}
+	}()
+
+	if err := kc.p.Storage.Setup(
+		kc.p.Metadata.Provider,
+		kc.p.Metadata.Region,
+		kc.p.Metadata.ClusterName,
+		consts.ClusterTypeSelfMang,
+	); err != nil {
+		return err
+	}
+
+	lockCtx, releaseLock, err := kc.b.LockCluster(kc.ctx, kc.p.Storage)
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
+	if state, err := kc.p.Storage.Read(); err != nil {
+		if !ksctlErrors.IsNoMatchingRecordsFound(err) {
+			return err
+		}
+
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			kc.l.NewError(
+				kc.ctx, "No previous state found, the cluster has to exist to be scaled",
+			),
+		)
+	} else {
+		kc.l.Debug(kc.ctx, "Found previous state, using it")
+		if errOp := state.PlatformSpec.State.IsControllerOperationAllowed(consts.OperationScale); errOp != nil {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrInvalidUserInput,
+				errOp,
+			)
+		}
+		if err := kc.b.Authorize(controller.ActionScale, state); err != nil {
+			return err
+		}
+	}
+
+	if !validation.ValidateDistro(kc.p.Metadata.K8sDistro) {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidBootstrapProvider,
+			kc.l.NewError(
+				kc.ctx, "Problem in validation", "bootstrap", kc.p.Metadata.K8sDistro,
+			),
+		)
+	}
+	defer func() {
+		if errC != nil {
+			kc.s.PlatformSpec.State = statefile.ConfiguringFailed
+			if err := kc.p.Storage.Write(kc.s); err != nil {
+				errC = errors.Join(errC, err)
+				kc.l.Error("Failed to write state after error", "error", err)
+			}
+		} else {
+			kc.s.PlatformSpec.State = statefile.Running
+			if err := kc.p.Storage.Write(kc.s); err != nil {
+				errC = errors.Join(errC, err)
+				kc.l.Error("Failed to write state after success", "error", err)
+			}
+		}
+	}()
+
+	opCtx, cancel := kc.b.OperationContext(lockCtx, consts.OperationScale, kc.p.Metadata.ClusterName)
+	defer cancel()
+	defer func() { events.Finish(opCtx, errC) }()
+
+	kpc, err := providerHandler.NewController(
+		opCtx,
+		kc.l,
+		kc.b,
+		kc.s,
+		consts.OperationScale,
+		kc.p,
+	)
+	if err != nil {
+		return err
+	}
+
+	return body(opCtx, kpc)
+}
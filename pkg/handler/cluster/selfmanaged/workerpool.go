@@ -0,0 +1,149 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selfmanaged
+
+import (
+	"context"
+	"strings"
+
+	bootstrapHandler "github.com/ksctl/ksctl/v2/pkg/bootstrap/handler"
+	"github.com/ksctl/ksctl/v2/pkg/config"
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	providerHandler "github.com/ksctl/ksctl/v2/pkg/provider/handler"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"github.com/ksctl/ksctl/v2/pkg/validation"
+)
+
+// layoutWorkerPools validates the worker pools of the metadata, when there are any
+// the no of workerplane becomes the total of their counts
+func (kc *Controller) layoutWorkerPools() error {
+	if len(kc.p.Metadata.WorkerPools) == 0 {
+		return nil
+	}
+
+	if err := validation.IsValidWorkerPools(kc.ctx, kc.l, kc.p.Metadata.WorkerPools); err != nil {
+		return err
+	}
+
+	kc.p.Metadata.NoWP = 0
+	for _, pool := range kc.p.Metadata.WorkerPools {
+		kc.p.Metadata.NoWP += pool.Count
+	}
+	return nil
+}
+
+// workerPoolOf returns the definition of the pool, from the state for an existing pool
+// and from the worker pools of the metadata for a new one
+func (kc *Controller) workerPoolOf(name string) (statefile.WorkerPool, error) {
+	if pool := kc.s.WorkerPool(name); pool != nil {
+		return *pool, nil
+	}
+	for _, pool := range kc.p.Metadata.WorkerPools {
+		if pool.Name == name {
+			if err := validation.IsValidWorkerPools(kc.ctx, kc.l, []statefile.WorkerPool{pool}); err != nil {
+				return statefile.WorkerPool{}, err
+			}
+			return pool, nil
+		}
+	}
+	return statefile.WorkerPool{}, ksctlErrors.WrapError(
+		ksctlErrors.ErrInvalidUserInput,
+		kc.l.NewError(kc.ctx, "worker pool is neither part of the cluster nor of the metadata", "pool", name),
+	)
+}
+
+// ScaleWorkerPool scales the worker pool to count workerplanes, the other pools are left as they are.
+// A pool missing from the cluster is created from its definition in the worker pools of the metadata,
+// a pool scaled to 0 is removed from the cluster
+func (kc *Controller) ScaleWorkerPool(name string, count int) error {
+	if count < 0 {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidNoOfWorkerplane,
+			kc.l.NewError(kc.ctx, "constrains for no of workerplane of a pool >= 0", "pool", name, "count", count),
+		)
+	}
+
+	return kc.scale(storage.AuditOperationScaleWorkerPool, map[string]any{
+		"pool":  name,
+		"count": count,
+	}, func(opCtx context.Context, kpc *providerHandler.Controller) error {
+		curr := kpc.WorkerPoolSize(name)
+		if curr == count {
+			kc.l.Note(kc.ctx, "Worker pool already has the desired no of workerplanes", "pool", name, "count", count)
+			return nil
+		}
+
+		if count > curr {
+			pool, err := kc.workerPoolOf(name)
+			if err != nil {
+				return err
+			}
+			pool.Count = count
+
+			transferableInfraState, idxWPNotConfigured, totalWP, err := kpc.AddWorkerPoolNodes(pool)
+			if err != nil {
+				return err
+			}
+
+			kbc, err := bootstrapHandler.NewController(
+				opCtx,
+				kc.l,
+				kc.b,
+				kc.s,
+				consts.OperationGet,
+				transferableInfraState,
+				kc.p,
+			)
+			if err != nil {
+				return err
+			}
+
+			if err := kbc.JoinMoreWorkerPlanes(idxWPNotConfigured, totalWP); err != nil {
+				return err
+			}
+		} else {
+			transferableInfraState, hostnames, err := kpc.DelWorkerPoolNodes(name, count)
+			if err != nil {
+				return err
+			}
+
+			kc.l.Debug(kc.ctx, "K8s nodes to be deleted", "hostnames", strings.Join(hostnames, ";"))
+
+			if _, ok := config.IsContextPresent(kc.ctx, consts.KsctlTestFlagKey); !ok {
+				kbc, err := bootstrapHandler.NewController(
+					opCtx,
+					kc.l,
+					kc.b,
+					kc.s,
+					consts.OperationGet,
+					transferableInfraState,
+					kc.p,
+				)
+				if err != nil {
+					return err
+				}
+
+				if err := kbc.DelWorkerPlanes(kc.s.ClusterKubeConfig, hostnames); err != nil {
+					return err
+				}
+			}
+		}
+
+		kc.l.Success(kc.ctx, "Scaled the worker pool", "pool", name, "count", count)
+		return nil
+	})
+}
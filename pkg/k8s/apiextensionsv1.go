@@ -15,8 +15,6 @@
 package k8s
 
 import (
-	"context"
-
 	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -28,13 +26,13 @@ func (k *Client) ApiExtensionsApply(o *apiextensionsv1.CustomResourceDefinition)
 	_, err := k.apiextensionsClient.
 		ApiextensionsV1().
 		CustomResourceDefinitions().
-		Create(context.Background(), o, v1.CreateOptions{})
+		Create(k.ctx, o, v1.CreateOptions{})
 	if err != nil {
 		if apierrors.IsAlreadyExists(err) {
 			_, err = k.apiextensionsClient.
 				ApiextensionsV1().
 				CustomResourceDefinitions().
-				Update(context.Background(), o, v1.UpdateOptions{})
+				Update(k.ctx, o, v1.UpdateOptions{})
 			if err != nil {
 				return ksctlErrors.WrapError(
 					ksctlErrors.ErrFailedKubernetesClient,
@@ -56,7 +54,7 @@ func (k *Client) ApiExtensionsDelete(o *apiextensionsv1.CustomResourceDefinition
 	err := k.apiextensionsClient.
 		ApiextensionsV1().
 		CustomResourceDefinitions().
-		Delete(context.Background(), o.Name, v1.DeleteOptions{})
+		Delete(k.ctx, o.Name, v1.DeleteOptions{})
 	if err != nil {
 		return ksctlErrors.WrapError(
 			ksctlErrors.ErrFailedKubernetesClient,
@@ -15,7 +15,6 @@
 package k8s
 
 import (
-	"context"
 	"fmt"
 	"time"
 
@@ -35,13 +34,13 @@ func (k *Client) DaemonsetApply(o *appsv1.DaemonSet) error {
 	_, err := k.clientset.
 		AppsV1().
 		DaemonSets(ns).
-		Create(context.Background(), o, metav1.CreateOptions{})
+		Create(k.ctx, o, metav1.CreateOptions{})
 	if err != nil {
 		if apierrors.IsAlreadyExists(err) {
 			_, err = k.clientset.
 				AppsV1().
 				DaemonSets(ns).
-				Update(context.Background(), o, metav1.UpdateOptions{})
+				Update(k.ctx, o, metav1.UpdateOptions{})
 			if err != nil {
 				return ksctlErrors.WrapError(
 					ksctlErrors.ErrFailedKubernetesClient,
@@ -64,13 +63,13 @@ func (k *Client) DeploymentApply(o *appsv1.Deployment) error {
 	_, err := k.clientset.
 		AppsV1().
 		Deployments(ns).
-		Create(context.Background(), o, metav1.CreateOptions{})
+		Create(k.ctx, o, metav1.CreateOptions{})
 	if err != nil {
 		if apierrors.IsAlreadyExists(err) {
 			_, err = k.clientset.
 				AppsV1().
 				Deployments(ns).
-				Update(context.Background(), o, metav1.UpdateOptions{})
+				Update(k.ctx, o, metav1.UpdateOptions{})
 			if err != nil {
 				return ksctlErrors.WrapError(
 					ksctlErrors.ErrFailedKubernetesClient,
@@ -104,7 +103,7 @@ func (k *Client) DeploymentReadyWait(name, namespace string) error {
 			status, err = k.clientset.
 				AppsV1().
 				Deployments(namespace).
-				Get(context.Background(), name, metav1.GetOptions{})
+				Get(k.ctx, name, metav1.GetOptions{})
 			if err != nil {
 				return ksctlErrors.WrapError(
 					ksctlErrors.ErrFailedKubernetesClient,
@@ -140,7 +139,7 @@ func (k *Client) DaemonsetDelete(o *appsv1.DaemonSet) error {
 	err := k.clientset.
 		AppsV1().
 		DaemonSets(ns).
-		Delete(context.Background(), o.Name, metav1.DeleteOptions{})
+		Delete(k.ctx, o.Name, metav1.DeleteOptions{})
 	if err != nil {
 		return ksctlErrors.WrapError(
 			ksctlErrors.ErrFailedKubernetesClient,
@@ -156,7 +155,7 @@ func (k *Client) DeploymentDelete(o *appsv1.Deployment) error {
 	err := k.clientset.
 		AppsV1().
 		Deployments(ns).
-		Delete(context.Background(), o.Name, metav1.DeleteOptions{})
+		Delete(k.ctx, o.Name, metav1.DeleteOptions{})
 	if err != nil {
 		return ksctlErrors.WrapError(
 			ksctlErrors.ErrFailedKubernetesClient,
@@ -172,13 +171,13 @@ func (k *Client) StatefulSetApply(o *appsv1.StatefulSet) error {
 	_, err := k.clientset.
 		AppsV1().
 		StatefulSets(ns).
-		Create(context.Background(), o, metav1.CreateOptions{})
+		Create(k.ctx, o, metav1.CreateOptions{})
 	if err != nil {
 		if apierrors.IsAlreadyExists(err) {
 			_, err = k.clientset.
 				AppsV1().
 				StatefulSets(ns).
-				Update(context.Background(), o, metav1.UpdateOptions{})
+				Update(k.ctx, o, metav1.UpdateOptions{})
 			if err != nil {
 				return ksctlErrors.WrapError(
 					ksctlErrors.ErrFailedKubernetesClient,
@@ -201,7 +200,7 @@ func (k *Client) StatefulSetDelete(o *appsv1.StatefulSet) error {
 	err := k.clientset.
 		AppsV1().
 		StatefulSets(ns).
-		Delete(context.Background(), o.Name, metav1.DeleteOptions{})
+		Delete(k.ctx, o.Name, metav1.DeleteOptions{})
 	if err != nil {
 		return ksctlErrors.WrapError(
 			ksctlErrors.ErrFailedKubernetesClient,
@@ -15,8 +15,6 @@
 package k8s
 
 import (
-	"context"
-
 	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
 	batchv1 "k8s.io/api/batch/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -29,13 +27,13 @@ func (k *Client) JobApply(o *batchv1.Job) error {
 	_, err := k.clientset.
 		BatchV1().
 		Jobs(ns).
-		Create(context.Background(), o, v1.CreateOptions{})
+		Create(k.ctx, o, v1.CreateOptions{})
 	if err != nil {
 		if apierrors.IsAlreadyExists(err) {
 			_, err = k.clientset.
 				BatchV1().
 				Jobs(ns).
-				Update(context.Background(), o, v1.UpdateOptions{})
+				Update(k.ctx, o, v1.UpdateOptions{})
 			if err != nil {
 				return ksctlErrors.WrapError(
 					ksctlErrors.ErrFailedKubernetesClient,
@@ -58,7 +56,7 @@ func (k *Client) JobDelete(o *batchv1.Job) error {
 	err := k.clientset.
 		BatchV1().
 		Jobs(ns).
-		Delete(context.Background(), o.Name, v1.DeleteOptions{})
+		Delete(k.ctx, o.Name, v1.DeleteOptions{})
 	if err != nil {
 		return ksctlErrors.WrapError(
 			ksctlErrors.ErrFailedKubernetesClient,
@@ -15,7 +15,6 @@
 package k8s
 
 import (
-	"context"
 	"fmt"
 	"time"
 
@@ -34,7 +33,7 @@ func (k *Client) ConfigMapApply(o *corev1.ConfigMap) error {
 	_, err := k.clientset.
 		CoreV1().
 		ConfigMaps(ns).
-		Create(context.Background(), o, metav1.CreateOptions{})
+		Create(k.ctx, o, metav1.CreateOptions{})
 
 	if err != nil {
 		if apierrors.IsAlreadyExists(err) {
@@ -42,7 +41,7 @@ func (k *Client) ConfigMapApply(o *corev1.ConfigMap) error {
 			_, err = k.clientset.
 				CoreV1().
 				ConfigMaps(ns).
-				Update(context.Background(), o, metav1.UpdateOptions{})
+				Update(k.ctx, o, metav1.UpdateOptions{})
 			if err != nil {
 				return ksctlErrors.WrapError(
 					ksctlErrors.ErrFailedKubernetesClient,
@@ -66,7 +65,7 @@ func (k *Client) ConfigMapDelete(o *corev1.ConfigMap) error {
 	err := k.clientset.
 		CoreV1().
 		ConfigMaps(ns).
-		Delete(context.Background(), o.Name, metav1.DeleteOptions{})
+		Delete(k.ctx, o.Name, metav1.DeleteOptions{})
 
 	if err != nil {
 		return ksctlErrors.WrapError(
@@ -83,7 +82,7 @@ func (k *Client) ServiceDelete(o *corev1.Service) error {
 	err := k.clientset.
 		CoreV1().
 		Services(ns).
-		Delete(context.Background(), o.Name, metav1.DeleteOptions{})
+		Delete(k.ctx, o.Name, metav1.DeleteOptions{})
 
 	if err != nil {
 		return ksctlErrors.WrapError(
@@ -100,14 +99,14 @@ func (k *Client) ServiceApply(o *corev1.Service) error {
 	_, err := k.clientset.
 		CoreV1().
 		Services(ns).
-		Create(context.Background(), o, metav1.CreateOptions{})
+		Create(k.ctx, o, metav1.CreateOptions{})
 	if err != nil {
 		if apierrors.IsAlreadyExists(err) {
 
 			_, err = k.clientset.
 				CoreV1().
 				Services(ns).
-				Update(context.Background(), o, metav1.UpdateOptions{})
+				Update(k.ctx, o, metav1.UpdateOptions{})
 			if err != nil {
 				return ksctlErrors.WrapError(
 					ksctlErrors.ErrFailedKubernetesClient,
@@ -130,12 +129,12 @@ func (k *Client) NamespaceCreate(ns *corev1.Namespace) error {
 	if _, err := k.clientset.
 		CoreV1().
 		Namespaces().
-		Create(context.Background(), ns, metav1.CreateOptions{}); err != nil {
+		Create(k.ctx, ns, metav1.CreateOptions{}); err != nil {
 		if apierrors.IsAlreadyExists(err) {
 			_, err = k.clientset.
 				CoreV1().
 				Namespaces().
-				Update(context.Background(), ns, metav1.UpdateOptions{})
+				Update(k.ctx, ns, metav1.UpdateOptions{})
 			if err != nil {
 				return ksctlErrors.WrapError(
 					ksctlErrors.ErrFailedKubernetesClient,
@@ -157,7 +156,7 @@ func (k *Client) NamespaceDelete(ns *corev1.Namespace, wait bool) error {
 	if err := k.clientset.
 		CoreV1().
 		Namespaces().
-		Delete(context.Background(), ns.Name, metav1.DeleteOptions{
+		Delete(k.ctx, ns.Name, metav1.DeleteOptions{
 			GracePeriodSeconds: func() *int64 {
 				v := int64(0)
 				return &v
@@ -185,7 +184,7 @@ func (k *Client) NamespaceDelete(ns *corev1.Namespace, wait bool) error {
 			_, errStat = k.clientset.
 				CoreV1().
 				Namespaces().
-				Get(context.Background(), ns.Name, metav1.GetOptions{})
+				Get(k.ctx, ns.Name, metav1.GetOptions{})
 			return err
 		},
 		func() bool {
@@ -214,7 +213,7 @@ func (k *Client) SecretDelete(o *corev1.Secret) error {
 	err := k.clientset.
 		CoreV1().
 		Secrets(o.Namespace).
-		Delete(context.Background(), o.Name, metav1.DeleteOptions{})
+		Delete(k.ctx, o.Name, metav1.DeleteOptions{})
 
 	if err != nil {
 		return ksctlErrors.WrapError(
@@ -231,7 +230,7 @@ func (k *Client) SecretApply(o *corev1.Secret) error {
 	_, err := k.clientset.
 		CoreV1().
 		Secrets(ns).
-		Create(context.Background(), o, metav1.CreateOptions{})
+		Create(k.ctx, o, metav1.CreateOptions{})
 
 	if err != nil {
 		if apierrors.IsAlreadyExists(err) {
@@ -239,7 +238,7 @@ func (k *Client) SecretApply(o *corev1.Secret) error {
 			_, err = k.clientset.
 				CoreV1().
 				Secrets(ns).
-				Update(context.Background(), o, metav1.UpdateOptions{})
+				Update(k.ctx, o, metav1.UpdateOptions{})
 			if err != nil {
 				return ksctlErrors.WrapError(
 					ksctlErrors.ErrFailedKubernetesClient,
@@ -274,7 +273,7 @@ func (k *Client) PodReadyWait(name, namespace string) error {
 			status, err = k.clientset.
 				CoreV1().
 				Pods(namespace).
-				Get(context.Background(), name, metav1.GetOptions{})
+				Get(k.ctx, name, metav1.GetOptions{})
 			if err != nil {
 				return ksctlErrors.WrapError(
 					ksctlErrors.ErrFailedKubernetesClient,
@@ -309,7 +308,7 @@ func (k *Client) PodApply(o *corev1.Pod) error {
 	_, err := k.clientset.
 		CoreV1().
 		Pods(ns).
-		Create(context.Background(), o, metav1.CreateOptions{})
+		Create(k.ctx, o, metav1.CreateOptions{})
 
 	if err != nil {
 		if apierrors.IsAlreadyExists(err) {
@@ -317,7 +316,7 @@ func (k *Client) PodApply(o *corev1.Pod) error {
 			_, err = k.clientset.
 				CoreV1().
 				Pods(ns).
-				Update(context.Background(), o, metav1.UpdateOptions{})
+				Update(k.ctx, o, metav1.UpdateOptions{})
 			if err != nil {
 				return ksctlErrors.WrapError(
 					ksctlErrors.ErrFailedKubernetesClient,
@@ -340,7 +339,7 @@ func (k *Client) PodDelete(o *corev1.Pod) error {
 	err := k.clientset.
 		CoreV1().
 		Pods(o.Namespace).
-		Delete(context.Background(), o.Name, metav1.DeleteOptions{
+		Delete(k.ctx, o.Name, metav1.DeleteOptions{
 			GracePeriodSeconds: o.DeletionGracePeriodSeconds,
 		})
 
@@ -358,7 +357,7 @@ func (k *Client) ServiceAccountDelete(o *corev1.ServiceAccount) error {
 	err := k.clientset.
 		CoreV1().
 		ServiceAccounts(o.Namespace).
-		Delete(context.Background(), o.Name, metav1.DeleteOptions{})
+		Delete(k.ctx, o.Name, metav1.DeleteOptions{})
 	if err != nil {
 		return ksctlErrors.WrapError(
 			ksctlErrors.ErrFailedKubernetesClient,
@@ -374,13 +373,13 @@ func (k *Client) ServiceAccountApply(o *corev1.ServiceAccount) error {
 	_, err := k.clientset.
 		CoreV1().
 		ServiceAccounts(ns).
-		Create(context.Background(), o, metav1.CreateOptions{})
+		Create(k.ctx, o, metav1.CreateOptions{})
 	if err != nil {
 		if apierrors.IsAlreadyExists(err) {
 			_, err = k.clientset.
 				CoreV1().
 				ServiceAccounts(ns).
-				Update(context.Background(), o, metav1.UpdateOptions{})
+				Update(k.ctx, o, metav1.UpdateOptions{})
 			if err != nil {
 				return ksctlErrors.WrapError(
 					ksctlErrors.ErrFailedKubernetesClient,
@@ -401,7 +400,7 @@ func (k *Client) NodesList() (*corev1.NodeList, error) {
 	v, err := k.clientset.
 		CoreV1().
 		Nodes().
-		List(context.Background(), metav1.ListOptions{})
+		List(k.ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil,
 			ksctlErrors.WrapError(
@@ -418,7 +417,7 @@ func (k *Client) NodeUpdate(node *corev1.Node,
 	v, err := k.clientset.
 		CoreV1().
 		Nodes().
-		Update(context.Background(), node, metav1.UpdateOptions{})
+		Update(k.ctx, node, metav1.UpdateOptions{})
 	if err != nil {
 		return nil,
 			ksctlErrors.WrapError(
@@ -430,7 +429,7 @@ func (k *Client) NodeUpdate(node *corev1.Node,
 }
 
 func (k *Client) NodeCordon(nodeName string) error {
-	node, err := k.clientset.CoreV1().Nodes().Get(context.Background(), nodeName, metav1.GetOptions{})
+	node, err := k.clientset.CoreV1().Nodes().Get(k.ctx, nodeName, metav1.GetOptions{})
 	if err != nil {
 		return ksctlErrors.WrapError(
 			ksctlErrors.ErrFailedKubernetesClient,
@@ -440,7 +439,7 @@ func (k *Client) NodeCordon(nodeName string) error {
 
 	node.Spec.Unschedulable = true
 
-	_, err = k.clientset.CoreV1().Nodes().Update(context.Background(), node, metav1.UpdateOptions{})
+	_, err = k.clientset.CoreV1().Nodes().Update(k.ctx, node, metav1.UpdateOptions{})
 	if err != nil {
 		return ksctlErrors.WrapError(
 			ksctlErrors.ErrFailedKubernetesClient,
@@ -457,7 +456,7 @@ func (k *Client) NodeDrain(nodeName string) error {
 		return err
 	}
 
-	pods, err := k.clientset.CoreV1().Pods("").List(context.Background(), metav1.ListOptions{
+	pods, err := k.clientset.CoreV1().Pods("").List(k.ctx, metav1.ListOptions{
 		FieldSelector: "spec.nodeName=" + nodeName,
 	})
 	if err != nil {
@@ -491,10 +490,16 @@ func (k *Client) NodeDelete(nodeName string) error {
 		return err
 	}
 
+	return k.NodeDeleteDrained(nodeName)
+}
+
+// NodeDeleteDrained deletes the node without draining it, for the nodes which got drained already
+// and whose kubelet may be stopped, so the evictions of a second drain would never complete
+func (k *Client) NodeDeleteDrained(nodeName string) error {
 	err := k.clientset.
 		CoreV1().
 		Nodes().
-		Delete(context.Background(), nodeName, metav1.DeleteOptions{})
+		Delete(k.ctx, nodeName, metav1.DeleteOptions{})
 	if err != nil {
 		return ksctlErrors.WrapError(
 			ksctlErrors.ErrFailedKubernetesClient,
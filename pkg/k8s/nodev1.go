@@ -15,8 +15,6 @@
 package k8s
 
 import (
-	"context"
-
 	nodev1 "k8s.io/api/node/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -28,12 +26,12 @@ import (
 func (k *Client) RuntimeApply(o *nodev1.RuntimeClass,
 ) error {
 	_, err := k.clientset.NodeV1().RuntimeClasses().Create(
-		context.Background(),
+		k.ctx,
 		o,
 		metav1.CreateOptions{})
 	if err != nil {
 		if apierrors.IsAlreadyExists(err) {
-			_, err = k.clientset.NodeV1().RuntimeClasses().Update(context.Background(), o, metav1.UpdateOptions{})
+			_, err = k.clientset.NodeV1().RuntimeClasses().Update(k.ctx, o, metav1.UpdateOptions{})
 			if err != nil {
 				return ksctlErrors.WrapError(
 					ksctlErrors.ErrFailedKubernetesClient,
@@ -53,7 +51,7 @@ func (k *Client) RuntimeApply(o *nodev1.RuntimeClass,
 
 func (k *Client) RuntimeDelete(resName string) error {
 	err := k.clientset.NodeV1().RuntimeClasses().Delete(
-		context.Background(),
+		k.ctx,
 		resName,
 		metav1.DeleteOptions{})
 	if err != nil {
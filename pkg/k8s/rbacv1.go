@@ -15,8 +15,6 @@
 package k8s
 
 import (
-	"context"
-
 	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -28,13 +26,13 @@ func (k *Client) ClusterRoleApply(o *rbacv1.ClusterRole) error {
 	_, err := k.clientset.
 		RbacV1().
 		ClusterRoles().
-		Create(context.Background(), o, v1.CreateOptions{})
+		Create(k.ctx, o, v1.CreateOptions{})
 	if err != nil {
 		if apierrors.IsAlreadyExists(err) {
 			_, err = k.clientset.
 				RbacV1().
 				ClusterRoles().
-				Update(context.Background(), o, v1.UpdateOptions{})
+				Update(k.ctx, o, v1.UpdateOptions{})
 			if err != nil {
 				return ksctlErrors.WrapError(
 					ksctlErrors.ErrFailedKubernetesClient,
@@ -56,7 +54,7 @@ func (k *Client) ClusterRoleDelete(o *rbacv1.ClusterRole) error {
 	err := k.clientset.
 		RbacV1().
 		ClusterRoles().
-		Delete(context.Background(), o.Name, v1.DeleteOptions{})
+		Delete(k.ctx, o.Name, v1.DeleteOptions{})
 	if err != nil {
 		return ksctlErrors.WrapError(
 			ksctlErrors.ErrFailedKubernetesClient,
@@ -71,7 +69,7 @@ func (k *Client) ClusterRoleBindingDelete(o *rbacv1.ClusterRoleBinding) error {
 	err := k.clientset.
 		RbacV1().
 		ClusterRoleBindings().
-		Delete(context.Background(), o.Name, v1.DeleteOptions{})
+		Delete(k.ctx, o.Name, v1.DeleteOptions{})
 	if err != nil {
 		return ksctlErrors.WrapError(
 			ksctlErrors.ErrFailedKubernetesClient,
@@ -86,13 +84,13 @@ func (k *Client) ClusterRoleBindingApply(o *rbacv1.ClusterRoleBinding) error {
 	_, err := k.clientset.
 		RbacV1().
 		ClusterRoleBindings().
-		Create(context.Background(), o, v1.CreateOptions{})
+		Create(k.ctx, o, v1.CreateOptions{})
 	if err != nil {
 		if apierrors.IsAlreadyExists(err) {
 			_, err = k.clientset.
 				RbacV1().
 				ClusterRoleBindings().
-				Update(context.Background(), o, v1.UpdateOptions{})
+				Update(k.ctx, o, v1.UpdateOptions{})
 			if err != nil {
 				return ksctlErrors.WrapError(
 					ksctlErrors.ErrFailedKubernetesClient,
@@ -114,7 +112,7 @@ func (k *Client) RoleDelete(o *rbacv1.Role) error {
 	err := k.clientset.
 		RbacV1().
 		Roles(o.Namespace).
-		Delete(context.Background(), o.Name, v1.DeleteOptions{})
+		Delete(k.ctx, o.Name, v1.DeleteOptions{})
 	if err != nil {
 		return ksctlErrors.WrapError(
 			ksctlErrors.ErrFailedKubernetesClient,
@@ -130,13 +128,13 @@ func (k *Client) RoleApply(o *rbacv1.Role) error {
 	_, err := k.clientset.
 		RbacV1().
 		Roles(ns).
-		Create(context.Background(), o, v1.CreateOptions{})
+		Create(k.ctx, o, v1.CreateOptions{})
 	if err != nil {
 		if apierrors.IsAlreadyExists(err) {
 			_, err = k.clientset.
 				RbacV1().
 				Roles(ns).
-				Update(context.Background(), o, v1.UpdateOptions{})
+				Update(k.ctx, o, v1.UpdateOptions{})
 			if err != nil {
 				return ksctlErrors.WrapError(
 					ksctlErrors.ErrFailedKubernetesClient,
@@ -159,13 +157,13 @@ func (k *Client) RoleBindingApply(o *rbacv1.RoleBinding) error {
 	_, err := k.clientset.
 		RbacV1().
 		RoleBindings(ns).
-		Create(context.Background(), o, v1.CreateOptions{})
+		Create(k.ctx, o, v1.CreateOptions{})
 	if err != nil {
 		if apierrors.IsAlreadyExists(err) {
 			_, err = k.clientset.
 				RbacV1().
 				RoleBindings(ns).
-				Update(context.Background(), o, v1.UpdateOptions{})
+				Update(k.ctx, o, v1.UpdateOptions{})
 			if err != nil {
 				return ksctlErrors.WrapError(
 					ksctlErrors.ErrFailedKubernetesClient,
@@ -188,7 +186,7 @@ func (k *Client) RoleBindingDelete(o *rbacv1.RoleBinding) error {
 	err := k.clientset.
 		RbacV1().
 		RoleBindings(ns).
-		Delete(context.Background(), o.Name, v1.DeleteOptions{})
+		Delete(k.ctx, o.Name, v1.DeleteOptions{})
 	if err != nil {
 		return ksctlErrors.WrapError(
 			ksctlErrors.ErrFailedKubernetesClient,
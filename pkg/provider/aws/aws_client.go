@@ -33,9 +33,11 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go-v2/service/eks"
+	eksTypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/waiter"
 )
 
 const kubeconfigTemplate = `apiVersion: v1
@@ -74,6 +76,8 @@ const (
 	managedClusterDeletionWaiter   = time.Minute * 10
 	managedNodeGroupActiveWaiter   = time.Minute * 10
 	managedNodeGroupDeletionWaiter = time.Minute * 15
+	managedNodeGroupUpdatePoll     = time.Second * 20
+	managedNodeGroupUpdateRetries  = 45
 )
 
 func ProvideClient() CloudSDK {
@@ -821,6 +825,53 @@ func (l *AwsClient) BeginCreateNodeGroup(ctx context.Context, paramter *eks.Crea
 	return resp, nil
 }
 
+func (l *AwsClient) BeginUpdateNodeGroup(ctx context.Context, parameter *eks.UpdateNodegroupConfigInput) (*eks.UpdateNodegroupConfigOutput, error) {
+	resp, err := l.eksClient.UpdateNodegroupConfig(ctx, parameter)
+	if err != nil {
+		return nil, err
+	}
+
+	// the nodegroup stays active while the update is only accepted, so it is the update which gets polled
+	describeUpdate := &eks.DescribeUpdateInput{
+		Name:          parameter.ClusterName,
+		NodegroupName: parameter.NodegroupName,
+		UpdateId:      resp.Update.Id,
+	}
+	var status eksTypes.UpdateStatus
+
+	err = waiter.NewWaiter(managedNodeGroupUpdatePoll, 1, managedNodeGroupUpdateRetries).Run(
+		ctx,
+		l.b.l,
+		func() error {
+			out, err := l.eksClient.DescribeUpdate(ctx, describeUpdate)
+			if err != nil {
+				return err
+			}
+			status = out.Update.Status
+			return nil
+		},
+		func() bool {
+			return status != eksTypes.UpdateStatusInProgress
+		},
+		nil,
+		func() error {
+			if status != eksTypes.UpdateStatusSuccessful {
+				return ksctlErrors.WrapError(
+					ksctlErrors.ErrFailedKsctlClusterOperation,
+					l.b.l.NewError(ctx, "failed to update the nodegroup", "status", status),
+				)
+			}
+			return nil
+		},
+		"waiting for the nodegroup update",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
 func (l *AwsClient) BeginDeleteNodeGroup(ctx context.Context, parameter *eks.DeleteNodegroupInput) (*eks.DeleteNodegroupOutput, error) {
 
 	resp, err := l.eksClient.DeleteNodegroup(ctx, parameter)
@@ -967,7 +1018,7 @@ func (l *AwsClient) GetKubeConfig(ctx context.Context, clusterName string) (stri
 
 	preSignClient := sts.NewPresignClient(l.stsClient)
 	tokenRetriver := NewSTSTokenRetriver(preSignClient)
-	token, errToken := tokenRetriver.GetToken(context.Background(), l.b, clusterName, *l.config)
+	token, errToken := tokenRetriver.GetToken(ctx, l.b, clusterName, *l.config)
 	if errToken != nil {
 		return "", errToken
 	}
@@ -1090,3 +1141,68 @@ func (l *AwsClient) ListK8sVersions(ctx context.Context) ([]string, error) {
 	}
 	return s, nil
 }
+
+func (l *AwsClient) CreateTags(ctx context.Context, resourceIDs []string, tags []types.Tag) error {
+	_, err := l.ec2Client.CreateTags(ctx, &ec2.CreateTagsInput{
+		Resources: resourceIDs,
+		Tags:      tags,
+	})
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrFailedKsctlClusterOperation,
+			l.b.l.NewError(l.b.ctx, "Error Creating Tags", "Reason", err),
+		)
+	}
+
+	return nil
+}
+
+func (l *AwsClient) DeleteTags(ctx context.Context, resourceIDs []string, keys []string) error {
+	tags := make([]types.Tag, 0, len(keys))
+	for _, k := range keys {
+		tags = append(tags, types.Tag{Key: aws.String(k)})
+	}
+
+	_, err := l.ec2Client.DeleteTags(ctx, &ec2.DeleteTagsInput{
+		Resources: resourceIDs,
+		Tags:      tags,
+	})
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrFailedKsctlClusterOperation,
+			l.b.l.NewError(l.b.ctx, "Error Deleting Tags", "Reason", err),
+		)
+	}
+
+	return nil
+}
+
+func (l *AwsClient) TagManagedResource(ctx context.Context, arn string, tags map[string]string) error {
+	_, err := l.eksClient.TagResource(ctx, &eks.TagResourceInput{
+		ResourceArn: aws.String(arn),
+		Tags:        tags,
+	})
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrFailedKsctlClusterOperation,
+			l.b.l.NewError(l.b.ctx, "Error Tagging EKS Resource", "Reason", err),
+		)
+	}
+
+	return nil
+}
+
+func (l *AwsClient) UntagManagedResource(ctx context.Context, arn string, keys []string) error {
+	_, err := l.eksClient.UntagResource(ctx, &eks.UntagResourceInput{
+		ResourceArn: aws.String(arn),
+		TagKeys:     keys,
+	})
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrFailedKsctlClusterOperation,
+			l.b.l.NewError(l.b.ctx, "Error Untagging EKS Resource", "Reason", err),
+		)
+	}
+
+	return nil
+}
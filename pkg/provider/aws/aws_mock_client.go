@@ -259,6 +259,14 @@ func (mock *AwsClient) DescribeInstanceState(ctx context.Context, instanceId str
 						},
 						PublicIpAddress:  aws.String("A.B.C.D"),
 						PrivateIpAddress: aws.String("192.168.1.2"),
+						BlockDeviceMappings: []types.InstanceBlockDeviceMapping{
+							{
+								DeviceName: aws.String("/dev/sda1"),
+								Ebs: &types.EbsInstanceBlockDevice{
+									VolumeId: aws.String("vol-" + instanceId),
+								},
+							},
+						},
 					},
 				},
 			},
@@ -326,6 +334,15 @@ func (mock *AwsClient) BeginCreateNodeGroup(ctx context.Context, paramter *eks.C
 	}, nil
 }
 
+func (mock *AwsClient) BeginUpdateNodeGroup(ctx context.Context, parameter *eks.UpdateNodegroupConfigInput) (*eks.UpdateNodegroupConfigOutput, error) {
+	return &eks.UpdateNodegroupConfigOutput{
+		Update: &eksTypes.Update{
+			Id:     aws.String("test-update"),
+			Status: eksTypes.UpdateStatusSuccessful,
+		},
+	}, nil
+}
+
 func (mock *AwsClient) BeginDeleteNodeGroup(ctx context.Context, parameter *eks.DeleteNodegroupInput) (*eks.DeleteNodegroupOutput, error) {
 	return &eks.DeleteNodegroupOutput{
 		Nodegroup: &eksTypes.Nodegroup{
@@ -381,3 +398,19 @@ func (l *AwsClient) CreateAddons(ctx context.Context, input *eks.CreateAddonInpu
 func (l *AwsClient) DeleteAddons(ctx context.Context, input *eks.DeleteAddonInput) error {
 	return nil
 }
+
+func (l *AwsClient) CreateTags(ctx context.Context, resourceIDs []string, tags []types.Tag) error {
+	return nil
+}
+
+func (l *AwsClient) DeleteTags(ctx context.Context, resourceIDs []string, keys []string) error {
+	return nil
+}
+
+func (l *AwsClient) TagManagedResource(ctx context.Context, arn string, tags map[string]string) error {
+	return nil
+}
+
+func (l *AwsClient) UntagManagedResource(ctx context.Context, arn string, keys []string) error {
+	return nil
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go-v2/service/eks"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 )
@@ -74,6 +75,8 @@ type CloudSDK interface {
 	BeginCreateEKS(ctx context.Context, parameter *eks.CreateClusterInput) (*eks.CreateClusterOutput, error)
 	BeginCreateNodeGroup(ctx context.Context, paramter *eks.CreateNodegroupInput) (*eks.CreateNodegroupOutput, error)
 
+	BeginUpdateNodeGroup(ctx context.Context, parameter *eks.UpdateNodegroupConfigInput) (*eks.UpdateNodegroupConfigOutput, error)
+
 	BeginDeleteNodeGroup(ctx context.Context, parameter *eks.DeleteNodegroupInput) (*eks.DeleteNodegroupOutput, error)
 	BeginDeleteManagedCluster(ctx context.Context, parameter *eks.DeleteClusterInput) (*eks.DeleteClusterOutput, error)
 	DescribeCluster(ctx context.Context, parameter *eks.DescribeClusterInput) (*eks.DescribeClusterOutput, error)
@@ -87,4 +90,10 @@ type CloudSDK interface {
 
 	CreateAddons(ctx context.Context, input *eks.CreateAddonInput) error
 	DeleteAddons(ctx context.Context, input *eks.DeleteAddonInput) error
+
+	CreateTags(ctx context.Context, resourceIDs []string, tags []types.Tag) error
+	DeleteTags(ctx context.Context, resourceIDs []string, keys []string) error
+
+	TagManagedResource(ctx context.Context, arn string, tags map[string]string) error
+	UntagManagedResource(ctx context.Context, arn string, keys []string) error
 }
@@ -96,6 +96,12 @@ func (p *Provider) CreateSecurityGroup(name string, role consts.KsctlRole) (stri
 		GroupName:   aws.String(name),
 		Description: aws.String(name + "-" + string(role)),
 		VpcId:       aws.String(p.state.CloudInfra.Aws.VpcId),
+		TagSpecifications: []types.TagSpecification{
+			{
+				ResourceType: types.ResourceTypeSecurityGroup,
+				Tags:         p.resourceTags(name),
+			},
+		},
 	}
 
 	switch role {
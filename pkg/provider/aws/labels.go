@@ -0,0 +1,140 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+)
+
+// resourceTags returns the Name tag of the resource followed by the cluster labels.
+// Name is kept as the first tag as the responses of the create calls are read using it
+func (p *Provider) resourceTags(name string) []types.Tag {
+	tags := []types.Tag{
+		{
+			Key:   aws.String("Name"),
+			Value: aws.String(name),
+		},
+	}
+	return append(tags, labelsToTags(p.state.PlatformSpec.Labels)...)
+}
+
+func labelsToTags(labels map[string]string) []types.Tag {
+	tags := make([]types.Tag, 0, len(labels))
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		tags = append(tags, types.Tag{
+			Key:   aws.String(k),
+			Value: aws.String(labels[k]),
+		})
+	}
+	return tags
+}
+
+// taggedEC2Resources returns the ids of the ec2 resources created for the cluster
+func (p *Provider) taggedEC2Resources() ([]string, error) {
+	infra := p.state.CloudInfra.Aws
+	ids := []string{infra.VpcId, infra.NetworkAclID, infra.GatewayID, infra.RouteTableID}
+	ids = append(ids, infra.SubnetIDs...)
+
+	var instances []string
+	for _, vms := range []statefile.AWSStateVms{infra.InfoControlPlanes, infra.InfoWorkerPlanes, infra.InfoDatabase} {
+		ids = append(ids, vms.NetworkSecurityGroupIDs)
+		ids = append(ids, vms.NetworkInterfaceIDs...)
+		instances = append(instances, vms.InstanceIds...)
+	}
+	ids = append(ids, infra.InfoLoadBalancer.NetworkSecurityGroupID, infra.InfoLoadBalancer.NetworkInterfaceId)
+	instances = append(instances, infra.InfoLoadBalancer.InstanceID)
+
+	for _, id := range instances {
+		if len(id) == 0 {
+			continue
+		}
+		ids = append(ids, id)
+
+		// the volumes don't get the tags updated on the instance so they are tagged on their own
+		out, err := p.client.DescribeInstanceState(p.ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range out.Reservations {
+			for _, i := range r.Instances {
+				for _, bd := range i.BlockDeviceMappings {
+					if bd.Ebs != nil && bd.Ebs.VolumeId != nil {
+						ids = append(ids, *bd.Ebs.VolumeId)
+					}
+				}
+			}
+		}
+	}
+
+	return slices.DeleteFunc(ids, func(id string) bool { return len(id) == 0 }), nil
+}
+
+// UpdateLabels replaces the labels of the cluster and updates the tags of all its aws resources to match
+func (p *Provider) UpdateLabels(labels map[string]string) error {
+	var removed []string
+	for k := range p.state.PlatformSpec.Labels {
+		if _, ok := labels[k]; !ok {
+			removed = append(removed, k)
+		}
+	}
+	slices.Sort(removed)
+
+	ids, err := p.taggedEC2Resources()
+	if err != nil {
+		return err
+	}
+
+	if len(ids) != 0 {
+		if len(removed) != 0 {
+			if err := p.client.DeleteTags(p.ctx, ids, removed); err != nil {
+				return err
+			}
+		}
+		if len(labels) != 0 {
+			if err := p.client.CreateTags(p.ctx, ids, labelsToTags(labels)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, arn := range []string{p.state.CloudInfra.Aws.ManagedClusterArn, p.state.CloudInfra.Aws.ManagedNodeGroupArn} {
+		if len(arn) == 0 {
+			continue
+		}
+		if len(removed) != 0 {
+			if err := p.client.UntagManagedResource(p.ctx, arn, removed); err != nil {
+				return err
+			}
+		}
+		if len(labels) != 0 {
+			if err := p.client.TagManagedResource(p.ctx, arn, labels); err != nil {
+				return err
+			}
+		}
+	}
+
+	p.state.PlatformSpec.Labels = labels
+	if err := p.store.Write(p.state); err != nil {
+		return err
+	}
+
+	p.l.Success(p.ctx, "updated the labels of the cluster", "resources", len(ids))
+	return nil
+}
@@ -191,6 +191,7 @@ func (p *Provider) InitState(operation consts.KsctlOperation) error {
 
 			p.state.PlatformSpec.Team = team
 			p.state.PlatformSpec.Owner = owner
+			p.state.PlatformSpec.Labels = p.Labels
 			p.state.PlatformSpec.State = statefile.Creating
 			p.state.ClusterName = p.ClusterName
 			p.state.Region = p.Region
@@ -376,6 +377,25 @@ func (p *Provider) NoOfWorkerPlane(no int, setter bool) (int, error) {
 
 }
 
+func (p *Provider) PruneWorkerPlanes(indexes []int) error {
+	if p.state == nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidOperation,
+			p.l.NewError(p.ctx, "state init not called!"),
+		)
+	}
+	wp := &p.state.CloudInfra.Aws.InfoWorkerPlanes
+	wp.HostNames = utilities.RemoveIndexes(wp.HostNames, indexes)
+	wp.InstanceIds = utilities.RemoveIndexes(wp.InstanceIds, indexes)
+	wp.PublicIPs = utilities.RemoveIndexes(wp.PublicIPs, indexes)
+	wp.PrivateIPs = utilities.RemoveIndexes(wp.PrivateIPs, indexes)
+	wp.NetworkInterfaceIDs = utilities.RemoveIndexes(wp.NetworkInterfaceIDs, indexes)
+	wp.VMSizes = utilities.RemoveIndexes(wp.VMSizes, indexes)
+	p.NoWP = len(wp.HostNames)
+
+	return p.store.Write(p.state)
+}
+
 func (p *Provider) NoOfControlPlane(no int, setter bool) (int, error) {
 	if !setter {
 		if p.state == nil {
@@ -393,7 +413,7 @@ func (p *Provider) NoOfControlPlane(no int, setter bool) (int, error) {
 
 		return len(p.state.CloudInfra.Aws.InfoControlPlanes.HostNames), nil
 	}
-	if no >= 3 && (no&1) == 1 {
+	if provider.ValidNoOfControlPlane(no) {
 		p.NoCP = no
 		if p.state == nil {
 			return -1, ksctlErrors.WrapError(
@@ -403,6 +423,9 @@ func (p *Provider) NoOfControlPlane(no int, setter bool) (int, error) {
 		}
 
 		currLen := len(p.state.CloudInfra.Aws.InfoControlPlanes.HostNames)
+
+		newLen := no
+
 		if currLen == 0 {
 			p.state.CloudInfra.Aws.InfoControlPlanes.HostNames = make([]string, no)
 			p.state.CloudInfra.Aws.InfoControlPlanes.InstanceIds = make([]string, no)
@@ -410,13 +433,37 @@ func (p *Provider) NoOfControlPlane(no int, setter bool) (int, error) {
 			p.state.CloudInfra.Aws.InfoControlPlanes.PrivateIPs = make([]string, no)
 			p.state.CloudInfra.Aws.InfoControlPlanes.NetworkInterfaceIDs = make([]string, no)
 			p.state.CloudInfra.Aws.InfoControlPlanes.VMSizes = make([]string, no)
+		} else {
+			if currLen == newLen {
+				return -1, nil
+			} else if currLen < newLen {
+				for i := currLen; i < newLen; i++ {
+					p.state.CloudInfra.Aws.InfoControlPlanes.HostNames = append(p.state.CloudInfra.Aws.InfoControlPlanes.HostNames, "")
+					p.state.CloudInfra.Aws.InfoControlPlanes.InstanceIds = append(p.state.CloudInfra.Aws.InfoControlPlanes.InstanceIds, "")
+					p.state.CloudInfra.Aws.InfoControlPlanes.PublicIPs = append(p.state.CloudInfra.Aws.InfoControlPlanes.PublicIPs, "")
+					p.state.CloudInfra.Aws.InfoControlPlanes.PrivateIPs = append(p.state.CloudInfra.Aws.InfoControlPlanes.PrivateIPs, "")
+					p.state.CloudInfra.Aws.InfoControlPlanes.NetworkInterfaceIDs = append(p.state.CloudInfra.Aws.InfoControlPlanes.NetworkInterfaceIDs, "")
+					p.state.CloudInfra.Aws.InfoControlPlanes.VMSizes = append(p.state.CloudInfra.Aws.InfoControlPlanes.VMSizes, "")
+				}
+			} else {
+				p.state.CloudInfra.Aws.InfoControlPlanes.HostNames = p.state.CloudInfra.Aws.InfoControlPlanes.HostNames[:newLen]
+				p.state.CloudInfra.Aws.InfoControlPlanes.InstanceIds = p.state.CloudInfra.Aws.InfoControlPlanes.InstanceIds[:newLen]
+				p.state.CloudInfra.Aws.InfoControlPlanes.PublicIPs = p.state.CloudInfra.Aws.InfoControlPlanes.PublicIPs[:newLen]
+				p.state.CloudInfra.Aws.InfoControlPlanes.PrivateIPs = p.state.CloudInfra.Aws.InfoControlPlanes.PrivateIPs[:newLen]
+				p.state.CloudInfra.Aws.InfoControlPlanes.NetworkInterfaceIDs = p.state.CloudInfra.Aws.InfoControlPlanes.NetworkInterfaceIDs[:newLen]
+				p.state.CloudInfra.Aws.InfoControlPlanes.VMSizes = p.state.CloudInfra.Aws.InfoControlPlanes.VMSizes[:newLen]
+			}
+
+			if err := p.store.Write(p.state); err != nil {
+				return -1, err
+			}
 		}
 
 		return -1, nil
 	}
 	return -1, ksctlErrors.WrapError(
 		ksctlErrors.ErrInvalidNoOfControlplane,
-		p.l.NewError(p.ctx, "constrains for no of controlplane >= 3 and odd number"),
+		p.l.NewError(p.ctx, "too few controlplanes for a HA cluster", "minimum", provider.MinNoOfControlPlane),
 	)
 
 }
@@ -450,6 +497,9 @@ func (p *Provider) NoOfDataStore(no int, setter bool) (int, error) {
 		}
 
 		currLen := len(p.state.CloudInfra.Aws.InfoDatabase.HostNames)
+
+		newLen := no
+
 		if currLen == 0 {
 			p.state.CloudInfra.Aws.InfoDatabase.HostNames = make([]string, no)
 			p.state.CloudInfra.Aws.InfoDatabase.InstanceIds = make([]string, no)
@@ -457,6 +507,30 @@ func (p *Provider) NoOfDataStore(no int, setter bool) (int, error) {
 			p.state.CloudInfra.Aws.InfoDatabase.PrivateIPs = make([]string, no)
 			p.state.CloudInfra.Aws.InfoDatabase.NetworkInterfaceIDs = make([]string, no)
 			p.state.CloudInfra.Aws.InfoDatabase.VMSizes = make([]string, no)
+		} else {
+			if currLen == newLen {
+				return -1, nil
+			} else if currLen < newLen {
+				for i := currLen; i < newLen; i++ {
+					p.state.CloudInfra.Aws.InfoDatabase.HostNames = append(p.state.CloudInfra.Aws.InfoDatabase.HostNames, "")
+					p.state.CloudInfra.Aws.InfoDatabase.InstanceIds = append(p.state.CloudInfra.Aws.InfoDatabase.InstanceIds, "")
+					p.state.CloudInfra.Aws.InfoDatabase.PublicIPs = append(p.state.CloudInfra.Aws.InfoDatabase.PublicIPs, "")
+					p.state.CloudInfra.Aws.InfoDatabase.PrivateIPs = append(p.state.CloudInfra.Aws.InfoDatabase.PrivateIPs, "")
+					p.state.CloudInfra.Aws.InfoDatabase.NetworkInterfaceIDs = append(p.state.CloudInfra.Aws.InfoDatabase.NetworkInterfaceIDs, "")
+					p.state.CloudInfra.Aws.InfoDatabase.VMSizes = append(p.state.CloudInfra.Aws.InfoDatabase.VMSizes, "")
+				}
+			} else {
+				p.state.CloudInfra.Aws.InfoDatabase.HostNames = p.state.CloudInfra.Aws.InfoDatabase.HostNames[:newLen]
+				p.state.CloudInfra.Aws.InfoDatabase.InstanceIds = p.state.CloudInfra.Aws.InfoDatabase.InstanceIds[:newLen]
+				p.state.CloudInfra.Aws.InfoDatabase.PublicIPs = p.state.CloudInfra.Aws.InfoDatabase.PublicIPs[:newLen]
+				p.state.CloudInfra.Aws.InfoDatabase.PrivateIPs = p.state.CloudInfra.Aws.InfoDatabase.PrivateIPs[:newLen]
+				p.state.CloudInfra.Aws.InfoDatabase.NetworkInterfaceIDs = p.state.CloudInfra.Aws.InfoDatabase.NetworkInterfaceIDs[:newLen]
+				p.state.CloudInfra.Aws.InfoDatabase.VMSizes = p.state.CloudInfra.Aws.InfoDatabase.VMSizes[:newLen]
+			}
+
+			if err := p.store.Write(p.state); err != nil {
+				return -1, err
+			}
 		}
 
 		return -1, nil
@@ -473,6 +547,12 @@ func (p *Provider) GetHostNameAllWorkerNode() []string {
 	return hostnames
 }
 
+func (p *Provider) GetHostNameAllControlPlane() []string {
+	hostnames := utilities.DeepCopySlice(p.state.CloudInfra.Aws.InfoControlPlanes.HostNames)
+	p.l.Debug(p.ctx, "Printing", "hostnameControlPlanes", hostnames)
+	return hostnames
+}
+
 func (p *Provider) GetStateFile() (string, error) {
 	cloudstate, err := json.Marshal(p.state)
 	if err != nil {
@@ -579,8 +659,10 @@ func (p *Provider) GetRAWClusterInfos() ([]provider.ClusterData, error) {
 				Region:        v.Region,
 				ClusterType:   K,
 				Owner:         v.PlatformSpec.Owner,
+				Labels:        v.PlatformSpec.Labels,
 				Team:          v.PlatformSpec.Team,
 				State:         v.PlatformSpec.State,
+				FailedPhases:  v.FailedCheckpoints(),
 				CP:            convertToAllClusterDataType(v, consts.RoleCp),
 				WP:            convertToAllClusterDataType(v, consts.RoleWp),
 				DS:            convertToAllClusterDataType(v, consts.RoleDs),
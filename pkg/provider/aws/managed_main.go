@@ -20,6 +20,7 @@ import (
 
 	"github.com/ksctl/ksctl/v2/pkg/addons"
 	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
 	"github.com/ksctl/ksctl/v2/pkg/statefile"
 	"github.com/ksctl/ksctl/v2/pkg/utilities"
 
@@ -263,6 +264,7 @@ func (p *Provider) NewManagedCluster(noOfNode int) error {
 				BootstrapClusterCreatorAdminPermissions: aws.Bool(true),
 			},
 			Version: aws.String(p.K8sVersion),
+			Tags:    p.state.PlatformSpec.Labels,
 		}
 		p.state.Versions.Eks = utilities.Ptr(p.K8sVersion)
 		p.state.BootstrapProvider = consts.K8sEks
@@ -364,6 +366,7 @@ func (p *Provider) NewManagedCluster(noOfNode int) error {
 
 			InstanceTypes: []string{vmType},
 			DiskSize:      aws.Int32(30),
+			Tags:          p.state.PlatformSpec.Labels,
 
 			ScalingConfig: &eksTypes.NodegroupScalingConfig{
 				DesiredSize: aws.Int32(int32(noOfNode)),
@@ -405,3 +408,52 @@ func (p *Provider) NewManagedCluster(noOfNode int) error {
 
 	return nil
 }
+
+// ScaleManagedCluster updates the scaling config of the EKS nodegroup, without autoscaling bounds
+// the nodegroup gets pinned to the desired size like it is on creation
+func (p *Provider) ScaleManagedCluster(noOfNodes, minNodes, maxNodes int) error {
+	if len(p.state.CloudInfra.Aws.ManagedNodeGroupName) == 0 {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidOperation,
+			p.l.NewError(p.ctx, "no EKS nodegroup to scale", "cluster", p.state.CloudInfra.Aws.ManagedClusterName),
+		)
+	}
+
+	if p.state.CloudInfra.Aws.NoManagedNodes == noOfNodes &&
+		p.state.CloudInfra.Aws.MinManagedNodes == minNodes &&
+		p.state.CloudInfra.Aws.MaxManagedNodes == maxNodes {
+		p.l.Print(p.ctx, "skipped scaling the EKS nodegroup, it already has the desired size", "name", p.state.CloudInfra.Aws.ManagedNodeGroupName)
+		return nil
+	}
+
+	scaling := &eksTypes.NodegroupScalingConfig{
+		DesiredSize: aws.Int32(int32(noOfNodes)),
+		MinSize:     aws.Int32(int32(noOfNodes)),
+		MaxSize:     aws.Int32(int32(noOfNodes)),
+	}
+	if maxNodes > 0 {
+		scaling.MinSize = aws.Int32(int32(minNodes))
+		scaling.MaxSize = aws.Int32(int32(maxNodes))
+	}
+
+	p.l.Print(p.ctx, "Scaling the EKS nodegroup", "name", p.state.CloudInfra.Aws.ManagedNodeGroupName, "desired", noOfNodes, "min", *scaling.MinSize, "max", *scaling.MaxSize)
+
+	_, err := p.client.BeginUpdateNodeGroup(p.ctx, &eks.UpdateNodegroupConfigInput{
+		ClusterName:   aws.String(p.state.CloudInfra.Aws.ManagedClusterName),
+		NodegroupName: aws.String(p.state.CloudInfra.Aws.ManagedNodeGroupName),
+		ScalingConfig: scaling,
+	})
+	if err != nil {
+		return err
+	}
+
+	p.state.CloudInfra.Aws.NoManagedNodes = noOfNodes
+	p.state.CloudInfra.Aws.MinManagedNodes = minNodes
+	p.state.CloudInfra.Aws.MaxManagedNodes = maxNodes
+	if err := p.store.Write(p.state); err != nil {
+		return err
+	}
+
+	p.l.Success(p.ctx, "Scaled the EKS nodegroup", "name", p.state.CloudInfra.Aws.ManagedNodeGroupName, "desired", noOfNodes)
+	return nil
+}
@@ -103,12 +103,7 @@ func (p *Provider) NewNetwork() error {
 			TagSpecifications: []types.TagSpecification{
 				{
 					ResourceType: types.ResourceType("vpc"),
-					Tags: []types.Tag{
-						{
-							Key:   aws.String("Name"),
-							Value: aws.String(p.ClusterName + "-vpc"),
-						},
-					},
+					Tags:         p.resourceTags(p.ClusterName + "-vpc"),
 				},
 			},
 		}
@@ -173,12 +168,7 @@ func (p *Provider) CreateSubnet(subnetName string) error {
 				TagSpecifications: []types.TagSpecification{
 					{
 						ResourceType: types.ResourceType("subnet"),
-						Tags: []types.Tag{
-							{
-								Key:   aws.String("Name"),
-								Value: aws.String(p.ClusterName + "-subnet" + strconv.Itoa(i)),
-							},
-						},
+						Tags:         p.resourceTags(p.ClusterName + "-subnet" + strconv.Itoa(i)),
 					},
 				},
 				AvailabilityZone: aws.String(*zones.AvailabilityZones[i].ZoneName),
@@ -209,12 +199,7 @@ func (p *Provider) CreateSubnet(subnetName string) error {
 			TagSpecifications: []types.TagSpecification{
 				{
 					ResourceType: types.ResourceType("network-acl"),
-					Tags: []types.Tag{
-						{
-							Key:   aws.String("Name"),
-							Value: aws.String(p.ClusterName + "-nacl"),
-						},
-					},
+					Tags:         p.resourceTags(p.ClusterName + "-nacl"),
 				},
 			},
 		}
@@ -245,12 +230,7 @@ func (p *Provider) CreateVirtualNetwork() error {
 			TagSpecifications: []types.TagSpecification{
 				{
 					ResourceType: types.ResourceType("internet-gateway"),
-					Tags: []types.Tag{
-						{
-							Key:   aws.String("Name"),
-							Value: aws.String(p.ClusterName + "-ig"),
-						},
-					},
+					Tags:         p.resourceTags(p.ClusterName + "-ig"),
 				},
 			},
 		}
@@ -260,12 +240,7 @@ func (p *Provider) CreateVirtualNetwork() error {
 			TagSpecifications: []types.TagSpecification{
 				{
 					ResourceType: types.ResourceType("route-table"),
-					Tags: []types.Tag{
-						{
-							Key:   aws.String("Name"),
-							Value: aws.String(p.ClusterName + "-rt"),
-						},
-					},
+					Tags:         p.resourceTags(p.ClusterName + "-rt"),
 				},
 			},
 		}
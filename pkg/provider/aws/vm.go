@@ -128,12 +128,7 @@ func (p *Provider) CreateNetworkInterface(resName string, index int, role consts
 		TagSpecifications: []types.TagSpecification{
 			{
 				ResourceType: types.ResourceType("network-interface"),
-				Tags: []types.Tag{
-					{
-						Key:   aws.String("Name"),
-						Value: aws.String(string(role) + strconv.Itoa(index) + resName),
-					},
-				},
+				Tags:         p.resourceTags(string(role) + strconv.Itoa(index) + resName),
 			},
 		},
 		Groups: []string{
@@ -247,6 +242,11 @@ func (p *Provider) NewVM(index int) error {
 	}
 	initScriptBase64 := base64.StdEncoding.EncodeToString([]byte(initScript))
 
+	diskSize := int32(30)
+	if pool := p.state.WorkerPoolOf(indexNo); role == consts.RoleWp && pool != nil && pool.DiskSize > 0 {
+		diskSize = int32(pool.DiskSize)
+	}
+
 	parameter := &ec2.RunInstancesInput{
 		ImageId:      aws.String(ami),
 		InstanceType: types.InstanceType(vmtype),
@@ -261,7 +261,7 @@ func (p *Provider) NewVM(index int) error {
 					DeleteOnTermination: aws.Bool(true),
 					VolumeType:          types.VolumeTypeGp3,
 					Throughput:          aws.Int32(125),
-					VolumeSize:          aws.Int32(30),
+					VolumeSize:          aws.Int32(diskSize),
 					Iops:                aws.Int32(3000),
 				},
 			},
@@ -270,12 +270,11 @@ func (p *Provider) NewVM(index int) error {
 		TagSpecifications: []types.TagSpecification{
 			{
 				ResourceType: types.ResourceType("instance"),
-				Tags: []types.Tag{
-					{
-						Key:   aws.String("Name"),
-						Value: aws.String(name),
-					},
-				},
+				Tags:         p.resourceTags(name),
+			},
+			{
+				ResourceType: types.ResourceTypeVolume,
+				Tags:         p.resourceTags(name + "-disk"),
 			},
 		},
 
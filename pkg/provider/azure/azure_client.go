@@ -207,6 +207,17 @@ func (p *AzureClient) PollUntilDoneCreateAKS(ctx context.Context, poll *runtime.
 	return res, nil
 }
 
+func (p *AzureClient) PollUntilDoneUpdateAgentPool(ctx context.Context, poll *runtime.Poller[armcontainerservice.AgentPoolsClientCreateOrUpdateResponse], options *runtime.PollUntilDoneOptions) (armcontainerservice.AgentPoolsClientCreateOrUpdateResponse, error) {
+	res, err := poll.PollUntilDone(ctx, options)
+	if err != nil {
+		return res, ksctlErrors.WrapError(
+			ksctlErrors.ErrTimeOut,
+			p.b.l.NewError(p.b.ctx, "failed waiting", "Reason", err),
+		)
+	}
+	return res, nil
+}
+
 func (p *AzureClient) PollUntilDoneDelAKS(ctx context.Context, poll *runtime.Poller[armcontainerservice.ManagedClustersClientDeleteResponse], options *runtime.PollUntilDoneOptions) (armcontainerservice.ManagedClustersClientDeleteResponse, error) {
 	res, err := poll.PollUntilDone(ctx, options)
 	if err != nil {
@@ -366,6 +377,69 @@ func (p *AzureClient) BeginDeleteResourceGrp(options *armresources.ResourceGroup
 	}
 }
 
+func (p *AzureClient) ListResourceIDs() ([]string, error) {
+	rgClient, err := armresources.NewResourceGroupsClient(p.b.subscriptionID, p.azureTokenCred, nil)
+	if err != nil {
+		return nil,
+			ksctlErrors.WrapError(
+				ksctlErrors.ErrFailedKsctlClusterOperation,
+				p.b.l.NewError(p.b.ctx, "failed in azure client", "Reason", err),
+			)
+	}
+	rg, err := rgClient.Get(p.b.ctx, p.resourceGrp, nil)
+	if err != nil {
+		return nil,
+			ksctlErrors.WrapError(
+				ksctlErrors.ErrFailedKsctlClusterOperation,
+				p.b.l.NewError(p.b.ctx, "failed to get resource group", "Reason", err),
+			)
+	}
+	ids := []string{*rg.ID}
+
+	client, err := armresources.NewClient(p.b.subscriptionID, p.azureTokenCred, nil)
+	if err != nil {
+		return nil,
+			ksctlErrors.WrapError(
+				ksctlErrors.ErrFailedKsctlClusterOperation,
+				p.b.l.NewError(p.b.ctx, "failed in azure client", "Reason", err),
+			)
+	}
+	pager := client.NewListByResourceGroupPager(p.resourceGrp, nil)
+	for pager.More() {
+		page, err := pager.NextPage(p.b.ctx)
+		if err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrFailedKsctlClusterOperation,
+				p.b.l.NewError(p.b.ctx, "failed to advance page", "Reason", err),
+			)
+		}
+		for _, v := range page.Value {
+			ids = append(ids, *v.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (p *AzureClient) UpdateResourceTags(resourceID string, parameters armresources.TagsPatchResource, options *armresources.TagsClientUpdateAtScopeOptions) (armresources.TagsClientUpdateAtScopeResponse, error) {
+	client, err := armresources.NewTagsClient(p.b.subscriptionID, p.azureTokenCred, nil)
+	if err != nil {
+		return armresources.TagsClientUpdateAtScopeResponse{},
+			ksctlErrors.WrapError(
+				ksctlErrors.ErrFailedKsctlClusterOperation,
+				p.b.l.NewError(p.b.ctx, "failed in azure client", "Reason", err),
+			)
+	}
+	if res, err := client.UpdateAtScope(p.b.ctx, resourceID, parameters, options); err != nil {
+		return armresources.TagsClientUpdateAtScopeResponse{},
+			ksctlErrors.WrapError(
+				ksctlErrors.ErrFailedKsctlClusterOperation,
+				p.b.l.NewError(p.b.ctx, "failed to update the resource tags", "Reason", err, "resource", resourceID),
+			)
+	} else {
+		return res, nil
+	}
+}
+
 func (p *AzureClient) BeginCreateVirtNet(virtualNetworkName string, parameters armnetwork.VirtualNetwork, options *armnetwork.VirtualNetworksClientBeginCreateOrUpdateOptions) (*runtime.Poller[armnetwork.VirtualNetworksClientCreateOrUpdateResponse], error) {
 	client, err := armnetwork.NewVirtualNetworksClient(p.b.subscriptionID, p.azureTokenCred, nil)
 	if err != nil {
@@ -724,6 +798,48 @@ func (p *AzureClient) BeginCreateAKS(resourceName string, parameters armcontaine
 	}
 }
 
+func (p *AzureClient) GetAgentPool(resourceName, agentPoolName string, options *armcontainerservice.AgentPoolsClientGetOptions) (armcontainerservice.AgentPoolsClientGetResponse, error) {
+	client, err := armcontainerservice.NewAgentPoolsClient(p.b.subscriptionID, p.azureTokenCred, nil)
+	if err != nil {
+		return armcontainerservice.AgentPoolsClientGetResponse{},
+			ksctlErrors.WrapError(
+				ksctlErrors.ErrFailedKsctlClusterOperation,
+				p.b.l.NewError(p.b.ctx, "failed in azure client", "Reason", err),
+			)
+	}
+
+	if res, err := client.Get(p.b.ctx, p.resourceGrp, resourceName, agentPoolName, options); err != nil {
+		return armcontainerservice.AgentPoolsClientGetResponse{},
+			ksctlErrors.WrapError(
+				ksctlErrors.ErrFailedKsctlClusterOperation,
+				p.b.l.NewError(p.b.ctx, "failed to get agent pool", "Reason", err),
+			)
+	} else {
+		return res, nil
+	}
+}
+
+func (p *AzureClient) BeginUpdateAgentPool(resourceName, agentPoolName string, parameters armcontainerservice.AgentPool, options *armcontainerservice.AgentPoolsClientBeginCreateOrUpdateOptions) (*runtime.Poller[armcontainerservice.AgentPoolsClientCreateOrUpdateResponse], error) {
+	client, err := armcontainerservice.NewAgentPoolsClient(p.b.subscriptionID, p.azureTokenCred, nil)
+	if err != nil {
+		return nil,
+			ksctlErrors.WrapError(
+				ksctlErrors.ErrFailedKsctlClusterOperation,
+				p.b.l.NewError(p.b.ctx, "failed in azure client", "Reason", err),
+			)
+	}
+
+	if res, err := client.BeginCreateOrUpdate(p.b.ctx, p.resourceGrp, resourceName, agentPoolName, parameters, options); err != nil {
+		return nil,
+			ksctlErrors.WrapError(
+				ksctlErrors.ErrFailedKsctlClusterOperation,
+				p.b.l.NewError(p.b.ctx, "failed to update agent pool", "Reason", err),
+			)
+	} else {
+		return res, nil
+	}
+}
+
 func (p *AzureClient) ListClusterAdminCredentials(resourceName string, options *armcontainerservice.ManagedClustersClientListClusterAdminCredentialsOptions) (armcontainerservice.ManagedClustersClientListClusterAdminCredentialsResponse, error) {
 	client, err := armcontainerservice.NewManagedClustersClient(p.b.subscriptionID, p.azureTokenCred, nil)
 	if err != nil {
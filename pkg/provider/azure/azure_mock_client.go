@@ -83,6 +83,22 @@ func (mock *AzureClient) BeginDeleteResourceGrp(options *armresources.ResourceGr
 	return &runtime.Poller[armresources.ResourceGroupsClientDeleteResponse]{}, nil
 }
 
+func (mock *AzureClient) ListResourceIDs() ([]string, error) {
+	return []string{
+		"/subscriptions/" + mock.SubscriptionID + "/resourceGroups/" + mock.b.resourceGroup,
+		"/subscriptions/" + mock.SubscriptionID + "/resourceGroups/" + mock.b.resourceGroup + "/providers/Microsoft.Network/virtualNetworks/fake-vnet",
+	}, nil
+}
+
+func (mock *AzureClient) UpdateResourceTags(resourceID string, parameters armresources.TagsPatchResource, options *armresources.TagsClientUpdateAtScopeOptions) (armresources.TagsClientUpdateAtScopeResponse, error) {
+	return armresources.TagsClientUpdateAtScopeResponse{
+		TagsResource: armresources.TagsResource{
+			ID:         utilities.Ptr(resourceID),
+			Properties: parameters.Properties,
+		},
+	}, nil
+}
+
 func (mock *AzureClient) BeginCreateVirtNet(virtualNetworkName string, parameters armnetwork.VirtualNetwork, options *armnetwork.VirtualNetworksClientBeginCreateOrUpdateOptions) (*runtime.Poller[armnetwork.VirtualNetworksClientCreateOrUpdateResponse], error) {
 	return &runtime.Poller[armnetwork.VirtualNetworksClientCreateOrUpdateResponse]{}, nil
 }
@@ -151,6 +167,22 @@ func (mock *AzureClient) BeginCreateAKS(resourceName string, parameters armconta
 	return &runtime.Poller[armcontainerservice.ManagedClustersClientCreateOrUpdateResponse]{}, nil
 }
 
+func (mock *AzureClient) GetAgentPool(resourceName, agentPoolName string, options *armcontainerservice.AgentPoolsClientGetOptions) (armcontainerservice.AgentPoolsClientGetResponse, error) {
+	return armcontainerservice.AgentPoolsClientGetResponse{
+		AgentPool: armcontainerservice.AgentPool{
+			Name: utilities.Ptr(agentPoolName),
+			Properties: &armcontainerservice.ManagedClusterAgentPoolProfileProperties{
+				Count:             utilities.Ptr[int32](1),
+				EnableAutoScaling: utilities.Ptr(false),
+			},
+		},
+	}, nil
+}
+
+func (mock *AzureClient) BeginUpdateAgentPool(resourceName, agentPoolName string, parameters armcontainerservice.AgentPool, options *armcontainerservice.AgentPoolsClientBeginCreateOrUpdateOptions) (*runtime.Poller[armcontainerservice.AgentPoolsClientCreateOrUpdateResponse], error) {
+	return &runtime.Poller[armcontainerservice.AgentPoolsClientCreateOrUpdateResponse]{}, nil
+}
+
 func (mock *AzureClient) ListClusterAdminCredentials(resourceName string, options *armcontainerservice.ManagedClustersClientListClusterAdminCredentialsOptions) (armcontainerservice.ManagedClustersClientListClusterAdminCredentialsResponse, error) {
 	return armcontainerservice.ManagedClustersClientListClusterAdminCredentialsResponse{
 		CredentialResults: armcontainerservice.CredentialResults{
@@ -222,6 +254,11 @@ func (mock *AzureClient) PollUntilDoneCreateAKS(ctx context.Context, poll *runti
 	}, nil
 }
 
+func (mock *AzureClient) PollUntilDoneUpdateAgentPool(ctx context.Context, poll *runtime.Poller[armcontainerservice.AgentPoolsClientCreateOrUpdateResponse], options *runtime.PollUntilDoneOptions) (armcontainerservice.AgentPoolsClientCreateOrUpdateResponse, error) {
+
+	return armcontainerservice.AgentPoolsClientCreateOrUpdateResponse{}, nil
+}
+
 func (mock *AzureClient) PollUntilDoneDelAKS(ctx context.Context, poll *runtime.Poller[armcontainerservice.ManagedClustersClientDeleteResponse], options *runtime.PollUntilDoneOptions) (armcontainerservice.ManagedClustersClientDeleteResponse, error) {
 
 	return armcontainerservice.ManagedClustersClientDeleteResponse{}, nil
@@ -240,6 +277,13 @@ func (mock *AzureClient) PollUntilDoneCreateVM(ctx context.Context, poll *runtim
 				OSProfile: &armcompute.OSProfile{
 					ComputerName: utilities.Ptr("fake-hostname"),
 				},
+				StorageProfile: &armcompute.StorageProfile{
+					OSDisk: &armcompute.OSDisk{
+						ManagedDisk: &armcompute.ManagedDiskParameters{
+							ID: utilities.Ptr("fake-disk-123"),
+						},
+					},
+				},
 			},
 			Name: utilities.Ptr("fake-vm-123"),
 		},
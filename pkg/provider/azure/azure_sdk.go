@@ -41,6 +41,14 @@ type CloudSDK interface {
 	BeginDeleteResourceGrp(
 		options *armresources.ResourceGroupsClientBeginDeleteOptions) (*runtime.Poller[armresources.ResourceGroupsClientDeleteResponse], error)
 
+	// Tags
+
+	// ListResourceIDs returns the id of the resource group followed by the ids of the resources in it
+	ListResourceIDs() ([]string, error)
+
+	UpdateResourceTags(resourceID string, parameters armresources.TagsPatchResource,
+		options *armresources.TagsClientUpdateAtScopeOptions) (armresources.TagsClientUpdateAtScopeResponse, error)
+
 	// VirtualNet
 
 	BeginCreateVirtNet(virtualNetworkName string, parameters armnetwork.VirtualNetwork,
@@ -113,6 +121,12 @@ type CloudSDK interface {
 	ListClusterAdminCredentials(resourceName string,
 		options *armcontainerservice.ManagedClustersClientListClusterAdminCredentialsOptions) (armcontainerservice.ManagedClustersClientListClusterAdminCredentialsResponse, error)
 
+	GetAgentPool(resourceName, agentPoolName string,
+		options *armcontainerservice.AgentPoolsClientGetOptions) (armcontainerservice.AgentPoolsClientGetResponse, error)
+
+	BeginUpdateAgentPool(resourceName, agentPoolName string, parameters armcontainerservice.AgentPool,
+		options *armcontainerservice.AgentPoolsClientBeginCreateOrUpdateOptions) (*runtime.Poller[armcontainerservice.AgentPoolsClientCreateOrUpdateResponse], error)
+
 	//-------------------
 	//|	 Pollers
 	//-------------------
@@ -145,6 +159,8 @@ type CloudSDK interface {
 
 	PollUntilDoneDelAKS(ctx context.Context, poll *runtime.Poller[armcontainerservice.ManagedClustersClientDeleteResponse], options *runtime.PollUntilDoneOptions) (armcontainerservice.ManagedClustersClientDeleteResponse, error)
 
+	PollUntilDoneUpdateAgentPool(ctx context.Context, poll *runtime.Poller[armcontainerservice.AgentPoolsClientCreateOrUpdateResponse], options *runtime.PollUntilDoneOptions) (armcontainerservice.AgentPoolsClientCreateOrUpdateResponse, error)
+
 	// VM
 
 	PollUntilDoneDelVM(ctx context.Context, poll *runtime.Poller[armcompute.VirtualMachinesClientDeleteResponse], options *runtime.PollUntilDoneOptions) (armcompute.VirtualMachinesClientDeleteResponse, error)
@@ -85,18 +85,36 @@ func TestNoOfControlPlane(t *testing.T) {
 
 	_, err = fakeClientVars.NoOfControlPlane(1, true)
 	if err == nil || !ksctlErrors.IsInvalidNoOfControlplane(err) {
-		t.Fatalf("setter should fail on when no < 3 controlplanes provided_no: %d", 1)
+		t.Fatalf("setter should fail on when no < 2 controlplanes provided_no: %d", 1)
 	}
 
 	_, err = fakeClientVars.NoOfControlPlane(5, true)
 	if err != nil {
-		t.Fatalf("setter should not fail on when n >= 3 controlplanes err: %v", err)
+		t.Fatalf("setter should not fail on when n >= 2 controlplanes err: %v", err)
 	}
 
 	no, err = fakeClientVars.NoOfControlPlane(-1, false)
 	if no != 5 {
 		t.Fatalf("Getter failed to get updated no of controlplanes array got no: %d and err: %v", no, err)
 	}
+
+	// the controlplanes hold no quorum, so an even count is fine
+	for _, desired := range []int{7, 4, 2} {
+		_, err = fakeClientVars.NoOfControlPlane(desired, true)
+		if err != nil {
+			t.Fatalf("setter should not fail on scaling controlplanes to %d err: %v", desired, err)
+		}
+
+		no, err = fakeClientVars.NoOfControlPlane(-1, false)
+		if no != desired {
+			t.Fatalf("Getter failed to get scaled no of controlplanes array expected: %d got no: %d and err: %v", desired, no, err)
+		}
+	}
+
+	_, err = fakeClientVars.NoOfControlPlane(5, true)
+	if err != nil {
+		t.Fatalf("setter should not fail on when n >= 2 controlplanes err: %v", err)
+	}
 }
 
 func TestNoOfDataStore(t *testing.T) {
@@ -121,6 +139,23 @@ func TestNoOfDataStore(t *testing.T) {
 	if no != 5 {
 		t.Fatalf("Getter failed to get updated no of datastore array got no: %d and err: %v", no, err)
 	}
+
+	for _, desired := range []int{7, 3} {
+		_, err = fakeClientVars.NoOfDataStore(desired, true)
+		if err != nil {
+			t.Fatalf("setter should not fail on scaling datastore to %d err: %v", desired, err)
+		}
+
+		no, err = fakeClientVars.NoOfDataStore(-1, false)
+		if no != desired {
+			t.Fatalf("Getter failed to get scaled no of datastore array expected: %d got no: %d and err: %v", desired, no, err)
+		}
+	}
+
+	_, err = fakeClientVars.NoOfDataStore(5, true)
+	if err != nil {
+		t.Fatalf("setter should not fail on when n >= 3 datastore err: %v", err)
+	}
 }
 
 func TestNoOfWorkerPlane(t *testing.T) {
@@ -157,6 +192,25 @@ func TestNoOfWorkerPlane(t *testing.T) {
 	}
 }
 
+func TestPruneWorkerPlanes(t *testing.T) {
+	_, err := fakeClientVars.NoOfWorkerPlane(3, true)
+	assert.NilError(t, err)
+
+	wp := &fakeClientVars.state.CloudInfra.Azure.InfoWorkerPlanes
+	wp.Names = []string{"wp-0", "wp-gpu-0", "wp-1"}
+	wp.DiskNames = []string{"wp-0-disk", "wp-gpu-0-disk", "wp-1-disk"}
+
+	assert.NilError(t, fakeClientVars.PruneWorkerPlanes([]int{1}))
+	assert.DeepEqual(t, wp.Names, []string{"wp-0", "wp-1"})
+	assert.DeepEqual(t, wp.DiskNames, []string{"wp-0-disk", "wp-1-disk"})
+	assert.Equal(t, len(wp.PublicIPIDs), 2)
+	assert.Equal(t, fakeClientVars.NoWP, 2)
+
+	no, err := fakeClientVars.NoOfWorkerPlane(-1, false)
+	assert.NilError(t, err)
+	assert.Equal(t, no, 2)
+}
+
 func TestValidRegion(t *testing.T) {
 	fortesting := map[string]error{
 		"fake":    nil,
@@ -117,6 +117,7 @@ func (p *Provider) NewFirewall() error {
 
 	parameters := armnetwork.SecurityGroup{
 		Location: utilities.Ptr(p.Region),
+		Tags:     p.resourceTags(),
 		Properties: &armnetwork.SecurityGroupPropertiesFormat{
 			SecurityRules: securityRules,
 		},
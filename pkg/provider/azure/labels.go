@@ -0,0 +1,99 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azure
+
+import (
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/ksctl/ksctl/v2/pkg/utilities"
+)
+
+// resourceTags returns the cluster labels as the tags of the azure resources
+func (p *Provider) resourceTags() map[string]*string {
+	return labelsToTags(p.state.PlatformSpec.Labels)
+}
+
+func labelsToTags(labels map[string]string) map[string]*string {
+	if len(labels) == 0 {
+		return nil
+	}
+	tags := make(map[string]*string, len(labels))
+	for k, v := range labels {
+		tags[k] = utilities.Ptr(v)
+	}
+	return tags
+}
+
+func (p *Provider) mergeResourceTags(resourceID string, labels map[string]string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+	_, err := p.client.UpdateResourceTags(resourceID, armresources.TagsPatchResource{
+		Operation: utilities.Ptr(armresources.TagsPatchOperationMerge),
+		Properties: &armresources.Tags{
+			Tags: labelsToTags(labels),
+		},
+	}, nil)
+	return err
+}
+
+func (p *Provider) deleteResourceTags(resourceID string, labels map[string]string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+	_, err := p.client.UpdateResourceTags(resourceID, armresources.TagsPatchResource{
+		Operation: utilities.Ptr(armresources.TagsPatchOperationDelete),
+		Properties: &armresources.Tags{
+			Tags: labelsToTags(labels),
+		},
+	}, nil)
+	return err
+}
+
+// UpdateLabels replaces the labels of the cluster and updates the tags of the resource group
+// and all the resources in it, which covers every resource created for the cluster
+func (p *Provider) UpdateLabels(labels map[string]string) error {
+	removed := make(map[string]string)
+	for k, v := range p.state.PlatformSpec.Labels {
+		if _, ok := labels[k]; !ok {
+			removed[k] = v
+		}
+	}
+
+	var ids []string
+	if len(p.state.CloudInfra.Azure.ResourceGroupName) != 0 {
+		var err error
+		ids, err = p.client.ListResourceIDs()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, id := range ids {
+		if err := p.deleteResourceTags(id, removed); err != nil {
+			return err
+		}
+		if err := p.mergeResourceTags(id, labels); err != nil {
+			return err
+		}
+	}
+
+	p.state.PlatformSpec.Labels = labels
+	if err := p.store.Write(p.state); err != nil {
+		return err
+	}
+
+	p.l.Success(p.ctx, "updated the labels of the cluster", "resources", len(ids))
+	return nil
+}
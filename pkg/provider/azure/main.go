@@ -99,6 +99,12 @@ func (p *Provider) GetHostNameAllWorkerNode() []string {
 	return hostnames
 }
 
+func (p *Provider) GetHostNameAllControlPlane() []string {
+	hostnames := utilities.DeepCopySlice(p.state.CloudInfra.Azure.InfoControlPlanes.Hostnames)
+	p.l.Debug(p.ctx, "Printing", "hostnameControlPlanes", hostnames)
+	return hostnames
+}
+
 func (p *Provider) ManagedK8sVersion(ver string) provider.Cloud {
 	p.l.Debug(p.ctx, "Printing", "K8sVersion", ver)
 	if err := p.isValidK8sVersion(ver); err != nil {
@@ -166,13 +172,14 @@ func (p *Provider) InitState(operation consts.KsctlOperation) error {
 
 	switch operation {
 	case consts.OperationCreate:
-		if errLoadState == nil && p.state.CloudInfra.Azure.B.IsCompleted {
+		// a failed creation resumes even when all the vms got created, as its bootstrap can still be incomplete
+		if errLoadState == nil && p.state.CloudInfra.Azure.B.IsCompleted && p.state.PlatformSpec.State != statefile.CreationFailed {
 			return ksctlErrors.WrapError(
 				ksctlErrors.ErrDuplicateRecords,
 				p.l.NewError(p.ctx, "cluster already exist", "name", p.state.ClusterName, "region", p.state.Region),
 			)
 		}
-		if errLoadState == nil && !p.state.CloudInfra.Azure.B.IsCompleted {
+		if errLoadState == nil {
 			p.l.Debug(p.ctx, "RESUME triggered!!")
 		} else {
 			p.l.Debug(p.ctx, "Fresh state!!")
@@ -188,6 +195,7 @@ func (p *Provider) InitState(operation consts.KsctlOperation) error {
 
 			p.state.PlatformSpec.Team = team
 			p.state.PlatformSpec.Owner = owner
+			p.state.PlatformSpec.Labels = p.Labels
 			p.state.PlatformSpec.State = statefile.Creating
 			p.state.ClusterName = p.ClusterName
 			p.state.Region = p.Region
@@ -293,6 +301,29 @@ func (p *Provider) Visibility(toBePublic bool) provider.Cloud {
 	return p
 }
 
+func (p *Provider) PruneWorkerPlanes(indexes []int) error {
+	if p.state == nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidOperation,
+			p.l.NewError(p.ctx, "state init not called!"),
+		)
+	}
+	wp := &p.state.CloudInfra.Azure.InfoWorkerPlanes
+	wp.Names = utilities.RemoveIndexes(wp.Names, indexes)
+	wp.Hostnames = utilities.RemoveIndexes(wp.Hostnames, indexes)
+	wp.PublicIPs = utilities.RemoveIndexes(wp.PublicIPs, indexes)
+	wp.PrivateIPs = utilities.RemoveIndexes(wp.PrivateIPs, indexes)
+	wp.DiskNames = utilities.RemoveIndexes(wp.DiskNames, indexes)
+	wp.NetworkInterfaceNames = utilities.RemoveIndexes(wp.NetworkInterfaceNames, indexes)
+	wp.NetworkInterfaceIDs = utilities.RemoveIndexes(wp.NetworkInterfaceIDs, indexes)
+	wp.PublicIPNames = utilities.RemoveIndexes(wp.PublicIPNames, indexes)
+	wp.PublicIPIDs = utilities.RemoveIndexes(wp.PublicIPIDs, indexes)
+	wp.VMSizes = utilities.RemoveIndexes(wp.VMSizes, indexes)
+	p.NoWP = len(wp.Names)
+
+	return p.store.Write(p.state)
+}
+
 func (p *Provider) NoOfControlPlane(no int, setter bool) (int, error) {
 
 	p.l.Debug(p.ctx, "Printing", "desiredNumber", no, "setterOrNot", setter)
@@ -314,7 +345,7 @@ func (p *Provider) NoOfControlPlane(no int, setter bool) (int, error) {
 		p.l.Debug(p.ctx, "Printing", "p.state.CloudInfra.Azure.InfoControlPlanes.Names", p.state.CloudInfra.Azure.InfoControlPlanes.Names)
 		return len(p.state.CloudInfra.Azure.InfoControlPlanes.Names), nil
 	}
-	if no >= 3 && (no&1) == 1 {
+	if provider.ValidNoOfControlPlane(no) {
 		p.NoCP = no
 		if p.state == nil {
 			return -1, ksctlErrors.WrapError(
@@ -324,6 +355,9 @@ func (p *Provider) NoOfControlPlane(no int, setter bool) (int, error) {
 		}
 
 		currLen := len(p.state.CloudInfra.Azure.InfoControlPlanes.Names)
+
+		newLen := no
+
 		if currLen == 0 {
 			p.state.CloudInfra.Azure.InfoControlPlanes.Names = make([]string, no)
 			p.state.CloudInfra.Azure.InfoControlPlanes.Hostnames = make([]string, no)
@@ -335,13 +369,48 @@ func (p *Provider) NoOfControlPlane(no int, setter bool) (int, error) {
 			p.state.CloudInfra.Azure.InfoControlPlanes.PublicIPNames = make([]string, no)
 			p.state.CloudInfra.Azure.InfoControlPlanes.PublicIPIDs = make([]string, no)
 			p.state.CloudInfra.Azure.InfoControlPlanes.VMSizes = make([]string, no)
+		} else {
+			if currLen == newLen {
+				// no changes needed
+				return -1, nil
+			} else if currLen < newLen {
+				// for up-scaling
+				for i := currLen; i < newLen; i++ {
+					p.state.CloudInfra.Azure.InfoControlPlanes.Names = append(p.state.CloudInfra.Azure.InfoControlPlanes.Names, "")
+					p.state.CloudInfra.Azure.InfoControlPlanes.Hostnames = append(p.state.CloudInfra.Azure.InfoControlPlanes.Hostnames, "")
+					p.state.CloudInfra.Azure.InfoControlPlanes.PublicIPs = append(p.state.CloudInfra.Azure.InfoControlPlanes.PublicIPs, "")
+					p.state.CloudInfra.Azure.InfoControlPlanes.PrivateIPs = append(p.state.CloudInfra.Azure.InfoControlPlanes.PrivateIPs, "")
+					p.state.CloudInfra.Azure.InfoControlPlanes.DiskNames = append(p.state.CloudInfra.Azure.InfoControlPlanes.DiskNames, "")
+					p.state.CloudInfra.Azure.InfoControlPlanes.NetworkInterfaceNames = append(p.state.CloudInfra.Azure.InfoControlPlanes.NetworkInterfaceNames, "")
+					p.state.CloudInfra.Azure.InfoControlPlanes.NetworkInterfaceIDs = append(p.state.CloudInfra.Azure.InfoControlPlanes.NetworkInterfaceIDs, "")
+					p.state.CloudInfra.Azure.InfoControlPlanes.PublicIPNames = append(p.state.CloudInfra.Azure.InfoControlPlanes.PublicIPNames, "")
+					p.state.CloudInfra.Azure.InfoControlPlanes.PublicIPIDs = append(p.state.CloudInfra.Azure.InfoControlPlanes.PublicIPIDs, "")
+					p.state.CloudInfra.Azure.InfoControlPlanes.VMSizes = append(p.state.CloudInfra.Azure.InfoControlPlanes.VMSizes, "")
+				}
+			} else {
+				// for downscaling
+				p.state.CloudInfra.Azure.InfoControlPlanes.Names = p.state.CloudInfra.Azure.InfoControlPlanes.Names[:newLen]
+				p.state.CloudInfra.Azure.InfoControlPlanes.Hostnames = p.state.CloudInfra.Azure.InfoControlPlanes.Hostnames[:newLen]
+				p.state.CloudInfra.Azure.InfoControlPlanes.PublicIPs = p.state.CloudInfra.Azure.InfoControlPlanes.PublicIPs[:newLen]
+				p.state.CloudInfra.Azure.InfoControlPlanes.PrivateIPs = p.state.CloudInfra.Azure.InfoControlPlanes.PrivateIPs[:newLen]
+				p.state.CloudInfra.Azure.InfoControlPlanes.DiskNames = p.state.CloudInfra.Azure.InfoControlPlanes.DiskNames[:newLen]
+				p.state.CloudInfra.Azure.InfoControlPlanes.NetworkInterfaceNames = p.state.CloudInfra.Azure.InfoControlPlanes.NetworkInterfaceNames[:newLen]
+				p.state.CloudInfra.Azure.InfoControlPlanes.NetworkInterfaceIDs = p.state.CloudInfra.Azure.InfoControlPlanes.NetworkInterfaceIDs[:newLen]
+				p.state.CloudInfra.Azure.InfoControlPlanes.PublicIPNames = p.state.CloudInfra.Azure.InfoControlPlanes.PublicIPNames[:newLen]
+				p.state.CloudInfra.Azure.InfoControlPlanes.PublicIPIDs = p.state.CloudInfra.Azure.InfoControlPlanes.PublicIPIDs[:newLen]
+				p.state.CloudInfra.Azure.InfoControlPlanes.VMSizes = p.state.CloudInfra.Azure.InfoControlPlanes.VMSizes[:newLen]
+			}
+
+			if err := p.store.Write(p.state); err != nil {
+				return -1, err
+			}
 		}
 
 		return -1, nil
 	}
 	return -1, ksctlErrors.WrapError(
 		ksctlErrors.ErrInvalidNoOfControlplane,
-		p.l.NewError(p.ctx, "constrains for no of controlplane >= 3 and odd number"),
+		p.l.NewError(p.ctx, "too few controlplanes for a HA cluster", "minimum", provider.MinNoOfControlPlane),
 	)
 }
 
@@ -375,6 +444,9 @@ func (p *Provider) NoOfDataStore(no int, setter bool) (int, error) {
 		}
 
 		currLen := len(p.state.CloudInfra.Azure.InfoDatabase.Names)
+
+		newLen := no
+
 		if currLen == 0 {
 			p.state.CloudInfra.Azure.InfoDatabase.Names = make([]string, no)
 			p.state.CloudInfra.Azure.InfoDatabase.Hostnames = make([]string, no)
@@ -386,6 +458,41 @@ func (p *Provider) NoOfDataStore(no int, setter bool) (int, error) {
 			p.state.CloudInfra.Azure.InfoDatabase.PublicIPNames = make([]string, no)
 			p.state.CloudInfra.Azure.InfoDatabase.PublicIPIDs = make([]string, no)
 			p.state.CloudInfra.Azure.InfoDatabase.VMSizes = make([]string, no)
+		} else {
+			if currLen == newLen {
+				// no changes needed
+				return -1, nil
+			} else if currLen < newLen {
+				// for up-scaling
+				for i := currLen; i < newLen; i++ {
+					p.state.CloudInfra.Azure.InfoDatabase.Names = append(p.state.CloudInfra.Azure.InfoDatabase.Names, "")
+					p.state.CloudInfra.Azure.InfoDatabase.Hostnames = append(p.state.CloudInfra.Azure.InfoDatabase.Hostnames, "")
+					p.state.CloudInfra.Azure.InfoDatabase.PublicIPs = append(p.state.CloudInfra.Azure.InfoDatabase.PublicIPs, "")
+					p.state.CloudInfra.Azure.InfoDatabase.PrivateIPs = append(p.state.CloudInfra.Azure.InfoDatabase.PrivateIPs, "")
+					p.state.CloudInfra.Azure.InfoDatabase.DiskNames = append(p.state.CloudInfra.Azure.InfoDatabase.DiskNames, "")
+					p.state.CloudInfra.Azure.InfoDatabase.NetworkInterfaceNames = append(p.state.CloudInfra.Azure.InfoDatabase.NetworkInterfaceNames, "")
+					p.state.CloudInfra.Azure.InfoDatabase.NetworkInterfaceIDs = append(p.state.CloudInfra.Azure.InfoDatabase.NetworkInterfaceIDs, "")
+					p.state.CloudInfra.Azure.InfoDatabase.PublicIPNames = append(p.state.CloudInfra.Azure.InfoDatabase.PublicIPNames, "")
+					p.state.CloudInfra.Azure.InfoDatabase.PublicIPIDs = append(p.state.CloudInfra.Azure.InfoDatabase.PublicIPIDs, "")
+					p.state.CloudInfra.Azure.InfoDatabase.VMSizes = append(p.state.CloudInfra.Azure.InfoDatabase.VMSizes, "")
+				}
+			} else {
+				// for downscaling
+				p.state.CloudInfra.Azure.InfoDatabase.Names = p.state.CloudInfra.Azure.InfoDatabase.Names[:newLen]
+				p.state.CloudInfra.Azure.InfoDatabase.Hostnames = p.state.CloudInfra.Azure.InfoDatabase.Hostnames[:newLen]
+				p.state.CloudInfra.Azure.InfoDatabase.PublicIPs = p.state.CloudInfra.Azure.InfoDatabase.PublicIPs[:newLen]
+				p.state.CloudInfra.Azure.InfoDatabase.PrivateIPs = p.state.CloudInfra.Azure.InfoDatabase.PrivateIPs[:newLen]
+				p.state.CloudInfra.Azure.InfoDatabase.DiskNames = p.state.CloudInfra.Azure.InfoDatabase.DiskNames[:newLen]
+				p.state.CloudInfra.Azure.InfoDatabase.NetworkInterfaceNames = p.state.CloudInfra.Azure.InfoDatabase.NetworkInterfaceNames[:newLen]
+				p.state.CloudInfra.Azure.InfoDatabase.NetworkInterfaceIDs = p.state.CloudInfra.Azure.InfoDatabase.NetworkInterfaceIDs[:newLen]
+				p.state.CloudInfra.Azure.InfoDatabase.PublicIPNames = p.state.CloudInfra.Azure.InfoDatabase.PublicIPNames[:newLen]
+				p.state.CloudInfra.Azure.InfoDatabase.PublicIPIDs = p.state.CloudInfra.Azure.InfoDatabase.PublicIPIDs[:newLen]
+				p.state.CloudInfra.Azure.InfoDatabase.VMSizes = p.state.CloudInfra.Azure.InfoDatabase.VMSizes[:newLen]
+			}
+
+			if err := p.store.Write(p.state); err != nil {
+				return -1, err
+			}
 		}
 
 		return -1, nil
@@ -572,8 +679,10 @@ func (p *Provider) GetRAWClusterInfos() ([]provider.ClusterData, error) {
 		for _, v := range Vs {
 			data = append(data, provider.ClusterData{
 				Owner:         v.PlatformSpec.Owner,
+				Labels:        v.PlatformSpec.Labels,
 				Team:          v.PlatformSpec.Team,
 				State:         v.PlatformSpec.State,
+				FailedPhases:  v.FailedCheckpoints(),
 				CloudProvider: consts.CloudAzure,
 				Name:          v.ClusterName,
 				Region:        v.Region,
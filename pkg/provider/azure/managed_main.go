@@ -21,10 +21,13 @@ import (
 	armcontainerservice "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v6"
 	"github.com/ksctl/ksctl/v2/pkg/addons"
 	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
 	"github.com/ksctl/ksctl/v2/pkg/statefile"
 	"github.com/ksctl/ksctl/v2/pkg/utilities"
 )
 
+const managedAgentPoolName = "askagent"
+
 func GetManagedCNIAddons() (addons.ClusterAddons, string) {
 	return addons.ClusterAddons{
 		{
@@ -157,6 +160,7 @@ func (p *Provider) NewManagedCluster(noOfNodes int) error {
 
 	parameter := armcontainerservice.ManagedCluster{
 		Location: utilities.Ptr(p.state.Region),
+		Tags:     p.resourceTags(),
 		SKU: &armcontainerservice.ManagedClusterSKU{
 			Name: utilities.Ptr(armcontainerservice.ManagedClusterSKUNameBase),
 			Tier: utilities.Ptr(armcontainerservice.ManagedClusterSKUTierStandard),
@@ -175,7 +179,7 @@ func (p *Provider) NewManagedCluster(noOfNodes int) error {
 			},
 			AgentPoolProfiles: []*armcontainerservice.ManagedClusterAgentPoolProfile{
 				{
-					Name:              utilities.Ptr("askagent"),
+					Name:              utilities.Ptr(managedAgentPoolName),
 					Count:             utilities.Ptr(int32(noOfNodes)),
 					VMSize:            utilities.Ptr(vmtype),
 					MaxPods:           utilities.Ptr[int32](110),
@@ -183,6 +187,7 @@ func (p *Provider) NewManagedCluster(noOfNodes int) error {
 					Type:              utilities.Ptr(armcontainerservice.AgentPoolTypeVirtualMachineScaleSets),
 					EnableAutoScaling: utilities.Ptr(false),
 					Mode:              utilities.Ptr(armcontainerservice.AgentPoolModeSystem),
+					Tags:              p.resourceTags(),
 				},
 			},
 			ServicePrincipalProfile: &armcontainerservice.ManagedClusterServicePrincipalProfile{
@@ -233,3 +238,63 @@ func (p *Provider) NewManagedCluster(noOfNodes int) error {
 	p.l.Success(p.ctx, "created AKS", "name", *resp.Name)
 	return nil
 }
+
+// ScaleManagedCluster updates the count of the AKS agent pool, the autoscaler gets enabled
+// with the bounds when they are given and disabled otherwise
+func (p *Provider) ScaleManagedCluster(noOfNodes, minNodes, maxNodes int) error {
+	name := p.state.CloudInfra.Azure.ManagedClusterName
+	if len(name) == 0 {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidOperation,
+			p.l.NewError(p.ctx, "no AKS cluster to scale", "cluster", p.state.ClusterName),
+		)
+	}
+
+	if p.state.CloudInfra.Azure.NoManagedNodes == noOfNodes &&
+		p.state.CloudInfra.Azure.MinManagedNodes == minNodes &&
+		p.state.CloudInfra.Azure.MaxManagedNodes == maxNodes {
+		p.l.Print(p.ctx, "skipped scaling the AKS agent pool, it already has the desired size", "name", managedAgentPoolName)
+		return nil
+	}
+
+	pool, err := p.client.GetAgentPool(name, managedAgentPoolName, nil)
+	if err != nil {
+		return err
+	}
+
+	parameter := pool.AgentPool
+	if parameter.Properties == nil {
+		parameter.Properties = &armcontainerservice.ManagedClusterAgentPoolProfileProperties{}
+	}
+	parameter.Properties.Count = utilities.Ptr(int32(noOfNodes))
+	if maxNodes > 0 {
+		parameter.Properties.EnableAutoScaling = utilities.Ptr(true)
+		parameter.Properties.MinCount = utilities.Ptr(int32(minNodes))
+		parameter.Properties.MaxCount = utilities.Ptr(int32(maxNodes))
+	} else {
+		parameter.Properties.EnableAutoScaling = utilities.Ptr(false)
+		parameter.Properties.MinCount = nil
+		parameter.Properties.MaxCount = nil
+	}
+
+	p.l.Print(p.ctx, "Scaling the AKS agent pool", "name", managedAgentPoolName, "desired", noOfNodes, "min", minNodes, "max", maxNodes)
+
+	pollerResp, err := p.client.BeginUpdateAgentPool(name, managedAgentPoolName, parameter, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.client.PollUntilDoneUpdateAgentPool(p.ctx, pollerResp, nil); err != nil {
+		return err
+	}
+
+	p.state.CloudInfra.Azure.NoManagedNodes = noOfNodes
+	p.state.CloudInfra.Azure.MinManagedNodes = minNodes
+	p.state.CloudInfra.Azure.MaxManagedNodes = maxNodes
+	if err := p.store.Write(p.state); err != nil {
+		return err
+	}
+
+	p.l.Success(p.ctx, "Scaled the AKS agent pool", "name", managedAgentPoolName, "desired", noOfNodes)
+	return nil
+}
@@ -57,6 +57,7 @@ func TestManagedCluster(t *testing.T) {
 			Region:      "fake",
 			ClusterType: consts.ClusterTypeMang,
 			Provider:    consts.CloudAzure,
+			Labels:      map[string]string{"env": "dev", "cost-center": "1234"},
 		},
 		&statefile.StorageDocument{},
 		storeManaged,
@@ -77,6 +78,7 @@ func TestManagedCluster(t *testing.T) {
 			t.Fatalf("There should be state of creating!!!")
 		} else {
 			assert.Equal(t, v.PlatformSpec.State, statefile.Creating, "state should be creating")
+			assert.DeepEqual(t, v.PlatformSpec.Labels, map[string]string{"env": "dev", "cost-center": "1234"})
 		}
 	})
 
@@ -104,6 +106,26 @@ func TestManagedCluster(t *testing.T) {
 		checkCurrentStateFile(t)
 	})
 
+	t.Run("Update labels", func(t *testing.T) {
+		assert.NilError(t, fakeClientManaged.UpdateLabels(map[string]string{"env": "prod"}))
+		assert.DeepEqual(t, fakeClientManaged.state.PlatformSpec.Labels, map[string]string{"env": "prod"})
+		checkCurrentStateFile(t)
+	})
+
+	t.Run("Scale managed cluster", func(t *testing.T) {
+		assert.NilError(t, fakeClientManaged.ScaleManagedCluster(3, 1, 5))
+		assert.Equal(t, fakeClientManaged.state.CloudInfra.Azure.NoManagedNodes, 3)
+		assert.Equal(t, fakeClientManaged.state.CloudInfra.Azure.MinManagedNodes, 1)
+		assert.Equal(t, fakeClientManaged.state.CloudInfra.Azure.MaxManagedNodes, 5)
+		checkCurrentStateFile(t)
+
+		assert.NilError(t, fakeClientManaged.ScaleManagedCluster(5, 0, 0))
+		assert.Equal(t, fakeClientManaged.state.CloudInfra.Azure.NoManagedNodes, 5)
+		assert.Equal(t, fakeClientManaged.state.CloudInfra.Azure.MinManagedNodes, 0)
+		assert.Equal(t, fakeClientManaged.state.CloudInfra.Azure.MaxManagedNodes, 0)
+		checkCurrentStateFile(t)
+	})
+
 	t.Run("Get cluster managed", func(t *testing.T) {
 		expected := []provider.ClusterData{
 			{
@@ -112,6 +134,7 @@ func TestManagedCluster(t *testing.T) {
 				ClusterType:     consts.ClusterTypeMang,
 				Owner:           "dipankar.das@ksctl.com",
 				Team:            "47f9a67b-2499-4e96-9576-ddc703d839f0",
+				Labels:          map[string]string{"env": "prod"},
 				State:           statefile.Creating, // As the controller is not here where it actually sets the state so it is creating
 				ResourceGrpName: generateResourceGroupName(fakeClientManaged.ClusterName, string(consts.ClusterTypeMang)),
 				Region:          fakeClientManaged.Region,
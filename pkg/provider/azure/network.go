@@ -33,6 +33,7 @@ func (p *Provider) NewNetwork() error {
 		// NOTE: for the azure resource group we are not using the resName field
 		parameter := armresources.ResourceGroup{
 			Location: utilities.Ptr(p.Region),
+			Tags:     p.resourceTags(),
 		}
 
 		p.l.Debug(p.ctx, "Printing", "resourceGrpConfig", parameter)
@@ -74,6 +75,7 @@ func (p *Provider) CreateVirtualNetwork(resName string) error {
 
 	parameters := armnetwork.VirtualNetwork{
 		Location: utilities.Ptr(p.Region),
+		Tags:     p.resourceTags(),
 		Properties: &armnetwork.VirtualNetworkPropertiesFormat{
 			AddressSpace: &armnetwork.AddressSpace{
 				AddressPrefixes: []*string{
@@ -116,6 +118,7 @@ func (p *Provider) CreateSubnet(subnetName string) error {
 		return nil
 	}
 
+	// subnets are child resources of the virtual network and azure doesn't support tags on them
 	parameters := armnetwork.Subnet{
 		Properties: &armnetwork.SubnetPropertiesFormat{
 			AddressPrefix: utilities.Ptr("10.1.0.0/16"),
@@ -39,6 +39,7 @@ func (p *Provider) CreateUploadSSHKeyPair() error {
 
 	parameters := armcompute.SSHPublicKeyResource{
 		Location: utilities.Ptr(p.Region),
+		Tags:     p.resourceTags(),
 		Properties: &armcompute.SSHPublicKeyResourceProperties{
 			PublicKey: utilities.Ptr(p.state.SSHKeyPair.PublicKey),
 		},
@@ -126,6 +126,12 @@ func (p *Provider) NewVM(index int) error {
 	diskName := name + "-disk"
 	p.l.Debug(p.ctx, "Printing", "pubIPName", pubIPName, "NICName", nicName, "diskName", diskName)
 
+	// It means E6 Standard_SSD_LRS has 64GB disk size
+	diskSize := int32(64)
+	if pool := p.state.WorkerPoolOf(indexNo); role == consts.RoleWp && pool != nil && pool.DiskSize > 0 {
+		diskSize = int32(pool.DiskSize)
+	}
+
 	if err := p.CreatePublicIP(pubIPName, indexNo, role); err != nil {
 		return err
 	}
@@ -192,6 +198,7 @@ func (p *Provider) NewVM(index int) error {
 
 	parameters := armcompute.VirtualMachine{
 		Location: utilities.Ptr(p.Region),
+		Tags:     p.resourceTags(),
 		Identity: &armcompute.VirtualMachineIdentity{
 			Type: utilities.Ptr(armcompute.ResourceIdentityTypeNone),
 		},
@@ -211,8 +218,7 @@ func (p *Provider) NewVM(index int) error {
 					ManagedDisk: &armcompute.ManagedDiskParameters{
 						StorageAccountType: utilities.Ptr(armcompute.StorageAccountTypesStandardSSDLRS), // OSDisk type Standard/Premium HDD/SSD
 					},
-					// It means E6 Standard_SSD_LRS has 64GB disk size
-					DiskSizeGB: utilities.Ptr[int32](64), // default 127G
+					DiskSizeGB: utilities.Ptr(diskSize), // default 127G
 				},
 			},
 			HardwareProfile: &armcompute.HardwareProfile{
@@ -284,6 +290,7 @@ func (p *Provider) NewVM(index int) error {
 	p.l.Print(p.ctx, "creating vm...", "name", name)
 
 	errCreateVM = nil //just to make sure its nil
+	diskID := ""
 	donePoll := make(chan struct{})
 	go func() {
 		defer close(donePoll)
@@ -293,6 +300,9 @@ func (p *Provider) NewVM(index int) error {
 			errCreateVM = err
 			return
 		}
+		if sp := resp.Properties.StorageProfile; sp != nil && sp.OSDisk != nil && sp.OSDisk.ManagedDisk != nil && sp.OSDisk.ManagedDisk.ID != nil {
+			diskID = *sp.OSDisk.ManagedDisk.ID
+		}
 		p.mu.Lock()
 		defer p.mu.Unlock()
 
@@ -331,6 +341,13 @@ func (p *Provider) NewVM(index int) error {
 		return errCreateVM
 	}
 
+	// the os disk gets created along with the vm and doesn't take its tags
+	if len(diskID) != 0 {
+		if err := p.mergeResourceTags(diskID, p.state.PlatformSpec.Labels); err != nil {
+			return err
+		}
+	}
+
 	p.l.Success(p.ctx, "Created virtual machine", "name", name)
 	return nil
 }
@@ -417,6 +434,7 @@ func (p *Provider) CreatePublicIP(publicIPName string, index int, role consts.Ks
 
 	parameters := armnetwork.PublicIPAddress{
 		Location: utilities.Ptr(p.Region),
+		Tags:     p.resourceTags(),
 		Properties: &armnetwork.PublicIPAddressPropertiesFormat{
 			PublicIPAllocationMethod: utilities.Ptr(armnetwork.IPAllocationMethodStatic), // Static or Dynamic
 		},
@@ -592,6 +610,7 @@ func (p *Provider) CreateNetworkInterface(nicName string, subnetID string, publi
 
 	parameters := armnetwork.Interface{
 		Location: utilities.Ptr(p.Region),
+		Tags:     p.resourceTags(),
 		Properties: &armnetwork.InterfacePropertiesFormat{
 			IPConfigurations: []*armnetwork.InterfaceIPConfiguration{
 				{
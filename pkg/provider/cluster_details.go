@@ -35,6 +35,7 @@ type ClusterData struct {
 	Name            string
 	Owner           string
 	Team            string
+	Labels          map[string]string
 	State           statefile.ClusterState
 	CloudProvider   consts.KsctlCloud
 	ClusterType     consts.KsctlClusterType
@@ -61,4 +62,7 @@ type ClusterData struct {
 	HAProxyVersion  string
 	Apps            []string
 	Cni             string
+
+	// FailedPhases are the phases of the creation which failed in its last attempt
+	FailedPhases []statefile.Checkpoint
 }
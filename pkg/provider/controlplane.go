@@ -0,0 +1,25 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+// MinNoOfControlPlane is the least no of controlplanes of a HA cluster. The controlplanes run against
+// the external datastores and hold no quorum, so their count can be even, still the loadbalancer needs
+// an api server to send the traffic to while another one is down
+const MinNoOfControlPlane = 2
+
+// ValidNoOfControlPlane reports if a HA cluster can have the no of controlplanes
+func ValidNoOfControlPlane(no int) bool {
+	return no >= MinNoOfControlPlane
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/ksctl/ksctl/v2/pkg/events"
 	"github.com/ksctl/ksctl/v2/pkg/handler/cluster/controller"
 	"github.com/ksctl/ksctl/v2/pkg/logger"
 	"github.com/ksctl/ksctl/v2/pkg/statefile"
@@ -26,6 +27,7 @@ import (
 	awsPkg "github.com/ksctl/ksctl/v2/pkg/provider/aws"
 	azurePkg "github.com/ksctl/ksctl/v2/pkg/provider/azure"
 	localPkg "github.com/ksctl/ksctl/v2/pkg/provider/local"
+	"github.com/ksctl/ksctl/v2/pkg/waiter"
 )
 
 type Controller struct {
@@ -34,6 +36,11 @@ type Controller struct {
 	p   *controller.Client
 	b   *controller.Controller
 	s   *statefile.StorageDocument
+
+	// plan is set when the controller only records the calls to the cloud
+	plan *planCloud
+
+	cp *controller.Checkpoints
 }
 
 func NewController(
@@ -51,6 +58,7 @@ func NewController(
 	cc.b = baseController
 	cc.p = controllerPayload
 	cc.s = state
+	cc.cp = controller.NewCheckpoints(state, controllerPayload.Storage)
 
 	err := cc.setupInterfaces(operation)
 	if err != nil {
@@ -91,6 +99,20 @@ func (kc *Controller) setupInterfaces(operation consts.KsctlOperation) error {
 	return nil
 }
 
-func pauseOperation(seconds time.Duration) {
-	time.Sleep(seconds * time.Second)
+func (kc *Controller) pauseOperation(seconds time.Duration) error {
+	return waiter.Sleep(kc.ctx, kc.l, seconds*time.Second)
+}
+
+// track runs fn as a step of the operation, reporting its start and its outcome to the events
+func (kc *Controller) track(phase statefile.CreationPhase, name string, role consts.KsctlRole, no int, fn func() error) error {
+	done := events.Step(kc.ctx, events.Event{
+		Phase:    string(phase),
+		Resource: string(phase),
+		Name:     name,
+		Role:     role,
+		Index:    no,
+	})
+	err := fn()
+	done(err)
+	return err
 }
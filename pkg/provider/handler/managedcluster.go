@@ -14,15 +14,26 @@
 
 package handler
 
+import (
+	"github.com/ksctl/ksctl/v2/pkg/events"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/waiter"
+)
+
 func (kc *Controller) CreateManagedCluster() (bool, error) {
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+		return false, err
+	}
 
 	if !kc.b.IsLocalProvider(kc.p) {
-		if err := kc.p.Cloud.Name(kc.p.Metadata.ClusterName + "-ksctl-managed-net").NewNetwork(); err != nil {
+		name := kc.p.Metadata.ClusterName + "-ksctl-managed-net"
+		if err := kc.track(statefile.PhaseNetwork, name, "", 0, kc.p.Cloud.Name(name).NewNetwork); err != nil {
 			return false, err
 		}
 	}
 
-	managedClient := kc.p.Cloud.Name(kc.p.Metadata.ClusterName + "-ksctl-managed")
+	name := kc.p.Metadata.ClusterName + "-ksctl-managed"
+	managedClient := kc.p.Cloud.Name(name)
 
 	managedClient = managedClient.VMType(kc.p.Metadata.ManagedNodeType)
 
@@ -34,22 +45,59 @@ func (kc *Controller) CreateManagedCluster() (bool, error) {
 		return externalCNI, kc.l.NewError(kc.ctx, "invalid k8s version")
 	}
 
-	if err := managedClient.NewManagedCluster(kc.p.Metadata.NoMP); err != nil {
+	done := events.Step(kc.ctx, events.Event{
+		Phase:    events.PhaseManagedCluster,
+		Resource: events.PhaseManagedCluster,
+		Name:     name,
+	})
+	err := managedClient.NewManagedCluster(kc.p.Metadata.NoMP)
+	done(err)
+	if err != nil {
 		return externalCNI, err
 	}
 	return externalCNI, nil
 }
 
 func (kc *Controller) DeleteManagedCluster() error {
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+		return err
+	}
+
+	if kc.b.IsLocalProvider(kc.p) {
+		events.Expect(kc.ctx, 1)
+	} else {
+		events.Expect(kc.ctx, 2)
+	}
 
-	if err := kc.p.Cloud.DelManagedCluster(); err != nil {
+	done := events.Step(kc.ctx, events.Event{
+		Phase:    events.PhaseManagedCluster,
+		Resource: events.PhaseManagedCluster,
+	})
+	err := kc.p.Cloud.DelManagedCluster()
+	done(err)
+	if err != nil {
 		return err
 	}
 
 	if !kc.b.IsLocalProvider(kc.p) {
-		if err := kc.p.Cloud.DelNetwork(); err != nil {
+		if err := kc.track(statefile.PhaseNetwork, "", "", 0, kc.p.Cloud.DelNetwork); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+func (kc *Controller) ScaleManagedCluster() error {
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+		return err
+	}
+
+	done := events.Step(kc.ctx, events.Event{
+		Phase:    events.PhaseManagedCluster,
+		Resource: events.PhaseManagedCluster,
+		Name:     kc.p.Metadata.ClusterName,
+	})
+	err := kc.p.Cloud.ScaleManagedCluster(kc.p.Metadata.NoMP, kc.p.Metadata.NoMPMin, kc.p.Metadata.NoMPMax)
+	done(err)
+	return err
+}
@@ -0,0 +1,629 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/ksctl/ksctl/v2/pkg/addons"
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/handler/cluster/controller"
+	"github.com/ksctl/ksctl/v2/pkg/logger"
+	"github.com/ksctl/ksctl/v2/pkg/provider"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/utilities"
+)
+
+// NewPlanController returns a Controller whose flows record the calls they would make
+// to the cloud instead of making them. state is the current state of the cluster and
+// is only read, it is empty for a cluster which doesn't exist yet
+func NewPlanController(
+	ctx context.Context,
+	log logger.Logger,
+	baseController *controller.Controller,
+	state *statefile.StorageDocument,
+	controllerPayload *controller.Client,
+) *Controller {
+
+	cc := new(Controller)
+	cc.ctx = context.WithValue(ctx, consts.KsctlModuleNameKey, "ksctl-provisioner")
+	cc.l = log
+	cc.b = baseController
+	cc.p = controllerPayload
+	cc.s = state
+	// the scratch state must never reach the storage
+	cc.cp = controller.NewCheckpoints(state, nil)
+
+	cc.plan = newPlanCloud(cc.ctx, log, controllerPayload.Metadata, state)
+	cc.p.Cloud = cc.plan
+
+	return cc
+}
+
+// PlannedResources returns the resources recorded so far in the order the flows would
+// create or delete them, the vms created or deleted in parallel are ordered by role and index
+func (kc *Controller) PlannedResources() []controller.PlannedResource {
+	if kc.plan == nil {
+		return nil
+	}
+	return kc.plan.resources()
+}
+
+// planVM is a vm found in the state, name is empty when it isn't created yet
+type planVM struct {
+	name      string
+	vmType    string
+	publicIP  string
+	privateIP string
+}
+
+// planExisting holds the resources found in the state of the cluster
+type planExisting struct {
+	network        bool
+	sshKey         string
+	firewalls      map[consts.KsctlRole]string
+	vms            map[consts.KsctlRole][]planVM
+	managedCluster string
+	sshUser        string
+}
+
+type planStep struct {
+	batch    int
+	index    int
+	resource controller.PlannedResource
+}
+
+// planRecorder is shared by all the builders of a planCloud
+// as the flows create and delete the vms from several goroutines
+type planRecorder struct {
+	mu    sync.Mutex
+	steps []planStep
+	batch int
+	count map[consts.KsctlRole]int
+}
+
+// planCloud is the provider.Cloud of the plan mode, every builder call returns a copy
+// so that concurrent chains don't share the name, role or vm type
+type planCloud struct {
+	ctx  context.Context
+	l    logger.Logger
+	meta controller.Metadata
+	ex   planExisting
+	rec  *planRecorder
+
+	name   string
+	role   consts.KsctlRole
+	vmType string
+}
+
+func newPlanCloud(ctx context.Context, l logger.Logger, meta controller.Metadata, state *statefile.StorageDocument) *planCloud {
+	ex := existingResources(meta.Provider, state)
+	rec := &planRecorder{count: map[consts.KsctlRole]int{}}
+	for role, vms := range ex.vms {
+		rec.count[role] = len(vms)
+	}
+	return &planCloud{ctx: ctx, l: l, meta: meta, ex: ex, rec: rec}
+}
+
+func existingResources(cloud consts.KsctlCloud, state *statefile.StorageDocument) planExisting {
+	ex := planExisting{
+		firewalls: map[consts.KsctlRole]string{},
+		vms:       map[consts.KsctlRole][]planVM{},
+	}
+	if state == nil || state.CloudInfra == nil {
+		return ex
+	}
+
+	at := func(s []string, i int) string {
+		if i < len(s) {
+			return s[i]
+		}
+		return ""
+	}
+
+	switch {
+	case cloud == consts.CloudAws && state.CloudInfra.Aws != nil:
+		s := state.CloudInfra.Aws
+		ex.network = len(s.VpcId) != 0
+		ex.sshKey = s.B.SSHKeyName
+		ex.sshUser = s.B.SSHUser
+		ex.managedCluster = s.ManagedClusterName
+
+		for role, vms := range map[consts.KsctlRole]statefile.AWSStateVms{
+			consts.RoleCp: s.InfoControlPlanes,
+			consts.RoleWp: s.InfoWorkerPlanes,
+			consts.RoleDs: s.InfoDatabase,
+		} {
+			ex.firewalls[role] = vms.NetworkSecurityGroupIDs
+			for i := range vms.HostNames {
+				vm := planVM{vmType: at(vms.VMSizes, i), publicIP: at(vms.PublicIPs, i), privateIP: at(vms.PrivateIPs, i)}
+				if len(at(vms.InstanceIds, i)) != 0 {
+					vm.name = vms.HostNames[i]
+				}
+				ex.vms[role] = append(ex.vms[role], vm)
+			}
+		}
+		ex.firewalls[consts.RoleLb] = s.InfoLoadBalancer.NetworkSecurityGroupID
+		if len(s.InfoLoadBalancer.InstanceID) != 0 {
+			lb := s.InfoLoadBalancer
+			ex.vms[consts.RoleLb] = []planVM{{name: lb.HostName, vmType: lb.VMSize, publicIP: lb.PublicIP, privateIP: lb.PrivateIP}}
+		}
+
+	case cloud == consts.CloudAzure && state.CloudInfra.Azure != nil:
+		s := state.CloudInfra.Azure
+		ex.network = len(s.ResourceGroupName) != 0
+		ex.sshKey = s.B.SSHKeyName
+		ex.sshUser = s.B.SSHUser
+		ex.managedCluster = s.ManagedClusterName
+
+		for role, vms := range map[consts.KsctlRole]statefile.AzureStateVMs{
+			consts.RoleCp: s.InfoControlPlanes,
+			consts.RoleWp: s.InfoWorkerPlanes,
+			consts.RoleDs: s.InfoDatabase,
+		} {
+			ex.firewalls[role] = vms.NetworkSecurityGroupName
+			for i := range vms.Names {
+				ex.vms[role] = append(ex.vms[role], planVM{
+					name:      vms.Names[i],
+					vmType:    at(vms.VMSizes, i),
+					publicIP:  at(vms.PublicIPs, i),
+					privateIP: at(vms.PrivateIPs, i),
+				})
+			}
+		}
+		ex.firewalls[consts.RoleLb] = s.InfoLoadBalancer.NetworkSecurityGroupName
+		if len(s.InfoLoadBalancer.Name) != 0 {
+			lb := s.InfoLoadBalancer
+			ex.vms[consts.RoleLb] = []planVM{{name: lb.Name, vmType: lb.VMSize, publicIP: lb.PublicIP, privateIP: lb.PrivateIP}}
+		}
+
+	case cloud == consts.CloudLocal && state.CloudInfra.Local != nil:
+		if state.CloudInfra.Local.B.IsCompleted {
+			ex.managedCluster = state.ClusterName
+		}
+	}
+
+	return ex
+}
+
+// roleRank is the order in which the flows start the vms of every role
+func roleRank(action controller.PlanAction, role consts.KsctlRole) int {
+	order := []consts.KsctlRole{consts.RoleLb, consts.RoleDs, consts.RoleCp, consts.RoleWp}
+	if action == controller.PlanActionDelete {
+		order = []consts.KsctlRole{consts.RoleWp, consts.RoleCp, consts.RoleDs, consts.RoleLb}
+	}
+	for i, r := range order {
+		if r == role {
+			return i
+		}
+	}
+	return len(order)
+}
+
+// record appends the resources of a sequential step
+func (c *planCloud) record(resources ...controller.PlannedResource) {
+	c.rec.mu.Lock()
+	defer c.rec.mu.Unlock()
+
+	c.rec.batch++
+	for _, r := range resources {
+		c.rec.steps = append(c.rec.steps, planStep{batch: c.rec.batch, resource: r})
+	}
+	// vms recorded after this step form a batch of their own
+	c.rec.batch++
+}
+
+// recordVM appends the resources of a vm, the vms share the batch of the steps around them
+func (c *planCloud) recordVM(index int, resources ...controller.PlannedResource) {
+	c.rec.mu.Lock()
+	defer c.rec.mu.Unlock()
+
+	for _, r := range resources {
+		c.rec.steps = append(c.rec.steps, planStep{batch: c.rec.batch, index: index, resource: r})
+	}
+}
+
+func (c *planCloud) resources() []controller.PlannedResource {
+	c.rec.mu.Lock()
+	steps := append([]planStep(nil), c.rec.steps...)
+	c.rec.mu.Unlock()
+
+	sort.SliceStable(steps, func(i, j int) bool {
+		a, b := steps[i], steps[j]
+		if a.batch != b.batch {
+			return a.batch < b.batch
+		}
+		ra, rb := roleRank(a.resource.Action, a.resource.Role), roleRank(b.resource.Action, b.resource.Role)
+		if ra != rb {
+			return ra < rb
+		}
+		return a.index < b.index
+	})
+
+	out := make([]controller.PlannedResource, 0, len(steps))
+	for _, s := range steps {
+		out = append(out, s.resource)
+	}
+	return out
+}
+
+// reversed returns the resources in the order they get deleted
+func reversed(action controller.PlanAction, resources []controller.PlannedResource) []controller.PlannedResource {
+	if action == controller.PlanActionCreate {
+		return resources
+	}
+	out := make([]controller.PlannedResource, 0, len(resources))
+	for i := len(resources) - 1; i >= 0; i-- {
+		r := resources[i]
+		r.Action = action
+		out = append(out, r)
+	}
+	return out
+}
+
+func (c *planCloud) networkResources(action controller.PlanAction) []controller.PlannedResource {
+	name := c.meta.ClusterName
+	r := func(kind, name string) controller.PlannedResource {
+		return controller.PlannedResource{Action: action, Kind: kind, Name: name}
+	}
+
+	var out []controller.PlannedResource
+	switch c.meta.Provider {
+	case consts.CloudAws:
+		out = append(out, r("vpc", name+"-vpc"))
+		for i := 0; i < 3; i++ {
+			out = append(out, r("subnet", name+"-subnet"+strconv.Itoa(i)))
+		}
+		out = append(out,
+			r("network-acl", name+"-nacl"),
+			r("internet-gateway", name+"-ig"),
+			r("route-table", name+"-rt"),
+		)
+	case consts.CloudAzure:
+		out = append(out, r("resource-group", fmt.Sprintf("ksctl-resgrp-%s-%s", c.meta.ClusterType, name)))
+		if c.meta.ClusterType == consts.ClusterTypeSelfMang {
+			out = append(out,
+				r("virtual-network", name+"-vnet"),
+				r("subnet", name+"-subnet"),
+			)
+		}
+	}
+	return reversed(action, out)
+}
+
+func (c *planCloud) vmResources(action controller.PlanAction, index int, name, vmType string) []controller.PlannedResource {
+	r := func(kind, name, vmType string) controller.PlannedResource {
+		return controller.PlannedResource{Action: action, Kind: kind, Name: name, Role: c.role, VMType: vmType}
+	}
+
+	var out []controller.PlannedResource
+	switch c.meta.Provider {
+	case consts.CloudAws:
+		out = append(out,
+			r("network-interface", string(c.role)+strconv.Itoa(index)+name, ""),
+			r("instance", name, vmType),
+			r("volume", name+"-disk", ""),
+		)
+	case consts.CloudAzure:
+		out = append(out,
+			r("public-ip", name+"-pub", ""),
+			r("network-interface", name+"-nic", ""),
+			r("virtual-machine", name, vmType),
+			r("disk", name+"-disk", ""),
+		)
+	}
+	return reversed(action, out)
+}
+
+func (c *planCloud) managedResources(action controller.PlanAction, name, vmType string, count int) []controller.PlannedResource {
+	r := func(kind, name string) controller.PlannedResource {
+		return controller.PlannedResource{Action: action, Kind: kind, Name: name}
+	}
+	nodes := func(kind, name string) controller.PlannedResource {
+		return controller.PlannedResource{Action: action, Kind: kind, Name: name, VMType: vmType, Count: count}
+	}
+
+	var out []controller.PlannedResource
+	switch c.meta.Provider {
+	case consts.CloudAws:
+		out = append(out,
+			r("iam-role", fmt.Sprintf("ksctl-%s-cp-role", name)),
+			r("eks-cluster", name),
+			r("iam-role", fmt.Sprintf("ksctl-%s-wp-role", name)),
+			nodes("eks-nodegroup", name+"-nodegroup"),
+		)
+	case consts.CloudAzure:
+		out = append(out, nodes("aks-cluster", name))
+	case consts.CloudLocal:
+		out = append(out, nodes("kind-cluster", name))
+	}
+	return reversed(action, out)
+}
+
+func (c *planCloud) firewallKind() string {
+	if c.meta.Provider == consts.CloudAzure {
+		return "network-security-group"
+	}
+	return "security-group"
+}
+
+func (c *planCloud) existingVM(index int) (planVM, bool) {
+	vms := c.ex.vms[c.role]
+	if index < len(vms) && len(vms[index].name) != 0 {
+		return vms[index], true
+	}
+	return planVM{}, false
+}
+
+func (c *planCloud) NewVM(index int) error {
+	if _, ok := c.existingVM(index); ok {
+		return nil
+	}
+	c.recordVM(index, c.vmResources(controller.PlanActionCreate, index, c.name, c.vmType)...)
+	return nil
+}
+
+func (c *planCloud) DelVM(index int) error {
+	vm, ok := c.existingVM(index)
+	if !ok {
+		return nil
+	}
+	c.recordVM(index, c.vmResources(controller.PlanActionDelete, index, vm.name, vm.vmType)...)
+	return nil
+}
+
+func (c *planCloud) NewFirewall() error {
+	if len(c.ex.firewalls[c.role]) != 0 {
+		return nil
+	}
+	c.record(controller.PlannedResource{Action: controller.PlanActionCreate, Kind: c.firewallKind(), Name: c.name, Role: c.role})
+	return nil
+}
+
+func (c *planCloud) DelFirewall() error {
+	name := c.ex.firewalls[c.role]
+	if len(name) == 0 {
+		return nil
+	}
+	c.record(controller.PlannedResource{Action: controller.PlanActionDelete, Kind: c.firewallKind(), Name: name, Role: c.role})
+	return nil
+}
+
+func (c *planCloud) NewNetwork() error {
+	if c.ex.network {
+		return nil
+	}
+	c.record(c.networkResources(controller.PlanActionCreate)...)
+	return nil
+}
+
+func (c *planCloud) DelNetwork() error {
+	if !c.ex.network {
+		return nil
+	}
+	c.record(c.networkResources(controller.PlanActionDelete)...)
+	return nil
+}
+
+func (c *planCloud) InitState(consts.KsctlOperation) error {
+	return nil
+}
+
+func (c *planCloud) CreateUploadSSHKeyPair() error {
+	if len(c.ex.sshKey) != 0 {
+		return nil
+	}
+	c.record(controller.PlannedResource{Action: controller.PlanActionCreate, Kind: "ssh-key", Name: c.name})
+	return nil
+}
+
+func (c *planCloud) DelSSHKeyPair() error {
+	if len(c.ex.sshKey) == 0 {
+		return nil
+	}
+	c.record(controller.PlannedResource{Action: controller.PlanActionDelete, Kind: "ssh-key", Name: c.ex.sshKey})
+	return nil
+}
+
+// GetStateForHACluster returns the addresses of the existing vms and placeholders for the planned ones
+func (c *planCloud) GetStateForHACluster() (provider.CloudResourceState, error) {
+	c.rec.mu.Lock()
+	defer c.rec.mu.Unlock()
+
+	addresses := func(role consts.KsctlRole) (public, private []string) {
+		for i := 0; i < c.rec.count[role]; i++ {
+			vms := c.ex.vms[role]
+			if i < len(vms) && len(vms[i].name) != 0 {
+				public = append(public, vms[i].publicIP)
+				private = append(private, vms[i].privateIP)
+				continue
+			}
+			public = append(public, fmt.Sprintf("<%s-%d-public-ip>", role, i))
+			private = append(private, fmt.Sprintf("<%s-%d-private-ip>", role, i))
+		}
+		return
+	}
+
+	state := provider.CloudResourceState{
+		SSHUserName: c.ex.sshUser,
+		ClusterName: c.meta.ClusterName,
+		Region:      c.meta.Region,
+		ClusterType: c.meta.ClusterType,
+		Provider:    c.meta.Provider,
+	}
+	state.IPv4ControlPlanes, state.PrivateIPv4ControlPlanes = addresses(consts.RoleCp)
+	state.IPv4DataStores, state.PrivateIPv4DataStores = addresses(consts.RoleDs)
+	state.IPv4WorkerPlanes, _ = addresses(consts.RoleWp)
+
+	if lb := c.ex.vms[consts.RoleLb]; len(lb) != 0 {
+		state.IPv4LoadBalancer, state.PrivateIPv4LoadBalancer = lb[0].publicIP, lb[0].privateIP
+	} else {
+		state.IPv4LoadBalancer = fmt.Sprintf("<%s-public-ip>", consts.RoleLb)
+		state.PrivateIPv4LoadBalancer = fmt.Sprintf("<%s-private-ip>", consts.RoleLb)
+	}
+
+	return state, nil
+}
+
+func (c *planCloud) NewManagedCluster(noOfNodes int) error {
+	if len(c.ex.managedCluster) != 0 {
+		return nil
+	}
+	c.record(c.managedResources(controller.PlanActionCreate, c.name, c.vmType, noOfNodes)...)
+	return nil
+}
+
+func (c *planCloud) DelManagedCluster() error {
+	if len(c.ex.managedCluster) == 0 {
+		return nil
+	}
+	c.record(c.managedResources(controller.PlanActionDelete, c.ex.managedCluster, "", 0)...)
+	return nil
+}
+
+func (c *planCloud) ScaleManagedCluster(int, int, int) error {
+	return nil
+}
+
+func (c *planCloud) GetRAWClusterInfos() ([]provider.ClusterData, error) {
+	return nil, nil
+}
+
+func (c *planCloud) Name(name string) provider.Cloud {
+	cc := *c
+	cc.name = name
+	return &cc
+}
+
+func (c *planCloud) Role(role consts.KsctlRole) provider.Cloud {
+	cc := *c
+	cc.role = role
+	return &cc
+}
+
+func (c *planCloud) VMType(vmType string) provider.Cloud {
+	cc := *c
+	cc.vmType = vmType
+	return &cc
+}
+
+func (c *planCloud) Visibility(bool) provider.Cloud {
+	cc := *c
+	return &cc
+}
+
+func (c *planCloud) ManagedAddons(addons.ClusterAddons) (willBeInstalled bool) {
+	return false
+}
+
+func (c *planCloud) ManagedK8sVersion(string) provider.Cloud {
+	cc := *c
+	return &cc
+}
+
+// noOf mirrors the constraints of the providers on the no of nodes of every role
+func (c *planCloud) noOf(role consts.KsctlRole, no int, setter bool) (int, error) {
+	c.rec.mu.Lock()
+	defer c.rec.mu.Unlock()
+
+	if !setter {
+		return c.rec.count[role], nil
+	}
+
+	switch role {
+	case consts.RoleCp:
+		if !provider.ValidNoOfControlPlane(no) {
+			return -1, ksctlErrors.WrapError(
+				ksctlErrors.ErrInvalidNoOfControlplane,
+				c.l.NewError(c.ctx, "too few controlplanes for a HA cluster", "minimum", provider.MinNoOfControlPlane),
+			)
+		}
+	case consts.RoleDs:
+		if no < 3 || (no&1) == 0 {
+			return -1, ksctlErrors.WrapError(
+				ksctlErrors.ErrInvalidNoOfDatastore,
+				c.l.NewError(c.ctx, "constrains for no of Datastore>= 3 and odd number"),
+			)
+		}
+	case consts.RoleWp:
+		if no < 0 {
+			return -1, ksctlErrors.WrapError(
+				ksctlErrors.ErrInvalidNoOfWorkerplane,
+				c.l.NewError(c.ctx, "constrains for no of workerplane >= 0"),
+			)
+		}
+	}
+
+	c.rec.count[role] = no
+	return no, nil
+}
+
+func (c *planCloud) NoOfWorkerPlane(no int, setter bool) (int, error) {
+	return c.noOf(consts.RoleWp, no, setter)
+}
+
+func (c *planCloud) PruneWorkerPlanes(indexes []int) error {
+	c.rec.mu.Lock()
+	defer c.rec.mu.Unlock()
+
+	for _, i := range indexes {
+		if i < c.rec.count[consts.RoleWp] {
+			c.rec.count[consts.RoleWp]--
+		}
+	}
+	c.ex.vms[consts.RoleWp] = utilities.RemoveIndexes(c.ex.vms[consts.RoleWp], indexes)
+	return nil
+}
+
+func (c *planCloud) NoOfControlPlane(no int, setter bool) (int, error) {
+	return c.noOf(consts.RoleCp, no, setter)
+}
+
+func (c *planCloud) NoOfDataStore(no int, setter bool) (int, error) {
+	return c.noOf(consts.RoleDs, no, setter)
+}
+
+func (c *planCloud) GetHostNameAllWorkerNode() []string {
+	var hostnames []string
+	for _, vm := range c.ex.vms[consts.RoleWp] {
+		hostnames = append(hostnames, vm.name)
+	}
+	return hostnames
+}
+
+func (c *planCloud) GetHostNameAllControlPlane() []string {
+	var hostnames []string
+	for _, vm := range c.ex.vms[consts.RoleCp] {
+		hostnames = append(hostnames, vm.name)
+	}
+	return hostnames
+}
+
+func (c *planCloud) IsPresent() error {
+	return nil
+}
+
+func (c *planCloud) GetKubeconfig() (*string, error) {
+	return nil, nil
+}
+
+func (c *planCloud) UpdateLabels(map[string]string) error {
+	return nil
+}
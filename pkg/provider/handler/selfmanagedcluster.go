@@ -19,10 +19,18 @@ import (
 	"sync"
 
 	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/events"
 	"github.com/ksctl/ksctl/v2/pkg/provider"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/utilities"
+	"github.com/ksctl/ksctl/v2/pkg/waiter"
 )
 
 func (kc *Controller) DeleteHACluster() error {
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+		return err
+	}
 
 	var err error
 
@@ -41,6 +49,9 @@ func (kc *Controller) DeleteHACluster() error {
 		return err
 	}
 
+	// the vms, the 4 firewalls, the ssh key and the network
+	events.Expect(kc.ctx, 1+noDS+noCP+noWP+6)
+
 	//////
 	wg := &sync.WaitGroup{}
 	errChanLB := make(chan error, 1)
@@ -54,7 +65,9 @@ func (kc *Controller) DeleteHACluster() error {
 		go func(no int) {
 			defer wg.Done()
 
-			err := kc.p.Cloud.Role(consts.RoleWp).DelVM(no)
+			err := kc.track(statefile.PhaseVM, "", consts.RoleWp, no, func() error {
+				return kc.p.Cloud.Role(consts.RoleWp).DelVM(no)
+			})
 			if err != nil {
 				errChanWP <- err
 			}
@@ -64,7 +77,9 @@ func (kc *Controller) DeleteHACluster() error {
 		go func(no int) {
 			defer wg.Done()
 
-			err := kc.p.Cloud.Role(consts.RoleCp).DelVM(no)
+			err := kc.track(statefile.PhaseVM, "", consts.RoleCp, no, func() error {
+				return kc.p.Cloud.Role(consts.RoleCp).DelVM(no)
+			})
 			if err != nil {
 				errChanCP <- err
 			}
@@ -74,7 +89,9 @@ func (kc *Controller) DeleteHACluster() error {
 		go func(no int) {
 			defer wg.Done()
 
-			err := kc.p.Cloud.Role(consts.RoleDs).DelVM(no)
+			err := kc.track(statefile.PhaseVM, "", consts.RoleDs, no, func() error {
+				return kc.p.Cloud.Role(consts.RoleDs).DelVM(no)
+			})
 			if err != nil {
 				errChanDS <- err
 			}
@@ -84,7 +101,9 @@ func (kc *Controller) DeleteHACluster() error {
 	go func() {
 		defer wg.Done()
 
-		err := kc.p.Cloud.Role(consts.RoleLb).DelVM(0)
+		err := kc.track(statefile.PhaseVM, "", consts.RoleLb, 0, func() error {
+			return kc.p.Cloud.Role(consts.RoleLb).DelVM(0)
+		})
 		if err != nil {
 			errChanLB <- err
 		}
@@ -118,35 +137,48 @@ func (kc *Controller) DeleteHACluster() error {
 		}
 	}
 
-	pauseOperation(20) // NOTE: experimental time to wait for generic cloud to update its state
+	if kc.plan == nil {
+		// NOTE: experimental time to wait for generic cloud to update its state
+		if err := kc.pauseOperation(20); err != nil {
+			return err
+		}
+	}
 
-	err = kc.p.Cloud.Role(consts.RoleDs).DelFirewall()
+	err = kc.track(statefile.PhaseFirewall, "", consts.RoleDs, 0, func() error {
+		return kc.p.Cloud.Role(consts.RoleDs).DelFirewall()
+	})
 	if err != nil {
 		return err
 	}
 
-	err = kc.p.Cloud.Role(consts.RoleCp).DelFirewall()
+	err = kc.track(statefile.PhaseFirewall, "", consts.RoleCp, 0, func() error {
+		return kc.p.Cloud.Role(consts.RoleCp).DelFirewall()
+	})
 	if err != nil {
 		return err
 	}
 
-	err = kc.p.Cloud.Role(consts.RoleWp).DelFirewall()
+	err = kc.track(statefile.PhaseFirewall, "", consts.RoleWp, 0, func() error {
+		return kc.p.Cloud.Role(consts.RoleWp).DelFirewall()
+	})
 	if err != nil {
 		return err
 	}
 
-	err = kc.p.Cloud.Role(consts.RoleLb).DelFirewall()
+	err = kc.track(statefile.PhaseFirewall, "", consts.RoleLb, 0, func() error {
+		return kc.p.Cloud.Role(consts.RoleLb).DelFirewall()
+	})
 	if err != nil {
 		return err
 	}
 
-	err = kc.p.Cloud.DelSSHKeyPair()
+	err = kc.track(statefile.PhaseSSHKey, "", "", 0, kc.p.Cloud.DelSSHKeyPair)
 	if err != nil {
 		return err
 	}
 
 	// NOTE: last one to delete is network
-	err = kc.p.Cloud.DelNetwork()
+	err = kc.track(statefile.PhaseNetwork, "", "", 0, kc.p.Cloud.DelNetwork)
 	if err != nil {
 		return err
 	}
@@ -154,34 +186,175 @@ func (kc *Controller) DeleteHACluster() error {
 	return nil
 }
 
-// AddWorkerNodes the user provides the desired no of workerplane not the no of workerplanes to be added
+// AddWorkerNodes the user provides the desired no of workerplane not the no of workerplanes to be added,
+// the new workerplanes are part of the default pool
 func (kc *Controller) AddWorkerNodes() (*provider.CloudResourceState, int, error) {
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+		return nil, -1, err
+	}
 
-	var err error
 	currWP, err := kc.p.Cloud.NoOfWorkerPlane(kc.p.Metadata.NoWP, false)
 	if err != nil {
 		return nil, -1, err
 	}
 
-	_, err = kc.p.Cloud.NoOfWorkerPlane(kc.p.Metadata.NoWP, true)
+	pool := statefile.WorkerPool{Name: statefile.DefaultWorkerPool, VMSize: kc.p.Metadata.WorkerPlaneNodeType}
+	kc.adoptWorkerPlanes(currWP)
+	// the default pool follows the node type of the workerplanes
+	if p := kc.s.WorkerPool(pool.Name); p != nil && len(pool.VMSize) != 0 {
+		p.VMSize = pool.VMSize
+	}
+
+	return kc.addWorkerNodes(currWP, kc.p.Metadata.NoWP, pool)
+}
+
+// AddWorkerPoolNodes grows the pool to its count, the pool is created when the cluster doesn't have it yet.
+// It returns the index of the first of the new workerplanes along with the no of workerplanes of the cluster
+func (kc *Controller) AddWorkerPoolNodes(pool statefile.WorkerPool) (*provider.CloudResourceState, int, int, error) {
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+		return nil, -1, -1, err
+	}
+
+	currWP := len(kc.p.Cloud.GetHostNameAllWorkerNode())
+	currPool := kc.WorkerPoolSize(pool.Name)
+	if pool.Count < currPool {
+		return nil, -1, -1, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidNoOfWorkerplane,
+			kc.l.NewError(kc.ctx, "not a valid count of wp for up scaling", "pool", pool.Name, "count", pool.Count, "current", currPool),
+		)
+	}
+
+	totalWP := currWP + pool.Count - currPool
+	transferableInfraState, idxWPNotConfigured, err := kc.addWorkerNodes(currWP, totalWP, pool)
+	if err != nil {
+		return nil, -1, -1, err
+	}
+	return transferableInfraState, idxWPNotConfigured, totalWP, nil
+}
+
+// WorkerPoolSize returns the no of workerplanes of the pool
+func (kc *Controller) WorkerPoolSize(name string) int {
+	kc.adoptWorkerPlanes(len(kc.p.Cloud.GetHostNameAllWorkerNode()))
+
+	count := 0
+	for _, pool := range kc.s.WorkerPlanePools {
+		if pool == name {
+			count++
+		}
+	}
+	return count
+}
+
+// adoptWorkerPlanes puts the workerplanes without a pool in the default pool,
+// they are the workerplanes of the clusters created before the worker pools
+func (kc *Controller) adoptWorkerPlanes(no int) {
+	if len(kc.s.WorkerPlanePools) >= no {
+		return
+	}
+	for len(kc.s.WorkerPlanePools) < no {
+		kc.s.WorkerPlanePools = append(kc.s.WorkerPlanePools, statefile.DefaultWorkerPool)
+	}
+	if kc.s.WorkerPool(statefile.DefaultWorkerPool) == nil {
+		kc.s.WorkerPools = append(kc.s.WorkerPools, statefile.WorkerPool{
+			Name:   statefile.DefaultWorkerPool,
+			VMSize: kc.p.Metadata.WorkerPlaneNodeType,
+		})
+	}
+	kc.s.SyncWorkerPools()
+}
+
+// workerPlanePool returns the pool of the workerplane at the index, the default pool when it has none
+func (kc *Controller) workerPlanePool(no int) statefile.WorkerPool {
+	pool := statefile.WorkerPool{Name: statefile.DefaultWorkerPool}
+	if p := kc.s.WorkerPoolOf(no); p != nil {
+		pool = *p
+	}
+	if len(pool.VMSize) == 0 {
+		pool.VMSize = kc.p.Metadata.WorkerPlaneNodeType
+	}
+	return pool
+}
+
+func workerPlaneName(clusterName, pool string, ordinal int) string {
+	if pool == statefile.DefaultWorkerPool {
+		return fmt.Sprintf("%s-vm-wp-%d", clusterName, ordinal)
+	}
+	return fmt.Sprintf("%s-vm-wp-%s-%d", clusterName, pool, ordinal)
+}
+
+// workerPlaneNames returns the names of the workerplanes [start, end). The ones already created keep their name,
+// the others get the smallest ordinal of their pool which no other workerplane uses
+func (kc *Controller) workerPlaneNames(start, end int) []string {
+	hostnames := kc.p.Cloud.GetHostNameAllWorkerNode()
+
+	used := make([]string, 0, len(hostnames)+end-start)
+	for _, hostname := range hostnames {
+		if len(hostname) != 0 {
+			used = append(used, hostname)
+		}
+	}
+
+	names := make([]string, 0, end-start)
+	for no := start; no < end; no++ {
+		if no < len(hostnames) && len(hostnames[no]) != 0 {
+			names = append(names, hostnames[no])
+			continue
+		}
+
+		pool := kc.workerPlanePool(no).Name
+		name := workerPlaneName(kc.p.Metadata.ClusterName, pool, 0)
+		for ordinal := 1; utilities.Contains(used, name); ordinal++ {
+			name = workerPlaneName(kc.p.Metadata.ClusterName, pool, ordinal)
+		}
+		used = append(used, name)
+		names = append(names, name)
+	}
+	return names
+}
+
+// addWorkerNodes creates the workerplanes [currWP, noWP of the metadata) in the pool
+func (kc *Controller) addWorkerNodes(currWP, desiredWP int, pool statefile.WorkerPool) (*provider.CloudResourceState, int, error) {
+	if desiredWP < currWP {
+		return nil, -1, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidNoOfWorkerplane,
+			kc.l.NewError(kc.ctx, "not a valid count of wp for up scaling"),
+		)
+	}
+
+	if kc.s.WorkerPool(pool.Name) == nil {
+		kc.s.WorkerPools = append(kc.s.WorkerPools, pool)
+	}
+	for len(kc.s.WorkerPlanePools) < desiredWP {
+		kc.s.WorkerPlanePools = append(kc.s.WorkerPlanePools, pool.Name)
+	}
+	kc.s.SyncWorkerPools()
+
+	_, err := kc.p.Cloud.NoOfWorkerPlane(desiredWP, true)
 	if err != nil {
 		return nil, -1, err
 	}
 
+	// the vms and their join to the cluster
+	events.Expect(kc.ctx, 2*(desiredWP-currWP))
+
 	wg := &sync.WaitGroup{}
 
-	errChanWP := make(chan error, kc.p.Metadata.NoWP-currWP)
+	errChanWP := make(chan error, desiredWP-currWP)
+	names := kc.workerPlaneNames(currWP, desiredWP)
 
-	for no := currWP; no < kc.p.Metadata.NoWP; no++ {
+	for no := currWP; no < desiredWP; no++ {
 		wg.Add(1)
 		go func(no int) {
 			defer wg.Done()
 
-			err := kc.p.Cloud.Name(fmt.Sprintf("%s-vm-wp-%d", kc.p.Metadata.ClusterName, no)).
-				Role(consts.RoleWp).
-				VMType(kc.p.Metadata.WorkerPlaneNodeType).
-				Visibility(true).
-				NewVM(no)
+			name := names[no-currWP]
+			err := kc.track(statefile.PhaseVM, name, consts.RoleWp, no, func() error {
+				return kc.p.Cloud.Name(name).
+					Role(consts.RoleWp).
+					VMType(kc.workerPlanePool(no).VMSize).
+					Visibility(true).
+					NewVM(no)
+			})
 			if err != nil {
 				errChanWP <- err
 			}
@@ -207,6 +380,9 @@ func (kc *Controller) AddWorkerNodes() (*provider.CloudResourceState, int, error
 
 // DelWorkerNodes uses the noWP as the desired count of workerplane which is desired
 func (kc *Controller) DelWorkerNodes() (*provider.CloudResourceState, []string, error) {
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+		return nil, nil, err
+	}
 
 	hostnames := kc.p.Cloud.GetHostNameAllWorkerNode()
 
@@ -222,6 +398,11 @@ func (kc *Controller) DelWorkerNodes() (*provider.CloudResourceState, []string,
 		return nil, nil, kc.l.NewError(kc.ctx, "not a valid count of wp for down scaling")
 	}
 
+	kc.adoptWorkerPlanes(currLen)
+
+	// the vms and their removal from the cluster
+	events.Expect(kc.ctx, 2*(currLen-desiredLen))
+
 	wg := &sync.WaitGroup{}
 	errChanWP := make(chan error, currLen-desiredLen)
 
@@ -230,7 +411,9 @@ func (kc *Controller) DelWorkerNodes() (*provider.CloudResourceState, []string,
 		go func(no int) {
 			defer wg.Done()
 
-			err := kc.p.Cloud.Role(consts.RoleWp).DelVM(no)
+			err := kc.track(statefile.PhaseVM, hostnames[no-desiredLen], consts.RoleWp, no, func() error {
+				return kc.p.Cloud.Role(consts.RoleWp).DelVM(no)
+			})
 			if err != nil {
 				errChanWP <- err
 			}
@@ -245,6 +428,9 @@ func (kc *Controller) DelWorkerNodes() (*provider.CloudResourceState, []string,
 		}
 	}
 
+	kc.s.WorkerPlanePools = kc.s.WorkerPlanePools[:desiredLen]
+	kc.s.SyncWorkerPools()
+
 	_, err := kc.p.Cloud.NoOfWorkerPlane(desiredLen, true)
 	if err != nil {
 		return nil, nil, err
@@ -259,118 +445,610 @@ func (kc *Controller) DelWorkerNodes() (*provider.CloudResourceState, []string,
 	return &transferableInfraState, hostnames, nil
 }
 
-func (kc *Controller) CreateHACluster() (*provider.CloudResourceState, error) {
+// DelWorkerPoolNodes shrinks the pool to the count by deleting its last workerplanes, the workerplanes
+// of the other pools after them move up. It returns the hostnames of the deleted workerplanes
+func (kc *Controller) DelWorkerPoolNodes(pool string, count int) (*provider.CloudResourceState, []string, error) {
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+		return nil, nil, err
+	}
+
+	hostnames := kc.p.Cloud.GetHostNameAllWorkerNode()
+	currPool := kc.WorkerPoolSize(pool)
+	if count < 0 || count > currPool {
+		return nil, nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidNoOfWorkerplane,
+			kc.l.NewError(kc.ctx, "not a valid count of wp for down scaling", "pool", pool, "count", count, "current", currPool),
+		)
+	}
+
+	var indexes []int
+	for no := len(kc.s.WorkerPlanePools) - 1; no >= 0 && len(indexes) < currPool-count; no-- {
+		if kc.s.WorkerPlanePools[no] == pool {
+			indexes = append(indexes, no)
+		}
+	}
+
+	// the vms and their removal from the cluster
+	events.Expect(kc.ctx, 2*len(indexes))
+
+	wg := &sync.WaitGroup{}
+	errChanWP := make(chan error, len(indexes))
+	deleted := make([]string, 0, len(indexes))
+
+	for _, no := range indexes {
+		deleted = append(deleted, hostnames[no])
+		wg.Add(1)
+		go func(no int) {
+			defer wg.Done()
+
+			err := kc.track(statefile.PhaseVM, hostnames[no], consts.RoleWp, no, func() error {
+				return kc.p.Cloud.Role(consts.RoleWp).DelVM(no)
+			})
+			if err != nil {
+				errChanWP <- err
+			}
+		}(no)
+	}
+	wg.Wait()
+	close(errChanWP)
+
+	for err := range errChanWP {
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	kc.s.WorkerPlanePools = utilities.RemoveIndexes(kc.s.WorkerPlanePools, indexes)
+	kc.s.SyncWorkerPools()
+
+	if err := kc.p.Cloud.PruneWorkerPlanes(indexes); err != nil {
+		return nil, nil, err
+	}
+	kc.p.Metadata.NoWP = len(kc.s.WorkerPlanePools)
+
+	transferableInfraState, errState := kc.p.Cloud.GetStateForHACluster()
+	if errState != nil {
+		kc.l.Error("handled error", "catch", errState)
+		return nil, nil, errState
+	}
+
+	return &transferableInfraState, deleted, nil
+}
+
+// validateControlPlaneScale checks the desired noCP for scaling the controlplanes up or down from the current one.
+// The controlplanes run against the external datastores and hold no quorum, so the count can be even, but the HA
+// needs at least 2 api servers. The controlplanes above the desired noCP get removed, which keeps the controlplane 0
+// holding the join tokens
+func (kc *Controller) validateControlPlaneScale(curr, desired int, up bool) error {
+	if !provider.ValidNoOfControlPlane(desired) {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidNoOfControlplane,
+			kc.l.NewError(kc.ctx, "too few controlplanes for a HA cluster", "minimum", provider.MinNoOfControlPlane, "desired", desired),
+		)
+	}
+	if (up && desired <= curr) || (!up && desired >= curr) {
+		direction := "down"
+		if up {
+			direction = "up"
+		}
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidNoOfControlplane,
+			kc.l.NewError(kc.ctx, "not a valid count of cp for scaling "+direction, "current", curr, "desired", desired),
+		)
+	}
+	return nil
+}
+
+// AddControlPlaneNodes the user provides the desired no of controlplane not the no of controlplanes to be added
+func (kc *Controller) AddControlPlaneNodes() (*provider.CloudResourceState, int, error) {
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+		return nil, -1, err
+	}
+
+	currCP, err := kc.p.Cloud.NoOfControlPlane(kc.p.Metadata.NoCP, false)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	if err := kc.validateControlPlaneScale(currCP, kc.p.Metadata.NoCP, true); err != nil {
+		return nil, -1, err
+	}
+
 	if _, err := kc.p.Cloud.NoOfControlPlane(kc.p.Metadata.NoCP, true); err != nil {
+		return nil, -1, err
+	}
+
+	// the vms, their join to the cluster and the backends of the loadbalancer
+	events.Expect(kc.ctx, 2*(kc.p.Metadata.NoCP-currCP)+1)
+
+	wg := &sync.WaitGroup{}
+
+	errChanCP := make(chan error, kc.p.Metadata.NoCP-currCP)
+
+	for no := currCP; no < kc.p.Metadata.NoCP; no++ {
+		wg.Add(1)
+		go func(no int) {
+			defer wg.Done()
+
+			name := fmt.Sprintf("%s-vm-cp-%d", kc.p.Metadata.ClusterName, no)
+			err := kc.track(statefile.PhaseVM, name, consts.RoleCp, no, func() error {
+				return kc.p.Cloud.Name(name).
+					Role(consts.RoleCp).
+					VMType(kc.p.Metadata.ControlPlaneNodeType).
+					Visibility(true).
+					NewVM(no)
+			})
+			if err != nil {
+				errChanCP <- err
+			}
+		}(no)
+	}
+	wg.Wait()
+	close(errChanCP)
+
+	for err := range errChanCP {
+		if err != nil {
+			return nil, -1, err
+		}
+	}
+
+	transferableInfraState, errState := kc.p.Cloud.GetStateForHACluster()
+	if errState != nil {
+		kc.l.Error("handled error", "catch", errState)
+		return nil, -1, errState
+	}
+
+	return &transferableInfraState, currCP, nil
+}
+
+// DelControlPlaneNodes deletes the vms of the controlplanes above the desired noCP,
+// they are expected to be removed from the cluster already
+func (kc *Controller) DelControlPlaneNodes() (*provider.CloudResourceState, error) {
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
 		return nil, err
 	}
 
-	if _, err := kc.p.Cloud.NoOfWorkerPlane(kc.p.Metadata.NoWP, true); err != nil {
+	currLen, err := kc.p.Cloud.NoOfControlPlane(kc.p.Metadata.NoCP, false)
+	if err != nil {
 		return nil, err
 	}
+	desiredLen := kc.p.Metadata.NoCP
+	hostnames := kc.p.Cloud.GetHostNameAllControlPlane()
 
-	if _, err := kc.p.Cloud.NoOfDataStore(kc.p.Metadata.NoDS, true); err != nil {
+	wg := &sync.WaitGroup{}
+	errChanCP := make(chan error, currLen-desiredLen)
+
+	for no := desiredLen; no < currLen; no++ {
+		wg.Add(1)
+		go func(no int) {
+			defer wg.Done()
+
+			err := kc.track(statefile.PhaseVM, hostnames[no], consts.RoleCp, no, func() error {
+				return kc.p.Cloud.Role(consts.RoleCp).DelVM(no)
+			})
+			if err != nil {
+				errChanCP <- err
+			}
+		}(no)
+	}
+	wg.Wait()
+	close(errChanCP)
+
+	for err := range errChanCP {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := kc.p.Cloud.NoOfControlPlane(desiredLen, true); err != nil {
 		return nil, err
 	}
 
-	var err error
-	err = kc.p.Cloud.Name(kc.p.Metadata.ClusterName + "-net").NewNetwork()
+	transferableInfraState, errState := kc.p.Cloud.GetStateForHACluster()
+	if errState != nil {
+		kc.l.Error("handled error", "catch", errState)
+		return nil, errState
+	}
+
+	return &transferableInfraState, nil
+}
+
+// ControlPlanesToRemove returns the hostnames of the controlplanes above the desired noCP along with the state of the infra,
+// which the bootstrap needs to remove them from the cluster before their vms get deleted
+func (kc *Controller) ControlPlanesToRemove() (*provider.CloudResourceState, []string, error) {
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+		return nil, nil, err
+	}
+
+	currLen, err := kc.p.Cloud.NoOfControlPlane(kc.p.Metadata.NoCP, false)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	desiredLen := kc.p.Metadata.NoCP
 
-	err = kc.p.Cloud.Name(kc.p.Metadata.ClusterName + "-ssh").CreateUploadSSHKeyPair()
+	if err := kc.validateControlPlaneScale(currLen, desiredLen, false); err != nil {
+		return nil, nil, err
+	}
+
+	transferableInfraState, errState := kc.p.Cloud.GetStateForHACluster()
+	if errState != nil {
+		kc.l.Error("handled error", "catch", errState)
+		return nil, nil, errState
+	}
+
+	hostnames := kc.p.Cloud.GetHostNameAllControlPlane()
+	return &transferableInfraState, hostnames[desiredLen:currLen], nil
+}
+
+// AddDataStoreNodes creates the vms of the datastores up to the desired noDS,
+// it returns the index of the first of them which are yet to become members of the etcd cluster
+func (kc *Controller) AddDataStoreNodes() (*provider.CloudResourceState, int, error) {
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+		return nil, -1, err
+	}
+
+	currDS, err := kc.p.Cloud.NoOfDataStore(kc.p.Metadata.NoDS, false)
 	if err != nil {
-		return nil, err
+		return nil, -1, err
+	}
+
+	if kc.p.Metadata.NoDS <= currDS {
+		return nil, -1, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidNoOfDatastore,
+			kc.l.NewError(kc.ctx, "not a valid count of ds for up scaling", "current", currDS, "desired", kc.p.Metadata.NoDS),
+		)
+	}
+
+	if _, err := kc.p.Cloud.NoOfDataStore(kc.p.Metadata.NoDS, true); err != nil {
+		return nil, -1, err
+	}
+
+	wg := &sync.WaitGroup{}
+
+	errChanDS := make(chan error, kc.p.Metadata.NoDS-currDS)
+
+	for no := currDS; no < kc.p.Metadata.NoDS; no++ {
+		wg.Add(1)
+		go func(no int) {
+			defer wg.Done()
+
+			if err := kc.newDataStoreVM(no); err != nil {
+				errChanDS <- err
+			}
+		}(no)
+	}
+	wg.Wait()
+	close(errChanDS)
+
+	for err := range errChanDS {
+		if err != nil {
+			return nil, -1, err
+		}
+	}
+
+	transferableInfraState, errState := kc.p.Cloud.GetStateForHACluster()
+	if errState != nil {
+		kc.l.Error("handled error", "catch", errState)
+		return nil, -1, errState
+	}
+
+	return &transferableInfraState, currDS, nil
+}
+
+// DataStoresToRemove validates the desired noDS for down scaling and returns the current noDS along with the state of the infra,
+// which the bootstrap needs to remove the datastores above the desired noDS from the etcd cluster before their vms get deleted
+func (kc *Controller) DataStoresToRemove() (*provider.CloudResourceState, int, error) {
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+		return nil, -1, err
 	}
 
-	err = kc.p.Cloud.Name(kc.p.Metadata.ClusterName + "-fw-lb").
-		Role(consts.RoleLb).
-		NewFirewall()
+	currLen, err := kc.p.Cloud.NoOfDataStore(kc.p.Metadata.NoDS, false)
 	if err != nil {
+		return nil, -1, err
+	}
+	desiredLen := kc.p.Metadata.NoDS
+
+	// the odd count of at least 3 keeps the quorum of the etcd cluster
+	if desiredLen < 3 || desiredLen&1 == 0 || desiredLen >= currLen {
+		return nil, -1, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidNoOfDatastore,
+			kc.l.NewError(kc.ctx, "not a valid count of ds for down scaling, it must be odd, at least 3 and below the current", "current", currLen, "desired", desiredLen),
+		)
+	}
+
+	transferableInfraState, errState := kc.p.Cloud.GetStateForHACluster()
+	if errState != nil {
+		kc.l.Error("handled error", "catch", errState)
+		return nil, -1, errState
+	}
+
+	return &transferableInfraState, currLen, nil
+}
+
+// DelDataStoreNodes deletes the vms of the datastores above the desired noDS,
+// they are expected to be removed from the etcd cluster already
+func (kc *Controller) DelDataStoreNodes() (*provider.CloudResourceState, error) {
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
 		return nil, err
 	}
 
-	err = kc.p.Cloud.Name(kc.p.Metadata.ClusterName + "-fw-db").
-		Role(consts.RoleDs).
-		NewFirewall()
+	currLen, err := kc.p.Cloud.NoOfDataStore(kc.p.Metadata.NoDS, false)
 	if err != nil {
 		return nil, err
 	}
+	desiredLen := kc.p.Metadata.NoDS
 
-	err = kc.p.Cloud.Name(kc.p.Metadata.ClusterName + "-fw-cp").
-		Role(consts.RoleCp).
-		NewFirewall()
-	if err != nil {
+	wg := &sync.WaitGroup{}
+	errChanDS := make(chan error, currLen-desiredLen)
+
+	for no := desiredLen; no < currLen; no++ {
+		wg.Add(1)
+		go func(no int) {
+			defer wg.Done()
+
+			if err := kc.delDataStoreVM(no); err != nil {
+				errChanDS <- err
+			}
+		}(no)
+	}
+	wg.Wait()
+	close(errChanDS)
+
+	for err := range errChanDS {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := kc.p.Cloud.NoOfDataStore(desiredLen, true); err != nil {
+		return nil, err
+	}
+
+	transferableInfraState, errState := kc.p.Cloud.GetStateForHACluster()
+	if errState != nil {
+		kc.l.Error("handled error", "catch", errState)
+		return nil, errState
+	}
+
+	return &transferableInfraState, nil
+}
+
+// DataStoreToReplace validates the index of the datastore to replace and returns the state of the infra,
+// which the bootstrap needs to remove it from the etcd cluster before its vm gets recreated
+func (kc *Controller) DataStoreToReplace(no int) (*provider.CloudResourceState, error) {
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
 		return nil, err
 	}
 
-	err = kc.p.Cloud.Name(kc.p.Metadata.ClusterName + "-fw-wp").
-		Role(consts.RoleWp).
-		NewFirewall()
+	currLen, err := kc.p.Cloud.NoOfDataStore(kc.p.Metadata.NoDS, false)
 	if err != nil {
 		return nil, err
 	}
 
+	if no < 0 || no >= currLen {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidNoOfDatastore,
+			kc.l.NewError(kc.ctx, "not a valid datastore to replace", "current", currLen, "index", no),
+		)
+	}
+
+	transferableInfraState, errState := kc.p.Cloud.GetStateForHACluster()
+	if errState != nil {
+		kc.l.Error("handled error", "catch", errState)
+		return nil, errState
+	}
+
+	return &transferableInfraState, nil
+}
+
+// ReplaceDataStoreNode deletes the vm of the datastore no and creates a new one in its place,
+// it is expected to be removed from the etcd cluster already
+func (kc *Controller) ReplaceDataStoreNode(no int) (*provider.CloudResourceState, error) {
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+		return nil, err
+	}
+
+	if err := kc.delDataStoreVM(no); err != nil {
+		return nil, err
+	}
+
+	if err := kc.newDataStoreVM(no); err != nil {
+		return nil, err
+	}
+
+	transferableInfraState, errState := kc.p.Cloud.GetStateForHACluster()
+	if errState != nil {
+		kc.l.Error("handled error", "catch", errState)
+		return nil, errState
+	}
+
+	return &transferableInfraState, nil
+}
+
+func (kc *Controller) newDataStoreVM(no int) error {
+	name := fmt.Sprintf("%s-vm-db-%d", kc.p.Metadata.ClusterName, no)
+	return kc.track(statefile.PhaseVM, name, consts.RoleDs, no, func() error {
+		return kc.p.Cloud.Name(name).
+			Role(consts.RoleDs).
+			VMType(kc.p.Metadata.DataStoreNodeType).
+			Visibility(true).
+			NewVM(no)
+	})
+}
+
+func (kc *Controller) delDataStoreVM(no int) error {
+	name := fmt.Sprintf("%s-vm-db-%d", kc.p.Metadata.ClusterName, no)
+	return kc.track(statefile.PhaseVM, name, consts.RoleDs, no, func() error {
+		return kc.p.Cloud.Role(consts.RoleDs).DelVM(no)
+	})
+}
+
+func (kc *Controller) CreateHACluster() (*provider.CloudResourceState, error) {
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+		return nil, err
+	}
+
+	if _, err := kc.p.Cloud.NoOfControlPlane(kc.p.Metadata.NoCP, true); err != nil {
+		return nil, err
+	}
+
+	// the layout of the pools is kept by a retried creation
+	if len(kc.s.WorkerPlanePools) == 0 {
+		kc.s.WorkerPools = append([]statefile.WorkerPool(nil), kc.p.Metadata.DesiredWorkerPools()...)
+		for _, pool := range kc.s.WorkerPools {
+			for i := 0; i < pool.Count; i++ {
+				kc.s.WorkerPlanePools = append(kc.s.WorkerPlanePools, pool.Name)
+			}
+		}
+		kc.s.SyncWorkerPools()
+	}
+
+	if _, err := kc.p.Cloud.NoOfWorkerPlane(kc.p.Metadata.NoWP, true); err != nil {
+		return nil, err
+	}
+
+	if _, err := kc.p.Cloud.NoOfDataStore(kc.p.Metadata.NoDS, true); err != nil {
+		return nil, err
+	}
+
+	if !kc.cp.Done(statefile.PhaseNetwork, "") {
+		name := kc.p.Metadata.ClusterName + "-net"
+		err := kc.track(statefile.PhaseNetwork, name, "", 0, kc.p.Cloud.Name(name).NewNetwork)
+		if err := kc.cp.Checkpoint(statefile.PhaseNetwork, "", err); err != nil {
+			return nil, err
+		}
+	}
+
+	if !kc.cp.Done(statefile.PhaseSSHKey, "") {
+		name := kc.p.Metadata.ClusterName + "-ssh"
+		err := kc.track(statefile.PhaseSSHKey, name, "", 0, kc.p.Cloud.Name(name).CreateUploadSSHKeyPair)
+		if err := kc.cp.Checkpoint(statefile.PhaseSSHKey, "", err); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, fw := range []struct {
+		name string
+		role consts.KsctlRole
+	}{
+		{name: "-fw-lb", role: consts.RoleLb},
+		{name: "-fw-db", role: consts.RoleDs},
+		{name: "-fw-cp", role: consts.RoleCp},
+		{name: "-fw-wp", role: consts.RoleWp},
+	} {
+		if kc.cp.Done(statefile.PhaseFirewall, string(fw.role)) {
+			continue
+		}
+		name := kc.p.Metadata.ClusterName + fw.name
+		err := kc.track(statefile.PhaseFirewall, name, fw.role, 0, func() error {
+			return kc.p.Cloud.Name(name).
+				Role(fw.role).
+				NewFirewall()
+		})
+		if err := kc.cp.Checkpoint(statefile.PhaseFirewall, string(fw.role), err); err != nil {
+			return nil, err
+		}
+	}
+
+	// the vms are the longest step, none of them starts once the operation is cancelled
+	if err := waiter.Cancelled(kc.ctx, kc.l); err != nil {
+		return nil, err
+	}
+
 	//////
 	wg := &sync.WaitGroup{}
 	errChanLB := make(chan error, 1)
 	errChanDS := make(chan error, kc.p.Metadata.NoDS)
 	errChanCP := make(chan error, kc.p.Metadata.NoCP)
 	errChanWP := make(chan error, kc.p.Metadata.NoWP)
-
-	wg.Add(1 + kc.p.Metadata.NoCP + kc.p.Metadata.NoDS + kc.p.Metadata.NoWP)
 	//////
-	go func() {
-		defer wg.Done()
 
-		err := kc.p.Cloud.Name(kc.p.Metadata.ClusterName + "-vm-lb").
-			Role(consts.RoleLb).
-			VMType(kc.p.Metadata.LoadBalancerNodeType).
-			Visibility(true).
-			NewVM(0)
-		if err != nil {
-			errChanLB <- err
-		}
-	}()
+	if step := vmStep(consts.RoleLb, 0); !kc.cp.Done(statefile.PhaseVM, step) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			name := kc.p.Metadata.ClusterName + "-vm-lb"
+			err := kc.track(statefile.PhaseVM, name, consts.RoleLb, 0, func() error {
+				return kc.p.Cloud.Name(name).
+					Role(consts.RoleLb).
+					VMType(kc.p.Metadata.LoadBalancerNodeType).
+					Visibility(true).
+					NewVM(0)
+			})
+			kc.cp.Record(statefile.PhaseVM, step, err)
+			if err != nil {
+				errChanLB <- err
+			}
+		}()
+	}
 
 	for no := 0; no < kc.p.Metadata.NoDS; no++ {
+		step := vmStep(consts.RoleDs, no)
+		if kc.cp.Done(statefile.PhaseVM, step) {
+			continue
+		}
+		wg.Add(1)
 		go func(no int) {
 			defer wg.Done()
 
-			err := kc.p.Cloud.Name(fmt.Sprintf("%s-vm-db-%d", kc.p.Metadata.ClusterName, no)).
-				Role(consts.RoleDs).
-				VMType(kc.p.Metadata.DataStoreNodeType).
-				Visibility(true).
-				NewVM(no)
+			name := fmt.Sprintf("%s-vm-db-%d", kc.p.Metadata.ClusterName, no)
+			err := kc.track(statefile.PhaseVM, name, consts.RoleDs, no, func() error {
+				return kc.p.Cloud.Name(name).
+					Role(consts.RoleDs).
+					VMType(kc.p.Metadata.DataStoreNodeType).
+					Visibility(true).
+					NewVM(no)
+			})
+			kc.cp.Record(statefile.PhaseVM, step, err)
 			if err != nil {
 				errChanDS <- err
 			}
 		}(no)
 	}
 	for no := 0; no < kc.p.Metadata.NoCP; no++ {
+		step := vmStep(consts.RoleCp, no)
+		if kc.cp.Done(statefile.PhaseVM, step) {
+			continue
+		}
+		wg.Add(1)
 		go func(no int) {
 			defer wg.Done()
 
-			err := kc.p.Cloud.Name(fmt.Sprintf("%s-vm-cp-%d", kc.p.Metadata.ClusterName, no)).
-				Role(consts.RoleCp).
-				VMType(kc.p.Metadata.ControlPlaneNodeType).
-				Visibility(true).
-				NewVM(no)
+			name := fmt.Sprintf("%s-vm-cp-%d", kc.p.Metadata.ClusterName, no)
+			err := kc.track(statefile.PhaseVM, name, consts.RoleCp, no, func() error {
+				return kc.p.Cloud.Name(name).
+					Role(consts.RoleCp).
+					VMType(kc.p.Metadata.ControlPlaneNodeType).
+					Visibility(true).
+					NewVM(no)
+			})
+			kc.cp.Record(statefile.PhaseVM, step, err)
 			if err != nil {
 				errChanCP <- err
 			}
 		}(no)
 	}
 
+	wpNames := kc.workerPlaneNames(0, kc.p.Metadata.NoWP)
 	for no := 0; no < kc.p.Metadata.NoWP; no++ {
+		step := vmStep(consts.RoleWp, no)
+		if kc.cp.Done(statefile.PhaseVM, step) {
+			continue
+		}
+		wg.Add(1)
 		go func(no int) {
 			defer wg.Done()
 
-			err := kc.p.Cloud.Name(fmt.Sprintf("%s-vm-wp-%d", kc.p.Metadata.ClusterName, no)).
-				Role(consts.RoleWp).
-				VMType(kc.p.Metadata.WorkerPlaneNodeType).
-				Visibility(true).
-				NewVM(no)
+			name := wpNames[no]
+			err := kc.track(statefile.PhaseVM, name, consts.RoleWp, no, func() error {
+				return kc.p.Cloud.Name(name).
+					Role(consts.RoleWp).
+					VMType(kc.workerPlanePool(no).VMSize).
+					Visibility(true).
+					NewVM(no)
+			})
+			kc.cp.Record(statefile.PhaseVM, step, err)
 			if err != nil {
 				errChanWP <- err
 			}
@@ -384,6 +1062,8 @@ func (kc *Controller) CreateHACluster() (*provider.CloudResourceState, error) {
 	close(errChanCP)
 	close(errChanWP)
 
+	errFlush := kc.cp.Flush()
+
 	for err := range errChanLB {
 		if err != nil {
 			return nil, err
@@ -404,12 +1084,20 @@ func (kc *Controller) CreateHACluster() (*provider.CloudResourceState, error) {
 			return nil, err
 		}
 	}
+	if errFlush != nil {
+		return nil, errFlush
+	}
 
 	transferableInfraState, errState := kc.p.Cloud.GetStateForHACluster()
 	if errState != nil {
 		kc.l.Error("handled error", "catch", errState)
-		return nil, err
+		return nil, errState
 	}
 
 	return &transferableInfraState, nil
 }
+
+// vmStep is the step of the vm checkpoints, like controlplane-1
+func vmStep(role consts.KsctlRole, no int) string {
+	return fmt.Sprintf("%s-%d", role, no)
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/ksctl/ksctl/v2/pkg/utilities"
 
 	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
 	"github.com/ksctl/ksctl/v2/pkg/handler/cluster/controller"
 	"github.com/ksctl/ksctl/v2/pkg/logger"
 	"github.com/ksctl/ksctl/v2/pkg/statefile"
@@ -252,5 +253,17 @@ func TestManagedCluster(t *testing.T) {
 		assert.DeepEqual(t, got, expected)
 	})
 
+	t.Run("scale managed cluster", func(t *testing.T) {
+		err := fakeClientManaged.ScaleManagedCluster(3, 1, 5)
+		assert.Check(t, ksctlErrors.IsInvalidUserInput(err), "kind has no autoscaling")
+
+		assert.NilError(t, fakeClientManaged.ScaleManagedCluster(3, 0, 0), "managed cluster should be recreated")
+		assert.Equal(t, fakeClientManaged.state.CloudInfra.Local.Nodes, 3, "missmatch of no of nodes")
+		assert.Equal(t, fakeClientManaged.state.CloudInfra.Local.B.IsCompleted, true, "cluster should be completed")
+		assert.Equal(t, *fakeClientManaged.state.Versions.Kind, "1.27.1", "k8s version should be kept")
+
+		assert.NilError(t, fakeClientManaged.ScaleManagedCluster(3, 0, 0), "same no of nodes should be skipped")
+	})
+
 	assert.Equal(t, fakeClientManaged.DelManagedCluster(), nil, "managed cluster should be deleted")
 }
@@ -108,6 +108,7 @@ func (p *Provider) InitState(operation consts.KsctlOperation) error {
 
 		p.state.PlatformSpec.Team = team
 		p.state.PlatformSpec.Owner = owner
+		p.state.PlatformSpec.Labels = p.Labels
 		p.state.CloudInfra = &statefile.InfrastructureState{Local: &statefile.StateConfigurationLocal{}}
 		p.state.PlatformSpec.State = statefile.Creating
 		p.state.ClusterName = p.ClusterName
@@ -136,7 +137,7 @@ func (p *Provider) InitState(operation consts.KsctlOperation) error {
 			return err
 		}
 
-	case consts.OperationConfigure:
+	case consts.OperationConfigure, consts.OperationScale:
 		err := p.loadStateHelper()
 		if err != nil {
 			return err
@@ -187,8 +188,10 @@ func (p *Provider) GetRAWClusterInfos() ([]provider.ClusterData, error) {
 				Region:        v.Region,
 				ClusterType:   K,
 				Owner:         v.PlatformSpec.Owner,
+				Labels:        v.PlatformSpec.Labels,
 				Team:          v.PlatformSpec.Team,
 				State:         v.PlatformSpec.State,
+				FailedPhases:  v.FailedCheckpoints(),
 
 				NoMgt: v.CloudInfra.Local.Nodes,
 				Mgt: provider.VMData{
@@ -236,3 +239,9 @@ func (p *Provider) GetKubeconfig() (*string, error) {
 	kubeconfig := _read.ClusterKubeConfig
 	return &kubeconfig, nil
 }
+
+// UpdateLabels replaces the labels of the cluster, kind has no resources to tag so only the state gets updated
+func (p *Provider) UpdateLabels(labels map[string]string) error {
+	p.state.PlatformSpec.Labels = labels
+	return p.store.Write(p.state)
+}
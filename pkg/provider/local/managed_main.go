@@ -192,3 +192,68 @@ func (p *Provider) NewManagedCluster(noOfNodes int) error {
 
 	return nil
 }
+
+// ScaleManagedCluster recreates the kind cluster with the new number of nodes as kind can't resize a running one,
+// the workloads of the cluster are lost on the way and only the clusters using kindnet can be recreated
+func (p *Provider) ScaleManagedCluster(noOfNodes, minNodes, maxNodes int) error {
+	if minNodes != 0 || maxNodes != 0 {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			p.l.NewError(p.ctx, "kind has no autoscaling", "min", minNodes, "max", maxNodes),
+		)
+	}
+
+	if p.state.CloudInfra.Local.Nodes == noOfNodes {
+		p.l.Print(p.ctx, "skipped recreating the managed cluster, it already has the desired nodes", "nodes", noOfNodes)
+		return nil
+	}
+
+	if len(p.state.ProvisionerAddons.Cni.Name) == 0 {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidOperation,
+			p.l.NewError(p.ctx, "recreating a kind cluster with an external cni is not supported"),
+		)
+	}
+
+	if p.state.Versions.Kind != nil {
+		p.K8sVersion = *p.state.Versions.Kind
+	}
+	p.vmType = p.state.CloudInfra.Local.ManagedNodeSize
+	p.managedAddonCNI = p.state.ProvisionerAddons.Cni.Name
+
+	dir, err := os.MkdirTemp("", p.ClusterName+"*")
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			p.l.NewError(p.ctx, "mkdirTemp", "Reason", err),
+		)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	_path := filepath.Join(dir, "kubeconfig")
+	if err := os.WriteFile(_path, []byte(p.state.ClusterKubeConfig), 0755); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			p.l.NewError(p.ctx, "failed to write file", "Reason", err),
+		)
+	}
+
+	p.l.Warn(p.ctx, "Recreating the managed cluster, its workloads will be lost", "nodes", noOfNodes)
+
+	p.client.NewProvider(p, nil)
+	if err := p.client.Delete(p.ClusterName, _path); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrFailedKsctlClusterOperation,
+			p.l.NewError(p.ctx, "failed to delete cluster", "Reason", err),
+		)
+	}
+
+	p.state.CloudInfra.Local.B.IsCompleted = false
+	if err := p.store.Write(p.state); err != nil {
+		return err
+	}
+
+	return p.NewManagedCluster(noOfNodes)
+}
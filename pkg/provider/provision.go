@@ -61,6 +61,9 @@ type Cloud interface {
 
 	DelManagedCluster() error
 
+	// ScaleManagedCluster resizes the node pool of the managed cluster, min and max of 0 disable its autoscaling
+	ScaleManagedCluster(noOfNodes, minNodes, maxNodes int) error
+
 	GetRAWClusterInfos() ([]ClusterData, error)
 
 	Name(string) Cloud
@@ -77,13 +80,21 @@ type Cloud interface {
 
 	NoOfWorkerPlane(int, bool) (int, error)
 
+	// PruneWorkerPlanes drops the already deleted workerplanes at the given indexes from the state, shifting the rest up
+	PruneWorkerPlanes([]int) error
+
 	NoOfControlPlane(int, bool) (int, error)
 
 	NoOfDataStore(int, bool) (int, error)
 
 	GetHostNameAllWorkerNode() []string
 
+	GetHostNameAllControlPlane() []string
+
 	IsPresent() error
 
 	GetKubeconfig() (*string, error)
+
+	// UpdateLabels replaces the labels of the cluster and updates the tags of its cloud resources
+	UpdateLabels(map[string]string) error
 }
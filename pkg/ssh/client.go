@@ -31,6 +31,7 @@ import (
 	"github.com/ksctl/ksctl/v2/pkg/config"
 	"github.com/ksctl/ksctl/v2/pkg/consts"
 	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/events"
 	"github.com/ksctl/ksctl/v2/pkg/logger"
 	"github.com/ksctl/ksctl/v2/pkg/statefile"
 	"github.com/ksctl/ksctl/v2/pkg/waiter"
@@ -200,7 +201,7 @@ func (client *SSH) SSHExecute() error {
 		Auth: []ssh.AuthMethod{
 			ssh.PublicKeys(signer),
 		},
-		Timeout: 5 * time.Minute,
+		Timeout: consts.DurationSSHDialTimeout,
 
 		HostKeyAlgorithms: []string{
 			ssh.KeyAlgoRSASHA256,
@@ -211,7 +212,7 @@ func (client *SSH) SSHExecute() error {
 				gotFingerprint := ssh.FingerprintSHA256(remoteSvrHostKey)
 				keyType := remoteSvrHostKey.Type()
 				if keyType == ssh.KeyAlgoRSA || keyType == ssh.KeyAlgoED25519 {
-					recvFingerprint, err := returnServerPublicKeys(client.PublicIP, keyType)
+					recvFingerprint, err := returnServerPublicKeys(client.ctx, client.PublicIP, keyType)
 					if err != nil {
 						return ksctlErrors.WrapError(
 							ksctlErrors.ErrSSHExec,
@@ -237,7 +238,9 @@ func (client *SSH) SSHExecute() error {
 			})}
 
 	if !client.fastMode {
-		time.Sleep(consts.DurationSSHPause)
+		if err := waiter.Sleep(client.ctx, client.log, consts.DurationSSHPause); err != nil {
+			return err
+		}
 	}
 
 	var conn *ssh.Client
@@ -257,12 +260,15 @@ func (client *SSH) SSHExecute() error {
 		maxRT,
 	)
 
+	dialAttempt := 0
 	_err := expoBackoff.Run(
 		client.ctx,
 		client.log,
 		func() (err error) {
-			conn, err = ssh.Dial("tcp", client.PublicIP+":22", c)
+			dialAttempt++
+			conn, err = dialContext(client.ctx, client.PublicIP+":22", c)
 			if err != nil {
+				client.emit("dial", events.StatusRetrying, dialAttempt, err)
 				return ksctlErrors.WrapError(
 					ksctlErrors.ErrSSHExec,
 					client.log.NewError(client.ctx, "failed to get", "Reason", err))
@@ -293,23 +299,35 @@ func (client *SSH) SSHExecute() error {
 		defer func(conn *ssh.Client) {
 			_ = conn.Close()
 		}(conn)
+
+		// closing the connection ends the script being run, as its session can't outlive it
+		stop := context.AfterFunc(client.ctx, func() {
+			_ = conn.Close()
+		})
+		defer stop()
 	}
 
 	scripts := client.script
 
 	for !scripts.IsCompleted() {
+		if err := waiter.Cancelled(client.ctx, client.log); err != nil {
+			return err
+		}
 		script := scripts.NextScript()
 
 		client.log.Print(client.ctx, "Executing Sub-Script", "name", script.Name)
+		client.emit(script.Name, events.StatusStarted, 1, nil)
 		success := false
 		var scriptFailureReason error
 		var stdout, stderr string
 		var err error
+		attempt := 1
 
 		if script.CanRetry {
 			retries := uint8(0)
 
 			for retries < script.MaxRetries {
+				attempt = int(retries) + 1
 				stdout, stderr, err = client.ExecuteScript(conn, script.ShellScript)
 				// adding some choas //
 				if _, ok := config.IsContextPresent(client.ctx, consts.KsctlTestFlagKey); ok {
@@ -321,7 +339,12 @@ func (client *SSH) SSHExecute() error {
 				if err != nil {
 					client.log.Warn(client.ctx, "Failure in executing script", "retryCount", retries)
 					scriptFailureReason = client.log.NewError(client.ctx, "Execute Failure", "stderr", stderr, "Reason", err)
-					<-time.After(time.Duration(mrand.Intn(2)+1) * time.Second)
+					if retries+1 < script.MaxRetries {
+						client.emit(script.Name, events.StatusRetrying, attempt+1, err)
+					}
+					if err := waiter.Sleep(client.ctx, client.log, time.Duration(mrand.Intn(2)+1)*time.Second); err != nil {
+						return err
+					}
 				} else {
 					client.log.Debug(client.ctx, "client outputs", "stdout", stdout)
 					success = true
@@ -341,10 +364,16 @@ func (client *SSH) SSHExecute() error {
 		}
 
 		if !success {
+			client.emit(script.Name, events.StatusFailed, attempt, scriptFailureReason)
+			// the failure comes from the connection closed on cancellation
+			if err := waiter.Cancelled(client.ctx, client.log); err != nil {
+				return err
+			}
 			return ksctlErrors.WrapError(
 				ksctlErrors.ErrSSHExec,
 				scriptFailureReason)
 		}
+		client.emit(script.Name, events.StatusSucceeded, attempt, nil)
 		if client.flag == consts.UtilExecWithOutput {
 			client.Output = append(client.Output, stdout)
 		}
@@ -355,6 +384,45 @@ func (client *SSH) SSHExecute() error {
 	return nil
 }
 
+// emit reports the progress of the script on the node to the events of the operation
+func (client *SSH) emit(name string, status events.Status, attempt int, err error) {
+	e := events.Event{
+		Phase:    events.PhaseSSH,
+		Resource: "script",
+		Name:     name,
+		Host:     client.PublicIP,
+		Status:   status,
+		Attempt:  attempt,
+	}
+	if err != nil {
+		e.Reason = err.Error()
+	}
+	events.Emit(client.ctx, e)
+}
+
+// dialContext is ssh.Dial bounded by the ctx, the handshake is aborted by closing
+// the tcp connection once the ctx is done
+func dialContext(ctx context.Context, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	d := net.Dialer{Timeout: config.Timeout}
+	tcpConn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		_ = tcpConn.Close()
+	})
+	defer stop()
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(tcpConn, addr, config)
+	if err != nil {
+		_ = tcpConn.Close()
+		return nil, err
+	}
+
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
+
 func signerFromPem(ctx context.Context, log logger.Logger, pemBytes []byte) (ssh.Signer, error) {
 
 	// read pem block
@@ -385,17 +453,17 @@ func signerFromPem(ctx context.Context, log logger.Logger, pemBytes []byte) (ssh
 
 // returnServerPublicKeys it uses the ssh-keygen and ssh-keyscan as OS deps
 // it uses this command -> ssh-keyscan -t rsa <remote_ssh_server_public_ipv4> | ssh-keygen -lf -
-func returnServerPublicKeys(publicIP string, keyType string) (string, error) {
+func returnServerPublicKeys(ctx context.Context, publicIP string, keyType string) (string, error) {
 	var c1, c2 *exec.Cmd
 
 	switch keyType {
 	case ssh.KeyAlgoRSA:
-		c1 = exec.Command("ssh-keyscan", "-t", "rsa", publicIP)
+		c1 = exec.CommandContext(ctx, "ssh-keyscan", "-t", "rsa", publicIP)
 	case ssh.KeyAlgoED25519:
-		c1 = exec.Command("ssh-keyscan", "-t", "ed25519", publicIP)
+		c1 = exec.CommandContext(ctx, "ssh-keyscan", "-t", "ed25519", publicIP)
 	}
 
-	c2 = exec.Command("ssh-keygen", "-lf", "-")
+	c2 = exec.CommandContext(ctx, "ssh-keygen", "-lf", "-")
 
 	r, w := io.Pipe()
 	c1.Stdout = w
@@ -25,6 +25,7 @@ type ExecutionPipeline interface {
 	String() string
 	Append(Script)
 	IsCompleted() bool
+	List() []Script
 }
 
 type Script struct {
@@ -89,6 +90,14 @@ func (s *Scripts) IsCompleted() bool {
 	}
 }
 
+// List returns all the scripts of the pipeline in execution order without consuming them
+func (s *Scripts) List() []Script {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Script(nil), s.data...)
+}
+
 func (s *Scripts) String() string {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -19,12 +19,14 @@ import (
 	"fmt"
 	"github.com/gookit/goutil/dump"
 	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
 	"github.com/ksctl/ksctl/v2/pkg/logger"
 	"github.com/ksctl/ksctl/v2/pkg/statefile"
 	"gotest.tools/v3/assert"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 var (
@@ -80,6 +82,31 @@ suao apt install ...
 
 }
 
+func TestSSHExecuteCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var sshTest RemoteConnection = &SSH{
+		ctx: ctx,
+		log: log,
+	}
+	testSimulator := NewExecutionPipeline()
+	testSimulator.Append(Script{
+		Name:           "test",
+		ScriptExecutor: consts.LinuxBash,
+		ShellScript:    "true",
+	})
+	sshTest.Username("fake")
+	sshTest.PrivateKey(mainStateDoc.SSHKeyPair.PrivateKey)
+
+	start := time.Now()
+	err := sshTest.Flag(consts.UtilExecWithoutOutput).Script(testSimulator).
+		IPv4("A.A.A.A").
+		FastMode(false).SSHExecute()
+	assert.Assert(t, ksctlErrors.IsContextCancelled(err), fmt.Sprintf("expected a cancellation, got: %v", err))
+	assert.Assert(t, time.Since(start) < consts.DurationSSHPause, "the pause before dialing should end with the context")
+}
+
 func TestScriptCollection(t *testing.T) {
 	scripts := NewExecutionPipeline()
 
@@ -61,6 +61,8 @@ type StateConfigurationAws struct {
 	ManagedNodeGroupArn    string `json:"managed_node_group_arns" bson:"managed_node_group_arns"`
 	ManagedClusterArn      string `json:"managed_cluster_arn" bson:"managed_cluster_arn"`
 	ManagedNodeGroupVmSize string `json:"managed_node_group_vm_size" bson:"managed_node_group_vm_size"`
+	MinManagedNodes        int    `json:"min_managed_nodes,omitempty" bson:"min_managed_nodes,omitempty"`
+	MaxManagedNodes        int    `json:"max_managed_nodes,omitempty" bson:"max_managed_nodes,omitempty"`
 
 	SubnetNames  []string `json:"subnet_names" bson:"subnet_names"`
 	SubnetIDs    []string `json:"subnet_id" bson:"subnet_ids"`
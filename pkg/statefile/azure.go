@@ -59,6 +59,8 @@ type StateConfigurationAzure struct {
 	ManagedClusterName string `json:"managed_cluster_name" bson:"managed_cluster_name"`
 	NoManagedNodes     int    `json:"no_managed_cluster_nodes" bson:"no_managed_cluster_nodes"`
 	ManagedNodeSize    string `json:"managed_node_size" bson:"managed_node_size"`
+	MinManagedNodes    int    `json:"min_managed_nodes,omitempty" bson:"min_managed_nodes,omitempty"`
+	MaxManagedNodes    int    `json:"max_managed_nodes,omitempty" bson:"max_managed_nodes,omitempty"`
 
 	SubnetName         string `json:"subnet_name" bson:"subnet_name"`
 	SubnetID           string `json:"subnet_id" bson:"subnet_id"`
@@ -0,0 +1,94 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefile
+
+import "time"
+
+// CreationPhase is a step of the creation of a self-managed cluster
+type CreationPhase string
+
+const (
+	PhaseNetwork       CreationPhase = "network"
+	PhaseSSHKey        CreationPhase = "ssh_key"
+	PhaseFirewall      CreationPhase = "firewall"
+	PhaseVM            CreationPhase = "vm"
+	PhaseLoadBalancer  CreationPhase = "loadbalancer"
+	PhaseDataStore     CreationPhase = "datastore"
+	PhaseControlPlane0 CreationPhase = "controlplane_0"
+	PhaseControlPlaneN CreationPhase = "controlplane_n"
+	PhaseWorkerPlane   CreationPhase = "workerplane"
+	PhaseCNI           CreationPhase = "cni"
+	PhaseApps          CreationPhase = "apps"
+)
+
+type CheckpointStatus string
+
+const (
+	CheckpointCompleted CheckpointStatus = "completed"
+	CheckpointFailed    CheckpointStatus = "failed"
+)
+
+// Checkpoint is the outcome of a phase, the phases which run once per node or per role
+// are told apart by their step, like the vm of the controlplane-1
+type Checkpoint struct {
+	Phase     CreationPhase    `json:"phase" bson:"phase"`
+	Step      string           `json:"step,omitempty" bson:"step,omitempty"`
+	Status    CheckpointStatus `json:"status" bson:"status"`
+	Reason    string           `json:"reason,omitempty" bson:"reason,omitempty"`
+	UpdatedAt time.Time        `json:"updated_at" bson:"updated_at"`
+}
+
+// IsCheckpointCompleted tells whether a previous attempt already completed the step of the phase
+func (s *StorageDocument) IsCheckpointCompleted(phase CreationPhase, step string) bool {
+	for _, c := range s.Checkpoints {
+		if c.Phase == phase && c.Step == step {
+			return c.Status == CheckpointCompleted
+		}
+	}
+	return false
+}
+
+// SetCheckpoint records the outcome of the step of the phase, a nil err marks it as completed
+func (s *StorageDocument) SetCheckpoint(phase CreationPhase, step string, err error) {
+	c := Checkpoint{
+		Phase:     phase,
+		Step:      step,
+		Status:    CheckpointCompleted,
+		UpdatedAt: time.Now().UTC(),
+	}
+	if err != nil {
+		c.Status = CheckpointFailed
+		c.Reason = err.Error()
+	}
+
+	for i := range s.Checkpoints {
+		if s.Checkpoints[i].Phase == phase && s.Checkpoints[i].Step == step {
+			s.Checkpoints[i] = c
+			return
+		}
+	}
+	s.Checkpoints = append(s.Checkpoints, c)
+}
+
+// FailedCheckpoints returns the steps which failed in the last attempt, in the order they were first run
+func (s *StorageDocument) FailedCheckpoints() []Checkpoint {
+	var failed []Checkpoint
+	for _, c := range s.Checkpoints {
+		if c.Status == CheckpointFailed {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
@@ -54,8 +54,8 @@ func (s ClusterState) IsControllerOperationAllowed(operation consts.KsctlOperati
 			return err(operation, s)
 		}
 
-	case CreationFailed: // we can retry creation or delete
-		if operation != consts.OperationCreate && operation != consts.OperationDelete {
+	case CreationFailed: // we can retry creation or delete, get tells which phase failed
+		if operation != consts.OperationCreate && operation != consts.OperationDelete && operation != consts.OperationGet {
 			return err(operation, s)
 		}
 
@@ -95,12 +95,22 @@ type PlatformSpec struct {
 	Team  string       `json:"team" bson:"team"`
 	Owner string       `json:"owner" bson:"owner"`
 	State ClusterState `json:"state" bson:"state"`
+
+	// Labels are set by the user to organize the clusters
+	Labels map[string]string `json:"labels,omitempty" bson:"labels,omitempty"`
 }
 
 // StorageDocument object which stores the state of infra and bootstrap in a doc
 type StorageDocument struct {
 	ID primitive.ObjectID `bson:"_id,omitempty"`
 
+	// Revision is incremented by the storage on every successful write,
+	// a write carrying an older revision than the stored one gets rejected
+	Revision int64 `json:"revision" bson:"revision"`
+
+	// SchemaVersion is the layout of the document, older documents are upgraded by the migrations package on read
+	SchemaVersion int `json:"schema_version" bson:"schema_version"`
+
 	PlatformSpec PlatformSpec `json:"platform" bson:"platform"`
 
 	ClusterType string `json:"cluster_type" bson:"cluster_type" `
@@ -119,6 +129,61 @@ type StorageDocument struct {
 	SSHKeyPair SSHKeyPairState `json:"ssh_key_pair" bson:"ssh_key_pair"`
 
 	ProvisionerAddons SlimProvisionerAddons `json:"provisioner_addons,omitempty" bson:"provisioner_addons,omitempty"`
+
+	// WorkerPools are the pools of the workerplanes of a self-managed cluster,
+	// WorkerPlanePools has the name of the pool of every workerplane by its index
+	WorkerPools      []WorkerPool `json:"worker_pools,omitempty" bson:"worker_pools,omitempty"`
+	WorkerPlanePools []string     `json:"workerplane_pools,omitempty" bson:"workerplane_pools,omitempty"`
+
+	// Checkpoints are the phases of the creation done so far, a retried creation resumes from the first incomplete one
+	Checkpoints []Checkpoint `json:"checkpoints,omitempty" bson:"checkpoints,omitempty"`
+}
+
+// SensitiveFields returns the fields of the document which hold secrets, keyed by a stable name.
+// Storages use it to keep them apart from the rest of the state or to seal them at rest
+func (s *StorageDocument) SensitiveFields() map[string]*string {
+	fields := map[string]*string{
+		"ssh_key_pair.private_key": &s.SSHKeyPair.PrivateKey,
+		"cluster_kubeconfig":       &s.ClusterKubeConfig,
+	}
+	if s.K8sBootstrap != nil {
+		fields["kubernetes_bootstrap_state.b.cloud_ssh_info.private_key"] = &s.K8sBootstrap.B.SSHInfo.PrivateKey
+		fields["kubernetes_bootstrap_state.b.etcd_key"] = &s.K8sBootstrap.B.EtcdKey
+		if s.K8sBootstrap.K3s != nil {
+			fields["kubernetes_bootstrap_state.k3s.k3s_token"] = &s.K8sBootstrap.K3s.K3sToken
+		}
+		if s.K8sBootstrap.Kubeadm != nil {
+			fields["kubernetes_bootstrap_state.kubeadm.certificate_key"] = &s.K8sBootstrap.Kubeadm.CertificateKey
+			fields["kubernetes_bootstrap_state.kubeadm.bootstrap_token"] = &s.K8sBootstrap.Kubeadm.BootstrapToken
+		}
+	}
+	return fields
+}
+
+// KubernetesVersion returns the version of kubernetes run by the cluster, empty when it is not known yet
+func (s *StorageDocument) KubernetesVersion() string {
+	var v *string
+	if s.ClusterType == string(consts.ClusterTypeMang) {
+		switch s.InfraProvider {
+		case consts.CloudAws:
+			v = s.Versions.Eks
+		case consts.CloudAzure:
+			v = s.Versions.Aks
+		case consts.CloudLocal:
+			v = s.Versions.Kind
+		}
+	} else {
+		switch s.BootstrapProvider {
+		case consts.K8sK3s:
+			v = s.Versions.K3s
+		case consts.K8sKubeadm:
+			v = s.Versions.Kubeadm
+		}
+	}
+	if v == nil {
+		return ""
+	}
+	return *v
 }
 
 type SlimProvisionerAddons struct {
@@ -0,0 +1,32 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefile
+
+type CredentialsS3 struct {
+	// Endpoint of the S3 compatible service, empty uses the endpoint of AWS for the region
+	Endpoint string `json:"endpoint"`
+	Region   string `json:"region"`
+	Bucket   string `json:"bucket"`
+
+	// Prefix is put in front of every object key, defaults to ksctl-state
+	Prefix string `json:"prefix"`
+
+	// AccessKeyID and SecretAccessKey are optional, the default aws credential chain is used when empty
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+
+	// UsePathStyle addresses the bucket in the path instead of the host, needed by most of the S3 compatible services
+	UsePathStyle bool `json:"use_path_style"`
+}
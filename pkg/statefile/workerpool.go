@@ -0,0 +1,71 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefile
+
+// DefaultWorkerPool holds the workerplanes created from the node type and the count of workerplanes,
+// it is also the pool of the workerplanes of the clusters created before the worker pools
+const DefaultWorkerPool = "default"
+
+// WorkerPool is a named group of workerplanes sharing their vm size, disk, node labels and taints
+type WorkerPool struct {
+	Name   string `json:"name" bson:"name"`
+	VMSize string `json:"vm_size" bson:"vm_size"`
+	Count  int    `json:"count" bson:"count"`
+
+	// DiskSize is the size of the os disk in GB, 0 keeps the default disk of the cloud
+	DiskSize int `json:"disk_size,omitempty" bson:"disk_size,omitempty"`
+
+	// Labels are the kubernetes labels of the nodes of the pool
+	Labels map[string]string `json:"labels,omitempty" bson:"labels,omitempty"`
+
+	// Taints are the kubernetes taints of the nodes of the pool, in the key[=value]:Effect form
+	Taints []string `json:"taints,omitempty" bson:"taints,omitempty"`
+}
+
+// WorkerPoolOf returns the pool of the workerplane at the index, nil when it has none
+func (s *StorageDocument) WorkerPoolOf(no int) *WorkerPool {
+	if no < 0 || no >= len(s.WorkerPlanePools) {
+		return nil
+	}
+	return s.WorkerPool(s.WorkerPlanePools[no])
+}
+
+// WorkerPool returns the pool with the name, nil when the cluster has no such pool
+func (s *StorageDocument) WorkerPool(name string) *WorkerPool {
+	for i := range s.WorkerPools {
+		if s.WorkerPools[i].Name == name {
+			return &s.WorkerPools[i]
+		}
+	}
+	return nil
+}
+
+// SyncWorkerPools counts the workerplanes of every pool from the pools of the workerplanes,
+// the pools left without any workerplane are dropped
+func (s *StorageDocument) SyncWorkerPools() {
+	counts := make(map[string]int, len(s.WorkerPools))
+	for _, pool := range s.WorkerPlanePools {
+		counts[pool]++
+	}
+
+	pools := s.WorkerPools[:0]
+	for _, pool := range s.WorkerPools {
+		pool.Count = counts[pool.Name]
+		if pool.Count > 0 {
+			pools = append(pools, pool)
+		}
+	}
+	s.WorkerPools = pools
+}
@@ -0,0 +1,249 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	"github.com/ksctl/ksctl/v2/pkg/utilities"
+)
+
+type AuditOperation string
+
+const (
+	AuditOperationCreate          AuditOperation = "create"
+	AuditOperationDelete          AuditOperation = "delete"
+	AuditOperationScaleUp         AuditOperation = "scale-up"
+	AuditOperationScaleDown       AuditOperation = "scale-down"
+	AuditOperationUpdateLabels    AuditOperation = "update-labels"
+	AuditOperationRollbackState   AuditOperation = "rollback-state"
+	AuditOperationReplaceNode     AuditOperation = "replace-node"
+	AuditOperationScaleNodePool   AuditOperation = "scale-node-pool"
+	AuditOperationScaleWorkerPool AuditOperation = "scale-worker-pool"
+)
+
+type AuditOutcome string
+
+const (
+	AuditOutcomeSucceeded AuditOutcome = "succeeded"
+	AuditOutcomeFailed    AuditOutcome = "failed"
+)
+
+// RedactedValue replaces the input values which look like credentials
+const RedactedValue = "<redacted>"
+
+// AuditEntry records a single operation run against a cluster
+type AuditEntry struct {
+	// ID is unique per entry and safe to use in object names
+	ID string `json:"id" bson:"id"`
+
+	Cloud       consts.KsctlCloud       `json:"cloud" bson:"cloud"`
+	Region      string                  `json:"region" bson:"region"`
+	ClusterName string                  `json:"cluster_name" bson:"cluster_name"`
+	ClusterType consts.KsctlClusterType `json:"cluster_type" bson:"cluster_type"`
+
+	Operation AuditOperation `json:"operation" bson:"operation"`
+	Actor     string         `json:"actor" bson:"actor"`
+	Group     string         `json:"group,omitempty" bson:"group,omitempty"`
+
+	StartedAt time.Time `json:"started_at" bson:"started_at"`
+	EndedAt   time.Time `json:"ended_at" bson:"ended_at"`
+
+	// Input is the flattened input of the operation with the secrets redacted
+	Input map[string]string `json:"input,omitempty" bson:"input,omitempty"`
+
+	Outcome AuditOutcome `json:"outcome" bson:"outcome"`
+	Error   string       `json:"error,omitempty" bson:"error,omitempty"`
+}
+
+func NewAuditEntry(
+	cloud consts.KsctlCloud,
+	region, clusterName string,
+	clusterType consts.KsctlClusterType,
+	op AuditOperation,
+	actor, group string,
+) (*AuditEntry, error) {
+	suffix, err := utilities.GenRandomString(8)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	return &AuditEntry{
+		ID:          strconv.FormatInt(now.UnixNano(), 36) + "-" + strings.ToLower(suffix),
+		Cloud:       cloud,
+		Region:      region,
+		ClusterName: clusterName,
+		ClusterType: clusterType,
+		Operation:   op,
+		Actor:       actor,
+		Group:       group,
+		StartedAt:   now,
+	}, nil
+}
+
+// Finish sets the end time and the outcome of the operation from its error
+func (e *AuditEntry) Finish(err error) {
+	e.EndedAt = time.Now().UTC()
+	if err != nil {
+		e.Outcome = AuditOutcomeFailed
+		e.Error = err.Error()
+	} else {
+		e.Outcome = AuditOutcomeSucceeded
+		e.Error = ""
+	}
+}
+
+func (e AuditEntry) String() string {
+	return fmt.Sprintf("%s %s by %s on %s %s/%s/%s: %s",
+		e.StartedAt.Format(time.RFC3339), e.Operation, e.Actor,
+		e.ClusterType, e.Cloud, e.Region, e.ClusterName, e.Outcome)
+}
+
+// AuditQuery selects the audit entries, the empty fields match everything
+type AuditQuery struct {
+	Cloud       consts.KsctlCloud
+	Region      string
+	ClusterName string
+	ClusterType consts.KsctlClusterType
+
+	Actor     string
+	Group     string
+	Operation AuditOperation
+
+	// Since and Until bound the start time of the operation, Until is exclusive
+	Since time.Time
+	Until time.Time
+
+	// Limit of 0 returns all the entries matching, else only the newest ones
+	Limit int
+}
+
+// IsClusterScoped reports if the query is for the trail of a single cluster
+func (q AuditQuery) IsClusterScoped() bool {
+	return len(q.Cloud) != 0 && len(q.Region) != 0 && len(q.ClusterName) != 0 && len(q.ClusterType) != 0
+}
+
+func (q AuditQuery) Validate() error {
+	if q.Limit < 0 {
+		return fmt.Errorf("limit cannot be negative")
+	}
+	if !q.Since.IsZero() && !q.Until.IsZero() && !q.Since.Before(q.Until) {
+		return fmt.Errorf("since has to be before until")
+	}
+	return nil
+}
+
+func (q AuditQuery) Matches(e *AuditEntry) bool {
+	if e == nil {
+		return false
+	}
+	exact := []struct{ want, got string }{
+		{string(q.Cloud), string(e.Cloud)},
+		{q.Region, e.Region},
+		{q.ClusterName, e.ClusterName},
+		{string(q.ClusterType), string(e.ClusterType)},
+		{q.Actor, e.Actor},
+		{q.Group, e.Group},
+		{string(q.Operation), string(e.Operation)},
+	}
+	for _, f := range exact {
+		if len(f.want) != 0 && f.want != f.got {
+			return false
+		}
+	}
+	if !q.Since.IsZero() && e.StartedAt.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !e.StartedAt.Before(q.Until) {
+		return false
+	}
+	return true
+}
+
+// FilterAuditEntries keeps the entries matching the query, oldest first.
+// With a Limit only the newest entries are kept
+func FilterAuditEntries(entries []*AuditEntry, q AuditQuery) []*AuditEntry {
+	out := make([]*AuditEntry, 0, len(entries))
+	for _, e := range entries {
+		if q.Matches(e) {
+			out = append(out, e)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].StartedAt.Equal(out[j].StartedAt) {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].StartedAt.Before(out[j].StartedAt)
+	})
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[len(out)-q.Limit:]
+	}
+	return out
+}
+
+// Auditor is implemented by the storage backends which keep an append-only trail of the
+// operations run against the clusters. The trail outlives DeleteCluster
+type Auditor interface {
+	// RecordAudit appends the entry to the trail of the cluster named in the entry
+	RecordAudit(entry *AuditEntry) error
+
+	QueryAudit(query AuditQuery) ([]*AuditEntry, error)
+}
+
+var sensitiveInputKeys = []string{
+	"password", "passwd", "secret", "token", "credential", "privatekey", "private_key", "accesskey", "access_key", "apikey", "api_key",
+}
+
+func isSensitiveInputKey(path string) bool {
+	k := strings.ToLower(path)
+	for _, s := range sensitiveInputKeys {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactInput flattens the json form of the input into paths, the values of
+// the paths naming a secret are replaced by RedactedValue
+func RedactInput(v any) (map[string]string, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	flat := make(map[string]any)
+	flatten("", doc, flat)
+
+	out := make(map[string]string, len(flat))
+	for path, val := range flat {
+		if isSensitiveInputKey(path) {
+			out[path] = RedactedValue
+			continue
+		}
+		out[path] = fmt.Sprint(val)
+	}
+	return out, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+)
+
+// SealDocument returns a copy of the doc with the secrets encrypted, the doc passed is left untouched
+// as the controllers and providers keep working on the plaintext state
+func SealDocument(e Encrypter, doc *statefile.StorageDocument) (*statefile.StorageDocument, error) {
+	if e == nil || doc == nil {
+		return doc, nil
+	}
+
+	out := *doc
+	if out.K8sBootstrap != nil {
+		b := *out.K8sBootstrap
+		if b.K3s != nil {
+			k3s := *b.K3s
+			b.K3s = &k3s
+		}
+		if b.Kubeadm != nil {
+			kubeadm := *b.Kubeadm
+			b.Kubeadm = &kubeadm
+		}
+		out.K8sBootstrap = &b
+	}
+
+	for _, v := range out.SensitiveFields() {
+		if len(*v) == 0 || IsSealed(*v) {
+			continue
+		}
+		sealed, err := e.Encrypt(*v)
+		if err != nil {
+			return nil, err
+		}
+		*v = sealed
+	}
+
+	return &out, nil
+}
+
+// OpenDocument decrypts the secrets of the doc in place, values which were never sealed are kept as is
+func OpenDocument(e Encrypter, doc *statefile.StorageDocument) error {
+	if doc == nil {
+		return nil
+	}
+
+	for _, v := range doc.SensitiveFields() {
+		if !IsSealed(*v) {
+			continue
+		}
+		if e == nil {
+			return ksctlErrors.WrapErrorf(ksctlErrors.ErrInternal, "state is encrypted but no encrypter is configured")
+		}
+		plain, err := e.Decrypt(*v)
+		if err != nil {
+			return err
+		}
+		*v = plain
+	}
+	return nil
+}
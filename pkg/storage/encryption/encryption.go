@@ -0,0 +1,213 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+)
+
+const (
+	// sealedPrefix marks the values which got sealed, values without it are treated as plaintext
+	// so that the state written before enabling the encryption can still be read
+	sealedPrefix = "ksctl-enc:v1:"
+
+	dataKeySize = 32
+)
+
+// KeyEncrypter wraps and unwraps the data keys, it is the part which talks to the local keyfile or a cloud KMS
+type KeyEncrypter interface {
+	// KeyID returns the id of the key which is used to wrap the new data keys
+	KeyID() string
+
+	WrapKey(ctx context.Context, dataKey []byte) ([]byte, error)
+
+	UnwrapKey(ctx context.Context, keyID string, wrappedKey []byte) ([]byte, error)
+}
+
+// Encrypter seals and opens the sensitive values stored in the StorageDocument
+type Encrypter interface {
+	Encrypt(plaintext string) (string, error)
+
+	Decrypt(value string) (string, error)
+}
+
+type envelope struct {
+	KeyID      string `json:"kid"`
+	WrappedKey []byte `json:"wk"`
+	Nonce      []byte `json:"n"`
+	Ciphertext []byte `json:"ct"`
+}
+
+type dataKey struct {
+	keyID   string
+	plain   []byte
+	wrapped []byte
+}
+
+// EnvelopeEncrypter encrypts every value with AES-GCM using a data key, the data key itself is
+// wrapped by the KeyEncrypter and stored along the ciphertext
+type EnvelopeEncrypter struct {
+	ctx context.Context
+	kek KeyEncrypter
+
+	mu      sync.Mutex
+	current *dataKey
+	// unwrapped caches the data keys which got unwrapped, so the KeyEncrypter is called once per data key
+	unwrapped map[string][]byte
+}
+
+func NewEnvelopeEncrypter(ctx context.Context, kek KeyEncrypter) *EnvelopeEncrypter {
+	return &EnvelopeEncrypter{
+		ctx:       ctx,
+		kek:       kek,
+		unwrapped: make(map[string][]byte),
+	}
+}
+
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
+
+// currentDataKey returns the data key for sealing, a new one is generated once the key encryption key got rotated
+func (e *EnvelopeEncrypter) currentDataKey() (*dataKey, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.current != nil && e.current.keyID == e.kek.KeyID() {
+		return e.current, nil
+	}
+
+	plain := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, plain); err != nil {
+		return nil, ksctlErrors.WrapErrorf(ksctlErrors.ErrInternal, "failed to generate data key: %v", err)
+	}
+	keyID := e.kek.KeyID()
+	wrapped, err := e.kek.WrapKey(e.ctx, plain)
+	if err != nil {
+		return nil, ksctlErrors.WrapErrorf(ksctlErrors.ErrInternal, "failed to wrap the data key: %v", err)
+	}
+	e.current = &dataKey{keyID: keyID, plain: plain, wrapped: wrapped}
+	return e.current, nil
+}
+
+func (e *EnvelopeEncrypter) unwrapDataKey(keyID string, wrapped []byte) ([]byte, error) {
+	cacheKey := keyID + "/" + base64.StdEncoding.EncodeToString(wrapped)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if v, ok := e.unwrapped[cacheKey]; ok {
+		return v, nil
+	}
+
+	plain, err := e.kek.UnwrapKey(e.ctx, keyID, wrapped)
+	if err != nil {
+		return nil, ksctlErrors.WrapErrorf(ksctlErrors.ErrInternal, "failed to unwrap the data key: %v", err)
+	}
+	e.unwrapped[cacheKey] = plain
+	return plain, nil
+}
+
+func (e *EnvelopeEncrypter) Encrypt(plaintext string) (string, error) {
+	dk, err := e.currentDataKey()
+	if err != nil {
+		return "", err
+	}
+
+	nonce, ciphertext, err := sealAESGCM(dk.plain, []byte(plaintext))
+	if err != nil {
+		return "", ksctlErrors.WrapErrorf(ksctlErrors.ErrInternal, "failed to encrypt: %v", err)
+	}
+
+	raw, err := json.Marshal(envelope{
+		KeyID:      dk.keyID,
+		WrappedKey: dk.wrapped,
+		Nonce:      nonce,
+		Ciphertext: ciphertext,
+	})
+	if err != nil {
+		return "", ksctlErrors.WrapErrorf(ksctlErrors.ErrInternal, "failed to serialize the envelope: %v", err)
+	}
+
+	return sealedPrefix + base64.StdEncoding.EncodeToString(raw), nil
+}
+
+func (e *EnvelopeEncrypter) Decrypt(value string) (string, error) {
+	if !IsSealed(value) {
+		return value, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, sealedPrefix))
+	if err != nil {
+		return "", ksctlErrors.WrapErrorf(ksctlErrors.ErrInternal, "failed to decode the envelope: %v", err)
+	}
+	var env envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return "", ksctlErrors.WrapErrorf(ksctlErrors.ErrInternal, "failed to deserialize the envelope: %v", err)
+	}
+
+	key, err := e.unwrapDataKey(env.KeyID, env.WrappedKey)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := openAESGCM(key, env.Nonce, env.Ciphertext)
+	if err != nil {
+		return "", ksctlErrors.WrapErrorf(ksctlErrors.ErrInternal, "failed to decrypt: %v", err)
+	}
+	return string(plaintext), nil
+}
+
+func sealAESGCM(key, plaintext []byte) (nonce, ciphertext []byte, err error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce = make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, nil, err
+	}
+	return nonce, gcm.Seal(nil, nonce, plaintext, nil), nil
+}
+
+func openAESGCM(key, nonce, ciphertext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce size %d", len(nonce))
+	}
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
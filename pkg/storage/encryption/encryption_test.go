@@ -0,0 +1,151 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"gotest.tools/v3/assert"
+)
+
+func newTestKeyring(t *testing.T) (*LocalKeyring, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keyfile.json")
+	k, err := NewLocalKeyring(path)
+	assert.NilError(t, err)
+	return k, path
+}
+
+func TestLocalKeyring(t *testing.T) {
+	k, path := newTestKeyring(t)
+
+	info, err := os.Stat(path)
+	assert.NilError(t, err)
+	assert.Equal(t, info.Mode().Perm(), keyfilePerm)
+
+	wrapped, err := k.WrapKey(context.TODO(), []byte("data-key"))
+	assert.NilError(t, err)
+
+	reloaded, err := NewLocalKeyring(path)
+	assert.NilError(t, err)
+	assert.Equal(t, reloaded.KeyID(), k.KeyID())
+
+	plain, err := reloaded.UnwrapKey(context.TODO(), k.KeyID(), wrapped)
+	assert.NilError(t, err)
+	assert.Equal(t, string(plain), "data-key")
+
+	_, err = reloaded.UnwrapKey(context.TODO(), "missing", wrapped)
+	assert.ErrorContains(t, err, "not present")
+}
+
+func TestEnvelopeEncrypter(t *testing.T) {
+	k, _ := newTestKeyring(t)
+	e := NewEnvelopeEncrypter(context.TODO(), k)
+
+	sealed, err := e.Encrypt("secret")
+	assert.NilError(t, err)
+	assert.Assert(t, IsSealed(sealed))
+	assert.Assert(t, !strings.Contains(sealed, "secret"))
+
+	plain, err := e.Decrypt(sealed)
+	assert.NilError(t, err)
+	assert.Equal(t, plain, "secret")
+
+	plain, err = e.Decrypt("not-sealed")
+	assert.NilError(t, err)
+	assert.Equal(t, plain, "not-sealed")
+
+	t.Run("rotation", func(t *testing.T) {
+		oldKey := k.KeyID()
+		newKey, err := k.Rotate()
+		assert.NilError(t, err)
+		assert.Assert(t, oldKey != newKey)
+
+		rotated, err := e.Encrypt("secret")
+		assert.NilError(t, err)
+		assert.Assert(t, rotated != sealed)
+
+		// a fresh encrypter has nothing cached, the retired key must still be usable
+		fresh := NewEnvelopeEncrypter(context.TODO(), k)
+		for _, v := range []string{sealed, rotated} {
+			plain, err := fresh.Decrypt(v)
+			assert.NilError(t, err)
+			assert.Equal(t, plain, "secret")
+		}
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		other, _ := newTestKeyring(t)
+		_, err := NewEnvelopeEncrypter(context.TODO(), other).Decrypt(sealed)
+		assert.Assert(t, err != nil)
+	})
+}
+
+func TestSealDocument(t *testing.T) {
+	k, _ := newTestKeyring(t)
+	e := NewEnvelopeEncrypter(context.TODO(), k)
+
+	doc := &statefile.StorageDocument{
+		ClusterName:       "demo",
+		ClusterKubeConfig: "kubeconfig",
+		SSHKeyPair: statefile.SSHKeyPairState{
+			PublicKey:  "public",
+			PrivateKey: "private",
+		},
+		K8sBootstrap: &statefile.KubernetesBootstrapState{
+			B: statefile.BaseK8sBootstrap{
+				SSHInfo: statefile.SSHInfo{UserName: "root", PrivateKey: "private"},
+				EtcdKey: "etcd-key",
+			},
+			K3s:     &statefile.StateConfigurationK3s{K3sToken: "token"},
+			Kubeadm: &statefile.StateConfigurationKubeadm{CertificateKey: "cert-key"},
+		},
+	}
+
+	sealed, err := SealDocument(e, doc)
+	assert.NilError(t, err)
+
+	assert.Equal(t, doc.ClusterKubeConfig, "kubeconfig")
+	assert.Equal(t, doc.K8sBootstrap.K3s.K3sToken, "token")
+	assert.Equal(t, doc.K8sBootstrap.Kubeadm.CertificateKey, "cert-key")
+
+	assert.Equal(t, sealed.ClusterName, "demo")
+	assert.Equal(t, sealed.SSHKeyPair.PublicKey, "public")
+	assert.Equal(t, sealed.K8sBootstrap.B.SSHInfo.UserName, "root")
+	assert.Equal(t, sealed.K8sBootstrap.Kubeadm.BootstrapToken, "")
+	for _, v := range sealed.SensitiveFields() {
+		if len(*v) != 0 {
+			assert.Assert(t, IsSealed(*v))
+		}
+	}
+
+	assert.NilError(t, OpenDocument(e, sealed))
+	assert.DeepEqual(t, sealed, doc)
+
+	t.Run("no encrypter", func(t *testing.T) {
+		same, err := SealDocument(nil, doc)
+		assert.NilError(t, err)
+		assert.Equal(t, same, doc)
+
+		sealed, err := SealDocument(e, doc)
+		assert.NilError(t, err)
+		assert.ErrorContains(t, OpenDocument(nil, sealed), "no encrypter")
+	})
+}
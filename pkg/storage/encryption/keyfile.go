@@ -0,0 +1,157 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/utilities"
+)
+
+const keyfilePerm = os.FileMode(0600)
+
+type keyfileKey struct {
+	ID  string `json:"id"`
+	Key []byte `json:"key"`
+}
+
+type keyfile struct {
+	// Primary is the id of the key used to wrap the new data keys,
+	// the remaining keys are kept so the data sealed before a rotation can still be opened
+	Primary string       `json:"primary"`
+	Keys    []keyfileKey `json:"keys"`
+}
+
+// LocalKeyring is a KeyEncrypter backed by a keyfile holding AES-256 keys
+type LocalKeyring struct {
+	path string
+
+	mu   sync.RWMutex
+	file keyfile
+}
+
+// NewLocalKeyring loads the keyfile present at the path, a keyfile with a fresh key gets created when it is missing
+func NewLocalKeyring(path string) (*LocalKeyring, error) {
+	k := &LocalKeyring{path: path}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, ksctlErrors.WrapErrorf(ksctlErrors.ErrInternal, "failed to read the keyfile: %v", err)
+		}
+		if _, err := k.Rotate(); err != nil {
+			return nil, err
+		}
+		return k, nil
+	}
+
+	if err := json.Unmarshal(raw, &k.file); err != nil {
+		return nil, ksctlErrors.WrapErrorf(ksctlErrors.ErrInternal, "failed to deserialize the keyfile: %v", err)
+	}
+	if _, ok := k.key(k.file.Primary); !ok {
+		return nil, ksctlErrors.WrapErrorf(ksctlErrors.ErrInternal, "keyfile has no primary key")
+	}
+	return k, nil
+}
+
+func (k *LocalKeyring) key(id string) ([]byte, bool) {
+	for _, v := range k.file.Keys {
+		if v.ID == id {
+			return v.Key, true
+		}
+	}
+	return nil, false
+}
+
+// Rotate adds a new key and makes it the primary one, the older keys are retained for decryption.
+// Documents pick up the new key the next time they get written
+func (k *LocalKeyring) Rotate() (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	key := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", ksctlErrors.WrapErrorf(ksctlErrors.ErrInternal, "failed to generate key: %v", err)
+	}
+
+	id, err := utilities.GenRandomString(16)
+	if err != nil {
+		return "", ksctlErrors.WrapErrorf(ksctlErrors.ErrInternal, "failed to generate key id: %v", err)
+	}
+	next := keyfile{
+		Primary: id,
+		Keys:    append(append([]keyfileKey{}, k.file.Keys...), keyfileKey{ID: id, Key: key}),
+	}
+	if err := k.save(next); err != nil {
+		return "", err
+	}
+	k.file = next
+	return id, nil
+}
+
+func (k *LocalKeyring) save(f keyfile) error {
+	raw, err := json.Marshal(f)
+	if err != nil {
+		return ksctlErrors.WrapErrorf(ksctlErrors.ErrInternal, "failed to serialize the keyfile: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(k.path), 0755); err != nil {
+		return ksctlErrors.WrapErrorf(ksctlErrors.ErrInternal, "failed to create the keyfile directory: %v", err)
+	}
+	if err := os.WriteFile(k.path, raw, keyfilePerm); err != nil {
+		return ksctlErrors.WrapErrorf(ksctlErrors.ErrInternal, "failed to write the keyfile: %v", err)
+	}
+	return nil
+}
+
+func (k *LocalKeyring) KeyID() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.file.Primary
+}
+
+func (k *LocalKeyring) WrapKey(_ context.Context, dataKey []byte) ([]byte, error) {
+	k.mu.RLock()
+	key, _ := k.key(k.file.Primary)
+	k.mu.RUnlock()
+
+	nonce, ciphertext, err := sealAESGCM(key, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return append(nonce, ciphertext...), nil
+}
+
+func (k *LocalKeyring) UnwrapKey(_ context.Context, keyID string, wrappedKey []byte) ([]byte, error) {
+	k.mu.RLock()
+	key, ok := k.key(keyID)
+	k.mu.RUnlock()
+	if !ok {
+		return nil, ksctlErrors.WrapErrorf(ksctlErrors.ErrInternal, "key %s is not present in the keyfile", keyID)
+	}
+
+	// AES-GCM uses a 12 byte nonce which is prefixed to the wrapped key
+	if len(wrappedKey) < 12 {
+		return nil, ksctlErrors.WrapErrorf(ksctlErrors.ErrInternal, "wrapped key is too short")
+	}
+	return openAESGCM(key, wrappedKey[:12], wrappedKey[12:])
+}
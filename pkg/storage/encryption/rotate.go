@@ -0,0 +1,50 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+)
+
+// Encryptable is implemented by the storages which can seal the secrets of the state at rest
+type Encryptable interface {
+	SetEncrypter(enc Encrypter)
+}
+
+// Reseal rewrites the state of every cluster present in the store, the secrets get sealed
+// with the current key so that the keys retired by a rotation are no longer needed.
+// The store must already have the Encrypter set which knows both the old and the new keys
+func Reseal(store storage.Storage) error {
+	clusters, err := store.GetOneOrMoreClusters(map[consts.KsctlSearchFilter]string{})
+	if err != nil {
+		return err
+	}
+
+	for clusterType, docs := range clusters {
+		for _, doc := range docs {
+			if doc == nil {
+				continue
+			}
+			if err := store.Setup(doc.InfraProvider, doc.Region, doc.ClusterName, clusterType); err != nil {
+				return err
+			}
+			if err := store.Write(doc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
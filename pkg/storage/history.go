@@ -0,0 +1,184 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ksctl/ksctl/v2/pkg/config"
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+)
+
+// StateRevision is a copy of the state kept by the storage for every successful write
+type StateRevision struct {
+	Revision  int64     `json:"revision" bson:"revision"`
+	WrittenBy string    `json:"written_by" bson:"written_by"`
+	WrittenAt time.Time `json:"written_at" bson:"written_at"`
+
+	Document *statefile.StorageDocument `json:"document,omitempty" bson:"document,omitempty"`
+}
+
+func NewStateRevision(writtenBy string, doc *statefile.StorageDocument) *StateRevision {
+	return &StateRevision{
+		Revision:  doc.Revision,
+		WrittenBy: writtenBy,
+		WrittenAt: time.Now().UTC(),
+		Document:  doc,
+	}
+}
+
+// WriterFromContext returns the user on whose behalf the storage writes the state
+func WriterFromContext(ctx context.Context) string {
+	if v, ok := config.IsContextPresent(ctx, consts.KsctlContextUser); ok {
+		return v
+	}
+	return "anonymous"
+}
+
+// History is implemented by the storage backends which keep the last
+// consts.CounterMaxStateRevisions revisions of the cluster selected through Setup
+type History interface {
+	// ListRevisions returns the revisions kept, newest first. The documents are not part of the result
+	ListRevisions() ([]*StateRevision, error)
+
+	ReadRevision(revision int64) (*StateRevision, error)
+}
+
+// StateChange is a single difference between two revisions of the state,
+// Path is the json path of the field and Old or New is nil when the field got added or removed.
+// The values of the secrets of the state are reported as RedactedValue
+type StateChange struct {
+	Path string `json:"path"`
+	Old  any    `json:"old,omitempty"`
+	New  any    `json:"new,omitempty"`
+}
+
+func (c StateChange) String() string {
+	switch {
+	case c.Old == nil:
+		return fmt.Sprintf("+ %s: %v", c.Path, c.New)
+	case c.New == nil:
+		return fmt.Sprintf("- %s: %v", c.Path, c.Old)
+	default:
+		return fmt.Sprintf("~ %s: %v -> %v", c.Path, c.Old, c.New)
+	}
+}
+
+// DiffDocuments returns the changes needed to go from the state 'from' to the state 'to', sorted by path.
+// The revision counter itself is not reported and the secrets only show up as changed, never by value
+func DiffDocuments(from, to *statefile.StorageDocument) ([]StateChange, error) {
+	a, err := flattenDocument(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := flattenDocument(to)
+	if err != nil {
+		return nil, err
+	}
+	delete(a, "revision")
+	delete(b, "revision")
+
+	var changes []StateChange
+	for path, old := range a {
+		if v, ok := b[path]; !ok {
+			changes = append(changes, StateChange{Path: path, Old: old})
+		} else if !reflect.DeepEqual(old, v) {
+			changes = append(changes, StateChange{Path: path, Old: old, New: v})
+		}
+	}
+	for path, v := range b {
+		if _, ok := a[path]; !ok {
+			changes = append(changes, StateChange{Path: path, New: v})
+		}
+	}
+
+	sensitive := sensitivePaths(from, to)
+	for i := range changes {
+		if _, ok := sensitive[changes[i].Path]; !ok {
+			continue
+		}
+		if changes[i].Old != nil {
+			changes[i].Old = RedactedValue
+		}
+		if changes[i].New != nil {
+			changes[i].New = RedactedValue
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes, nil
+}
+
+// sensitivePaths returns the json paths of the secrets of the documents
+func sensitivePaths(docs ...*statefile.StorageDocument) map[string]struct{} {
+	paths := make(map[string]struct{})
+	for _, doc := range docs {
+		if doc == nil {
+			continue
+		}
+		for path := range doc.SensitiveFields() {
+			paths[path] = struct{}{}
+		}
+	}
+	return paths
+}
+
+func flattenDocument(doc *statefile.StorageDocument) (map[string]any, error) {
+	out := make(map[string]any)
+	if doc == nil {
+		return out, nil
+	}
+
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	flatten("", v, out)
+	return out, nil
+}
+
+func flatten(prefix string, v any, out map[string]any) {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			if prefix == "" {
+				flatten(k, child, out)
+			} else {
+				flatten(prefix+"."+k, child, out)
+			}
+		}
+	case []any:
+		for i, child := range t {
+			flatten(prefix+"["+strconv.Itoa(i)+"]", child, out)
+		}
+	case nil:
+		// absent and null fields are treated the same
+	default:
+		out[prefix] = t
+	}
+}
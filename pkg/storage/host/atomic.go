@@ -0,0 +1,94 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package host
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+const backupSuffix = ".bak"
+
+// writeFileAtomic writes the data to a temporary file next to loc and renames it over loc
+// once it is synced, so loc holds either the old or the new content even if the process gets killed
+func writeFileAtomic(loc string, data []byte, perm os.FileMode) (err error) {
+	dir := filepath.Dir(loc)
+
+	f, err := os.CreateTemp(dir, "."+filepath.Base(loc)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(tmp)
+		}
+	}()
+
+	if _, err = f.Write(data); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Chmod(perm); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, loc); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// backupFile keeps the current content of loc in its backup file, the backup is only
+// refreshed when the current content is valid so that a broken file never replaces a good backup
+func backupFile(loc string, valid func([]byte) bool) error {
+	data, err := os.ReadFile(loc)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if !valid(data) {
+		return nil
+	}
+	return writeFileAtomic(loc+backupSuffix, data, filePerm)
+}
+
+// lockPath takes an exclusive advisory lock on the file at loc, waiting for the other
+// processes holding it. The returned func releases the lock
+func lockPath(loc string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(loc), dirPerm); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(loc, os.O_RDWR|os.O_CREATE, filePerm)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return func() {
+		_ = unlockFile(f)
+		_ = f.Close()
+	}, nil
+}
@@ -0,0 +1,174 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package host
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+)
+
+const subDirAudit = "audit"
+
+// auditPath returns the file holding the trail of the cluster, one json entry per line.
+// It lives outside of the cluster directory so that the trail outlives the cluster
+func (s *Store) auditPath(e *storage.AuditEntry) (string, error) {
+	return s.genOsClusterPath(subDirAudit, string(e.Cloud), string(e.ClusterType), e.ClusterName+" "+e.Region+".jsonl")
+}
+
+func (s *Store) RecordAudit(entry *storage.AuditEntry) error {
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	loc, err := s.auditPath(entry)
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to gen auditpath in host", "Reason", err),
+		)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to serialize the audit entry", "Reason", err),
+		)
+	}
+
+	unlock, err := lockPath(loc + ".lock")
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to lock the audit trail", "Reason", err),
+		)
+	}
+	defer unlock()
+
+	f, err := os.OpenFile(loc, os.O_WRONLY|os.O_CREATE|os.O_APPEND, filePerm)
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to open the audit trail", "Reason", err),
+		)
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to append to the audit trail", "Reason", err),
+		)
+	}
+	if err := f.Sync(); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to sync the audit trail", "Reason", err),
+		)
+	}
+	return nil
+}
+
+func (s *Store) QueryAudit(query storage.AuditQuery) ([]*storage.AuditEntry, error) {
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	var locs []string
+	if query.IsClusterScoped() {
+		loc, err := s.auditPath(&storage.AuditEntry{
+			Cloud:       query.Cloud,
+			Region:      query.Region,
+			ClusterName: query.ClusterName,
+			ClusterType: query.ClusterType,
+		})
+		if err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				s.l.NewError(s.ctx, "failed to gen auditpath in host", "Reason", err),
+			)
+		}
+		locs = append(locs, loc)
+	} else {
+		root, err := s.genOsClusterPath(subDirAudit)
+		if err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				s.l.NewError(s.ctx, "failed to gen auditpath in host", "Reason", err),
+			)
+		}
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			if !d.IsDir() && strings.HasSuffix(d.Name(), ".jsonl") {
+				locs = append(locs, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				s.l.NewError(s.ctx, "failed to list the audit trails in host", "Reason", err),
+			)
+		}
+	}
+
+	var entries []*storage.AuditEntry
+	for _, loc := range locs {
+		v, err := readAuditFile(loc)
+		if err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				s.l.NewError(s.ctx, "failed to read the audit trail in host", "Reason", err),
+			)
+		}
+		entries = append(entries, v...)
+	}
+	return storage.FilterAuditEntries(entries, query), nil
+}
+
+// readAuditFile reads the entries of a trail, a line cut short by a crash is skipped
+func readAuditFile(loc string) ([]*storage.AuditEntry, error) {
+	f, err := os.Open(loc)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var out []*storage.AuditEntry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		var v *storage.AuditEntry
+		if err := json.Unmarshal(sc.Bytes(), &v); err != nil || v == nil {
+			continue
+		}
+		out = append(out, v)
+	}
+	return out, sc.Err()
+}
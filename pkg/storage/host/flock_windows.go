@@ -0,0 +1,67 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package host
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+var errFileLocked = errors.New("file is locked by another process")
+
+// lockOverlapped locks a byte far beyond the end of the file so that the
+// lease details stored in the file can still be read by the other processes
+func lockOverlapped() *windows.Overlapped {
+	return &windows.Overlapped{OffsetHigh: 0x7fffffff}
+}
+
+func tryLockFile(f *os.File) error {
+	ol := lockOverlapped()
+	if err := windows.LockFileEx(
+		windows.Handle(f.Fd()),
+		windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY,
+		0, 1, 0, ol,
+	); err != nil {
+		if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+			return errFileLocked
+		}
+		return err
+	}
+	return nil
+}
+
+// lockFile waits till the exclusive lock on the file is acquired
+func lockFile(f *os.File) error {
+	ol := lockOverlapped()
+	return windows.LockFileEx(
+		windows.Handle(f.Fd()),
+		windows.LOCKFILE_EXCLUSIVE_LOCK,
+		0, 1, 0, ol,
+	)
+}
+
+// syncDir is a no-op as directories cannot be opened for syncing on windows
+func syncDir(_ string) error {
+	return nil
+}
+
+func unlockFile(f *os.File) error {
+	ol := lockOverlapped()
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, 1, 0, ol)
+}
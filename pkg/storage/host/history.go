@@ -0,0 +1,182 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package host
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"github.com/ksctl/ksctl/v2/pkg/storage/encryption"
+)
+
+const subDirHistory = "history"
+
+// recordRevision keeps a copy of the state just written under the history directory of the cluster
+// and removes the revisions which are older than the ones to be kept
+func (s *Store) recordRevision(dirPath string, doc *statefile.StorageDocument) error {
+	historyPath := filepath.Join(dirPath, subDirHistory)
+	if err := os.MkdirAll(historyPath, dirPerm); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(storage.NewStateRevision(s.writer, doc))
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(historyPath, fmt.Sprintf("%d.json", doc.Revision)), data, filePerm); err != nil {
+		return err
+	}
+
+	revisions, err := listRevisionFiles(historyPath)
+	if err != nil {
+		return err
+	}
+	for _, rev := range revisions {
+		if rev <= doc.Revision-int64(consts.CounterMaxStateRevisions) {
+			if err := os.Remove(filepath.Join(historyPath, fmt.Sprintf("%d.json", rev))); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// listRevisionFiles returns the revisions present in the history directory, newest first
+func listRevisionFiles(historyPath string) ([]int64, error) {
+	files, err := os.ReadDir(historyPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var revisions []int64
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		rev, err := strconv.ParseInt(strings.TrimSuffix(f.Name(), ".json"), 10, 64)
+		if err != nil {
+			continue
+		}
+		revisions = append(revisions, rev)
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i] > revisions[j]
+	})
+	return revisions, nil
+}
+
+func readRevisionFile(loc string) (*storage.StateRevision, error) {
+	data, err := os.ReadFile(loc)
+	if err != nil {
+		return nil, err
+	}
+	var v *storage.StateRevision
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (s *Store) historyPath() (string, error) {
+	dirPath, err := s.genOsClusterPath(s.cloudProvider, s.clusterType, s.clusterName+" "+s.region, subDirHistory)
+	if err != nil {
+		return "", ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to gen clusterpath in host", "Reason", err),
+		)
+	}
+	return dirPath, nil
+}
+
+func (s *Store) ListRevisions() ([]*storage.StateRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	historyPath, err := s.historyPath()
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := listRevisionFiles(historyPath)
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to list the revisions in host", "Reason", err),
+		)
+	}
+
+	var out []*storage.StateRevision
+	for _, rev := range revisions {
+		v, err := readRevisionFile(filepath.Join(historyPath, fmt.Sprintf("%d.json", rev)))
+		if err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				s.l.NewError(s.ctx, "failed to read the revision in host", "revision", rev, "Reason", err),
+			)
+		}
+		v.Document = nil
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func (s *Store) ReadRevision(revision int64) (*storage.StateRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	historyPath, err := s.historyPath()
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := readRevisionFile(filepath.Join(historyPath, fmt.Sprintf("%d.json", revision)))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrNoMatchingRecordsFound,
+				s.l.NewError(s.ctx, "revision not present", "revision", revision),
+			)
+		}
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to read the revision in host", "revision", revision, "Reason", err),
+		)
+	}
+
+	if err := encryption.OpenDocument(s.enc, v.Document); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to decrypt the state", "Reason", err),
+		)
+	}
+	return v, nil
+}
@@ -16,6 +16,8 @@ package host
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"os"
@@ -23,9 +25,12 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/ksctl/ksctl/v2/migrations"
 	"github.com/ksctl/ksctl/v2/pkg/config"
 	"github.com/ksctl/ksctl/v2/pkg/logger"
 	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"github.com/ksctl/ksctl/v2/pkg/storage/encryption"
 
 	"github.com/ksctl/ksctl/v2/pkg/consts"
 	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
@@ -48,17 +53,66 @@ type Store struct {
 	region        string
 	mu            *sync.RWMutex
 	wg            *sync.WaitGroup
+
+	// etags holds the checksum of the state file last seen by this store
+	// so that Write can detect the file being changed behind its back
+	etags  map[string]string
+	etagMu *sync.Mutex
+
+	// locks holds the lock files of the leases acquired by this store
+	locks   map[string]*os.File
+	locksMu *sync.Mutex
+
+	// enc seals the secrets of the state at rest, nil keeps the state in plaintext
+	enc encryption.Encrypter
+
+	// writer is recorded along every revision of the state
+	writer string
+
+	// noUpgrade hands out the documents as stored, set on the copy used while upgrading them in bulk
+	noUpgrade bool
 }
 
 func NewClient(parentCtx context.Context, _log logger.Logger) *Store {
 	return &Store{
-		ctx: context.WithValue(parentCtx, consts.KsctlModuleNameKey, string(consts.StoreLocal)),
-		l:   _log,
-		mu:  &sync.RWMutex{},
-		wg:  &sync.WaitGroup{},
+		ctx:     context.WithValue(parentCtx, consts.KsctlModuleNameKey, string(consts.StoreLocal)),
+		l:       _log,
+		mu:      &sync.RWMutex{},
+		wg:      &sync.WaitGroup{},
+		etags:   make(map[string]string),
+		etagMu:  &sync.Mutex{},
+		locks:   make(map[string]*os.File),
+		locksMu: &sync.Mutex{},
+		writer:  storage.WriterFromContext(parentCtx),
 	}
 }
 
+func (s *Store) SetEncrypter(enc encryption.Encrypter) {
+	s.enc = enc
+}
+
+// WithoutSchemaUpgrade returns a copy of the store handing out the documents as stored,
+// the copy shares the connection with the store and is Setup on its own
+func (s *Store) WithoutSchemaUpgrade() storage.Storage {
+	raw := *s
+	raw.noUpgrade = true
+	return &raw
+}
+
+// upgradeDocument brings the document read to the latest schema version, the upgraded document is stored on the next write
+func (s *Store) upgradeDocument(doc *statefile.StorageDocument) error {
+	if s.noUpgrade {
+		return nil
+	}
+	if _, err := migrations.UpgradeDocument(doc); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidVersion,
+			s.l.NewError(s.ctx, "failed to upgrade the state", "Reason", err),
+		)
+	}
+	return nil
+}
+
 func (s *Store) PresentDirectory(_path []string) (loc string, isPresent bool) {
 	loc = filepath.Join(_path...)
 	_, err := os.ReadDir(loc)
@@ -129,20 +183,76 @@ func (s *Store) genOsClusterPath(subDir ...string) (string, error) {
 	return filepath.Join(pathArr...), nil
 }
 
-func reader(loc string) (*statefile.StorageDocument, error) {
+func (s *Store) reader(loc string) (*statefile.StorageDocument, error) {
+	v, _, err := s.readerWithETag(loc)
+	return v, err
+}
+
+// readerWithETag reads the state file, a state file which cannot be parsed is served from its
+// backup if the backup is readable. The file itself is left as it is, only Write replaces it
+// while holding the lock of the state so a concurrent writer is never overwritten
+func (s *Store) readerWithETag(loc string) (*statefile.StorageDocument, string, error) {
 	data, err := os.ReadFile(loc)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	v, err := parseState(data)
+	if err == nil {
+		return v, genETag(data), nil
 	}
 
+	bak, _err := os.ReadFile(loc + backupSuffix)
+	if _err != nil {
+		return nil, "", err
+	}
+	v, _err = parseState(bak)
+	if _err != nil {
+		return nil, "", errors.Join(err, _err)
+	}
+
+	s.l.Warn(s.ctx, "state file is corrupted, using its backup till the next write", "file", loc, "Reason", err)
+	return v, genETag(bak), nil
+}
+
+func parseState(data []byte) (*statefile.StorageDocument, error) {
 	var v *statefile.StorageDocument
 	if err := json.Unmarshal(data, &v); err != nil {
 		return nil, err
 	}
-
+	if v == nil {
+		return nil, errors.New("state file is empty")
+	}
 	return v, nil
 }
 
+func isValidState(data []byte) bool {
+	_, err := parseState(data)
+	return err == nil
+}
+
+func genETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *Store) getETag(loc string) (string, bool) {
+	s.etagMu.Lock()
+	defer s.etagMu.Unlock()
+	v, ok := s.etags[loc]
+	return v, ok
+}
+
+func (s *Store) setETag(loc, etag string) {
+	s.etagMu.Lock()
+	defer s.etagMu.Unlock()
+	if etag == "" {
+		delete(s.etags, loc)
+		return
+	}
+	s.etags[loc] = etag
+}
+
 func (s *Store) Read() (*statefile.StorageDocument, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -168,12 +278,29 @@ func (s *Store) Read() (*statefile.StorageDocument, error) {
 		)
 	}
 	s.l.Debug(s.ctx, "storage.local.Read", "dirPath", dirPath)
-	if v, e := reader(dirPath); e != nil {
+	if v, etag, e := s.readerWithETag(dirPath); e != nil {
+		// the directory is created before the state file, a write cut short leaves it empty
+		if errors.Is(e, os.ErrNotExist) {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrNoMatchingRecordsFound,
+				s.l.NewError(s.ctx, "cluster not present", "Reason", e),
+			)
+		}
 		return nil, ksctlErrors.WrapError(
 			ksctlErrors.ErrInternal,
-			s.l.NewError(s.ctx, "failed to read in host", "Reason", err),
+			s.l.NewError(s.ctx, "failed to read in host", "Reason", e),
 		)
 	} else {
+		s.setETag(dirPath, etag)
+		if err := encryption.OpenDocument(s.enc, v); err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				s.l.NewError(s.ctx, "failed to decrypt the state", "Reason", err),
+			)
+		}
+		if err := s.upgradeDocument(v); err != nil {
+			return nil, err
+		}
 		return v, nil
 	}
 }
@@ -184,6 +311,8 @@ func (s *Store) Write(v *statefile.StorageDocument) error {
 	s.wg.Add(1)
 	defer s.wg.Done()
 
+	migrations.StampDocument(v)
+
 	dirPath, err := s.genOsClusterPath(s.cloudProvider, s.clusterType, s.clusterName+" "+s.region)
 	if err != nil {
 		return ksctlErrors.WrapError(
@@ -218,19 +347,105 @@ func (s *Store) Write(v *statefile.StorageDocument) error {
 	FileLoc = filepath.Join(dirPath, "state.json")
 	s.l.Debug(s.ctx, "storage.local.Write", "FileLoc", FileLoc)
 
-	data, err := json.Marshal(v)
+	unlock, err := s.lockState()
 	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := s.checkStaleWrite(FileLoc, v.Revision); err != nil {
+		return err
+	}
+
+	expectedRevision := v.Revision
+	v.Revision = expectedRevision + 1
+
+	sealed, err := encryption.SealDocument(s.enc, v)
+	if err != nil {
+		v.Revision = expectedRevision
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to encrypt the state", "Reason", err),
+		)
+	}
+
+	data, err := json.Marshal(sealed)
+	if err != nil {
+		v.Revision = expectedRevision
 		return ksctlErrors.WrapError(
 			ksctlErrors.ErrInternal,
 			s.l.NewError(s.ctx, "failed to serialize state", "Reason", err),
 		)
 	}
-	if err := os.WriteFile(FileLoc, data, filePerm); err != nil {
+	if err := backupFile(FileLoc, isValidState); err != nil {
+		v.Revision = expectedRevision
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to backup the state in host", "Reason", err),
+		)
+	}
+	if err := writeFileAtomic(FileLoc, data, filePerm); err != nil {
+		v.Revision = expectedRevision
 		return ksctlErrors.WrapError(
 			ksctlErrors.ErrInternal,
 			s.l.NewError(s.ctx, "failed to write in host", "Reason", err),
 		)
 	}
+	s.setETag(FileLoc, genETag(data))
+
+	if err := s.recordRevision(dirPath, sealed); err != nil {
+		s.l.Warn(s.ctx, "failed to record the state revision", "revision", v.Revision, "Reason", err)
+	}
+	return nil
+}
+
+// lockState serializes the writers of the state file of the cluster across processes.
+// The lock file lives outside of the cluster directory as the directory gets removed on delete
+func (s *Store) lockState() (func(), error) {
+	dirPath, err := s.genOsClusterPath(subDirLocks, s.cloudProvider, s.clusterType)
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to gen lockpath in host", "Reason", err),
+		)
+	}
+	unlock, err := lockPath(filepath.Join(dirPath, s.clusterName+" "+s.region+".state.lock"))
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to lock the state file", "Reason", err),
+		)
+	}
+	return unlock, nil
+}
+
+// checkStaleWrite makes sure the state on disk is the one the caller is based on,
+// the revision has to match and the file must not have changed since it was last seen
+func (s *Store) checkStaleWrite(loc string, revision int64) error {
+	current, etag, err := s.readerWithETag(loc)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to read in host", "Reason", err),
+		)
+	}
+
+	if seen, ok := s.getETag(loc); ok && seen != etag {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrStaleStateWrite,
+			s.l.NewError(s.ctx, "state file got modified since it was last read", "file", loc),
+		)
+	}
+
+	if current.Revision != revision {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrStaleStateWrite,
+			s.l.NewError(s.ctx, "state revision mismatch", "stored", current.Revision, "got", revision),
+		)
+	}
 	return nil
 }
 
@@ -276,12 +491,19 @@ func (s *Store) DeleteCluster() error {
 		)
 	}
 
+	unlock, err := s.lockState()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	if err := os.RemoveAll(dirPath); err != nil {
 		return ksctlErrors.WrapError(
 			ksctlErrors.ErrInternal,
 			s.l.NewError(s.ctx, "failed to perform complete clenup some directories are left behind", "Reason", err),
 		)
 	}
+	s.setETag(filepath.Join(dirPath, "state.json"), "")
 	return nil
 }
 
@@ -339,6 +561,42 @@ func (s *Store) GetOneOrMoreClusters(filter map[consts.KsctlSearchFilter]string)
 	s.wg.Add(1)
 	defer s.wg.Done()
 
+	if err := storage.ValidateFilters(filter); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			s.l.NewError(s.ctx, "invalid filters", "Reason", err),
+		)
+	}
+	return s.getClusters(filter)
+}
+
+func (s *Store) QueryClusters(query storage.ClusterQuery) (*storage.ClusterPage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	if err := query.Validate(); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			s.l.NewError(s.ctx, "invalid query", "Reason", err),
+		)
+	}
+
+	clusters, err := s.getClusters(query.Filters)
+	if err != nil {
+		return nil, err
+	}
+	var docs []*statefile.StorageDocument
+	for _, v := range clusters {
+		docs = append(docs, v...)
+	}
+	return storage.QueryDocuments(docs, query), nil
+}
+
+// getClusters reads the state files below the directories of the cloud and cluster type filters and
+// keeps the clusters matching the rest of the filters, the host has no index so every state file is read
+func (s *Store) getClusters(filter map[consts.KsctlSearchFilter]string) (map[consts.KsctlClusterType][]*statefile.StorageDocument, error) {
 	clusterType := filter[consts.ClusterType]
 	cloud := filter[consts.Cloud]
 
@@ -383,7 +641,14 @@ func (s *Store) GetOneOrMoreClusters(filter map[consts.KsctlSearchFilter]string)
 				return nil, err
 			}
 
-			clustersInfo[consts.KsctlClusterType(clusterType)] = append(clustersInfo[consts.KsctlClusterType(clusterType)], v...)
+			var matched []*statefile.StorageDocument
+			for _, doc := range v {
+				if storage.MatchesFilters(filter, doc) {
+					matched = append(matched, doc)
+				}
+			}
+
+			clustersInfo[consts.KsctlClusterType(clusterType)] = append(clustersInfo[consts.KsctlClusterType(clusterType)], matched...)
 		}
 	}
 
@@ -394,13 +659,25 @@ func (s *Store) getClustersInfo(locs []string) ([]*statefile.StorageDocument, er
 	var data []*statefile.StorageDocument
 
 	for _, loc := range locs {
-		v, err := reader(loc)
+		v, err := s.reader(loc)
 		if err != nil && !errors.Is(err, os.ErrNotExist) {
 			return nil, ksctlErrors.WrapError(
 				ksctlErrors.ErrInternal,
 				s.l.NewError(s.ctx, "failed to read in host", "Reason", err),
 			)
 		}
+		if v == nil {
+			continue
+		}
+		if err := encryption.OpenDocument(s.enc, v); err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				s.l.NewError(s.ctx, "failed to decrypt the state", "Reason", err),
+			)
+		}
+		if err := s.upgradeDocument(v); err != nil {
+			return nil, err
+		}
 		data = append(data, v)
 	}
 
@@ -21,14 +21,20 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"github.com/ksctl/ksctl/v2/pkg/storage/encryption"
+	"github.com/ksctl/ksctl/v2/pkg/storage/storagetest"
 
 	"gotest.tools/v3/assert"
 
 	"github.com/gookit/goutil/dump"
 	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
 	"github.com/ksctl/ksctl/v2/pkg/logger"
 )
 
@@ -148,6 +154,365 @@ func TestStore_RWD(t *testing.T) {
 	assert.NilError(t, err, fmt.Sprintf("Error shouln't happen on deleting cluster info: %v", err))
 }
 
+func TestStore_StaleWrite(t *testing.T) {
+	storagetest.StaleWrite(t, db)
+}
+
+func TestStore_Lock(t *testing.T) {
+	if err := db.Setup(consts.CloudAzure, "region", "locked", consts.ClusterTypeSelfMang); err != nil {
+		t.Fatal(err)
+	}
+	locker := db
+
+	lease, err := locker.Lock("alice", time.Minute)
+	assert.NilError(t, err)
+
+	_, err = locker.Lock("bob", time.Minute)
+	assert.Check(t, ksctlErrors.IsClusterLocked(err), fmt.Sprintf("expected cluster locked error, got: %v", err))
+	assert.ErrorContains(t, err, "locked by alice")
+
+	// the lease keeps to its cluster while the store is Setup for another one
+	if err := db.Setup(consts.CloudAws, "region", "other", consts.ClusterTypeMang); err != nil {
+		t.Fatal(err)
+	}
+	assert.NilError(t, locker.Renew(lease, 2*time.Minute))
+	assert.NilError(t, locker.Unlock(lease))
+	if err := db.Setup(consts.CloudAzure, "region", "locked", consts.ClusterTypeSelfMang); err != nil {
+		t.Fatal(err)
+	}
+
+	lease, err = locker.Lock("bob", time.Minute)
+	assert.NilError(t, err)
+	assert.NilError(t, locker.Unlock(lease))
+}
+
+func TestStore_Encryption(t *testing.T) {
+	// Reseal rewrites every cluster of the store, so it gets a directory of its own
+	db := NewClient(context.WithValue(parentCtx, consts.KsctlCustomDirLoc, t.TempDir()), parentLogger)
+
+	keyring, err := encryption.NewLocalKeyring(filepath.Join(t.TempDir(), "keyfile.json"))
+	assert.NilError(t, err)
+	db.SetEncrypter(encryption.NewEnvelopeEncrypter(parentCtx, keyring))
+
+	if err := db.Setup(consts.CloudAzure, "region", "sealed", consts.ClusterTypeSelfMang); err != nil {
+		t.Fatal(err)
+	}
+
+	fakeData := &statefile.StorageDocument{
+		Region:            "region",
+		ClusterName:       "sealed",
+		ClusterType:       "selfmanaged",
+		InfraProvider:     consts.CloudAzure,
+		ClusterKubeConfig: "kubeconfig-secret",
+		SSHKeyPair:        statefile.SSHKeyPairState{PrivateKey: "private-secret"},
+	}
+	assert.NilError(t, db.Write(fakeData))
+	assert.Equal(t, fakeData.ClusterKubeConfig, "kubeconfig-secret")
+
+	loc, err := db.genOsClusterPath(string(consts.CloudAzure), string(consts.ClusterTypeSelfMang), "sealed region", "state.json")
+	assert.NilError(t, err)
+	raw, err := os.ReadFile(loc)
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(string(raw), "kubeconfig-secret"))
+	assert.Assert(t, !strings.Contains(string(raw), "private-secret"))
+
+	got, err := db.Read()
+	assert.NilError(t, err)
+	assert.Equal(t, got.ClusterKubeConfig, "kubeconfig-secret")
+	assert.Equal(t, got.SSHKeyPair.PrivateKey, "private-secret")
+
+	_, err = keyring.Rotate()
+	assert.NilError(t, err)
+	assert.NilError(t, encryption.Reseal(db))
+
+	if err := db.Setup(consts.CloudAzure, "region", "sealed", consts.ClusterTypeSelfMang); err != nil {
+		t.Fatal(err)
+	}
+	got, err = db.Read()
+	assert.NilError(t, err)
+	assert.Equal(t, got.ClusterKubeConfig, "kubeconfig-secret")
+	assert.Equal(t, got.Revision, int64(2))
+
+	assert.NilError(t, db.DeleteCluster())
+}
+
+func TestStore_CrashRecovery(t *testing.T) {
+	db := NewClient(context.WithValue(parentCtx, consts.KsctlCustomDirLoc, t.TempDir()), parentLogger)
+	if err := db.Setup(consts.CloudAzure, "region", "crash", consts.ClusterTypeSelfMang); err != nil {
+		t.Fatal(err)
+	}
+
+	fakeData := &statefile.StorageDocument{
+		Region:            "region",
+		ClusterName:       "crash",
+		ClusterType:       "selfmanaged",
+		InfraProvider:     consts.CloudAzure,
+		ClusterKubeConfig: "v1",
+	}
+	assert.NilError(t, db.Write(fakeData))
+	fakeData.ClusterKubeConfig = "v2"
+	assert.NilError(t, db.Write(fakeData))
+
+	loc, err := db.genOsClusterPath(string(consts.CloudAzure), string(consts.ClusterTypeSelfMang), "crash region", "state.json")
+	assert.NilError(t, err)
+
+	leftovers, err := filepath.Glob(filepath.Join(filepath.Dir(loc), ".state.json.tmp-*"))
+	assert.NilError(t, err)
+	assert.Equal(t, len(leftovers), 0)
+
+	// a write cut short by the older releases leaves a truncated state file behind
+	assert.NilError(t, os.WriteFile(loc, []byte(`{"cluster_name": "cra`), filePerm))
+
+	got, err := db.Read()
+	assert.NilError(t, err)
+	assert.Equal(t, got.ClusterKubeConfig, "v1")
+	assert.Equal(t, got.Revision, int64(1))
+
+	// the file is only replaced by a writer holding the lock of the state
+	raw, err := os.ReadFile(loc)
+	assert.NilError(t, err)
+	assert.Assert(t, !isValidState(raw))
+
+	got.ClusterKubeConfig = "v3"
+	assert.NilError(t, db.Write(got))
+	assert.Equal(t, got.Revision, int64(2))
+
+	raw, err = os.ReadFile(loc)
+	assert.NilError(t, err)
+	assert.Assert(t, isValidState(raw))
+
+	assert.NilError(t, db.DeleteCluster())
+}
+
+func TestStore_ConcurrentWriters(t *testing.T) {
+	// every store has a lock file descriptor of its own, just like separate processes
+	ctx := context.WithValue(parentCtx, consts.KsctlCustomDirLoc, t.TempDir())
+	const writers, writes = 4, 10
+
+	errCh := make(chan error, writers)
+	successes := make(chan int, writers)
+	for i := 0; i < writers; i++ {
+		go func() {
+			db := NewClient(ctx, parentLogger)
+			if err := db.Setup(consts.CloudAzure, "region", "shared", consts.ClusterTypeSelfMang); err != nil {
+				errCh <- err
+				return
+			}
+			ok := 0
+			for j := 0; j < writes; j++ {
+				cur, err := db.Read()
+				if err != nil {
+					if !ksctlErrors.IsNoMatchingRecordsFound(err) {
+						errCh <- err
+						return
+					}
+					cur = &statefile.StorageDocument{Region: "region", ClusterName: "shared"}
+				}
+				if err := db.Write(cur); err != nil {
+					if ksctlErrors.IsStaleStateWrite(err) || ksctlErrors.IsDuplicateRecords(err) {
+						continue
+					}
+					errCh <- err
+					return
+				}
+				ok++
+			}
+			successes <- ok
+			errCh <- nil
+		}()
+	}
+
+	total := 0
+	for i := 0; i < writers; i++ {
+		assert.NilError(t, <-errCh)
+	}
+	for i := 0; i < writers; i++ {
+		total += <-successes
+	}
+
+	db := NewClient(ctx, parentLogger)
+	if err := db.Setup(consts.CloudAzure, "region", "shared", consts.ClusterTypeSelfMang); err != nil {
+		t.Fatal(err)
+	}
+	got, err := db.Read()
+	assert.NilError(t, err)
+	assert.Equal(t, got.Revision, int64(total))
+}
+
+func TestStore_History(t *testing.T) {
+	storagetest.History(t, db)
+}
+
+func TestStore_Audit(t *testing.T) {
+	storagetest.Audit(t, NewClient(context.WithValue(parentCtx, consts.KsctlCustomDirLoc, t.TempDir()), parentLogger))
+}
+
+func nextWatchEvent(t *testing.T, ch <-chan storage.WatchEvent) storage.WatchEvent {
+	t.Helper()
+	select {
+	case ev, ok := <-ch:
+		assert.Assert(t, ok, "watch got closed")
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("no watch event got delivered")
+	}
+	return storage.WatchEvent{}
+}
+
+func TestStore_Watch(t *testing.T) {
+	store := NewClient(context.WithValue(parentCtx, consts.KsctlCustomDirLoc, t.TempDir()), parentLogger)
+
+	write := func(cloud consts.KsctlCloud, name string, doc *statefile.StorageDocument) {
+		t.Helper()
+		assert.NilError(t, store.Setup(cloud, "region", name, consts.ClusterTypeMang))
+		if doc == nil {
+			doc = &statefile.StorageDocument{
+				Region:        "region",
+				ClusterName:   name,
+				ClusterType:   string(consts.ClusterTypeMang),
+				InfraProvider: cloud,
+			}
+		}
+		assert.NilError(t, store.Write(doc))
+	}
+
+	write(consts.CloudAzure, "present", nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := store.Watch(ctx, map[consts.KsctlSearchFilter]string{"cloud": "azure"})
+	assert.NilError(t, err)
+
+	ev := nextWatchEvent(t, events)
+	assert.Equal(t, ev.Type, storage.WatchEventAdded)
+	assert.Equal(t, ev.Document.ClusterName, "present")
+
+	// not selected by the filters
+	write(consts.CloudAws, "ignored", nil)
+
+	doc := &statefile.StorageDocument{
+		Region:        "region",
+		ClusterName:   "fresh",
+		ClusterType:   string(consts.ClusterTypeMang),
+		InfraProvider: consts.CloudAzure,
+		PlatformSpec:  statefile.PlatformSpec{State: statefile.Creating},
+	}
+	write(consts.CloudAzure, "fresh", doc)
+	ev = nextWatchEvent(t, events)
+	assert.Equal(t, ev.Type, storage.WatchEventAdded)
+	assert.Equal(t, ev.Document.ClusterName, "fresh")
+
+	doc.PlatformSpec.State = statefile.Running
+	write(consts.CloudAzure, "fresh", doc)
+	ev = nextWatchEvent(t, events)
+	assert.Equal(t, ev.Type, storage.WatchEventUpdated)
+	assert.Equal(t, ev.Document.PlatformSpec.State, statefile.Running)
+	assert.Equal(t, ev.Document.Revision, doc.Revision)
+
+	assert.NilError(t, store.DeleteCluster())
+	ev = nextWatchEvent(t, events)
+	assert.Equal(t, ev.Type, storage.WatchEventDeleted)
+	assert.Equal(t, ev.Document.ClusterName, "fresh")
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestQueryClusters(t *testing.T) {
+	store := NewClient(context.WithValue(parentCtx, consts.KsctlCustomDirLoc, t.TempDir()), parentLogger)
+
+	version := func(v string) *string { return &v }
+	fixtures := []*statefile.StorageDocument{
+		{
+			ClusterName: "alpha", Region: "eastus", ClusterType: string(consts.ClusterTypeMang), InfraProvider: consts.CloudAzure,
+			Versions:     statefile.ComponentVersions{Aks: version("1.30.4")},
+			PlatformSpec: statefile.PlatformSpec{State: statefile.Running, Owner: "alice", Team: "payments", Labels: map[string]string{"env": "prod", "cost-center": "42"}},
+		},
+		{
+			ClusterName: "bravo", Region: "us-east-1", ClusterType: string(consts.ClusterTypeMang), InfraProvider: consts.CloudAws,
+			Versions:     statefile.ComponentVersions{Eks: version("1.31")},
+			PlatformSpec: statefile.PlatformSpec{State: statefile.Creating, Owner: "bob", Team: "payments", Labels: map[string]string{"env": "dev"}},
+		},
+		{
+			ClusterName: "charlie", Region: "us-east-1", ClusterType: string(consts.ClusterTypeSelfMang), InfraProvider: consts.CloudAws,
+			BootstrapProvider: consts.K8sK3s,
+			Versions:          statefile.ComponentVersions{K3s: version("v1.30.2+k3s1")},
+			PlatformSpec:      statefile.PlatformSpec{State: statefile.Running, Owner: "alice", Team: "search", Labels: map[string]string{"env": "prod"}},
+		},
+		{
+			ClusterName: "delta", Region: "eastus", ClusterType: string(consts.ClusterTypeSelfMang), InfraProvider: consts.CloudAzure,
+			BootstrapProvider: consts.K8sKubeadm,
+			Versions:          statefile.ComponentVersions{Kubeadm: version("1.29.8")},
+			PlatformSpec:      statefile.PlatformSpec{State: statefile.Running, Owner: "carol", Team: "search"},
+		},
+	}
+	for _, doc := range fixtures {
+		assert.NilError(t, store.Setup(doc.InfraProvider, doc.Region, doc.ClusterName, consts.KsctlClusterType(doc.ClusterType)))
+		assert.NilError(t, store.Write(doc))
+	}
+
+	names := func(docs []*statefile.StorageDocument) []string {
+		var out []string
+		for _, d := range docs {
+			out = append(out, d.ClusterName)
+		}
+		return out
+	}
+
+	for _, tc := range []struct {
+		filters map[consts.KsctlSearchFilter]string
+		want    []string
+	}{
+		{map[consts.KsctlSearchFilter]string{}, []string{"alpha", "bravo", "charlie", "delta"}},
+		{map[consts.KsctlSearchFilter]string{consts.State: string(statefile.Running)}, []string{"alpha", "charlie", "delta"}},
+		{map[consts.KsctlSearchFilter]string{consts.Owner: "alice", consts.Team: "search"}, []string{"charlie"}},
+		{map[consts.KsctlSearchFilter]string{consts.BootstrapProvider: string(consts.K8sKubeadm)}, []string{"delta"}},
+		{map[consts.KsctlSearchFilter]string{consts.KubernetesVersion: "1.30"}, []string{"alpha", "charlie"}},
+		{map[consts.KsctlSearchFilter]string{consts.Labels: "env=prod"}, []string{"alpha", "charlie"}},
+		{map[consts.KsctlSearchFilter]string{consts.Labels: "env=prod,cost-center"}, []string{"alpha"}},
+		{map[consts.KsctlSearchFilter]string{consts.Cloud: string(consts.CloudAws), consts.Labels: "env"}, []string{"bravo", "charlie"}},
+	} {
+		page, err := store.QueryClusters(storage.ClusterQuery{Filters: tc.filters})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, names(page.Clusters), tc.want)
+		assert.Equal(t, page.Total, len(tc.want))
+
+		m, err := store.GetOneOrMoreClusters(tc.filters)
+		assert.NilError(t, err)
+		assert.Equal(t, len(m[consts.ClusterTypeMang])+len(m[consts.ClusterTypeSelfMang]), len(tc.want), fmt.Sprintf("filters: %v", tc.filters))
+	}
+
+	page, err := store.QueryClusters(storage.ClusterQuery{SortBy: storage.SortByOwner, Descending: true})
+	assert.NilError(t, err)
+	// the ties are ordered by the identity of the cluster in the same direction
+	assert.DeepEqual(t, names(page.Clusters), []string{"delta", "bravo", "alpha", "charlie"})
+
+	var paged []string
+	query := storage.ClusterQuery{SortBy: storage.SortByRegion, Limit: 3}
+	for {
+		page, err := store.QueryClusters(query)
+		assert.NilError(t, err)
+		assert.Equal(t, page.Total, 4)
+		paged = append(paged, names(page.Clusters)...)
+		if !page.HasMore() {
+			break
+		}
+		query.Offset += query.Limit
+	}
+	assert.DeepEqual(t, paged, []string{"alpha", "delta", "bravo", "charlie"})
+
+	_, err = store.QueryClusters(storage.ClusterQuery{SortBy: "size"})
+	assert.Check(t, ksctlErrors.IsInvalidUserInput(err), fmt.Sprintf("expected invalid user input error, got: %v", err))
+	_, err = store.GetOneOrMoreClusters(map[consts.KsctlSearchFilter]string{consts.Labels: "=prod"})
+	assert.Check(t, ksctlErrors.IsInvalidUserInput(err), fmt.Sprintf("expected invalid user input error, got: %v", err))
+
+	for _, doc := range fixtures {
+		assert.NilError(t, store.Setup(doc.InfraProvider, doc.Region, doc.ClusterName, consts.KsctlClusterType(doc.ClusterType)))
+		assert.NilError(t, store.DeleteCluster())
+	}
+}
+
 func TestGetClusterInfo(t *testing.T) {
 
 	t.Run("Setup some demo clusters", func(t *testing.T) {
@@ -0,0 +1,180 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package host
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+)
+
+const subDirLocks = "locks"
+
+// Lock takes an exclusive flock on the lock file of the cluster. The flock is released by
+// the kernel if the process dies, so the ttl is only informational for the host store
+func (s *Store) Lock(owner string, ttl time.Duration) (*storage.ClusterLease, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dirPath, err := s.genOsClusterPath(subDirLocks, s.cloudProvider, s.clusterType)
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to gen lockpath in host", "Reason", err),
+		)
+	}
+	if err := os.MkdirAll(dirPath, dirPerm); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failure in creating directories", "Reason", err),
+		)
+	}
+
+	loc := filepath.Join(dirPath, s.clusterName+" "+s.region+".lock")
+	s.l.Debug(s.ctx, "storage.local.Lock", "loc", loc)
+
+	f, err := os.OpenFile(loc, os.O_RDWR|os.O_CREATE, filePerm)
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to open the lock file", "Reason", err),
+		)
+	}
+
+	if err := tryLockFile(f); err != nil {
+		defer func() { _ = f.Close() }()
+		if errors.Is(err, errFileLocked) {
+			if holder, _err := readLease(f); _err == nil {
+				return nil, ksctlErrors.WrapError(
+					ksctlErrors.ErrClusterLocked,
+					s.l.NewError(s.ctx, "cluster is "+holder.String()),
+				)
+			}
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrClusterLocked,
+				s.l.NewError(s.ctx, "cluster is locked by another process"),
+			)
+		}
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to lock the lock file", "Reason", err),
+		)
+	}
+
+	lease, err := storage.NewClusterLease(owner, ttl)
+	if err != nil {
+		_ = unlockFile(f)
+		_ = f.Close()
+		return nil, err
+	}
+
+	if err := writeLease(f, lease); err != nil {
+		_ = unlockFile(f)
+		_ = f.Close()
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to write the lease", "Reason", err),
+		)
+	}
+
+	s.locksMu.Lock()
+	s.locks[lease.ID] = f
+	s.locksMu.Unlock()
+
+	return lease, nil
+}
+
+func (s *Store) Renew(lease *storage.ClusterLease, ttl time.Duration) error {
+	s.locksMu.Lock()
+	defer s.locksMu.Unlock()
+
+	f, ok := s.locks[lease.ID]
+	if !ok {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidOperation,
+			s.l.NewError(s.ctx, "lease is not held by this store", "id", lease.ID),
+		)
+	}
+
+	lease.ExpiresAt = time.Now().UTC().Add(ttl)
+	if err := writeLease(f, lease); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to renew the lease", "Reason", err),
+		)
+	}
+	return nil
+}
+
+func (s *Store) Unlock(lease *storage.ClusterLease) error {
+	s.locksMu.Lock()
+	defer s.locksMu.Unlock()
+
+	f, ok := s.locks[lease.ID]
+	if !ok {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidOperation,
+			s.l.NewError(s.ctx, "lease is not held by this store", "id", lease.ID),
+		)
+	}
+	delete(s.locks, lease.ID)
+
+	// the lock file is never removed, as some other process might have it
+	// open already and would end up locking an unlinked file
+	_ = f.Truncate(0)
+	if err := unlockFile(f); err != nil {
+		_ = f.Close()
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to unlock the lock file", "Reason", err),
+		)
+	}
+	return f.Close()
+}
+
+func readLease(f *os.File) (*storage.ClusterLease, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	raw, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	var lease *storage.ClusterLease
+	if err := json.Unmarshal(raw, &lease); err != nil {
+		return nil, err
+	}
+	return lease, nil
+}
+
+func writeLease(f *os.File, lease *storage.ClusterLease) error {
+	raw, err := json.Marshal(lease)
+	if err != nil {
+		return err
+	}
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.WriteAt(raw, 0); err != nil {
+		return err
+	}
+	return f.Sync()
+}
@@ -0,0 +1,272 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package host
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"github.com/ksctl/ksctl/v2/pkg/storage/encryption"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+)
+
+const stateFile = "state.json"
+
+// watcher follows the state tree <root>/<cloud>/<cluster type>/<name region>/state.json,
+// fsnotify is not recursive so every directory of the tree gets a watch of its own
+type watcher struct {
+	s       *Store
+	fs      *fsnotify.Watcher
+	root    string
+	clouds  map[string]bool
+	types   map[string]bool
+	tracker *storage.WatchTracker
+}
+
+func (s *Store) Watch(ctx context.Context, filters map[consts.KsctlSearchFilter]string) (<-chan storage.WatchEvent, error) {
+	root, err := s.genOsClusterPath()
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to gen clusterpath in host", "Reason", err),
+		)
+	}
+	if err := os.MkdirAll(root, dirPerm); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to create the state directory", "Reason", err),
+		)
+	}
+
+	fs, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to create the file watcher", "Reason", err),
+		)
+	}
+
+	w := &watcher{
+		s:       s,
+		fs:      fs,
+		root:    root,
+		clouds:  make(map[string]bool),
+		types:   make(map[string]bool),
+		tracker: storage.NewWatchTracker(filters),
+	}
+	switch v := filters[consts.Cloud]; v {
+	case string(consts.CloudAll), "":
+		w.clouds[string(consts.CloudAws)] = true
+		w.clouds[string(consts.CloudAzure)] = true
+		w.clouds[string(consts.CloudLocal)] = true
+	default:
+		w.clouds[v] = true
+	}
+	switch v := filters[consts.ClusterType]; v {
+	case "":
+		w.types[string(consts.ClusterTypeMang)] = true
+		w.types[string(consts.ClusterTypeSelfMang)] = true
+	default:
+		w.types[v] = true
+	}
+
+	// the directories get watched before they are scanned so a state written in between is not missed
+	files, err := w.addTree(root)
+	if err != nil {
+		_ = fs.Close()
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			s.l.NewError(s.ctx, "failed to watch the state directory", "Reason", err),
+		)
+	}
+
+	events := make(chan storage.WatchEvent)
+	go w.run(ctx, events, files)
+	return events, nil
+}
+
+// parts splits the path into its elements below the root of the state tree
+func (w *watcher) parts(loc string) []string {
+	rel, err := filepath.Rel(w.root, loc)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+	return strings.Split(rel, string(filepath.Separator))
+}
+
+// wanted reports if the path is part of the tree selected by the filters
+func (w *watcher) wanted(parts []string) bool {
+	switch {
+	case len(parts) >= 1 && !w.clouds[parts[0]]:
+		return false
+	case len(parts) >= 2 && !w.types[parts[1]]:
+		return false
+	case len(parts) == 4:
+		return parts[3] == stateFile
+	}
+	return len(parts) <= 3
+}
+
+// addTree watches the directory along with the ones below it and returns the state files found
+func (w *watcher) addTree(dir string) ([]string, error) {
+	parts := w.parts(dir)
+	if !w.wanted(parts) || len(parts) > 3 {
+		return nil, nil
+	}
+	if err := w.fs.Add(dir); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var files []string
+	for _, e := range entries {
+		loc := filepath.Join(dir, e.Name())
+		switch {
+		case e.IsDir() && len(parts) < 3:
+			v, err := w.addTree(loc)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, v...)
+		case !e.IsDir() && len(parts) == 3 && e.Name() == stateFile:
+			files = append(files, loc)
+		}
+	}
+	return files, nil
+}
+
+func (w *watcher) run(ctx context.Context, events chan<- storage.WatchEvent, files []string) {
+	defer close(events)
+	defer func() { _ = w.fs.Close() }()
+
+	for _, loc := range files {
+		if !w.reconcile(ctx, events, loc) {
+			return
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+			storage.SendWatchEvent(ctx, events, storage.WatchEvent{
+				Type: storage.WatchEventError,
+				Err: ksctlErrors.WrapError(
+					ksctlErrors.ErrInternal,
+					w.s.l.NewError(w.s.ctx, "failed to watch the state directory", "Reason", err),
+				),
+			})
+			return
+
+		case ev, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			if !w.handle(ctx, events, ev) {
+				return
+			}
+		}
+	}
+}
+
+func (w *watcher) handle(ctx context.Context, events chan<- storage.WatchEvent, ev fsnotify.Event) bool {
+	parts := w.parts(ev.Name)
+	if !w.wanted(parts) {
+		return true
+	}
+
+	if len(parts) == 4 {
+		return w.reconcile(ctx, events, ev.Name)
+	}
+
+	if ev.Has(fsnotify.Create) {
+		files, err := w.addTree(ev.Name)
+		if err != nil {
+			w.s.l.Warn(w.s.ctx, "failed to watch the directory", "dir", ev.Name, "Reason", err)
+		}
+		for _, loc := range files {
+			if !w.reconcile(ctx, events, loc) {
+				return false
+			}
+		}
+	}
+	if ev.Has(fsnotify.Remove) || ev.Has(fsnotify.Rename) {
+		// the state files below a directory which is gone don't always report their own removal
+		prefix := ev.Name + string(filepath.Separator)
+		for _, loc := range w.tracker.Keys() {
+			if strings.HasPrefix(loc, prefix) && !w.reconcile(ctx, events, loc) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// reconcile compares the state file with the one last seen and sends the resulting event
+func (w *watcher) reconcile(ctx context.Context, events chan<- storage.WatchEvent, loc string) bool {
+	data, err := os.ReadFile(loc)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			w.s.l.Warn(w.s.ctx, "failed to read the state file", "file", loc, "Reason", err)
+			return true
+		}
+		if ev, ok := w.tracker.Remove(loc); ok {
+			return storage.SendWatchEvent(ctx, events, ev)
+		}
+		return true
+	}
+
+	// a state file which cannot be parsed is left for the store to recover from the backup
+	v, err := parseState(data)
+	if err != nil {
+		w.s.l.Debug(w.s.ctx, "skipping the state file which cannot be parsed", "file", loc, "Reason", err)
+		return true
+	}
+	if err := encryption.OpenDocument(w.s.enc, v); err != nil {
+		w.s.l.Warn(w.s.ctx, "failed to decrypt the state", "file", loc, "Reason", err)
+		return true
+	}
+	if err := w.s.upgradeDocument(v); err != nil {
+		w.s.l.Warn(w.s.ctx, "failed to upgrade the state", "file", loc, "Reason", err)
+		return true
+	}
+
+	if ev, ok := w.tracker.Upsert(loc, v); ok {
+		return storage.SendWatchEvent(ctx, events, ev)
+	}
+	return true
+}
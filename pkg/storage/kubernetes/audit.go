@@ -0,0 +1,140 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"encoding/json"
+
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+const (
+	componentAudit = "audit"
+	labelActor     = "ksctl.com/actor"
+
+	auditEntryKey = "entry"
+)
+
+// helperGenerateAuditName returns the configmap holding a single entry of the trail,
+// the entries are never updated so a configmap per entry keeps the trail append-only
+func helperGenerateAuditName(e *storage.AuditEntry) string {
+	return "ksctl-audit-" + helperSanitizeName(e.ID)
+}
+
+func helperGenerateAuditLabels(e *storage.AuditEntry) map[string]string {
+	return map[string]string{
+		labelManagedBy:   managedByKsctl,
+		labelComponent:   componentAudit,
+		labelCloud:       string(e.Cloud),
+		labelClusterType: string(e.ClusterType),
+		labelClusterName: helperLabelValue(e.ClusterName),
+		labelRegion:      helperLabelValue(e.Region),
+		labelActor:       helperLabelValue(e.Actor),
+	}
+}
+
+// helperGenerateAuditSelector narrows down the entries listed, the label values are
+// sanitized so the entries are matched once more against the query
+func helperGenerateAuditSelector(q storage.AuditQuery) (string, error) {
+	reqs := map[string]string{
+		labelManagedBy: managedByKsctl,
+		labelComponent: componentAudit,
+	}
+	optional := map[string]string{
+		labelCloud:       string(q.Cloud),
+		labelClusterType: string(q.ClusterType),
+		labelClusterName: helperLabelValue(q.ClusterName),
+		labelRegion:      helperLabelValue(q.Region),
+		labelActor:       helperLabelValue(q.Actor),
+	}
+	for k, v := range optional {
+		if len(v) != 0 {
+			reqs[k] = v
+		}
+	}
+
+	selector := labels.NewSelector()
+	for k, v := range reqs {
+		req, err := labels.NewRequirement(k, selection.Equals, []string{v})
+		if err != nil {
+			return "", err
+		}
+		selector = selector.Add(*req)
+	}
+	return selector.String(), nil
+}
+
+func (s *Store) RecordAudit(entry *storage.AuditEntry) error {
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "failed to serialize the audit entry", "Reason", err),
+		)
+	}
+
+	c := generateConfigMap(helperGenerateAuditName(entry), s.namespace)
+	c.Labels = helperGenerateAuditLabels(entry)
+	c.Data = map[string]string{auditEntryKey: string(raw)}
+
+	if _, err := s.clientSet.WriteConfigMap(s.namespace, c, metav1.UpdateOptions{}); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "failed to write the audit configmap", "Reason", err),
+		)
+	}
+	return nil
+}
+
+func (s *Store) QueryAudit(query storage.AuditQuery) ([]*storage.AuditEntry, error) {
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	selector, err := helperGenerateAuditSelector(query)
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "failed to create the label selector", "Reason", err),
+		)
+	}
+
+	configMaps, err := s.clientSet.ListConfigMaps(s.namespace, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "failed to list the audit configmaps", "Reason", err),
+		)
+	}
+
+	entries := make([]*storage.AuditEntry, 0, len(configMaps.Items))
+	for _, c := range configMaps.Items {
+		var v *storage.AuditEntry
+		if err := json.Unmarshal([]byte(c.Data[auditEntryKey]), &v); err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				log.NewError(storeCtx, "unable to deserialize the audit entry", "name", c.Name, "Reason", err),
+			)
+		}
+		entries = append(entries, v)
+	}
+	return storage.FilterAuditEntries(entries, query), nil
+}
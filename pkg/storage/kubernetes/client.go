@@ -17,6 +17,7 @@ package kubernetes
 import (
 	"context"
 
+	coordinationv1 "k8s.io/api/coordination/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/kubernetes"
@@ -24,6 +25,7 @@ import (
 
 	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes/fake"
 )
 
@@ -33,6 +35,19 @@ type ClientSet interface {
 
 	ReadSecret(namespace, name string, opts metav1.GetOptions) (*v1.Secret, error)
 	ReadConfigMap(namespace, name string, opts metav1.GetOptions) (*v1.ConfigMap, error)
+
+	ListSecrets(namespace string, opts metav1.ListOptions) (*v1.SecretList, error)
+	ListConfigMaps(namespace string, opts metav1.ListOptions) (*v1.ConfigMapList, error)
+
+	WatchSecrets(namespace string, opts metav1.ListOptions) (watch.Interface, error)
+
+	DeleteSecret(namespace, name string, opts metav1.DeleteOptions) error
+	DeleteConfigMap(namespace, name string, opts metav1.DeleteOptions) error
+
+	ReadLease(namespace, name string, opts metav1.GetOptions) (*coordinationv1.Lease, error)
+	CreateLease(namespace string, l *coordinationv1.Lease, opts metav1.CreateOptions) (*coordinationv1.Lease, error)
+	UpdateLease(namespace string, l *coordinationv1.Lease, opts metav1.UpdateOptions) (*coordinationv1.Lease, error)
+	DeleteLease(namespace, name string, opts metav1.DeleteOptions) error
 }
 
 type Client struct {
@@ -98,6 +113,42 @@ func (c2 *Client) ReadConfigMap(namespace, name string, opts metav1.GetOptions)
 	return c2.client.CoreV1().ConfigMaps(namespace).Get(c2.ctx, name, opts)
 }
 
+func (c2 *Client) ListSecrets(namespace string, opts metav1.ListOptions) (*v1.SecretList, error) {
+	return c2.client.CoreV1().Secrets(namespace).List(c2.ctx, opts)
+}
+
+func (c2 *Client) ListConfigMaps(namespace string, opts metav1.ListOptions) (*v1.ConfigMapList, error) {
+	return c2.client.CoreV1().ConfigMaps(namespace).List(c2.ctx, opts)
+}
+
+func (c2 *Client) WatchSecrets(namespace string, opts metav1.ListOptions) (watch.Interface, error) {
+	return c2.client.CoreV1().Secrets(namespace).Watch(c2.ctx, opts)
+}
+
+func (c2 *Client) DeleteSecret(namespace, name string, opts metav1.DeleteOptions) error {
+	return c2.client.CoreV1().Secrets(namespace).Delete(c2.ctx, name, opts)
+}
+
+func (c2 *Client) DeleteConfigMap(namespace, name string, opts metav1.DeleteOptions) error {
+	return c2.client.CoreV1().ConfigMaps(namespace).Delete(c2.ctx, name, opts)
+}
+
+func (c2 *Client) ReadLease(namespace, name string, opts metav1.GetOptions) (*coordinationv1.Lease, error) {
+	return c2.client.CoordinationV1().Leases(namespace).Get(c2.ctx, name, opts)
+}
+
+func (c2 *Client) CreateLease(namespace string, l *coordinationv1.Lease, opts metav1.CreateOptions) (*coordinationv1.Lease, error) {
+	return c2.client.CoordinationV1().Leases(namespace).Create(c2.ctx, l, opts)
+}
+
+func (c2 *Client) UpdateLease(namespace string, l *coordinationv1.Lease, opts metav1.UpdateOptions) (*coordinationv1.Lease, error) {
+	return c2.client.CoordinationV1().Leases(namespace).Update(c2.ctx, l, opts)
+}
+
+func (c2 *Client) DeleteLease(namespace, name string, opts metav1.DeleteOptions) error {
+	return c2.client.CoordinationV1().Leases(namespace).Delete(c2.ctx, name, opts)
+}
+
 type FakeClient struct {
 	client *fake.Clientset
 	ctx    context.Context
@@ -144,12 +195,38 @@ func (f *FakeClient) ReadConfigMap(namespace, name string, opts metav1.GetOption
 	return f.client.CoreV1().ConfigMaps(namespace).Get(f.ctx, name, opts)
 }
 
-func (f *FakeClient) DeleteResourcesForTest() error {
-	if err := f.client.
-		CoreV1().
-		ConfigMaps(ksctlNamespace).
-		Delete(f.ctx, ksctlStateName, metav1.DeleteOptions{}); err != nil {
-		return err
-	}
-	return nil
+func (f *FakeClient) ListSecrets(namespace string, opts metav1.ListOptions) (*v1.SecretList, error) {
+	return f.client.CoreV1().Secrets(namespace).List(f.ctx, opts)
+}
+
+func (f *FakeClient) ListConfigMaps(namespace string, opts metav1.ListOptions) (*v1.ConfigMapList, error) {
+	return f.client.CoreV1().ConfigMaps(namespace).List(f.ctx, opts)
+}
+
+func (f *FakeClient) WatchSecrets(namespace string, opts metav1.ListOptions) (watch.Interface, error) {
+	return f.client.CoreV1().Secrets(namespace).Watch(f.ctx, opts)
+}
+
+func (f *FakeClient) DeleteSecret(namespace, name string, opts metav1.DeleteOptions) error {
+	return f.client.CoreV1().Secrets(namespace).Delete(f.ctx, name, opts)
+}
+
+func (f *FakeClient) DeleteConfigMap(namespace, name string, opts metav1.DeleteOptions) error {
+	return f.client.CoreV1().ConfigMaps(namespace).Delete(f.ctx, name, opts)
+}
+
+func (f *FakeClient) ReadLease(namespace, name string, opts metav1.GetOptions) (*coordinationv1.Lease, error) {
+	return f.client.CoordinationV1().Leases(namespace).Get(f.ctx, name, opts)
+}
+
+func (f *FakeClient) CreateLease(namespace string, l *coordinationv1.Lease, opts metav1.CreateOptions) (*coordinationv1.Lease, error) {
+	return f.client.CoordinationV1().Leases(namespace).Create(f.ctx, l, opts)
+}
+
+func (f *FakeClient) UpdateLease(namespace string, l *coordinationv1.Lease, opts metav1.UpdateOptions) (*coordinationv1.Lease, error) {
+	return f.client.CoordinationV1().Leases(namespace).Update(f.ctx, l, opts)
+}
+
+func (f *FakeClient) DeleteLease(namespace, name string, opts metav1.DeleteOptions) error {
+	return f.client.CoordinationV1().Leases(namespace).Delete(f.ctx, name, opts)
 }
@@ -0,0 +1,196 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"github.com/ksctl/ksctl/v2/pkg/storage/encryption"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// annotationCluster on a revision tells the cluster it belongs to, the label values are
+	// sanitized so the revisions listed are matched once more against it
+	annotationCluster = "ksctl.com/cluster"
+
+	revisionKey = "revision"
+)
+
+// helperGenerateRevisionName returns the secret holding a single revision of the cluster, every revision
+// carries the sensitive fields hence a secret is used and a secret per revision keeps each one under the object size limit
+func helperGenerateRevisionName(db *Store, revision int64) string {
+	return helperGenerateObjectName(db) + "-rev-" + strconv.FormatInt(revision, 10)
+}
+
+func helperGenerateHistorySelector(db *Store) string {
+	return labels.SelectorFromSet(helperGenerateLabels(db, componentHistory)).String()
+}
+
+// readHistory returns the secrets of the revisions of the cluster by their revision
+func (s *Store) readHistory() (map[int64]*v1.Secret, error) {
+	secrets, err := s.clientSet.ListSecrets(s.namespace, metav1.ListOptions{LabelSelector: helperGenerateHistorySelector(s)})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[int64]*v1.Secret, len(secrets.Items))
+	for i := range secrets.Items {
+		c := &secrets.Items[i]
+		if c.Annotations[annotationCluster] != helperGenerateKeyForState(s) {
+			continue
+		}
+		rev, err := strconv.ParseInt(c.Annotations[annotationRevision], 10, 64)
+		if err != nil {
+			continue
+		}
+		out[rev] = c
+	}
+	return out, nil
+}
+
+// helperRevisionsOf returns the revisions present in the history, newest first
+func helperRevisionsOf(c map[int64]*v1.Secret) []int64 {
+	revisions := make([]int64, 0, len(c))
+	for rev := range c {
+		revisions = append(revisions, rev)
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i] > revisions[j]
+	})
+	return revisions
+}
+
+// recordRevision keeps a copy of the state just written and removes the revisions which are older than the ones to be kept
+func (s *Store) recordRevision(doc *statefile.StorageDocument) error {
+	raw, err := json.Marshal(storage.NewStateRevision(s.writer, doc))
+	if err != nil {
+		return err
+	}
+
+	c := generateSecret(helperGenerateRevisionName(s, doc.Revision), s.namespace)
+	c.Labels = helperGenerateLabels(s, componentHistory)
+	c.Annotations = map[string]string{
+		annotationCluster:  helperGenerateKeyForState(s),
+		annotationRevision: strconv.FormatInt(doc.Revision, 10),
+	}
+	c.Data = map[string][]byte{revisionKey: raw}
+	if _, err := s.clientSet.WriteSecret(s.namespace, c, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+
+	history, err := s.readHistory()
+	if err != nil {
+		return err
+	}
+	for rev, c := range history {
+		if rev <= doc.Revision-int64(consts.CounterMaxStateRevisions) {
+			if err := s.clientSet.DeleteSecret(s.namespace, c.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// removeHistory drops all the revisions of the cluster
+func (s *Store) removeHistory() error {
+	history, err := s.readHistory()
+	if err != nil {
+		return err
+	}
+	for _, c := range history {
+		if err := s.clientSet.DeleteSecret(s.namespace, c.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) ListRevisions() ([]*storage.StateRevision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	data, err := s.readHistory()
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "failed to list the revision secrets", "Reason", err),
+		)
+	}
+
+	var out []*storage.StateRevision
+	for _, rev := range helperRevisionsOf(data) {
+		var v *storage.StateRevision
+		if err := json.Unmarshal(data[rev].Data[revisionKey], &v); err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				log.NewError(storeCtx, "unable to deserialize the revision", "Reason", err),
+			)
+		}
+		v.Document = nil
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func (s *Store) ReadRevision(revision int64) (*storage.StateRevision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	data, err := s.readHistory()
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "failed to list the revision secrets", "Reason", err),
+		)
+	}
+
+	c, ok := data[revision]
+	if !ok {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrNoMatchingRecordsFound,
+			log.NewError(storeCtx, "revision not present", "revision", revision),
+		)
+	}
+
+	var v *storage.StateRevision
+	if err := json.Unmarshal(c.Data[revisionKey], &v); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "unable to deserialize the revision", "Reason", err),
+		)
+	}
+	if err := encryption.OpenDocument(s.enc, v.Document); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "unable to decrypt the state", "Reason", err),
+		)
+	}
+	return v, nil
+}
@@ -16,13 +16,19 @@ package kubernetes
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/ksctl/ksctl/v2/migrations"
 	"github.com/ksctl/ksctl/v2/pkg/config"
 	"github.com/ksctl/ksctl/v2/pkg/logger"
 	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"github.com/ksctl/ksctl/v2/pkg/storage/encryption"
 
 	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
 
@@ -33,17 +39,35 @@ import (
 	"github.com/ksctl/ksctl/v2/pkg/consts"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 )
 
+// Store keeps every cluster in a configmap of its own, the sensitive fields of the state
+// are moved out of it into a secret with the same name
 type Store struct {
 	cloudProvider string
 	clusterType   string
 	clusterName   string
 	region        string
 
+	namespace string
+
 	mu        *sync.Mutex
 	wg        *sync.WaitGroup
 	clientSet ClientSet
+
+	// enc seals the secrets of the state at rest, nil keeps the state in plaintext
+	enc encryption.Encrypter
+
+	// writer is recorded along every revision of the state
+	writer string
+
+	// noUpgrade hands out the documents as stored, set on the copy used while upgrading them in bulk
+	noUpgrade bool
+
+	// legacyMigrated is set once the clusters of the single configmap layout got moved to their own objects
+	legacyMigrated bool
 }
 
 var (
@@ -51,17 +75,46 @@ var (
 	storeCtx context.Context
 )
 
+// ksctlNamespace is used when no namespace is set through consts.KsctlKubernetesStoreNamespace
 var ksctlNamespace string = "ksctl"
 
 const (
-	ksctlStateName string = "ksctl-state" // configmap name
+	// ksctlStateName is the configmap of the old layout which held the state of all the clusters
+	ksctlStateName string = "ksctl-state"
+
+	stateKey string = "state"
+)
+
+const (
+	labelManagedBy   = "app.kubernetes.io/managed-by"
+	labelComponent   = "ksctl.com/component"
+	labelCloud       = "ksctl.com/cloud"
+	labelClusterType = "ksctl.com/cluster-type"
+	labelClusterName = "ksctl.com/cluster-name"
+	labelRegion      = "ksctl.com/region"
+
+	// annotationRevision on the secret tells the revision of the state it belongs to
+	annotationRevision = "ksctl.com/revision"
+
+	managedByKsctl   = "ksctl"
+	componentState   = "state"
+	componentHistory = "history"
 )
 
 func NewClient(parentCtx context.Context, _log logger.Logger) (*Store, error) {
 	storeCtx = context.WithValue(parentCtx, consts.KsctlModuleNameKey, string(consts.StoreK8s))
 	log = _log
 	var err error
-	s := &Store{mu: &sync.Mutex{}, wg: &sync.WaitGroup{}}
+	s := &Store{
+		mu:        &sync.Mutex{},
+		wg:        &sync.WaitGroup{},
+		writer:    storage.WriterFromContext(parentCtx),
+		namespace: ksctlNamespace,
+	}
+
+	if v, ok := config.IsContextPresent(parentCtx, consts.KsctlKubernetesStoreNamespace); ok {
+		s.namespace = v
+	}
 
 	if _, ok := config.IsContextPresent(storeCtx, consts.KsctlTestFlagKey); ok {
 		s.clientSet, err = NewFakeK8sClient(storeCtx)
@@ -73,10 +126,36 @@ func NewClient(parentCtx context.Context, _log logger.Logger) (*Store, error) {
 		return nil, err
 	}
 
-	log.Debug(storeCtx, "CONN to k8s configmap")
+	log.Debug(storeCtx, "CONN to k8s configmap", "namespace", s.namespace)
 	return s, nil
 }
 
+func (s *Store) SetEncrypter(enc encryption.Encrypter) {
+	s.enc = enc
+}
+
+// WithoutSchemaUpgrade returns a copy of the store handing out the documents as stored,
+// the copy shares the connection with the store and is Setup on its own
+func (s *Store) WithoutSchemaUpgrade() storage.Storage {
+	raw := *s
+	raw.noUpgrade = true
+	return &raw
+}
+
+// upgradeDocument brings the document read to the latest schema version, the upgraded document is stored on the next write
+func (s *Store) upgradeDocument(doc *statefile.StorageDocument) error {
+	if s.noUpgrade {
+		return nil
+	}
+	if _, err := migrations.UpgradeDocument(doc); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidVersion,
+			log.NewError(storeCtx, "failed to upgrade the state", "Reason", err),
+		)
+	}
+	return nil
+}
+
 func (s *Store) disconnect() error {
 	return nil
 }
@@ -87,27 +166,95 @@ func (s *Store) Read() (*statefile.StorageDocument, error) {
 	s.wg.Add(1)
 	defer s.wg.Done()
 
-	if c, err := s.isPresent(); err == nil {
-		var result *statefile.StorageDocument
-		if raw, ok := c.BinaryData[helperGenerateKeyForState(s)]; ok {
-			if err := json.Unmarshal(raw, &result); err != nil {
-				return nil, ksctlErrors.WrapError(
-					ksctlErrors.ErrInternal,
-					log.NewError(storeCtx, "unable to deserialize the state", "Reason", err),
-				)
-			}
-			return result, nil
+	if err := s.migrateLegacy(); err != nil {
+		return nil, err
+	}
+
+	c, err := s.isPresent()
+	if err != nil {
+		return nil, err
+	}
 
-		} else {
+	secret, err := s.clientSet.ReadSecret(s.namespace, c.Name, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
 			return nil, ksctlErrors.WrapError(
-				ksctlErrors.ErrNoMatchingRecordsFound,
-				log.NewError(storeCtx, "no state as binarydata", "Reason", "c.BinaryData==nil"),
+				ksctlErrors.ErrInternal,
+				log.NewError(storeCtx, "failed to read the secret", "Reason", err),
 			)
 		}
+		secret = nil
+	}
 
-	} else {
+	return s.decodeState(c, secret)
+}
+
+// decodeState builds the state back from the configmap and the secret of the cluster
+func (s *Store) decodeState(c *v1.ConfigMap, secret *v1.Secret) (*statefile.StorageDocument, error) {
+	raw, ok := c.BinaryData[stateKey]
+	if !ok {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrNoMatchingRecordsFound,
+			log.NewError(storeCtx, "no state as binarydata", "configmap", c.Name),
+		)
+	}
+
+	var result *statefile.StorageDocument
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "unable to deserialize the state", "Reason", err),
+		)
+	}
+
+	if secret != nil {
+		// the secret is written after the configmap, a write which failed in between leaves the
+		// secret of the previous revision which must not be merged into the newer state
+		if rev := secret.Annotations[annotationRevision]; rev != strconv.FormatInt(result.Revision, 10) {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				log.NewError(storeCtx, "the secret is not at the revision of the configmap, the last write did not complete",
+					"configmap", c.Name, "revision", result.Revision, "secretRevision", rev),
+			)
+		}
+		for k, v := range result.SensitiveFields() {
+			if val, ok := secret.Data[k]; ok {
+				*v = string(val)
+			}
+		}
+	}
+
+	if err := encryption.OpenDocument(s.enc, result); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "unable to decrypt the state", "Reason", err),
+		)
+	}
+	if err := s.upgradeDocument(result); err != nil {
 		return nil, err
 	}
+	return result, nil
+}
+
+// splitState returns a copy of the state without the sensitive fields along with the sensitive fields
+func splitState(data *statefile.StorageDocument) (*statefile.StorageDocument, map[string][]byte, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	var public *statefile.StorageDocument
+	if err := json.Unmarshal(raw, &public); err != nil {
+		return nil, nil, err
+	}
+
+	secrets := make(map[string][]byte)
+	for k, v := range public.SensitiveFields() {
+		if len(*v) != 0 {
+			secrets[k] = []byte(*v)
+			*v = ""
+		}
+	}
+	return public, secrets, nil
 }
 
 func generateSecret(name string, namespace string) *v1.Secret {
@@ -144,39 +291,178 @@ func (s *Store) Write(data *statefile.StorageDocument) error {
 	s.wg.Add(1)
 	defer s.wg.Done()
 
-	raw, err := json.Marshal(data)
+	migrations.StampDocument(data)
+
+	if err := s.migrateLegacy(); err != nil {
+		return err
+	}
+
+	return s.write(data)
+}
+
+func (s *Store) write(data *statefile.StorageDocument) error {
+	name := helperGenerateObjectName(s)
+
+	c, err := s.clientSet.ReadConfigMap(s.namespace, name, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				log.NewError(storeCtx, "failed to read the configmap", "Reason", err),
+			)
+		}
+		log.Debug(storeCtx, "configmap for write was not found", "name", name)
+		c = generateConfigMap(name, s.namespace)
+	} else {
+		log.Debug(storeCtx, "configmap for write was found", "name", name)
+	}
+
+	expectedRevision := data.Revision
+	if prev, ok := c.BinaryData[stateKey]; ok {
+		var stored *statefile.StorageDocument
+		if err := json.Unmarshal(prev, &stored); err != nil {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				log.NewError(storeCtx, "unable to deserialize the state", "Reason", err),
+			)
+		}
+		if stored.Revision != expectedRevision {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrStaleStateWrite,
+				log.NewError(storeCtx, "state revision mismatch", "stored", stored.Revision, "got", expectedRevision),
+			)
+		}
+	}
+
+	data.Revision = expectedRevision + 1
+	sealed, err := encryption.SealDocument(s.enc, data)
 	if err != nil {
+		data.Revision = expectedRevision
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "unable to encrypt the state", "Reason", err),
+		)
+	}
+
+	public, secrets, err := splitState(sealed)
+	if err != nil {
+		data.Revision = expectedRevision
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "unable to serialize state", "Reason", err),
+		)
+	}
+	raw, err := json.Marshal(public)
+	if err != nil {
+		data.Revision = expectedRevision
 		return ksctlErrors.WrapError(
 			ksctlErrors.ErrInternal,
 			log.NewError(storeCtx, "unable to serialize state", "Reason", err),
 		)
 	}
 
-	var c *v1.ConfigMap
+	c.Labels = helperGenerateLabels(s, componentState)
+	c.BinaryData = map[string][]byte{stateKey: raw}
+	// the resourceVersion of the configmap we read is sent back so that the apiserver
+	// rejects the update if some other writer got in between
+	if _, err := s.clientSet.WriteConfigMap(s.namespace, c, metav1.UpdateOptions{}); err != nil {
+		data.Revision = expectedRevision
+		if errors.IsConflict(err) || errors.IsAlreadyExists(err) {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrStaleStateWrite,
+				log.NewError(storeCtx, "configmap got updated by someone else", "Reason", err),
+			)
+		}
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "failed to write to the configmap", "Reason", err),
+		)
+	}
 
-	c, err = s.isPresent()
+	// the configmap guards the revision, so the secret is written only once the configmap got accepted
+	secret := generateSecret(name, s.namespace)
+	secret.Labels = helperGenerateLabels(s, componentState)
+	secret.Annotations = map[string]string{annotationRevision: strconv.FormatInt(data.Revision, 10)}
+	secret.Data = secrets
+	if _, err := s.clientSet.WriteSecret(s.namespace, secret, metav1.UpdateOptions{}); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "failed to write to the secret, the state can't be read till it gets written again", "revision", data.Revision, "Reason", err),
+		)
+	}
+
+	if err := s.recordRevision(sealed); err != nil {
+		log.Warn(storeCtx, "failed to record the state revision", "revision", data.Revision, "Reason", err)
+	}
+	return nil
+}
+
+// migrateLegacy moves the clusters out of the single configmap used by the older releases
+// into objects of their own, the old configmap is removed once all of them got moved
+func (s *Store) migrateLegacy() error {
+	if s.legacyMigrated {
+		return nil
+	}
+
+	c, err := s.clientSet.ReadConfigMap(s.namespace, ksctlStateName, metav1.GetOptions{})
 	if err != nil {
-		if ksctlErrors.IsNoMatchingRecordsFound(err) {
-			log.Debug(storeCtx, "configmap for write was not found")
+		if errors.IsNotFound(err) {
+			s.legacyMigrated = true
+			return nil
+		}
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "failed to read the configmap", "Reason", err),
+		)
+	}
 
-			c = generateConfigMap(ksctlStateName, ksctlNamespace)
-		} else {
-			return err
+	cloud, clusterType, clusterName, region := s.cloudProvider, s.clusterType, s.clusterName, s.region
+	defer func() {
+		s.cloudProvider, s.clusterType, s.clusterName, s.region = cloud, clusterType, clusterName, region
+	}()
+
+	for k, raw := range c.BinaryData {
+		_data := strings.Split(k, ".")
+		if len(_data) < 2 {
+			continue
 		}
-	} else {
-		log.Debug(storeCtx, "configmap for write was found")
-		if c.BinaryData == nil {
-			c.BinaryData = make(map[string][]byte)
+
+		var doc *statefile.StorageDocument
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				log.NewError(storeCtx, "unable to deserialize the state", "key", k, "Reason", err),
+			)
 		}
+
+		s.cloudProvider, s.clusterType = _data[0], _data[1]
+		s.clusterName, s.region = doc.ClusterName, doc.Region
+
+		if _, err := s.clientSet.ReadConfigMap(s.namespace, helperGenerateObjectName(s), metav1.GetOptions{}); err == nil {
+			log.Debug(storeCtx, "cluster already moved out of the legacy configmap", "key", k)
+			continue
+		} else if !errors.IsNotFound(err) {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				log.NewError(storeCtx, "failed to read the configmap", "Reason", err),
+			)
+		}
+
+		if err := s.write(doc); err != nil {
+			return err
+		}
+		log.Debug(storeCtx, "moved the cluster out of the legacy configmap", "key", k)
 	}
 
-	c.BinaryData[helperGenerateKeyForState(s)] = raw
-	if _, err := s.clientSet.WriteConfigMap(ksctlNamespace, c, metav1.UpdateOptions{}); err != nil {
+	if err := s.clientSet.DeleteConfigMap(s.namespace, ksctlStateName, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
 		return ksctlErrors.WrapError(
 			ksctlErrors.ErrInternal,
-			log.NewError(storeCtx, "failed to write to the configmap", "Reason", err),
+			log.NewError(storeCtx, "failed to delete the legacy configmap", "Reason", err),
 		)
 	}
+
+	log.Note(storeCtx, "Moved the clusters to a configmap and secret of their own", "count", len(c.BinaryData))
+	s.legacyMigrated = true
 	return nil
 }
 
@@ -204,39 +490,100 @@ func (s *Store) DeleteCluster() error {
 	s.wg.Add(1)
 	defer s.wg.Done()
 
-	if c, err := s.isPresent(); err != nil {
+	if err := s.migrateLegacy(); err != nil {
 		return err
+	}
 
-	} else {
-		delete(c.BinaryData, helperGenerateKeyForState(s))
-		_, err := s.clientSet.WriteConfigMap(ksctlNamespace, c, metav1.UpdateOptions{})
-		if err != nil {
-			return ksctlErrors.WrapError(
-				ksctlErrors.ErrInternal,
-				log.NewError(storeCtx, "unable to write the configmap", "Reason", err),
-			)
-		}
-		return nil
+	c, err := s.isPresent()
+	if err != nil {
+		return err
+	}
+
+	if err := s.clientSet.DeleteConfigMap(s.namespace, c.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{ResourceVersion: &c.ResourceVersion},
+	}); err != nil && !errors.IsNotFound(err) {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "unable to delete the configmap", "Reason", err),
+		)
+	}
+	if err := s.clientSet.DeleteSecret(s.namespace, c.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "unable to delete the secret", "Reason", err),
+		)
 	}
+
+	if err := s.removeHistory(); err != nil {
+		log.Warn(storeCtx, "failed to remove the state revisions", "Reason", err)
+	}
+	return nil
 }
 
+// helperGenerateKeyForState identifies the cluster, it was the key of the cluster in the legacy configmap
 func helperGenerateKeyForState(db *Store) string {
 	return fmt.Sprintf("%s.%s.%s.%s", db.cloudProvider, db.clusterType, db.clusterName, db.region)
 }
 
+// helperSanitizeName makes the value usable in the name of a kubernetes object
+func helperSanitizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(name))
+}
+
+// helperGenerateObjectName returns the name of the configmap and the secret of the cluster.
+// The hash of the cluster key keeps the names unique as the sanitization is lossy
+func helperGenerateObjectName(db *Store) string {
+	sum := sha256.Sum256([]byte(helperGenerateKeyForState(db)))
+	name := helperSanitizeName(fmt.Sprintf("ksctl-state-%s-%s-%s-%s", db.cloudProvider, db.clusterType, db.clusterName, db.region))
+	if len(name) > 200 {
+		name = name[:200]
+	}
+	return name + "-" + hex.EncodeToString(sum[:4])
+}
+
+// helperLabelValue makes the value usable as a label value
+func helperLabelValue(v string) string {
+	v = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '-'
+	}, v)
+	if len(v) > 63 {
+		v = v[:63]
+	}
+	return strings.Trim(v, "-_.")
+}
+
+func helperGenerateLabels(db *Store, component string) map[string]string {
+	return map[string]string{
+		labelManagedBy:   managedByKsctl,
+		labelComponent:   component,
+		labelCloud:       db.cloudProvider,
+		labelClusterType: db.clusterType,
+		labelClusterName: helperLabelValue(db.clusterName),
+		labelRegion:      helperLabelValue(db.region),
+	}
+}
+
 func (s *Store) isPresent() (*v1.ConfigMap, error) {
-	c, err := s.clientSet.ReadConfigMap(ksctlNamespace, ksctlStateName, metav1.GetOptions{})
+	c, err := s.clientSet.ReadConfigMap(s.namespace, helperGenerateObjectName(s), metav1.GetOptions{})
 	if err != nil {
 		log.Debug(storeCtx, "storage.kubernetes.isPresent", "err", err)
 		if errors.IsNotFound(err) {
 			return nil, ksctlErrors.WrapError(
 				ksctlErrors.ErrNoMatchingRecordsFound,
-				log.NewError(storeCtx, "no credential is present", "Reason", err),
+				log.NewError(storeCtx, "no matching cluster present", "Reason", err),
 			)
 		}
 		return nil, ksctlErrors.WrapError(
 			ksctlErrors.ErrInternal,
-			log.NewError(storeCtx, "failed to read the secret", "Reason", err),
+			log.NewError(storeCtx, "failed to read the configmap", "Reason", err),
 		)
 	}
 	return c, nil
@@ -260,6 +607,10 @@ func (s *Store) AlreadyCreated(cloud consts.KsctlCloud, region, clusterName stri
 		return err
 	}
 
+	if err := s.migrateLegacy(); err != nil {
+		return err
+	}
+
 	return s.clusterPresent()
 }
 
@@ -268,8 +619,46 @@ func (s *Store) GetOneOrMoreClusters(filters map[consts.KsctlSearchFilter]string
 	defer s.mu.Unlock()
 	s.wg.Add(1)
 	defer s.wg.Done()
+
+	if err := storage.ValidateFilters(filters); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			log.NewError(storeCtx, "invalid filters", "Reason", err),
+		)
+	}
+	return s.getClusters(filters)
+}
+
+func (s *Store) QueryClusters(query storage.ClusterQuery) (*storage.ClusterPage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	if err := query.Validate(); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			log.NewError(storeCtx, "invalid query", "Reason", err),
+		)
+	}
+
+	clusters, err := s.getClusters(query.Filters)
+	if err != nil {
+		return nil, err
+	}
+	var docs []*statefile.StorageDocument
+	for _, v := range clusters {
+		docs = append(docs, v...)
+	}
+	return storage.QueryDocuments(docs, query), nil
+}
+
+// getClusters lists the objects selected by the labels of the cloud, cluster type and region filters,
+// the rest of the filters are matched against the decoded state
+func (s *Store) getClusters(filters map[consts.KsctlSearchFilter]string) (map[consts.KsctlClusterType][]*statefile.StorageDocument, error) {
 	clusterType := filters[consts.ClusterType]
 	cloud := filters[consts.Cloud]
+	region := filters[consts.Region]
 
 	var filterCloudPath, filterClusterType []string
 
@@ -300,48 +689,83 @@ func (s *Store) GetOneOrMoreClusters(filters map[consts.KsctlSearchFilter]string
 	log.Debug(storeCtx, "storage.kubernetes.GetOneOrMoreClusters", "filter", filters, "filterCloudPath", filterCloudPath, "filterClusterType", filterClusterType)
 
 	clustersInfo := make(map[consts.KsctlClusterType][]*statefile.StorageDocument)
+	if len(filterCloudPath) == 0 || len(filterClusterType) == 0 {
+		return clustersInfo, nil
+	}
 
-	c, err := s.clientSet.ReadConfigMap(ksctlNamespace, ksctlStateName, metav1.GetOptions{})
+	if err := s.migrateLegacy(); err != nil {
+		return nil, err
+	}
+
+	selector, err := helperGenerateSelector(filterCloudPath, filterClusterType, region)
 	if err != nil {
-		if errors.IsNotFound(err) {
-			return nil, ksctlErrors.WrapError(
-				ksctlErrors.ErrNoMatchingRecordsFound,
-				log.NewError(storeCtx, "no configmap is present", "Reason", err),
-			)
-		}
 		return nil, ksctlErrors.WrapError(
 			ksctlErrors.ErrInternal,
-			log.NewError(storeCtx, "failed to read the configmap", "Reason", err),
+			log.NewError(storeCtx, "failed to create the label selector", "Reason", err),
 		)
 	}
+	opts := metav1.ListOptions{LabelSelector: selector}
 
-	data := c.BinaryData
-	// storageIdx will index all the required cloud providers and
-	storageIdx := make(map[string][]*statefile.StorageDocument)
-	for k, v := range data {
-		_data := strings.Split(k, ".")
-		_cloud := _data[0]
-		_type := _data[1]
+	configMaps, err := s.clientSet.ListConfigMaps(s.namespace, opts)
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "failed to list the configmaps", "Reason", err),
+		)
+	}
+	secrets, err := s.clientSet.ListSecrets(s.namespace, opts)
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "failed to list the secrets", "Reason", err),
+		)
+	}
+	secretIdx := make(map[string]*v1.Secret, len(secrets.Items))
+	for i := range secrets.Items {
+		secretIdx[secrets.Items[i].Name] = &secrets.Items[i]
+	}
 
-		var result *statefile.StorageDocument
-		err := json.Unmarshal(v, &result)
+	for i := range configMaps.Items {
+		c := &configMaps.Items[i]
+		result, err := s.decodeState(c, secretIdx[c.Name])
 		if err != nil {
-			return nil, ksctlErrors.WrapError(
-				ksctlErrors.ErrInternal,
-				log.NewError(storeCtx, "failed to desearialize the state", "Reason", err),
-			)
+			return nil, err
 		}
-		storageIdx[_cloud+" "+_type] = append(storageIdx[_cloud+" "+_type], result)
+		// label values are sanitized, so the region is matched once more against the state
+		if !storage.MatchesFilters(filters, result) {
+			continue
+		}
+
+		_type := consts.KsctlClusterType(c.Labels[labelClusterType])
+		clustersInfo[_type] = append(clustersInfo[_type], result)
 	}
-	clear(data)
 
-	for _, cloud := range filterCloudPath {
-		for _, clusterType := range filterClusterType {
-			clusters := storageIdx[cloud+" "+clusterType]
+	return clustersInfo, nil
+}
 
-			clustersInfo[consts.KsctlClusterType(clusterType)] = append(clustersInfo[consts.KsctlClusterType(clusterType)], clusters...)
-		}
+func helperGenerateSelector(clouds, clusterTypes []string, region string) (string, error) {
+	type requirement struct {
+		key    string
+		op     selection.Operator
+		values []string
+	}
+	reqs := []requirement{
+		{labelManagedBy, selection.Equals, []string{managedByKsctl}},
+		{labelComponent, selection.Equals, []string{componentState}},
+		{labelCloud, selection.In, clouds},
+		{labelClusterType, selection.In, clusterTypes},
+	}
+	if len(region) != 0 {
+		reqs = append(reqs, requirement{labelRegion, selection.Equals, []string{helperLabelValue(region)}})
 	}
 
-	return clustersInfo, nil
+	selector := labels.NewSelector()
+	for _, r := range reqs {
+		req, err := labels.NewRequirement(r.key, r.op, r.values)
+		if err != nil {
+			return "", err
+		}
+		selector = selector.Add(*req)
+	}
+	return selector.String(), nil
 }
@@ -16,18 +16,26 @@ package kubernetes
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ksctl/ksctl/v2/pkg/statefile"
 	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"github.com/ksctl/ksctl/v2/pkg/storage/storagetest"
 
 	"github.com/gookit/goutil/dump"
 	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
 	"github.com/ksctl/ksctl/v2/pkg/logger"
 	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var (
@@ -38,8 +46,9 @@ var (
 )
 
 func TestMain(m *testing.M) {
-	parentCtx = context.WithValue(context.TODO(), consts.KsctlTestFlagKey, "true")
-	ksctlNamespace = "default"
+	parentCtx = context.WithValue(
+		context.WithValue(context.TODO(), consts.KsctlTestFlagKey, "true"),
+		consts.KsctlKubernetesStoreNamespace, "default")
 
 	exitVal := m.Run()
 
@@ -101,6 +110,382 @@ func TestStore_RWD(t *testing.T) {
 	}
 }
 
+func TestStore_StaleWrite(t *testing.T) {
+	storagetest.StaleWrite(t, db)
+}
+
+func TestStore_Lock(t *testing.T) {
+	if err := db.Setup(consts.CloudAzure, "region", "locked", consts.ClusterTypeSelfMang); err != nil {
+		t.Fatal(err)
+	}
+	locker := db.(storage.Locker)
+
+	lease, err := locker.Lock("alice", time.Minute)
+	assert.NilError(t, err)
+
+	_, err = locker.Lock("bob", time.Minute)
+	assert.Check(t, ksctlErrors.IsClusterLocked(err), fmt.Sprintf("expected cluster locked error, got: %v", err))
+	assert.ErrorContains(t, err, "locked by alice")
+
+	// the lease keeps to its cluster while the store is Setup for another one
+	if err := db.Setup(consts.CloudAws, "region", "other", consts.ClusterTypeMang); err != nil {
+		t.Fatal(err)
+	}
+	assert.NilError(t, locker.Renew(lease, 2*time.Minute))
+	assert.NilError(t, locker.Unlock(lease))
+	if err := db.Setup(consts.CloudAzure, "region", "locked", consts.ClusterTypeSelfMang); err != nil {
+		t.Fatal(err)
+	}
+
+	lease, err = locker.Lock("bob", time.Minute)
+	assert.NilError(t, err)
+	assert.NilError(t, locker.Unlock(lease))
+
+	expired, err := locker.Lock("alice", -time.Second)
+	assert.NilError(t, err)
+	lease, err = locker.Lock("bob", time.Minute)
+	assert.NilError(t, err, "expired lease should be taken over")
+	assert.Check(t, ksctlErrors.IsClusterLocked(locker.Renew(expired, time.Minute)))
+	assert.NilError(t, locker.Unlock(lease))
+}
+
+func TestStore_History(t *testing.T) {
+	storagetest.History(t, db)
+}
+
+func TestStore_RevisionSecrets(t *testing.T) {
+	if err := db.Setup(consts.CloudAws, "region", "revisions", consts.ClusterTypeSelfMang); err != nil {
+		t.Fatal(err)
+	}
+	store := db.(*Store)
+
+	fakeData := &statefile.StorageDocument{
+		Region:        "region",
+		ClusterName:   "revisions",
+		ClusterType:   "selfmanaged",
+		InfraProvider: consts.CloudAws,
+	}
+	limit := int(consts.CounterMaxStateRevisions)
+	for i := 1; i <= limit+1; i++ {
+		assert.NilError(t, db.Write(fakeData))
+	}
+
+	// every revision is an object of its own, the oldest one got pruned
+	secrets, err := store.clientSet.ListSecrets(store.namespace, metav1.ListOptions{LabelSelector: helperGenerateHistorySelector(store)})
+	assert.NilError(t, err)
+	assert.Equal(t, len(secrets.Items), limit)
+	for _, c := range secrets.Items {
+		assert.Equal(t, len(c.Data), 1)
+		assert.Assert(t, c.Name != helperGenerateRevisionName(store, 1))
+	}
+
+	assert.NilError(t, db.DeleteCluster())
+
+	secrets, err = store.clientSet.ListSecrets(store.namespace, metav1.ListOptions{LabelSelector: helperGenerateHistorySelector(store)})
+	assert.NilError(t, err)
+	assert.Equal(t, len(secrets.Items), 0)
+}
+
+func TestStore_Audit(t *testing.T) {
+	storagetest.Audit(t, db)
+}
+
+func TestStore_SecretsInSecret(t *testing.T) {
+	if err := db.Setup(consts.CloudAzure, "region", "secrets", consts.ClusterTypeSelfMang); err != nil {
+		t.Fatal(err)
+	}
+	store := db.(*Store)
+
+	fakeData := &statefile.StorageDocument{
+		Region:            "region",
+		ClusterName:       "secrets",
+		ClusterType:       "selfmanaged",
+		InfraProvider:     consts.CloudAzure,
+		ClusterKubeConfig: "kubeconfig-secret",
+	}
+	assert.NilError(t, db.Write(fakeData))
+
+	name := helperGenerateObjectName(store)
+	c, err := store.clientSet.ReadConfigMap(store.namespace, name, metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(string(c.BinaryData[stateKey]), "kubeconfig-secret"))
+	assert.Equal(t, c.Labels[labelManagedBy], managedByKsctl)
+	assert.Equal(t, c.Labels[labelClusterName], "secrets")
+
+	sec, err := store.clientSet.ReadSecret(store.namespace, name, metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, string(sec.Data["cluster_kubeconfig"]), "kubeconfig-secret")
+
+	got, err := db.Read()
+	assert.NilError(t, err)
+	assert.Equal(t, got.ClusterKubeConfig, "kubeconfig-secret")
+
+	// a write which failed on the secret leaves it at the previous revision
+	sec.Annotations[annotationRevision] = strconv.FormatInt(fakeData.Revision-1, 10)
+	_, err = store.clientSet.WriteSecret(store.namespace, sec, metav1.UpdateOptions{})
+	assert.NilError(t, err)
+	_, err = db.Read()
+	assert.ErrorContains(t, err, "the last write did not complete")
+
+	// the next write brings both back to the same revision
+	assert.NilError(t, db.Write(fakeData))
+	got, err = db.Read()
+	assert.NilError(t, err)
+	assert.Equal(t, got.ClusterKubeConfig, "kubeconfig-secret")
+
+	assert.NilError(t, db.DeleteCluster())
+	_, err = store.clientSet.ReadSecret(store.namespace, name, metav1.GetOptions{})
+	assert.Assert(t, errors.IsNotFound(err))
+}
+
+func TestStore_LegacyMigration(t *testing.T) {
+	legacy, err := NewClient(parentCtx, parentLogger)
+	assert.NilError(t, err)
+
+	raw, err := json.Marshal(&statefile.StorageDocument{
+		Region:            "region",
+		ClusterName:       "legacy",
+		ClusterType:       "managed",
+		InfraProvider:     consts.CloudAws,
+		ClusterKubeConfig: "kubeconfig-secret",
+		Revision:          3,
+	})
+	assert.NilError(t, err)
+
+	c := generateConfigMap(ksctlStateName, legacy.namespace)
+	c.BinaryData["aws.managed.legacy.region"] = raw
+	_, err = legacy.clientSet.WriteConfigMap(legacy.namespace, c, metav1.UpdateOptions{})
+	assert.NilError(t, err)
+
+	clusters, err := legacy.GetOneOrMoreClusters(map[consts.KsctlSearchFilter]string{
+		consts.Cloud:  string(consts.CloudAws),
+		consts.Region: "region",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(clusters[consts.ClusterTypeMang]), 1)
+	assert.Equal(t, clusters[consts.ClusterTypeMang][0].ClusterKubeConfig, "kubeconfig-secret")
+	assert.Equal(t, clusters[consts.ClusterTypeMang][0].Revision, int64(4))
+
+	_, err = legacy.clientSet.ReadConfigMap(legacy.namespace, ksctlStateName, metav1.GetOptions{})
+	assert.Assert(t, errors.IsNotFound(err))
+
+	assert.NilError(t, legacy.AlreadyCreated(consts.CloudAws, "region", "legacy", consts.ClusterTypeMang))
+}
+
+func nextWatchEvent(t *testing.T, ch <-chan storage.WatchEvent) storage.WatchEvent {
+	t.Helper()
+	select {
+	case ev, ok := <-ch:
+		assert.Assert(t, ok, "watch got closed")
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("no watch event got delivered")
+	}
+	return storage.WatchEvent{}
+}
+
+func TestStore_Watch(t *testing.T) {
+	store, err := NewClient(parentCtx, parentLogger)
+	assert.NilError(t, err)
+
+	write := func(cloud consts.KsctlCloud, name string, doc *statefile.StorageDocument) {
+		t.Helper()
+		assert.NilError(t, store.Setup(cloud, "region", name, consts.ClusterTypeMang))
+		if doc == nil {
+			doc = &statefile.StorageDocument{
+				Region:        "region",
+				ClusterName:   name,
+				ClusterType:   string(consts.ClusterTypeMang),
+				InfraProvider: cloud,
+			}
+		}
+		assert.NilError(t, store.Write(doc))
+	}
+
+	write(consts.CloudAzure, "present", nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := store.Watch(ctx, map[consts.KsctlSearchFilter]string{"cloud": "azure"})
+	assert.NilError(t, err)
+
+	ev := nextWatchEvent(t, events)
+	assert.Equal(t, ev.Type, storage.WatchEventAdded)
+	assert.Equal(t, ev.Document.ClusterName, "present")
+
+	// not selected by the filters
+	write(consts.CloudAws, "ignored", nil)
+
+	doc := &statefile.StorageDocument{
+		Region:            "region",
+		ClusterName:       "fresh",
+		ClusterType:       string(consts.ClusterTypeMang),
+		InfraProvider:     consts.CloudAzure,
+		ClusterKubeConfig: "kubeconfig",
+		PlatformSpec:      statefile.PlatformSpec{State: statefile.Creating},
+	}
+	write(consts.CloudAzure, "fresh", doc)
+	ev = nextWatchEvent(t, events)
+	assert.Equal(t, ev.Type, storage.WatchEventAdded)
+	assert.Equal(t, ev.Document.ClusterName, "fresh")
+	assert.Equal(t, ev.Document.ClusterKubeConfig, "kubeconfig")
+
+	// the sensitive fields are untouched, still the change has to be seen
+	doc.PlatformSpec.State = statefile.Running
+	write(consts.CloudAzure, "fresh", doc)
+	ev = nextWatchEvent(t, events)
+	assert.Equal(t, ev.Type, storage.WatchEventUpdated)
+	assert.Equal(t, ev.Document.PlatformSpec.State, statefile.Running)
+	assert.Equal(t, ev.Document.Revision, doc.Revision)
+
+	assert.NilError(t, store.DeleteCluster())
+	ev = nextWatchEvent(t, events)
+	assert.Equal(t, ev.Type, storage.WatchEventDeleted)
+	assert.Equal(t, ev.Document.ClusterName, "fresh")
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestStore_WatchLeavingFilters(t *testing.T) {
+	store, err := NewClient(parentCtx, parentLogger)
+	assert.NilError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := store.Watch(ctx, map[consts.KsctlSearchFilter]string{
+		consts.Cloud: "azure",
+		consts.State: string(statefile.Creating),
+	})
+	assert.NilError(t, err)
+
+	assert.NilError(t, store.Setup(consts.CloudAzure, "region", "moving", consts.ClusterTypeMang))
+	doc := &statefile.StorageDocument{
+		Region:        "region",
+		ClusterName:   "moving",
+		ClusterType:   string(consts.ClusterTypeMang),
+		InfraProvider: consts.CloudAzure,
+		PlatformSpec:  statefile.PlatformSpec{State: statefile.Creating},
+	}
+	assert.NilError(t, store.Write(doc))
+	ev := nextWatchEvent(t, events)
+	assert.Equal(t, ev.Type, storage.WatchEventAdded)
+	assert.Equal(t, ev.Document.ClusterName, "moving")
+
+	// the cluster is no longer selected by the filters of the watch
+	doc.PlatformSpec.State = statefile.Running
+	assert.NilError(t, store.Write(doc))
+	ev = nextWatchEvent(t, events)
+	assert.Equal(t, ev.Type, storage.WatchEventDeleted)
+	assert.Equal(t, ev.Document.PlatformSpec.State, statefile.Running)
+
+	// and it is back
+	doc.PlatformSpec.State = statefile.Creating
+	assert.NilError(t, store.Write(doc))
+	ev = nextWatchEvent(t, events)
+	assert.Equal(t, ev.Type, storage.WatchEventAdded)
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestQueryClusters(t *testing.T) {
+	store, err := NewClient(parentCtx, parentLogger)
+	assert.NilError(t, err)
+
+	version := func(v string) *string { return &v }
+	fixtures := []*statefile.StorageDocument{
+		{
+			ClusterName: "alpha", Region: "eastus", ClusterType: string(consts.ClusterTypeMang), InfraProvider: consts.CloudAzure,
+			Versions:     statefile.ComponentVersions{Aks: version("1.30.4")},
+			PlatformSpec: statefile.PlatformSpec{State: statefile.Running, Owner: "alice", Team: "payments", Labels: map[string]string{"env": "prod", "cost-center": "42"}},
+		},
+		{
+			ClusterName: "bravo", Region: "us-east-1", ClusterType: string(consts.ClusterTypeMang), InfraProvider: consts.CloudAws,
+			Versions:     statefile.ComponentVersions{Eks: version("1.31")},
+			PlatformSpec: statefile.PlatformSpec{State: statefile.Creating, Owner: "bob", Team: "payments", Labels: map[string]string{"env": "dev"}},
+		},
+		{
+			ClusterName: "charlie", Region: "us-east-1", ClusterType: string(consts.ClusterTypeSelfMang), InfraProvider: consts.CloudAws,
+			BootstrapProvider: consts.K8sK3s,
+			Versions:          statefile.ComponentVersions{K3s: version("v1.30.2+k3s1")},
+			PlatformSpec:      statefile.PlatformSpec{State: statefile.Running, Owner: "alice", Team: "search", Labels: map[string]string{"env": "prod"}},
+		},
+		{
+			ClusterName: "delta", Region: "eastus", ClusterType: string(consts.ClusterTypeSelfMang), InfraProvider: consts.CloudAzure,
+			BootstrapProvider: consts.K8sKubeadm,
+			Versions:          statefile.ComponentVersions{Kubeadm: version("1.29.8")},
+			PlatformSpec:      statefile.PlatformSpec{State: statefile.Running, Owner: "carol", Team: "search"},
+		},
+	}
+	for _, doc := range fixtures {
+		assert.NilError(t, store.Setup(doc.InfraProvider, doc.Region, doc.ClusterName, consts.KsctlClusterType(doc.ClusterType)))
+		assert.NilError(t, store.Write(doc))
+	}
+
+	names := func(docs []*statefile.StorageDocument) []string {
+		var out []string
+		for _, d := range docs {
+			out = append(out, d.ClusterName)
+		}
+		return out
+	}
+
+	for _, tc := range []struct {
+		filters map[consts.KsctlSearchFilter]string
+		want    []string
+	}{
+		{map[consts.KsctlSearchFilter]string{}, []string{"alpha", "bravo", "charlie", "delta"}},
+		{map[consts.KsctlSearchFilter]string{consts.State: string(statefile.Running)}, []string{"alpha", "charlie", "delta"}},
+		{map[consts.KsctlSearchFilter]string{consts.Owner: "alice", consts.Team: "search"}, []string{"charlie"}},
+		{map[consts.KsctlSearchFilter]string{consts.BootstrapProvider: string(consts.K8sKubeadm)}, []string{"delta"}},
+		{map[consts.KsctlSearchFilter]string{consts.KubernetesVersion: "1.30"}, []string{"alpha", "charlie"}},
+		{map[consts.KsctlSearchFilter]string{consts.Labels: "env=prod"}, []string{"alpha", "charlie"}},
+		{map[consts.KsctlSearchFilter]string{consts.Labels: "env=prod,cost-center"}, []string{"alpha"}},
+		{map[consts.KsctlSearchFilter]string{consts.Cloud: string(consts.CloudAws), consts.Labels: "env"}, []string{"bravo", "charlie"}},
+	} {
+		page, err := store.QueryClusters(storage.ClusterQuery{Filters: tc.filters})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, names(page.Clusters), tc.want)
+		assert.Equal(t, page.Total, len(tc.want))
+
+		m, err := store.GetOneOrMoreClusters(tc.filters)
+		assert.NilError(t, err)
+		assert.Equal(t, len(m[consts.ClusterTypeMang])+len(m[consts.ClusterTypeSelfMang]), len(tc.want), fmt.Sprintf("filters: %v", tc.filters))
+	}
+
+	page, err := store.QueryClusters(storage.ClusterQuery{SortBy: storage.SortByOwner, Descending: true})
+	assert.NilError(t, err)
+	// the ties are ordered by the identity of the cluster in the same direction
+	assert.DeepEqual(t, names(page.Clusters), []string{"delta", "bravo", "alpha", "charlie"})
+
+	var paged []string
+	query := storage.ClusterQuery{SortBy: storage.SortByRegion, Limit: 3}
+	for {
+		page, err := store.QueryClusters(query)
+		assert.NilError(t, err)
+		assert.Equal(t, page.Total, 4)
+		paged = append(paged, names(page.Clusters)...)
+		if !page.HasMore() {
+			break
+		}
+		query.Offset += query.Limit
+	}
+	assert.DeepEqual(t, paged, []string{"alpha", "delta", "bravo", "charlie"})
+
+	_, err = store.QueryClusters(storage.ClusterQuery{SortBy: "size"})
+	assert.Check(t, ksctlErrors.IsInvalidUserInput(err), fmt.Sprintf("expected invalid user input error, got: %v", err))
+	_, err = store.GetOneOrMoreClusters(map[consts.KsctlSearchFilter]string{consts.Labels: "=prod"})
+	assert.Check(t, ksctlErrors.IsInvalidUserInput(err), fmt.Sprintf("expected invalid user input error, got: %v", err))
+
+	for _, doc := range fixtures {
+		assert.NilError(t, store.Setup(doc.InfraProvider, doc.Region, doc.ClusterName, consts.KsctlClusterType(doc.ClusterType)))
+		assert.NilError(t, store.DeleteCluster())
+	}
+}
+
 func TestGetClusterInfo(t *testing.T) {
 
 	t.Run("Setup some demo clusters", func(t *testing.T) {
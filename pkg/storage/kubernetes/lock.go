@@ -0,0 +1,199 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"fmt"
+	"time"
+
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"github.com/ksctl/ksctl/v2/pkg/utilities"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	annotationLockOwner = "ksctl.com/lock-owner"
+)
+
+func helperGenerateLeaseName(db *Store) string {
+	return helperSanitizeName(fmt.Sprintf("ksctl-lock-%s-%s-%s-%s", db.cloudProvider, db.clusterType, db.clusterName, db.region))
+}
+
+func generateLease(name, namespace string, lease *storage.ClusterLease) *coordinationv1.Lease {
+	l := &coordinationv1.Lease{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Lease",
+			APIVersion: "coordination.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+	setLeaseSpec(l, lease)
+	return l
+}
+
+func setLeaseSpec(l *coordinationv1.Lease, lease *storage.ClusterLease) {
+	if l.Annotations == nil {
+		l.Annotations = make(map[string]string)
+	}
+	l.Annotations[annotationLockOwner] = lease.Owner
+
+	l.Spec.HolderIdentity = utilities.Ptr(lease.ID)
+	l.Spec.AcquireTime = &metav1.MicroTime{Time: lease.AcquiredAt}
+	l.Spec.RenewTime = &metav1.MicroTime{Time: time.Now().UTC()}
+	l.Spec.LeaseDurationSeconds = utilities.Ptr(int32(lease.ExpiresAt.Sub(l.Spec.RenewTime.Time).Seconds()))
+}
+
+func getClusterLease(l *coordinationv1.Lease) *storage.ClusterLease {
+	lease := &storage.ClusterLease{
+		Owner: l.Annotations[annotationLockOwner],
+	}
+	if l.Spec.HolderIdentity != nil {
+		lease.ID = *l.Spec.HolderIdentity
+	}
+	if l.Spec.AcquireTime != nil {
+		lease.AcquiredAt = l.Spec.AcquireTime.Time
+	}
+	if l.Spec.RenewTime != nil && l.Spec.LeaseDurationSeconds != nil {
+		lease.ExpiresAt = l.Spec.RenewTime.Add(time.Duration(*l.Spec.LeaseDurationSeconds) * time.Second)
+	}
+	return lease
+}
+
+func (s *Store) Lock(owner string, ttl time.Duration) (*storage.ClusterLease, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lease, err := storage.NewClusterLease(owner, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	name := helperGenerateLeaseName(s)
+	lease.Key = name
+	if _, err := s.clientSet.CreateLease(s.namespace, generateLease(name, s.namespace, lease), metav1.CreateOptions{}); err == nil {
+		return lease, nil
+	} else if !errors.IsAlreadyExists(err) {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "failed to create the lease", "Reason", err),
+		)
+	}
+
+	existing, err := s.clientSet.ReadLease(s.namespace, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "failed to read the lease", "Reason", err),
+		)
+	}
+
+	if holder := getClusterLease(existing); !holder.IsExpired(time.Now()) {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrClusterLocked,
+			log.NewError(storeCtx, "cluster is "+holder.String()),
+		)
+	}
+
+	// the lease has expired, taking it over. The resourceVersion makes sure
+	// only one of the competing writers wins
+	setLeaseSpec(existing, lease)
+	if _, err := s.clientSet.UpdateLease(s.namespace, existing, metav1.UpdateOptions{}); err != nil {
+		if errors.IsConflict(err) {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrClusterLocked,
+				log.NewError(storeCtx, "cluster lock got acquired by someone else", "Reason", err),
+			)
+		}
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "failed to take over the lease", "Reason", err),
+		)
+	}
+	return lease, nil
+}
+
+func (s *Store) readOwnLease(lease *storage.ClusterLease) (*coordinationv1.Lease, error) {
+	existing, err := s.clientSet.ReadLease(s.namespace, lease.Key, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrNoMatchingRecordsFound,
+				log.NewError(storeCtx, "lease is not present", "Reason", err),
+			)
+		}
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "failed to read the lease", "Reason", err),
+		)
+	}
+
+	if holder := getClusterLease(existing); holder.ID != lease.ID {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrClusterLocked,
+			log.NewError(storeCtx, "lease got lost, cluster is "+holder.String()),
+		)
+	}
+	return existing, nil
+}
+
+func (s *Store) Renew(lease *storage.ClusterLease, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.readOwnLease(lease)
+	if err != nil {
+		return err
+	}
+
+	lease.ExpiresAt = time.Now().UTC().Add(ttl)
+	setLeaseSpec(existing, lease)
+	if _, err := s.clientSet.UpdateLease(s.namespace, existing, metav1.UpdateOptions{}); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "failed to renew the lease", "Reason", err),
+		)
+	}
+	return nil
+}
+
+func (s *Store) Unlock(lease *storage.ClusterLease) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.readOwnLease(lease)
+	if err != nil {
+		if ksctlErrors.IsNoMatchingRecordsFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if err := s.clientSet.DeleteLease(s.namespace, existing.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{ResourceVersion: &existing.ResourceVersion},
+	}); err != nil && !errors.IsNotFound(err) {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "failed to delete the lease", "Reason", err),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Watch runs an informer on the secrets of the clusters. The secret is the object written last
+// on a write and removed last on a delete, so once it changes the configmap is already in place
+func (s *Store) Watch(ctx context.Context, filters map[consts.KsctlSearchFilter]string) (<-chan storage.WatchEvent, error) {
+	s.mu.Lock()
+	err := s.migrateLegacy()
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var clouds, clusterTypes []string
+	switch v := filters[consts.Cloud]; v {
+	case string(consts.CloudAll), "":
+		clouds = append(clouds, string(consts.CloudAws), string(consts.CloudAzure), string(consts.CloudLocal))
+	default:
+		clouds = append(clouds, v)
+	}
+	switch v := filters[consts.ClusterType]; v {
+	case "":
+		clusterTypes = append(clusterTypes, string(consts.ClusterTypeMang), string(consts.ClusterTypeSelfMang))
+	default:
+		clusterTypes = append(clusterTypes, v)
+	}
+
+	selector, err := helperGenerateSelector(clouds, clusterTypes, filters[consts.Region])
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			log.NewError(storeCtx, "failed to create the label selector", "Reason", err),
+		)
+	}
+
+	events := make(chan storage.WatchEvent)
+	tracker := storage.NewWatchTracker(filters)
+
+	upsert := func(obj any) {
+		secret, ok := obj.(*v1.Secret)
+		if !ok || secret.Labels[labelComponent] != componentState {
+			return
+		}
+		c, err := s.clientSet.ReadConfigMap(s.namespace, secret.Name, metav1.GetOptions{})
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				log.Warn(storeCtx, "failed to read the configmap", "name", secret.Name, "Reason", err)
+			}
+			return
+		}
+		doc, err := s.decodeState(c, secret)
+		if err != nil {
+			log.Warn(storeCtx, "failed to decode the state", "name", secret.Name, "Reason", err)
+			return
+		}
+		if ev, ok := tracker.Upsert(secret.Name, doc); ok {
+			storage.SendWatchEvent(ctx, events, ev)
+		}
+	}
+
+	remove := func(obj any) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		secret, ok := obj.(*v1.Secret)
+		if !ok {
+			return
+		}
+		if ev, ok := tracker.Remove(secret.Name); ok {
+			storage.SendWatchEvent(ctx, events, ev)
+		}
+	}
+
+	_, informer := cache.NewInformerWithOptions(cache.InformerOptions{
+		ListerWatcher: &cache.ListWatch{
+			ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+				opts.LabelSelector = selector
+				return s.clientSet.ListSecrets(s.namespace, opts)
+			},
+			WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+				opts.LabelSelector = selector
+				return s.clientSet.WatchSecrets(s.namespace, opts)
+			},
+		},
+		ObjectType: &v1.Secret{},
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    upsert,
+			UpdateFunc: func(_, obj any) { upsert(obj) },
+			DeleteFunc: remove,
+		},
+	})
+
+	go func() {
+		defer close(events)
+		// the handlers run on this goroutine, so none of them sends once Run returned
+		informer.Run(ctx.Done())
+	}()
+
+	return events, nil
+}
@@ -0,0 +1,389 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"path"
+	"time"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/logger"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"github.com/ksctl/ksctl/v2/pkg/storage/encryption"
+)
+
+const (
+	// ArchiveFormatVersion is bumped whenever the layout of the archive changes,
+	// the archives of a newer version are refused
+	ArchiveFormatVersion = 1
+
+	archiveManifest    = "manifest.json"
+	archiveClustersDir = "clusters"
+
+	// maxArchiveEntrySize bounds the memory used while reading a single state from the archive
+	maxArchiveEntrySize = 64 << 20
+)
+
+// ArchiveEntry describes the state of a single cluster stored in the archive
+type ArchiveEntry struct {
+	InfraProvider consts.KsctlCloud       `json:"cloud"`
+	ClusterType   consts.KsctlClusterType `json:"cluster_type"`
+	ClusterName   string                  `json:"cluster_name"`
+	Region        string                  `json:"region"`
+
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ArchiveManifest is the first file of the archive, it lists the states the archive holds
+type ArchiveManifest struct {
+	FormatVersion int       `json:"format_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	CreatedBy     string    `json:"created_by"`
+
+	// Encrypted tells the states are sealed as a whole, the same key is needed to read them back
+	Encrypted bool `json:"encrypted"`
+
+	Clusters []ArchiveEntry `json:"clusters"`
+}
+
+type ExportOptions struct {
+	// Filters selects the clusters to export, same as storage.Storage.GetOneOrMoreClusters
+	Filters map[consts.KsctlSearchFilter]string
+
+	// Encrypter seals every state written to the archive, nil keeps them in plaintext
+	Encrypter encryption.Encrypter
+}
+
+type ImportOptions struct {
+	// DryRun verifies the archive and reports what would be done, nothing is written to the destination
+	DryRun bool
+
+	// OnConflict decides what happens to clusters already present in the destination, defaults to ConflictSkip
+	OnConflict ConflictPolicy
+
+	// ResolveConflict is asked for every cluster already present in the destination,
+	// returning an empty policy falls back to OnConflict
+	ResolveConflict func(incoming, existing *statefile.StorageDocument) ConflictPolicy
+
+	// Encrypter opens the states of an encrypted archive
+	Encrypter encryption.Encrypter
+}
+
+func archivePath(doc *statefile.StorageDocument) string {
+	return path.Join(archiveClustersDir, string(doc.InfraProvider), doc.ClusterType, doc.ClusterName, doc.Region+".json")
+}
+
+func checksum(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// Export writes the state of every cluster of the storage to w as a tar archive. The manifest
+// comes first and records the checksum of every state so that the archive can be verified on import
+func Export(ctx context.Context, l logger.Logger, from storage.Storage, w io.Writer, opts ExportOptions) (*ArchiveManifest, error) {
+	ctx = context.WithValue(ctx, consts.KsctlModuleNameKey, "storage-export")
+
+	filters := opts.Filters
+	if filters == nil {
+		filters = map[consts.KsctlSearchFilter]string{}
+	}
+	clusters, err := from.GetOneOrMoreClusters(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []*statefile.StorageDocument
+	for _, v := range clusters {
+		for _, doc := range v {
+			if doc != nil {
+				docs = append(docs, doc)
+			}
+		}
+	}
+	storage.SortClusters(docs, storage.SortByCloud, false)
+
+	manifest := &ArchiveManifest{
+		FormatVersion: ArchiveFormatVersion,
+		CreatedAt:     time.Now().UTC(),
+		CreatedBy:     storage.WriterFromContext(ctx),
+		Encrypted:     opts.Encrypter != nil,
+	}
+	payloads := make([][]byte, 0, len(docs))
+	for _, doc := range docs {
+		raw, err := canonical(doc)
+		if err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				l.NewError(ctx, "failed to serialize the state", "cluster", doc.ClusterName, "Reason", err),
+			)
+		}
+		if opts.Encrypter != nil {
+			sealed, err := opts.Encrypter.Encrypt(string(raw))
+			if err != nil {
+				return nil, ksctlErrors.WrapError(
+					ksctlErrors.ErrInternal,
+					l.NewError(ctx, "failed to encrypt the state", "cluster", doc.ClusterName, "Reason", err),
+				)
+			}
+			raw = []byte(sealed)
+		}
+
+		payloads = append(payloads, raw)
+		manifest.Clusters = append(manifest.Clusters, ArchiveEntry{
+			InfraProvider: doc.InfraProvider,
+			ClusterType:   consts.KsctlClusterType(doc.ClusterType),
+			ClusterName:   doc.ClusterName,
+			Region:        doc.Region,
+			Path:          archivePath(doc),
+			Size:          int64(len(raw)),
+			SHA256:        checksum(raw),
+		})
+		l.Debug(ctx, "storage.export", "cluster", doc.ClusterName, "region", doc.Region, "cloud", doc.InfraProvider, "type", doc.ClusterType)
+	}
+
+	rawManifest, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			l.NewError(ctx, "failed to serialize the manifest", "Reason", err),
+		)
+	}
+
+	tw := tar.NewWriter(w)
+	writeFile := func(name string, raw []byte) error {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    name,
+			Mode:    0600,
+			Size:    int64(len(raw)),
+			ModTime: manifest.CreatedAt,
+		}); err != nil {
+			return err
+		}
+		_, err := tw.Write(raw)
+		return err
+	}
+
+	if err := writeFile(archiveManifest, rawManifest); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			l.NewError(ctx, "failed to write the archive", "Reason", err),
+		)
+	}
+	for i, e := range manifest.Clusters {
+		if err := writeFile(e.Path, payloads[i]); err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				l.NewError(ctx, "failed to write the archive", "Reason", err),
+			)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			l.NewError(ctx, "failed to write the archive", "Reason", err),
+		)
+	}
+
+	return manifest, nil
+}
+
+// VerifyArchive reads the whole archive and checks its version, the checksum of every state
+// listed in the manifest and that the states decode to the clusters the manifest names
+func VerifyArchive(ctx context.Context, l logger.Logger, r io.Reader, enc encryption.Encrypter) (*ArchiveManifest, error) {
+	ctx = context.WithValue(ctx, consts.KsctlModuleNameKey, "storage-import")
+	manifest, _, err := readArchive(ctx, l, r, enc)
+	return manifest, err
+}
+
+// readArchive returns the manifest and the states of the archive in the order of the manifest,
+// nothing is returned unless the whole archive is valid
+func readArchive(ctx context.Context, l logger.Logger, r io.Reader, enc encryption.Encrypter) (*ArchiveManifest, []*statefile.StorageDocument, error) {
+	invalid := func(msg string, args ...any) error {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			l.NewError(ctx, msg, args...),
+		)
+	}
+
+	tr := tar.NewReader(r)
+	var (
+		manifest *ArchiveManifest
+		entries  map[string]int
+		payloads = make(map[string][]byte)
+	)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, invalid("failed to read the archive", "Reason", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if hdr.Size > maxArchiveEntrySize {
+			return nil, nil, invalid("archive entry is too large", "path", hdr.Name, "size", hdr.Size)
+		}
+		raw, err := io.ReadAll(io.LimitReader(tr, maxArchiveEntrySize))
+		if err != nil {
+			return nil, nil, invalid("failed to read the archive", "path", hdr.Name, "Reason", err)
+		}
+
+		if manifest == nil {
+			if hdr.Name != archiveManifest {
+				return nil, nil, invalid("archive doesn't start with the manifest", "path", hdr.Name)
+			}
+			if err := json.Unmarshal(raw, &manifest); err != nil || manifest == nil {
+				return nil, nil, invalid("failed to decode the manifest", "Reason", err)
+			}
+			if manifest.FormatVersion < 1 || manifest.FormatVersion > ArchiveFormatVersion {
+				return nil, nil, invalid("archive format version is not supported", "version", manifest.FormatVersion, "supported", ArchiveFormatVersion)
+			}
+			if manifest.Encrypted && enc == nil {
+				return nil, nil, invalid("archive is encrypted, the key used for the export is needed")
+			}
+			entries = make(map[string]int, len(manifest.Clusters))
+			for i, e := range manifest.Clusters {
+				if _, ok := entries[e.Path]; ok {
+					return nil, nil, invalid("manifest lists the same state twice", "path", e.Path)
+				}
+				entries[e.Path] = i
+			}
+			continue
+		}
+
+		if _, ok := entries[hdr.Name]; !ok {
+			return nil, nil, invalid("archive holds a file missing from the manifest", "path", hdr.Name)
+		}
+		if _, ok := payloads[hdr.Name]; ok {
+			return nil, nil, invalid("archive holds the same state twice", "path", hdr.Name)
+		}
+		payloads[hdr.Name] = raw
+	}
+	if manifest == nil {
+		return nil, nil, invalid("archive has no manifest")
+	}
+
+	docs := make([]*statefile.StorageDocument, 0, len(manifest.Clusters))
+	for _, e := range manifest.Clusters {
+		raw, ok := payloads[e.Path]
+		if !ok {
+			return nil, nil, invalid("state listed in the manifest is missing from the archive", "path", e.Path)
+		}
+		if int64(len(raw)) != e.Size || checksum(raw) != e.SHA256 {
+			return nil, nil, invalid("checksum of the state doesn't match the manifest", "path", e.Path)
+		}
+
+		if manifest.Encrypted {
+			opened, err := enc.Decrypt(string(raw))
+			if err != nil {
+				return nil, nil, invalid("failed to decrypt the state", "path", e.Path, "Reason", err)
+			}
+			raw = []byte(opened)
+		}
+
+		var doc *statefile.StorageDocument
+		if err := json.Unmarshal(raw, &doc); err != nil || doc == nil {
+			return nil, nil, invalid("failed to decode the state", "path", e.Path, "Reason", err)
+		}
+		if doc.InfraProvider != e.InfraProvider || doc.ClusterType != string(e.ClusterType) ||
+			doc.ClusterName != e.ClusterName || doc.Region != e.Region {
+			return nil, nil, invalid("state doesn't belong to the cluster named in the manifest", "path", e.Path)
+		}
+		docs = append(docs, doc)
+	}
+
+	return manifest, docs, nil
+}
+
+// Import restores the states of an archive written by Export into the storage. The whole archive is
+// verified before anything gets written, then every state is written and read back to be compared
+// with the archive. The results are returned for the clusters handled till the first error
+func Import(ctx context.Context, l logger.Logger, r io.Reader, to storage.Storage, opts ImportOptions) ([]Result, error) {
+	ctx = context.WithValue(ctx, consts.KsctlModuleNameKey, "storage-import")
+
+	if opts.OnConflict == "" {
+		opts.OnConflict = ConflictSkip
+	}
+	if !opts.OnConflict.isValid() {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			l.NewError(ctx, "invalid conflict policy", "policy", opts.OnConflict),
+		)
+	}
+
+	_, docs, err := readArchive(ctx, l, r, opts.Encrypter)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	for _, doc := range docs {
+		res := Result{
+			InfraProvider: doc.InfraProvider,
+			ClusterType:   consts.KsctlClusterType(doc.ClusterType),
+			ClusterName:   doc.ClusterName,
+			Region:        doc.Region,
+		}
+
+		if err := to.Setup(res.InfraProvider, res.Region, res.ClusterName, res.ClusterType); err != nil {
+			return results, err
+		}
+
+		existing, err := to.Read()
+		if err != nil && !ksctlErrors.IsNoMatchingRecordsFound(err) {
+			return results, err
+		}
+
+		policy := opts.OnConflict
+		if existing != nil && opts.ResolveConflict != nil {
+			if v := opts.ResolveConflict(doc, existing); len(v) != 0 {
+				policy = v
+			}
+			if !policy.isValid() {
+				return results, ksctlErrors.WrapError(
+					ksctlErrors.ErrInvalidUserInput,
+					l.NewError(ctx, "invalid conflict policy", "policy", policy, "cluster", res.ClusterName),
+				)
+			}
+		}
+		res.Action = actionFor(existing, policy)
+		l.Debug(ctx, "storage.import", "cluster", res.ClusterName, "region", res.Region, "cloud", res.InfraProvider, "type", res.ClusterType, "action", res.Action)
+
+		if opts.DryRun || res.Action == ActionSkip {
+			results = append(results, res)
+			continue
+		}
+
+		if err := copyDocument(ctx, l, to, doc, existing); err != nil {
+			return results, err
+		}
+		results = append(results, res)
+	}
+
+	return results, nil
+}
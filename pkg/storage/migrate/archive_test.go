@@ -0,0 +1,166 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage/encryption"
+	"github.com/ksctl/ksctl/v2/pkg/storage/host"
+	"github.com/ksctl/ksctl/v2/pkg/storage/kubernetes"
+	"gotest.tools/v3/assert"
+)
+
+// tamperArchive rewrites the archive passing the content of every file through fn
+func tamperArchive(t *testing.T, archive []byte, fn func(name string, raw []byte) []byte) []byte {
+	t.Helper()
+	out := new(bytes.Buffer)
+	tr := tar.NewReader(bytes.NewReader(archive))
+	tw := tar.NewWriter(out)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NilError(t, err)
+		raw, err := io.ReadAll(tr)
+		assert.NilError(t, err)
+		raw = fn(hdr.Name, raw)
+		hdr.Size = int64(len(raw))
+		assert.NilError(t, tw.WriteHeader(hdr))
+		_, err = tw.Write(raw)
+		assert.NilError(t, err)
+	}
+	assert.NilError(t, tw.Close())
+	return out.Bytes()
+}
+
+func TestArchive(t *testing.T) {
+	from := host.NewClient(context.WithValue(parentCtx, consts.KsctlCustomDirLoc, t.TempDir()), parentLogger)
+
+	docs := []*statefile.StorageDocument{
+		fakeDocument(consts.CloudAzure, "backup", "eastus", consts.ClusterTypeSelfMang),
+		fakeDocument(consts.CloudAzure, "backup-conflict", "eastus", consts.ClusterTypeMang),
+		fakeDocument(consts.CloudAws, "backup-keep", "us-east-1", consts.ClusterTypeMang),
+	}
+	for _, doc := range docs {
+		assert.NilError(t, from.Setup(doc.InfraProvider, doc.Region, doc.ClusterName, consts.KsctlClusterType(doc.ClusterType)))
+		assert.NilError(t, from.Write(doc))
+	}
+
+	keyring, err := encryption.NewLocalKeyring(filepath.Join(t.TempDir(), "keyfile.json"))
+	assert.NilError(t, err)
+	enc := encryption.NewEnvelopeEncrypter(parentCtx, keyring)
+
+	plain := new(bytes.Buffer)
+	manifest, err := Export(parentCtx, parentLogger, from, plain, ExportOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, manifest.FormatVersion, ArchiveFormatVersion)
+	assert.Equal(t, len(manifest.Clusters), 3)
+	assert.Equal(t, manifest.Clusters[0].InfraProvider, consts.CloudAws)
+
+	sealed := new(bytes.Buffer)
+	_, err = Export(parentCtx, parentLogger, from, sealed, ExportOptions{Encrypter: enc})
+	assert.NilError(t, err)
+	assert.Assert(t, !bytes.Contains(sealed.Bytes(), []byte("kubeconfig-backup")))
+
+	t.Run("verify", func(t *testing.T) {
+		_, err := VerifyArchive(parentCtx, parentLogger, bytes.NewReader(plain.Bytes()), nil)
+		assert.NilError(t, err)
+
+		_, err = VerifyArchive(parentCtx, parentLogger, bytes.NewReader(sealed.Bytes()), nil)
+		assert.ErrorContains(t, err, "archive is encrypted")
+
+		m, err := VerifyArchive(parentCtx, parentLogger, bytes.NewReader(sealed.Bytes()), enc)
+		assert.NilError(t, err)
+		assert.Assert(t, m.Encrypted)
+
+		corrupted := tamperArchive(t, plain.Bytes(), func(name string, raw []byte) []byte {
+			if name == archiveManifest {
+				return raw
+			}
+			return bytes.ReplaceAll(raw, []byte("rg-"), []byte("xx-"))
+		})
+		_, err = VerifyArchive(parentCtx, parentLogger, bytes.NewReader(corrupted), nil)
+		assert.Assert(t, ksctlErrors.IsInvalidUserInput(err))
+		assert.ErrorContains(t, err, "checksum")
+
+		newer := tamperArchive(t, plain.Bytes(), func(name string, raw []byte) []byte {
+			if name == archiveManifest {
+				return bytes.Replace(raw, []byte(`"format_version": 1`), []byte(`"format_version": 99`), 1)
+			}
+			return raw
+		})
+		_, err = VerifyArchive(parentCtx, parentLogger, bytes.NewReader(newer), nil)
+		assert.ErrorContains(t, err, "format version")
+	})
+
+	t.Run("import", func(t *testing.T) {
+		to, err := kubernetes.NewClient(parentCtx, parentLogger)
+		assert.NilError(t, err)
+
+		stale := fakeDocument(consts.CloudAzure, "backup-conflict", "eastus", consts.ClusterTypeMang)
+		stale.ClusterKubeConfig = "stale"
+		assert.NilError(t, to.Setup(stale.InfraProvider, stale.Region, stale.ClusterName, consts.ClusterTypeMang))
+		assert.NilError(t, to.Write(stale))
+
+		kept := fakeDocument(consts.CloudAws, "backup-keep", "us-east-1", consts.ClusterTypeMang)
+		kept.ClusterKubeConfig = "kept"
+		assert.NilError(t, to.Setup(kept.InfraProvider, kept.Region, kept.ClusterName, consts.ClusterTypeMang))
+		assert.NilError(t, to.Write(kept))
+
+		results, err := Import(parentCtx, parentLogger, bytes.NewReader(sealed.Bytes()), to, ImportOptions{
+			OnConflict: ConflictOverwrite,
+			Encrypter:  enc,
+			ResolveConflict: func(incoming, existing *statefile.StorageDocument) ConflictPolicy {
+				if existing.ClusterKubeConfig == "kept" {
+					return ConflictSkip
+				}
+				return ""
+			},
+		})
+		assert.NilError(t, err)
+
+		actions := make(map[string]Action)
+		for _, r := range results {
+			actions[r.ClusterName] = r.Action
+		}
+		assert.DeepEqual(t, actions, map[string]Action{
+			"backup":          ActionCopy,
+			"backup-conflict": ActionOverwrite,
+			"backup-keep":     ActionSkip,
+		})
+
+		for name, want := range map[string]string{"backup": "kubeconfig-backup", "backup-conflict": "kubeconfig-backup-conflict", "backup-keep": "kept"} {
+			for _, doc := range docs {
+				if doc.ClusterName != name {
+					continue
+				}
+				assert.NilError(t, to.Setup(doc.InfraProvider, doc.Region, doc.ClusterName, consts.KsctlClusterType(doc.ClusterType)))
+				got, err := to.Read()
+				assert.NilError(t, err)
+				assert.Equal(t, got.ClusterKubeConfig, want)
+			}
+		}
+	})
+}
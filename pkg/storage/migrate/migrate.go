@@ -0,0 +1,189 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/logger"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ConflictPolicy string
+
+const (
+	// ConflictSkip leaves the clusters already present in the destination untouched
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictOverwrite replaces the state of the clusters already present in the destination
+	ConflictOverwrite ConflictPolicy = "overwrite"
+)
+
+func (p ConflictPolicy) isValid() bool {
+	return p == ConflictSkip || p == ConflictOverwrite
+}
+
+type Action string
+
+const (
+	ActionCopy      Action = "copy"
+	ActionSkip      Action = "skip"
+	ActionOverwrite Action = "overwrite"
+)
+
+type Options struct {
+	// DryRun only reports what would be done, nothing is written to the destination
+	DryRun bool
+
+	// OnConflict decides what happens to clusters present in both the storages, defaults to ConflictSkip
+	OnConflict ConflictPolicy
+
+	// Filters selects the clusters to migrate, same as storage.Storage.GetOneOrMoreClusters
+	Filters map[consts.KsctlSearchFilter]string
+}
+
+type Result struct {
+	InfraProvider consts.KsctlCloud
+	ClusterType   consts.KsctlClusterType
+	ClusterName   string
+	Region        string
+
+	Action Action
+}
+
+// Migrate copies the state of every cluster from the source storage to the destination storage.
+// Every copy is read back from the destination and compared with the source, a mismatch fails the migration.
+// The results are returned for the clusters handled till the first error
+func Migrate(ctx context.Context, l logger.Logger, from, to storage.Storage, opts Options) ([]Result, error) {
+	ctx = context.WithValue(ctx, consts.KsctlModuleNameKey, "storage-migrate")
+
+	if opts.OnConflict == "" {
+		opts.OnConflict = ConflictSkip
+	}
+	if !opts.OnConflict.isValid() {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			l.NewError(ctx, "invalid conflict policy", "policy", opts.OnConflict),
+		)
+	}
+	filters := opts.Filters
+	if filters == nil {
+		filters = map[consts.KsctlSearchFilter]string{}
+	}
+
+	clusters, err := from.GetOneOrMoreClusters(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	for clusterType, docs := range clusters {
+		for _, doc := range docs {
+			if doc == nil {
+				continue
+			}
+			res := Result{
+				InfraProvider: doc.InfraProvider,
+				ClusterType:   clusterType,
+				ClusterName:   doc.ClusterName,
+				Region:        doc.Region,
+			}
+
+			if err := to.Setup(doc.InfraProvider, doc.Region, doc.ClusterName, clusterType); err != nil {
+				return results, err
+			}
+
+			existing, err := to.Read()
+			if err != nil && !ksctlErrors.IsNoMatchingRecordsFound(err) {
+				return results, err
+			}
+
+			res.Action = actionFor(existing, opts.OnConflict)
+			l.Debug(ctx, "storage.migrate", "cluster", res.ClusterName, "region", res.Region, "cloud", res.InfraProvider, "type", res.ClusterType, "action", res.Action)
+
+			if opts.DryRun || res.Action == ActionSkip {
+				results = append(results, res)
+				continue
+			}
+
+			if err := copyDocument(ctx, l, to, doc, existing); err != nil {
+				return results, err
+			}
+			results = append(results, res)
+		}
+	}
+
+	return results, nil
+}
+
+// actionFor decides what happens to a cluster given its state already present in the destination, if any
+func actionFor(existing *statefile.StorageDocument, policy ConflictPolicy) Action {
+	if existing == nil {
+		return ActionCopy
+	}
+	if policy == ConflictSkip {
+		return ActionSkip
+	}
+	return ActionOverwrite
+}
+
+// copyDocument writes the doc to the storage which was already Setup for it and verifies the copy
+func copyDocument(ctx context.Context, l logger.Logger, to storage.Storage, doc, existing *statefile.StorageDocument) error {
+	cp := *doc
+	cp.ID = primitive.NilObjectID
+	cp.Revision = 0
+	if existing != nil {
+		cp.ID = existing.ID
+		cp.Revision = existing.Revision
+	}
+
+	if err := to.Write(&cp); err != nil {
+		return err
+	}
+
+	got, err := to.Read()
+	if err != nil {
+		return err
+	}
+
+	want, err := canonical(doc)
+	if err != nil {
+		return err
+	}
+	have, err := canonical(got)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(want, have) {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			l.NewError(ctx, "state copied to the destination doesn't match the source", "cluster", doc.ClusterName, "region", doc.Region),
+		)
+	}
+	return nil
+}
+
+// canonical serializes the doc without the fields owned by the storage it was read from
+func canonical(doc *statefile.StorageDocument) ([]byte, error) {
+	v := *doc
+	v.ID = primitive.NilObjectID
+	v.Revision = 0
+	return json.Marshal(v)
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	"github.com/ksctl/ksctl/v2/pkg/logger"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage/host"
+	"github.com/ksctl/ksctl/v2/pkg/storage/kubernetes"
+	"gotest.tools/v3/assert"
+)
+
+var (
+	parentCtx    = context.WithValue(context.TODO(), consts.KsctlTestFlagKey, "true")
+	parentLogger = logger.NewStructuredLogger(-1, os.Stdout)
+)
+
+func fakeDocument(cloud consts.KsctlCloud, name, region string, clusterType consts.KsctlClusterType) *statefile.StorageDocument {
+	return &statefile.StorageDocument{
+		InfraProvider:     cloud,
+		ClusterName:       name,
+		Region:            region,
+		ClusterType:       string(clusterType),
+		ClusterKubeConfig: "kubeconfig-" + name,
+		SSHKeyPair:        statefile.SSHKeyPairState{PublicKey: "public", PrivateKey: "private"},
+		CloudInfra: &statefile.InfrastructureState{
+			Azure: &statefile.StateConfigurationAzure{ResourceGroupName: "rg-" + name},
+		},
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	from := host.NewClient(context.WithValue(parentCtx, consts.KsctlCustomDirLoc, t.TempDir()), parentLogger)
+	to, err := kubernetes.NewClient(parentCtx, parentLogger)
+	assert.NilError(t, err)
+
+	docs := []*statefile.StorageDocument{
+		fakeDocument(consts.CloudAzure, "demo", "eastus", consts.ClusterTypeSelfMang),
+		fakeDocument(consts.CloudAzure, "conflict", "eastus", consts.ClusterTypeMang),
+	}
+	for _, doc := range docs {
+		assert.NilError(t, from.Setup(doc.InfraProvider, doc.Region, doc.ClusterName, consts.KsctlClusterType(doc.ClusterType)))
+		assert.NilError(t, from.Write(doc))
+	}
+
+	stale := fakeDocument(consts.CloudAzure, "conflict", "eastus", consts.ClusterTypeMang)
+	stale.ClusterKubeConfig = "stale"
+	assert.NilError(t, to.Setup(stale.InfraProvider, stale.Region, stale.ClusterName, consts.ClusterTypeMang))
+	assert.NilError(t, to.Write(stale))
+
+	actions := func(results []Result) map[string]Action {
+		out := make(map[string]Action)
+		for _, r := range results {
+			out[r.ClusterName] = r.Action
+		}
+		return out
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		results, err := Migrate(parentCtx, parentLogger, from, to, Options{DryRun: true, OnConflict: ConflictOverwrite})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, actions(results), map[string]Action{"demo": ActionCopy, "conflict": ActionOverwrite})
+
+		assert.NilError(t, to.Setup(consts.CloudAzure, "eastus", "demo", consts.ClusterTypeSelfMang))
+		_, err = to.Read()
+		assert.ErrorContains(t, err, "NoMatchingRecordsFound")
+	})
+
+	t.Run("skip conflicts", func(t *testing.T) {
+		results, err := Migrate(parentCtx, parentLogger, from, to, Options{})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, actions(results), map[string]Action{"demo": ActionCopy, "conflict": ActionSkip})
+
+		assert.NilError(t, to.Setup(consts.CloudAzure, "eastus", "demo", consts.ClusterTypeSelfMang))
+		got, err := to.Read()
+		assert.NilError(t, err)
+		assert.Equal(t, got.ClusterKubeConfig, "kubeconfig-demo")
+		assert.Equal(t, got.CloudInfra.Azure.ResourceGroupName, "rg-demo")
+
+		assert.NilError(t, to.Setup(consts.CloudAzure, "eastus", "conflict", consts.ClusterTypeMang))
+		got, err = to.Read()
+		assert.NilError(t, err)
+		assert.Equal(t, got.ClusterKubeConfig, "stale")
+	})
+
+	t.Run("overwrite conflicts", func(t *testing.T) {
+		results, err := Migrate(parentCtx, parentLogger, from, to, Options{
+			OnConflict: ConflictOverwrite,
+			Filters:    map[consts.KsctlSearchFilter]string{consts.ClusterType: string(consts.ClusterTypeMang)},
+		})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, actions(results), map[string]Action{"conflict": ActionOverwrite})
+
+		assert.NilError(t, to.Setup(consts.CloudAzure, "eastus", "conflict", consts.ClusterTypeMang))
+		got, err := to.Read()
+		assert.NilError(t, err)
+		assert.Equal(t, got.ClusterKubeConfig, "kubeconfig-conflict")
+		assert.Equal(t, got.Revision, int64(2))
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		_, err := Migrate(parentCtx, parentLogger, from, to, Options{OnConflict: "merge"})
+		assert.ErrorContains(t, err, "invalid conflict policy")
+	})
+}
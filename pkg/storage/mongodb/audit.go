@@ -0,0 +1,102 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongodb
+
+import (
+	"context"
+
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"go.mongodb.org/mongo-driver/bson"
+	mongoOptions "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// auditCollection is left as is by DeleteCluster so that the trail outlives the cluster
+const auditCollection = "audit"
+
+func getAuditFilters(q storage.AuditQuery) bson.M {
+	f := bson.M{}
+	exact := map[string]string{
+		"cloud":        string(q.Cloud),
+		"region":       q.Region,
+		"cluster_name": q.ClusterName,
+		"cluster_type": string(q.ClusterType),
+		"actor":        q.Actor,
+		"group":        q.Group,
+		"operation":    string(q.Operation),
+	}
+	for k, v := range exact {
+		if len(v) != 0 {
+			f[k] = v
+		}
+	}
+
+	started := bson.M{}
+	if !q.Since.IsZero() {
+		started["$gte"] = q.Since
+	}
+	if !q.Until.IsZero() {
+		started["$lt"] = q.Until
+	}
+	if len(started) != 0 {
+		f["started_at"] = started
+	}
+	return f
+}
+
+func (db *Store) RecordAudit(entry *storage.AuditEntry) error {
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	if _, err := db.databaseClient.Collection(auditCollection).InsertOne(db.ctx, entry); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to insert the audit entry", "Reason", err),
+		)
+	}
+	return nil
+}
+
+func (db *Store) QueryAudit(query storage.AuditQuery) ([]*storage.AuditEntry, error) {
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	opts := mongoOptions.Find().SetSort(bson.D{{Key: "started_at", Value: -1}, {Key: "id", Value: -1}})
+	if query.Limit > 0 {
+		opts.SetLimit(int64(query.Limit))
+	}
+
+	c, err := db.databaseClient.Collection(auditCollection).Find(db.ctx, getAuditFilters(query), opts)
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to list the audit entries", "Reason", err),
+		)
+	}
+	defer func() { _ = c.Close(context.Background()) }()
+
+	var entries []*storage.AuditEntry
+	for c.Next(context.Background()) {
+		var v storage.AuditEntry
+		if err := c.Decode(&v); err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to deserialize the audit entry", "Reason", err),
+			)
+		}
+		entries = append(entries, &v)
+	}
+	return storage.FilterAuditEntries(entries, query), nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongodb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"github.com/ksctl/ksctl/v2/pkg/storage/encryption"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mongoOptions "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const historyCollection = "history"
+
+type historyDocument struct {
+	ClusterType   string `bson:"cluster_type"`
+	Region        string `bson:"region"`
+	ClusterName   string `bson:"cluster_name"`
+	CloudProvider string `bson:"cloud_provider"`
+
+	storage.StateRevision `bson:",inline"`
+}
+
+// recordRevision keeps a copy of the state just written and removes the revisions which are older than the ones to be kept
+func (db *Store) recordRevision(doc *statefile.StorageDocument) error {
+	c := db.databaseClient.Collection(historyCollection)
+
+	if _, err := c.InsertOne(db.ctx, historyDocument{
+		ClusterType:   db.clusterType,
+		Region:        db.region,
+		ClusterName:   db.clusterName,
+		CloudProvider: db.cloudProvider,
+		StateRevision: *storage.NewStateRevision(db.writer, doc),
+	}); err != nil {
+		return err
+	}
+
+	f := getClusterFilters(db)
+	f["revision"] = bson.M{"$lte": doc.Revision - int64(consts.CounterMaxStateRevisions)}
+	_, err := c.DeleteMany(db.ctx, f)
+	return err
+}
+
+func (db *Store) ListRevisions() ([]*storage.StateRevision, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	c, err := db.databaseClient.Collection(historyCollection).Find(
+		db.ctx,
+		getClusterFilters(db),
+		mongoOptions.Find().
+			SetSort(bson.D{{Key: "revision", Value: -1}}).
+			SetProjection(bson.M{"document": 0}),
+	)
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to list the revisions", "Reason", err),
+		)
+	}
+	defer func() { _ = c.Close(context.Background()) }()
+
+	var out []*storage.StateRevision
+	for c.Next(context.Background()) {
+		var v historyDocument
+		if err := c.Decode(&v); err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to deserialize the revision", "Reason", err),
+			)
+		}
+		out = append(out, &v.StateRevision)
+	}
+	return out, nil
+}
+
+func (db *Store) ReadRevision(revision int64) (*storage.StateRevision, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	f := getClusterFilters(db)
+	f["revision"] = revision
+
+	var v historyDocument
+	if err := db.databaseClient.Collection(historyCollection).FindOne(db.ctx, f).Decode(&v); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrNoMatchingRecordsFound,
+				db.l.NewError(db.ctx, "revision not present", "revision", revision),
+			)
+		}
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to get the revision", "Reason", err),
+		)
+	}
+
+	if err := encryption.OpenDocument(db.enc, v.Document); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to decrypt the state", "Reason", err),
+		)
+	}
+	return &v.StateRevision, nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongodb
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mongoOptions "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	collectionLocks = "locks"
+)
+
+type lockDocument struct {
+	Key                  string `bson:"_id"`
+	storage.ClusterLease `bson:",inline"`
+}
+
+func getLockKey(db *Store) string {
+	return fmt.Sprintf("%s.%s.%s.%s", db.cloudProvider, db.clusterType, db.clusterName, db.region)
+}
+
+// ensureLockIndex makes mongodb remove the expired leases on its own
+func (db *Store) ensureLockIndex() error {
+	_, err := db.databaseClient.Collection(collectionLocks).Indexes().CreateOne(db.ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: mongoOptions.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+func (db *Store) Lock(owner string, ttl time.Duration) (*storage.ClusterLease, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := db.ensureLockIndex(); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to create the ttl index for locks", "Reason", err),
+		)
+	}
+
+	lease, err := storage.NewClusterLease(owner, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	key := getLockKey(db)
+	lease.Key = key
+	doc := lockDocument{Key: key, ClusterLease: *lease}
+
+	_, err = db.databaseClient.Collection(collectionLocks).InsertOne(db.ctx, doc)
+	if err == nil {
+		return lease, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to acquire the lock", "Reason", err),
+		)
+	}
+
+	// the ttl monitor of mongodb runs periodically so an expired lease might still be around
+	res, err := db.databaseClient.Collection(collectionLocks).ReplaceOne(db.ctx, bson.M{
+		"_id":        key,
+		"expires_at": bson.M{"$lte": time.Now().UTC()},
+	}, doc)
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to acquire the lock", "Reason", err),
+		)
+	}
+	if res.MatchedCount == 1 {
+		return lease, nil
+	}
+
+	var holder lockDocument
+	if err := db.databaseClient.Collection(collectionLocks).FindOne(db.ctx, bson.M{"_id": key}).Decode(&holder); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrClusterLocked,
+			db.l.NewError(db.ctx, "cluster is locked by someone else", "Reason", err),
+		)
+	}
+	return nil, ksctlErrors.WrapError(
+		ksctlErrors.ErrClusterLocked,
+		db.l.NewError(db.ctx, "cluster is "+holder.ClusterLease.String()),
+	)
+}
+
+func (db *Store) Renew(lease *storage.ClusterLease, ttl time.Duration) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	expiresAt := time.Now().UTC().Add(ttl)
+	res, err := db.databaseClient.Collection(collectionLocks).UpdateOne(db.ctx, bson.M{
+		"_id": lease.Key,
+		"id":  lease.ID,
+	}, bson.M{
+		"$set": bson.M{"expires_at": expiresAt},
+	})
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to renew the lock", "Reason", err),
+		)
+	}
+	if res.MatchedCount == 0 {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrClusterLocked,
+			db.l.NewError(db.ctx, "lease got lost", "id", lease.ID),
+		)
+	}
+	lease.ExpiresAt = expiresAt
+	return nil
+}
+
+func (db *Store) Unlock(lease *storage.ClusterLease) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	_, err := db.databaseClient.Collection(collectionLocks).DeleteOne(db.ctx, bson.M{
+		"_id": lease.Key,
+		"id":  lease.ID,
+	})
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to release the lock", "Reason", err),
+		)
+	}
+	return nil
+}
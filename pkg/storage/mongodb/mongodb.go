@@ -20,8 +20,11 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/ksctl/ksctl/v2/migrations"
 	"github.com/ksctl/ksctl/v2/pkg/logger"
 	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"github.com/ksctl/ksctl/v2/pkg/storage/encryption"
 
 	"github.com/ksctl/ksctl/v2/pkg/consts"
 	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
@@ -71,15 +74,25 @@ type Store struct {
 
 	mu *sync.Mutex
 	wg *sync.WaitGroup
+
+	// enc seals the secrets of the state at rest, nil keeps the state in plaintext
+	enc encryption.Encrypter
+
+	// writer is recorded along every revision of the state
+	writer string
+
+	// noUpgrade hands out the documents as stored, set on the copy used while upgrading them in bulk
+	noUpgrade bool
 }
 
 func (conn *MongoConn) NewDatabaseClient(ksctlConfig context.Context, l logger.Logger) (*Store, error) {
 
 	db := &Store{
-		ctx: conn.ctx,
-		l:   l,
-		mu:  conn.mu,
-		wg:  new(sync.WaitGroup),
+		ctx:    conn.ctx,
+		l:      l,
+		mu:     conn.mu,
+		wg:     new(sync.WaitGroup),
+		writer: storage.WriterFromContext(ksctlConfig),
 	}
 
 	db.databaseClient = conn.client.Database("ksctl-db")
@@ -96,10 +109,73 @@ func getClusterFilters(db *Store) bson.M {
 	}
 }
 
+// getRevisionFilters matches the cluster only when it is still at the given revision,
+// documents written before revisions got introduced are treated as revision 0
+func getRevisionFilters(db *Store, revision int64) bson.M {
+	f := getClusterFilters(db)
+	if revision == 0 {
+		f["$or"] = bson.A{
+			bson.M{"revision": int64(0)},
+			bson.M{"revision": bson.M{"$exists": false}},
+		}
+	} else {
+		f["revision"] = revision
+	}
+	return f
+}
+
+func (db *Store) SetEncrypter(enc encryption.Encrypter) {
+	db.enc = enc
+}
+
+// WithoutSchemaUpgrade returns a copy of the store handing out the documents as stored,
+// the copy shares the connection with the store and is Setup on its own
+func (db *Store) WithoutSchemaUpgrade() storage.Storage {
+	raw := *db
+	raw.noUpgrade = true
+	return &raw
+}
+
+// upgradeDocument brings the document read to the latest schema version, the upgraded document is stored on the next write
+func (db *Store) upgradeDocument(doc *statefile.StorageDocument) error {
+	if db.noUpgrade {
+		return nil
+	}
+	if _, err := migrations.UpgradeDocument(doc); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidVersion,
+			db.l.NewError(db.ctx, "failed to upgrade the state", "Reason", err),
+		)
+	}
+	return nil
+}
+
+// ensureClusterIndex keeps a single document per cluster in the collection of the cloud,
+// so that of two first writes of a cluster racing each other only one gets inserted
+func (db *Store) ensureClusterIndex(cloud string) error {
+	_, err := db.databaseClient.Collection(cloud).Indexes().CreateOne(db.ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "cluster_name", Value: 1},
+			{Key: "region", Value: 1},
+			{Key: "cluster_type", Value: 1},
+		},
+		Options: mongoOptions.Index().SetUnique(true),
+	})
+	return err
+}
+
 func (db *Store) Connect(_ context.Context) error {
 
 	db.l.Debug(db.ctx, "CONN to MongoDB")
 
+	for _, cloud := range []consts.KsctlCloud{consts.CloudAws, consts.CloudAzure, consts.CloudLocal} {
+		if err := db.ensureClusterIndex(string(cloud)); err != nil {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to create the unique index for clusters", "cloud", cloud, "Reason", err),
+			)
+		}
+	}
 	return nil
 }
 
@@ -118,6 +194,15 @@ func (db *Store) Read() (*statefile.StorageDocument, error) {
 				db.l.NewError(db.ctx, "failed to deserialize the state", "Reason", err),
 			)
 		}
+		if err := encryption.OpenDocument(db.enc, result); err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to decrypt the state", "Reason", err),
+			)
+		}
+		if err := db.upgradeDocument(result); err != nil {
+			return nil, err
+		}
 
 		return result, nil
 	} else {
@@ -132,8 +217,23 @@ func (db *Store) Write(data *statefile.StorageDocument) error {
 	db.wg.Add(1)
 	defer db.wg.Done()
 
-	bsonMap, err := bson.Marshal(data)
+	migrations.StampDocument(data)
+
+	expectedRevision := data.Revision
+	data.Revision = expectedRevision + 1
+
+	sealed, err := encryption.SealDocument(db.enc, data)
 	if err != nil {
+		data.Revision = expectedRevision
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to encrypt the state", "Reason", err),
+		)
+	}
+
+	bsonMap, err := bson.Marshal(sealed)
+	if err != nil {
+		data.Revision = expectedRevision
 		return ksctlErrors.WrapError(
 			ksctlErrors.ErrInternal,
 			db.l.NewError(db.ctx, "failed to serialize the state", "Reason", err),
@@ -141,22 +241,49 @@ func (db *Store) Write(data *statefile.StorageDocument) error {
 	}
 
 	if _, err := db.isPresent(); err == nil {
-		res := db.databaseClient.Collection(db.cloudProvider).FindOneAndReplace(db.ctx, getClusterFilters(db), bsonMap)
-		if _err := res.Err(); _err != nil {
+		res, _err := db.databaseClient.Collection(db.cloudProvider).ReplaceOne(db.ctx, getRevisionFilters(db, expectedRevision), bsonMap)
+		if _err != nil {
+			data.Revision = expectedRevision
 			return ksctlErrors.WrapError(
 				ksctlErrors.ErrInternal,
 				db.l.NewError(db.ctx, "failed to update the state", "Reason", _err),
 			)
 		}
+		if res.MatchedCount == 0 {
+			data.Revision = expectedRevision
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrStaleStateWrite,
+				db.l.NewError(db.ctx, "state got updated by someone else", "revision", expectedRevision),
+			)
+		}
 	} else {
+		// the store might not be connected, the index has to be there before the cluster gets inserted
+		if err := db.ensureClusterIndex(db.cloudProvider); err != nil {
+			data.Revision = expectedRevision
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to create the unique index for clusters", "Reason", err),
+			)
+		}
 		_, _err := db.databaseClient.Collection(db.cloudProvider).InsertOne(db.ctx, bsonMap)
 		if _err != nil {
+			data.Revision = expectedRevision
+			if mongo.IsDuplicateKeyError(_err) {
+				return ksctlErrors.WrapError(
+					ksctlErrors.ErrStaleStateWrite,
+					db.l.NewError(db.ctx, "state got created by someone else", "revision", expectedRevision),
+				)
+			}
 			return ksctlErrors.WrapError(
 				ksctlErrors.ErrInternal,
 				db.l.NewError(db.ctx, "failed to write state", "Reason", _err),
 			)
 		}
 	}
+
+	if err := db.recordRevision(sealed); err != nil {
+		db.l.Warn(db.ctx, "failed to record the state revision", "revision", data.Revision, "Reason", err)
+	}
 	return nil
 }
 
@@ -191,6 +318,9 @@ func (db *Store) DeleteCluster() error {
 	if err != nil {
 		return err
 	}
+	if _, err := db.databaseClient.Collection(historyCollection).DeleteMany(db.ctx, getClusterFilters(db)); err != nil {
+		db.l.Warn(db.ctx, "failed to remove the state revisions", "Reason", err)
+	}
 
 	return nil
 }
@@ -252,16 +382,11 @@ func (db *Store) AlreadyCreated(cloud consts.KsctlCloud, region, clusterName str
 	return db.clusterPresent()
 }
 
-func (db *Store) GetOneOrMoreClusters(filters map[consts.KsctlSearchFilter]string) (map[consts.KsctlClusterType][]*statefile.StorageDocument, error) {
-	db.mu.Lock()
-	defer db.mu.Unlock()
-	db.wg.Add(1)
-	defer db.wg.Done()
+// getFilterPaths returns the collections of the clouds and the cluster types selected by the filters
+func getFilterPaths(filters map[consts.KsctlSearchFilter]string) (filterCloudPath, filterClusterType []string) {
 	clusterType := filters[consts.ClusterType]
 	cloud := filters[consts.Cloud]
 
-	var filterCloudPath, filterClusterType []string
-
 	switch cloud {
 	case string(consts.CloudAll), "":
 		filterCloudPath = append(filterCloudPath, string(consts.CloudAws), string(consts.CloudAzure), string(consts.CloudLocal))
@@ -286,6 +411,50 @@ func (db *Store) GetOneOrMoreClusters(filters map[consts.KsctlSearchFilter]strin
 	case "":
 		filterClusterType = append(filterClusterType, string(consts.ClusterTypeMang), string(consts.ClusterTypeSelfMang))
 	}
+	return
+}
+
+// decodeClusters returns the states the cursor points to, the cursor is closed once they are read
+func (db *Store) decodeClusters(c *mongo.Cursor) ([]*statefile.StorageDocument, error) {
+	defer func() { _ = c.Close(context.Background()) }()
+
+	var clusters []*statefile.StorageDocument
+	for c.Next(context.Background()) {
+		var result *statefile.StorageDocument
+		if err := c.Decode(&result); err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to deserialize the state", "Reason", err),
+			)
+		}
+		if err := encryption.OpenDocument(db.enc, result); err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to decrypt the state", "Reason", err),
+			)
+		}
+		if err := db.upgradeDocument(result); err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, result)
+	}
+	return clusters, nil
+}
+
+func (db *Store) GetOneOrMoreClusters(filters map[consts.KsctlSearchFilter]string) (map[consts.KsctlClusterType][]*statefile.StorageDocument, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	if err := storage.ValidateFilters(filters); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			db.l.NewError(db.ctx, "invalid filters", "Reason", err),
+		)
+	}
+
+	filterCloudPath, filterClusterType := getFilterPaths(filters)
 	db.l.Debug(db.ctx, "storage.external.mongodb.GetOneOrMoreClusters", "filter", filters, "filterCloudPath", filterCloudPath, "filterClusterType", filterClusterType)
 
 	clustersInfo := make(map[consts.KsctlClusterType][]*statefile.StorageDocument)
@@ -293,10 +462,11 @@ func (db *Store) GetOneOrMoreClusters(filters map[consts.KsctlSearchFilter]strin
 	for _, cloud := range filterCloudPath {
 		for _, clusterType := range filterClusterType {
 
-			c, err := db.databaseClient.Collection(cloud).Find(db.ctx, bson.M{
-				"cloud_provider": cloud,
-				"cluster_type":   clusterType,
-			})
+			query := getQueryFilters(filters)
+			query["cloud_provider"] = cloud
+			query["cluster_type"] = clusterType
+
+			c, err := db.databaseClient.Collection(cloud).Find(db.ctx, query)
 			if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
 				return nil, ksctlErrors.WrapError(
 					ksctlErrors.ErrInternal,
@@ -304,19 +474,10 @@ func (db *Store) GetOneOrMoreClusters(filters map[consts.KsctlSearchFilter]strin
 				)
 			}
 
-			var clusters []*statefile.StorageDocument
-			for c.Next(context.Background()) {
-				var result *statefile.StorageDocument
-				if err := c.Decode(&result); err != nil {
-					_ = c.Close(context.Background())
-					return nil, ksctlErrors.WrapError(
-						ksctlErrors.ErrInternal,
-						db.l.NewError(db.ctx, "failed to deserialize the state", "Reason", err),
-					)
-				}
-				clusters = append(clusters, result)
+			clusters, err := db.decodeClusters(c)
+			if err != nil {
+				return nil, err
 			}
-			_ = c.Close(context.Background())
 
 			clustersInfo[consts.KsctlClusterType(clusterType)] = append(clustersInfo[consts.KsctlClusterType(clusterType)], clusters...)
 		}
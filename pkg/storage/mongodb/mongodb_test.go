@@ -24,8 +24,11 @@ import (
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"github.com/ksctl/ksctl/v2/pkg/storage/storagetest"
 
 	"github.com/docker/docker/api/types/image"
 
@@ -36,6 +39,7 @@ import (
 
 	"github.com/gookit/goutil/dump"
 	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
 	"github.com/ksctl/ksctl/v2/pkg/logger"
 )
 
@@ -167,6 +171,191 @@ func TestStore_RWD(t *testing.T) {
 	}
 }
 
+func TestStore_StaleWrite(t *testing.T) {
+	storagetest.StaleWrite(t, db)
+}
+
+func TestStore_Lock(t *testing.T) {
+	if err := db.Setup(consts.CloudAzure, "region", "locked", consts.ClusterTypeSelfMang); err != nil {
+		t.Fatal(err)
+	}
+	locker := db
+
+	lease, err := locker.Lock("alice", time.Minute)
+	assert.NilError(t, err)
+
+	_, err = locker.Lock("bob", time.Minute)
+	assert.Check(t, ksctlErrors.IsClusterLocked(err), fmt.Sprintf("expected cluster locked error, got: %v", err))
+	assert.ErrorContains(t, err, "locked by alice")
+
+	// the lease keeps to its cluster while the store is Setup for another one
+	if err := db.Setup(consts.CloudAws, "region", "other", consts.ClusterTypeMang); err != nil {
+		t.Fatal(err)
+	}
+	assert.NilError(t, locker.Renew(lease, 2*time.Minute))
+	assert.NilError(t, locker.Unlock(lease))
+	if err := db.Setup(consts.CloudAzure, "region", "locked", consts.ClusterTypeSelfMang); err != nil {
+		t.Fatal(err)
+	}
+
+	lease, err = locker.Lock("bob", time.Minute)
+	assert.NilError(t, err)
+	assert.NilError(t, locker.Unlock(lease))
+}
+
+func TestStore_History(t *testing.T) {
+	storagetest.History(t, db)
+}
+func TestStore_Audit(t *testing.T) {
+	storagetest.Audit(t, db)
+}
+
+func nextWatchEvent(t *testing.T, ch <-chan storage.WatchEvent) storage.WatchEvent {
+	t.Helper()
+	select {
+	case ev, ok := <-ch:
+		assert.Assert(t, ok, "watch got closed")
+		return ev
+	case <-time.After(10 * time.Second):
+		t.Fatal("no watch event got delivered")
+	}
+	return storage.WatchEvent{}
+}
+
+func TestStore_Watch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := db.Watch(ctx, map[consts.KsctlSearchFilter]string{"cloud": "local"})
+	if err != nil {
+		// change streams are served only by replica sets
+		t.Skipf("change streams are not available: %v", err)
+	}
+
+	if err := db.Setup(consts.CloudLocal, "region", "watched", consts.ClusterTypeMang); err != nil {
+		t.Fatal(err)
+	}
+	doc := &statefile.StorageDocument{
+		Region:        "region",
+		ClusterName:   "watched",
+		ClusterType:   string(consts.ClusterTypeMang),
+		InfraProvider: consts.CloudLocal,
+		PlatformSpec:  statefile.PlatformSpec{State: statefile.Creating},
+	}
+	assert.NilError(t, db.Write(doc))
+	ev := nextWatchEvent(t, events)
+	assert.Equal(t, ev.Type, storage.WatchEventAdded)
+	assert.Equal(t, ev.Document.ClusterName, "watched")
+
+	doc.PlatformSpec.State = statefile.Running
+	assert.NilError(t, db.Write(doc))
+	ev = nextWatchEvent(t, events)
+	assert.Equal(t, ev.Type, storage.WatchEventUpdated)
+	assert.Equal(t, ev.Document.PlatformSpec.State, statefile.Running)
+
+	assert.NilError(t, db.DeleteCluster())
+	ev = nextWatchEvent(t, events)
+	assert.Equal(t, ev.Type, storage.WatchEventDeleted)
+	assert.Equal(t, ev.Document.ClusterName, "watched")
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestQueryClusters(t *testing.T) {
+	store := db
+
+	version := func(v string) *string { return &v }
+	fixtures := []*statefile.StorageDocument{
+		{
+			ClusterName: "alpha", Region: "eastus", ClusterType: string(consts.ClusterTypeMang), InfraProvider: consts.CloudAzure,
+			Versions:     statefile.ComponentVersions{Aks: version("1.30.4")},
+			PlatformSpec: statefile.PlatformSpec{State: statefile.Running, Owner: "alice", Team: "payments", Labels: map[string]string{"env": "prod", "cost-center": "42"}},
+		},
+		{
+			ClusterName: "bravo", Region: "us-east-1", ClusterType: string(consts.ClusterTypeMang), InfraProvider: consts.CloudAws,
+			Versions:     statefile.ComponentVersions{Eks: version("1.31")},
+			PlatformSpec: statefile.PlatformSpec{State: statefile.Creating, Owner: "bob", Team: "payments", Labels: map[string]string{"env": "dev"}},
+		},
+		{
+			ClusterName: "charlie", Region: "us-east-1", ClusterType: string(consts.ClusterTypeSelfMang), InfraProvider: consts.CloudAws,
+			BootstrapProvider: consts.K8sK3s,
+			Versions:          statefile.ComponentVersions{K3s: version("v1.30.2+k3s1")},
+			PlatformSpec:      statefile.PlatformSpec{State: statefile.Running, Owner: "alice", Team: "search", Labels: map[string]string{"env": "prod"}},
+		},
+		{
+			ClusterName: "delta", Region: "eastus", ClusterType: string(consts.ClusterTypeSelfMang), InfraProvider: consts.CloudAzure,
+			BootstrapProvider: consts.K8sKubeadm,
+			Versions:          statefile.ComponentVersions{Kubeadm: version("1.29.8")},
+			PlatformSpec:      statefile.PlatformSpec{State: statefile.Running, Owner: "carol", Team: "search"},
+		},
+	}
+	for _, doc := range fixtures {
+		assert.NilError(t, store.Setup(doc.InfraProvider, doc.Region, doc.ClusterName, consts.KsctlClusterType(doc.ClusterType)))
+		assert.NilError(t, store.Write(doc))
+	}
+
+	names := func(docs []*statefile.StorageDocument) []string {
+		var out []string
+		for _, d := range docs {
+			out = append(out, d.ClusterName)
+		}
+		return out
+	}
+
+	for _, tc := range []struct {
+		filters map[consts.KsctlSearchFilter]string
+		want    []string
+	}{
+		{map[consts.KsctlSearchFilter]string{}, []string{"alpha", "bravo", "charlie", "delta"}},
+		{map[consts.KsctlSearchFilter]string{consts.State: string(statefile.Running)}, []string{"alpha", "charlie", "delta"}},
+		{map[consts.KsctlSearchFilter]string{consts.Owner: "alice", consts.Team: "search"}, []string{"charlie"}},
+		{map[consts.KsctlSearchFilter]string{consts.BootstrapProvider: string(consts.K8sKubeadm)}, []string{"delta"}},
+		{map[consts.KsctlSearchFilter]string{consts.KubernetesVersion: "1.30"}, []string{"alpha", "charlie"}},
+		{map[consts.KsctlSearchFilter]string{consts.Labels: "env=prod"}, []string{"alpha", "charlie"}},
+		{map[consts.KsctlSearchFilter]string{consts.Labels: "env=prod,cost-center"}, []string{"alpha"}},
+		{map[consts.KsctlSearchFilter]string{consts.Cloud: string(consts.CloudAws), consts.Labels: "env"}, []string{"bravo", "charlie"}},
+	} {
+		page, err := store.QueryClusters(storage.ClusterQuery{Filters: tc.filters})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, names(page.Clusters), tc.want)
+		assert.Equal(t, page.Total, len(tc.want))
+
+		m, err := store.GetOneOrMoreClusters(tc.filters)
+		assert.NilError(t, err)
+		assert.Equal(t, len(m[consts.ClusterTypeMang])+len(m[consts.ClusterTypeSelfMang]), len(tc.want), fmt.Sprintf("filters: %v", tc.filters))
+	}
+
+	page, err := store.QueryClusters(storage.ClusterQuery{SortBy: storage.SortByOwner, Descending: true})
+	assert.NilError(t, err)
+	// the ties are ordered by the identity of the cluster in the same direction
+	assert.DeepEqual(t, names(page.Clusters), []string{"delta", "bravo", "alpha", "charlie"})
+
+	var paged []string
+	query := storage.ClusterQuery{SortBy: storage.SortByRegion, Limit: 3}
+	for {
+		page, err := store.QueryClusters(query)
+		assert.NilError(t, err)
+		assert.Equal(t, page.Total, 4)
+		paged = append(paged, names(page.Clusters)...)
+		if !page.HasMore() {
+			break
+		}
+		query.Offset += query.Limit
+	}
+	assert.DeepEqual(t, paged, []string{"alpha", "delta", "bravo", "charlie"})
+
+	_, err = store.QueryClusters(storage.ClusterQuery{SortBy: "size"})
+	assert.Check(t, ksctlErrors.IsInvalidUserInput(err), fmt.Sprintf("expected invalid user input error, got: %v", err))
+	_, err = store.GetOneOrMoreClusters(map[consts.KsctlSearchFilter]string{consts.Labels: "=prod"})
+	assert.Check(t, ksctlErrors.IsInvalidUserInput(err), fmt.Sprintf("expected invalid user input error, got: %v", err))
+
+	for _, doc := range fixtures {
+		assert.NilError(t, store.Setup(doc.InfraProvider, doc.Region, doc.ClusterName, consts.KsctlClusterType(doc.ClusterType)))
+		assert.NilError(t, store.DeleteCluster())
+	}
+}
+
 func TestGetClusterInfo(t *testing.T) {
 
 	t.Run("Setup some demo clusters", func(t *testing.T) {
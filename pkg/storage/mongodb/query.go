@@ -0,0 +1,204 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongodb
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"github.com/ksctl/ksctl/v2/pkg/storage/encryption"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// getQueryFilters turns the filters which are not the collection or the cluster type into the match
+// of the documents, so that it is the database which selects the clusters
+func getQueryFilters(filters map[consts.KsctlSearchFilter]string) bson.M {
+	m := bson.M{}
+	for filter, field := range map[consts.KsctlSearchFilter]string{
+		consts.Region:            "region",
+		consts.Name:              "cluster_name",
+		consts.State:             "platform.state",
+		consts.Owner:             "platform.owner",
+		consts.Team:              "platform.team",
+		consts.BootstrapProvider: "bootstrap_provider",
+	} {
+		if v := filters[filter]; len(v) != 0 {
+			m[field] = v
+		}
+	}
+
+	var and bson.A
+	if v := filters[consts.KubernetesVersion]; len(v) != 0 {
+		// same as statefile.StorageDocument.KubernetesVersion, the field depends on the kind of cluster
+		version := bson.M{"$regex": "^v?" + regexp.QuoteMeta(strings.TrimPrefix(v, "v")) + `(\.|$)`}
+		and = append(and, bson.M{"$or": bson.A{
+			bson.M{"cluster_type": string(consts.ClusterTypeMang), "cloud_provider": string(consts.CloudAws), "versions.eks": version},
+			bson.M{"cluster_type": string(consts.ClusterTypeMang), "cloud_provider": string(consts.CloudAzure), "versions.aks": version},
+			bson.M{"cluster_type": string(consts.ClusterTypeMang), "cloud_provider": string(consts.CloudLocal), "versions.kind": version},
+			bson.M{"cluster_type": bson.M{"$ne": string(consts.ClusterTypeMang)}, "bootstrap_provider": string(consts.K8sK3s), "versions.k3s": version},
+			bson.M{"cluster_type": bson.M{"$ne": string(consts.ClusterTypeMang)}, "bootstrap_provider": string(consts.K8sKubeadm), "versions.kubeadm": version},
+		}})
+	}
+
+	// the label keys are free form and may hold dots, so they are compared as data instead of as field paths
+	selector, _ := storage.ParseLabelSelector(filters[consts.Labels])
+	labels := bson.M{"$objectToArray": bson.M{"$ifNull": bson.A{"$platform.labels", bson.M{}}}}
+	for k, v := range selector {
+		if len(v) == 0 {
+			and = append(and, bson.M{"$expr": bson.M{"$in": bson.A{
+				k,
+				bson.M{"$map": bson.M{"input": labels, "as": "l", "in": "$$l.k"}},
+			}}})
+		} else {
+			and = append(and, bson.M{"$expr": bson.M{"$in": bson.A{
+				bson.D{{Key: "k", Value: k}, {Key: "v", Value: v}},
+				labels,
+			}}})
+		}
+	}
+
+	if len(and) != 0 {
+		m["$and"] = and
+	}
+	return m
+}
+
+// getSortFields returns the order of the documents, ties are broken by the identity of the cluster
+func getSortFields(field storage.SortField, descending bool) bson.D {
+	dir := 1
+	if descending {
+		dir = -1
+	}
+	first := map[storage.SortField]string{
+		storage.SortByCloud:       "cloud_provider",
+		storage.SortByClusterType: "cluster_type",
+		storage.SortByRegion:      "region",
+		storage.SortByState:       "platform.state",
+		storage.SortByOwner:       "platform.owner",
+		storage.SortByTeam:        "platform.team",
+	}[field]
+	if len(first) == 0 {
+		first = "cluster_name"
+	}
+
+	order := bson.D{{Key: first, Value: dir}}
+	for _, key := range []string{"cloud_provider", "cluster_type", "cluster_name", "region"} {
+		if key != first {
+			order = append(order, bson.E{Key: key, Value: dir})
+		}
+	}
+	return order
+}
+
+// QueryClusters runs a single aggregation over the collections of the clouds selected,
+// the documents are matched, sorted and paged by the database
+func (db *Store) QueryClusters(query storage.ClusterQuery) (*storage.ClusterPage, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	if err := query.Validate(); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			db.l.NewError(db.ctx, "invalid query", "Reason", err),
+		)
+	}
+
+	clouds, clusterTypes := getFilterPaths(query.Filters)
+	page := &storage.ClusterPage{Offset: query.Offset}
+	if len(clouds) == 0 || len(clusterTypes) == 0 {
+		return page, nil
+	}
+
+	match := func(cloud string) bson.M {
+		m := getQueryFilters(query.Filters)
+		m["cloud_provider"] = cloud
+		m["cluster_type"] = bson.M{"$in": clusterTypes}
+		return m
+	}
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: match(clouds[0])}}}
+	for _, cloud := range clouds[1:] {
+		pipeline = append(pipeline, bson.D{{Key: "$unionWith", Value: bson.M{
+			"coll":     cloud,
+			"pipeline": bson.A{bson.M{"$match": match(cloud)}},
+		}}})
+	}
+	pageStages := bson.A{bson.M{"$skip": query.Offset}}
+	if query.Limit > 0 {
+		pageStages = append(pageStages, bson.M{"$limit": query.Limit})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: getSortFields(query.SortBy, query.Descending)}},
+		bson.D{{Key: "$facet", Value: bson.M{
+			"total": bson.A{bson.M{"$count": "n"}},
+			"page":  pageStages,
+		}}},
+	)
+
+	c, err := db.databaseClient.Collection(clouds[0]).Aggregate(db.ctx, pipeline)
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to query the state", "Reason", err),
+		)
+	}
+	var out []struct {
+		Total []struct {
+			N int `bson:"n"`
+		} `bson:"total"`
+		Page []bson.Raw `bson:"page"`
+	}
+	if err := c.All(db.ctx, &out); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to query the state", "Reason", err),
+		)
+	}
+	if len(out) == 0 {
+		return page, nil
+	}
+	if len(out[0].Total) != 0 {
+		page.Total = out[0].Total[0].N
+	}
+
+	for _, raw := range out[0].Page {
+		var result *statefile.StorageDocument
+		if err := bson.Unmarshal(raw, &result); err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to deserialize the state", "Reason", err),
+			)
+		}
+		if err := encryption.OpenDocument(db.enc, result); err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to decrypt the state", "Reason", err),
+			)
+		}
+		if err := db.upgradeDocument(result); err != nil {
+			return nil, err
+		}
+		page.Clusters = append(page.Clusters, result)
+	}
+	return page, nil
+}
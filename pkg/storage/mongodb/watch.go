@@ -0,0 +1,151 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongodb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"github.com/ksctl/ksctl/v2/pkg/storage/encryption"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mongoOptions "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// changeEvent holds the fields of a change stream event used by Watch
+type changeEvent struct {
+	OperationType string `bson:"operationType"`
+	DocumentKey   struct {
+		ID bson.RawValue `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument bson.Raw `bson:"fullDocument"`
+}
+
+// Watch follows the collections of the clouds with a change stream, it needs MongoDB to run as a replica set.
+// The stream is opened before the clusters present get listed so that no change in between is lost
+func (db *Store) Watch(ctx context.Context, filters map[consts.KsctlSearchFilter]string) (<-chan storage.WatchEvent, error) {
+	var clouds bson.A
+	switch v := filters[consts.Cloud]; v {
+	case string(consts.CloudAll), "":
+		clouds = bson.A{string(consts.CloudAws), string(consts.CloudAzure), string(consts.CloudLocal)}
+	default:
+		clouds = bson.A{v}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"ns.coll":       bson.M{"$in": clouds},
+			"operationType": bson.M{"$in": bson.A{"insert", "replace", "update", "delete"}},
+		}}},
+	}
+	stream, err := db.databaseClient.Watch(ctx, pipeline, mongoOptions.ChangeStream().SetFullDocument(mongoOptions.UpdateLookup))
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to open the change stream", "Reason", err),
+		)
+	}
+
+	tracker := storage.NewWatchTracker(filters)
+	var present []bson.Raw
+	for _, cloud := range clouds {
+		c, err := db.databaseClient.Collection(cloud.(string)).Find(ctx, bson.M{"cloud_provider": cloud})
+		if err != nil {
+			_ = stream.Close(context.Background())
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to find the state", "Reason", err),
+			)
+		}
+		for c.Next(ctx) {
+			// Current is only valid until the next call of Next
+			present = append(present, append(bson.Raw(nil), c.Current...))
+		}
+		_ = c.Close(context.Background())
+	}
+
+	events := make(chan storage.WatchEvent)
+	go func() {
+		defer close(events)
+		defer func() { _ = stream.Close(context.Background()) }()
+
+		for _, raw := range present {
+			if !db.watchUpsert(ctx, events, tracker, raw.Lookup("_id"), raw) {
+				return
+			}
+		}
+
+		for stream.Next(ctx) {
+			var ev changeEvent
+			if err := stream.Decode(&ev); err != nil {
+				db.l.Warn(db.ctx, "failed to decode the change event", "Reason", err)
+				continue
+			}
+
+			if ev.OperationType == "delete" {
+				if out, ok := tracker.Remove(ev.DocumentKey.ID.String()); ok && !storage.SendWatchEvent(ctx, events, out) {
+					return
+				}
+				continue
+			}
+			// the lookup of an update finds nothing if the cluster got deleted in the meantime
+			if len(ev.FullDocument) == 0 {
+				continue
+			}
+			if !db.watchUpsert(ctx, events, tracker, ev.DocumentKey.ID, ev.FullDocument) {
+				return
+			}
+		}
+
+		if err := stream.Err(); err != nil && !errors.Is(err, context.Canceled) && ctx.Err() == nil {
+			storage.SendWatchEvent(ctx, events, storage.WatchEvent{
+				Type: storage.WatchEventError,
+				Err: ksctlErrors.WrapError(
+					ksctlErrors.ErrInternal,
+					db.l.NewError(db.ctx, "change stream failed", "Reason", err),
+				),
+			})
+		}
+	}()
+
+	return events, nil
+}
+
+// watchUpsert decodes the stored document and sends the event for it, false means the watch has to stop
+func (db *Store) watchUpsert(ctx context.Context, events chan<- storage.WatchEvent, tracker *storage.WatchTracker, id bson.RawValue, raw bson.Raw) bool {
+	var doc *statefile.StorageDocument
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		db.l.Warn(db.ctx, "failed to deserialize the state", "Reason", err)
+		return true
+	}
+	if err := encryption.OpenDocument(db.enc, doc); err != nil {
+		db.l.Warn(db.ctx, "failed to decrypt the state", "Reason", err)
+		return true
+	}
+	if err := db.upgradeDocument(doc); err != nil {
+		db.l.Warn(db.ctx, "failed to upgrade the state", "Reason", err)
+		return true
+	}
+
+	if ev, ok := tracker.Upsert(id.String(), doc); ok {
+		return storage.SendWatchEvent(ctx, events, ev)
+	}
+	return true
+}
@@ -0,0 +1,225 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+)
+
+type SortField string
+
+const (
+	SortByName        SortField = "name"
+	SortByCloud       SortField = "cloud"
+	SortByClusterType SortField = "clusterType"
+	SortByRegion      SortField = "region"
+	SortByState       SortField = "state"
+	SortByOwner       SortField = "owner"
+	SortByTeam        SortField = "team"
+)
+
+// ClusterQuery selects, orders and pages the clusters of a storage
+type ClusterQuery struct {
+	// Filters takes the same keys as GetOneOrMoreClusters
+	Filters map[consts.KsctlSearchFilter]string
+
+	// SortBy defaults to SortByName, the clusters with the same value are ordered by cloud, cluster type, name and region
+	SortBy     SortField
+	Descending bool
+
+	Offset int
+	// Limit of 0 returns all the clusters after the Offset
+	Limit int
+}
+
+// ClusterPage is the part of the clusters matching a ClusterQuery
+type ClusterPage struct {
+	Clusters []*statefile.StorageDocument
+
+	// Total is the number of clusters matching the filters
+	Total  int
+	Offset int
+}
+
+// HasMore reports if there are clusters after this page
+func (p *ClusterPage) HasMore() bool {
+	return p.Offset+len(p.Clusters) < p.Total
+}
+
+// Querier is implemented by the storage backends which can order and page the clusters
+type Querier interface {
+	QueryClusters(query ClusterQuery) (*ClusterPage, error)
+}
+
+// Validate checks the query before it gets run, the errors are meant for the user
+func (q ClusterQuery) Validate() error {
+	if err := ValidateFilters(q.Filters); err != nil {
+		return err
+	}
+	switch q.SortBy {
+	case "", SortByName, SortByCloud, SortByClusterType, SortByRegion, SortByState, SortByOwner, SortByTeam:
+	default:
+		return fmt.Errorf("cannot sort by %q", q.SortBy)
+	}
+	if q.Offset < 0 || q.Limit < 0 {
+		return fmt.Errorf("offset and limit cannot be negative")
+	}
+	return nil
+}
+
+// ValidateFilters checks the values of the filters which have a syntax of their own
+func ValidateFilters(filters map[consts.KsctlSearchFilter]string) error {
+	if _, err := ParseLabelSelector(filters[consts.Labels]); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ParseLabelSelector reads a selector like "env=prod,cost-center" into the labels required,
+// an empty value means the label only has to be present
+func ParseLabelSelector(selector string) (map[string]string, error) {
+	out := make(map[string]string)
+	for _, part := range strings.Split(selector, ",") {
+		part = strings.TrimSpace(part)
+		if len(part) == 0 {
+			continue
+		}
+		k, v, _ := strings.Cut(part, "=")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if len(k) == 0 {
+			return nil, fmt.Errorf("label selector %q has a requirement without a key", selector)
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+// KubernetesVersionMatches reports if the version is the one wanted or one of its patch releases
+func KubernetesVersionMatches(want, version string) bool {
+	want = strings.TrimPrefix(want, "v")
+	version = strings.TrimPrefix(version, "v")
+	return version == want || strings.HasPrefix(version, want+".")
+}
+
+// MatchesFilters reports if the cluster is selected by the filters accepted by GetOneOrMoreClusters
+func MatchesFilters(filters map[consts.KsctlSearchFilter]string, doc *statefile.StorageDocument) bool {
+	if doc == nil {
+		return false
+	}
+	if v := filters[consts.Cloud]; len(v) != 0 && v != string(consts.CloudAll) && v != string(doc.InfraProvider) {
+		return false
+	}
+
+	exact := []struct {
+		filter consts.KsctlSearchFilter
+		value  string
+	}{
+		{consts.ClusterType, doc.ClusterType},
+		{consts.Region, doc.Region},
+		{consts.Name, doc.ClusterName},
+		{consts.State, string(doc.PlatformSpec.State)},
+		{consts.Owner, doc.PlatformSpec.Owner},
+		{consts.Team, doc.PlatformSpec.Team},
+		{consts.BootstrapProvider, string(doc.BootstrapProvider)},
+	}
+	for _, f := range exact {
+		if v := filters[f.filter]; len(v) != 0 && v != f.value {
+			return false
+		}
+	}
+
+	if v := filters[consts.KubernetesVersion]; len(v) != 0 && !KubernetesVersionMatches(v, doc.KubernetesVersion()) {
+		return false
+	}
+
+	if v := filters[consts.Labels]; len(v) != 0 {
+		selector, err := ParseLabelSelector(v)
+		if err != nil {
+			return false
+		}
+		for k, want := range selector {
+			got, ok := doc.PlatformSpec.Labels[k]
+			if !ok || (len(want) != 0 && got != want) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// sortValue returns the value of the document the clusters get ordered by
+func sortValue(doc *statefile.StorageDocument, field SortField) string {
+	switch field {
+	case SortByCloud:
+		return string(doc.InfraProvider)
+	case SortByClusterType:
+		return doc.ClusterType
+	case SortByRegion:
+		return doc.Region
+	case SortByState:
+		return string(doc.PlatformSpec.State)
+	case SortByOwner:
+		return doc.PlatformSpec.Owner
+	case SortByTeam:
+		return doc.PlatformSpec.Team
+	default:
+		return doc.ClusterName
+	}
+}
+
+// SortClusters orders the clusters in place, the ties are broken by the identity of
+// the cluster so that the pages of a query don't overlap
+func SortClusters(docs []*statefile.StorageDocument, field SortField, descending bool) {
+	identity := func(d *statefile.StorageDocument) string {
+		return strings.Join([]string{string(d.InfraProvider), d.ClusterType, d.ClusterName, d.Region}, "\x00")
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		a, b := sortValue(docs[i], field), sortValue(docs[j], field)
+		if a == b {
+			a, b = identity(docs[i]), identity(docs[j])
+		}
+		if descending {
+			return a > b
+		}
+		return a < b
+	})
+}
+
+// QueryDocuments runs the query over the clusters already loaded by a storage backend
+func QueryDocuments(docs []*statefile.StorageDocument, query ClusterQuery) *ClusterPage {
+	var matched []*statefile.StorageDocument
+	for _, doc := range docs {
+		if MatchesFilters(query.Filters, doc) {
+			matched = append(matched, doc)
+		}
+	}
+	SortClusters(matched, query.SortBy, query.Descending)
+
+	page := &ClusterPage{Total: len(matched), Offset: query.Offset}
+	if query.Offset >= len(matched) {
+		return page
+	}
+	end := len(matched)
+	if query.Limit > 0 && query.Offset+query.Limit < end {
+		end = query.Offset + query.Limit
+	}
+	page.Clusters = matched[query.Offset:end]
+	return page
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// getAuditIndexKey returns the key of the set which holds the keys of all the trails
+func getAuditIndexKey() string {
+	return fmt.Sprintf("%s:audit", keyPrefix)
+}
+
+// getAuditKey returns the key of the list which holds the trail of the cluster, oldest first.
+// It is left as is by DeleteCluster so that the trail outlives the cluster
+func getAuditKey(cloud, clusterType, clusterName, region string) string {
+	return fmt.Sprintf("%s:audit:%s:%s:%s:%s", keyPrefix, cloud, clusterType, clusterName, region)
+}
+
+func (db *Store) RecordAudit(entry *storage.AuditEntry) error {
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to serialize the audit entry", "Reason", err),
+		)
+	}
+
+	key := getAuditKey(string(entry.Cloud), string(entry.ClusterType), entry.ClusterName, entry.Region)
+	if _, err := db.databaseClient.TxPipelined(db.ctx, func(pipe goredis.Pipeliner) error {
+		pipe.RPush(db.ctx, key, raw)
+		pipe.SAdd(db.ctx, getAuditIndexKey(), key)
+		return nil
+	}); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to append the audit entry", "Reason", err),
+		)
+	}
+	return nil
+}
+
+func (db *Store) QueryAudit(query storage.AuditQuery) ([]*storage.AuditEntry, error) {
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	var keys []string
+	if query.IsClusterScoped() {
+		keys = append(keys, getAuditKey(string(query.Cloud), string(query.ClusterType), query.ClusterName, query.Region))
+	} else {
+		v, err := db.databaseClient.SMembers(db.ctx, getAuditIndexKey()).Result()
+		if err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to list the audit trails", "Reason", err),
+			)
+		}
+		keys = v
+	}
+
+	var entries []*storage.AuditEntry
+	for _, key := range keys {
+		values, err := db.databaseClient.LRange(db.ctx, key, 0, -1).Result()
+		if err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to read the audit trail", "Reason", err),
+			)
+		}
+		for _, raw := range values {
+			var v *storage.AuditEntry
+			if err := json.Unmarshal([]byte(raw), &v); err != nil {
+				return nil, ksctlErrors.WrapError(
+					ksctlErrors.ErrInternal,
+					db.l.NewError(db.ctx, "failed to deserialize the audit entry", "Reason", err),
+				)
+			}
+			entries = append(entries, v)
+		}
+	}
+	return storage.FilterAuditEntries(entries, query), nil
+}
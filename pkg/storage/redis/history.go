@@ -0,0 +1,91 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"encoding/json"
+	"strconv"
+
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"github.com/ksctl/ksctl/v2/pkg/storage/encryption"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func (db *Store) ListRevisions() ([]*storage.StateRevision, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	values, err := db.databaseClient.ZRevRange(db.ctx, getHistoryKey(db), 0, -1).Result()
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to list the revisions", "Reason", err),
+		)
+	}
+
+	var out []*storage.StateRevision
+	for _, raw := range values {
+		var v *storage.StateRevision
+		if err := json.Unmarshal([]byte(raw), &v); err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to deserialize the revision", "Reason", err),
+			)
+		}
+		v.Document = nil
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func (db *Store) ReadRevision(revision int64) (*storage.StateRevision, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	score := strconv.FormatInt(revision, 10)
+	values, err := db.databaseClient.ZRangeByScore(db.ctx, getHistoryKey(db), &goredis.ZRangeBy{Min: score, Max: score}).Result()
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to get the revision", "Reason", err),
+		)
+	}
+	if len(values) == 0 {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrNoMatchingRecordsFound,
+			db.l.NewError(db.ctx, "revision not present", "revision", revision),
+		)
+	}
+
+	var v *storage.StateRevision
+	if err := json.Unmarshal([]byte(values[0]), &v); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to deserialize the revision", "Reason", err),
+		)
+	}
+	if err := encryption.OpenDocument(db.enc, v.Document); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to decrypt the state", "Reason", err),
+		)
+	}
+	return v, nil
+}
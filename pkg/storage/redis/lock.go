@@ -0,0 +1,173 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func getLockKey(db *Store) string {
+	return fmt.Sprintf("%s:lock:%s:%s:%s:%s", keyPrefix, db.cloudProvider, db.clusterType, db.clusterName, db.region)
+}
+
+func (db *Store) Lock(owner string, ttl time.Duration) (*storage.ClusterLease, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	lease, err := storage.NewClusterLease(owner, ttl)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(lease)
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to serialize the lease", "Reason", err),
+		)
+	}
+
+	lease.Key = getLockKey(db)
+
+	// the key expires along with the lease, so no one has to clean up after a crashed holder
+	ok, err := db.databaseClient.SetNX(db.ctx, lease.Key, raw, ttl).Result()
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to acquire the lock", "Reason", err),
+		)
+	}
+	if ok {
+		return lease, nil
+	}
+
+	holder, err := db.getLease(lease.Key)
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrClusterLocked,
+			db.l.NewError(db.ctx, "cluster is locked by someone else", "Reason", err),
+		)
+	}
+	return nil, ksctlErrors.WrapError(
+		ksctlErrors.ErrClusterLocked,
+		db.l.NewError(db.ctx, "cluster is "+holder.String()),
+	)
+}
+
+func (db *Store) getLease(key string) (*storage.ClusterLease, error) {
+	raw, err := db.databaseClient.Get(db.ctx, key).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var lease *storage.ClusterLease
+	if err := json.Unmarshal(raw, &lease); err != nil {
+		return nil, err
+	}
+	return lease, nil
+}
+
+// withOwnLease runs fn in a transaction which fails if the lease changes hands in between
+func (db *Store) withOwnLease(lease *storage.ClusterLease, fn func(pipe goredis.Pipeliner) error) error {
+	key := lease.Key
+	err := db.databaseClient.Watch(db.ctx, func(tx *goredis.Tx) error {
+		raw, err := tx.Get(db.ctx, key).Bytes()
+		if err != nil {
+			return err
+		}
+		var holder *storage.ClusterLease
+		if err := json.Unmarshal(raw, &holder); err != nil {
+			return err
+		}
+		if holder.ID != lease.ID {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrClusterLocked,
+				db.l.NewError(db.ctx, "lease got lost, cluster is "+holder.String()),
+			)
+		}
+		_, err = tx.TxPipelined(db.ctx, fn)
+		return err
+	}, key)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, goredis.Nil) {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrNoMatchingRecordsFound,
+			db.l.NewError(db.ctx, "lease is not present"),
+		)
+	}
+	if errors.Is(err, goredis.TxFailedErr) {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrClusterLocked,
+			db.l.NewError(db.ctx, "lease got updated by someone else"),
+		)
+	}
+	if _, ok := err.(ksctlErrors.KsctlError); ok {
+		return err
+	}
+	return ksctlErrors.WrapError(
+		ksctlErrors.ErrInternal,
+		db.l.NewError(db.ctx, "failed to update the lease", "Reason", err),
+	)
+}
+
+func (db *Store) Renew(lease *storage.ClusterLease, ttl time.Duration) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	renewed := *lease
+	renewed.ExpiresAt = time.Now().UTC().Add(ttl)
+	raw, err := json.Marshal(renewed)
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to serialize the lease", "Reason", err),
+		)
+	}
+
+	if err := db.withOwnLease(lease, func(pipe goredis.Pipeliner) error {
+		pipe.Set(db.ctx, lease.Key, raw, ttl)
+		return nil
+	}); err != nil {
+		if ksctlErrors.IsNoMatchingRecordsFound(err) {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrClusterLocked,
+				db.l.NewError(db.ctx, "lease got expired", "id", lease.ID),
+			)
+		}
+		return err
+	}
+	lease.ExpiresAt = renewed.ExpiresAt
+	return nil
+}
+
+func (db *Store) Unlock(lease *storage.ClusterLease) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := db.withOwnLease(lease, func(pipe goredis.Pipeliner) error {
+		pipe.Del(db.ctx, lease.Key)
+		return nil
+	}); err != nil && !ksctlErrors.IsNoMatchingRecordsFound(err) {
+		return err
+	}
+	return nil
+}
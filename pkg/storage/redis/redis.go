@@ -0,0 +1,464 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/ksctl/ksctl/v2/migrations"
+	"github.com/ksctl/ksctl/v2/pkg/logger"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"github.com/ksctl/ksctl/v2/pkg/storage/encryption"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	keyPrefix = "ksctl-db"
+)
+
+type RedisConn struct {
+	ctx    context.Context
+	client *goredis.Client
+	mu     *sync.Mutex
+}
+
+func NewDBClient(parentCtx context.Context, creds statefile.CredentialsRedis) (*RedisConn, error) {
+	db := &RedisConn{
+		ctx: context.WithValue(parentCtx, consts.KsctlModuleNameKey, string(consts.StoreExtRedis)),
+		mu:  &sync.Mutex{},
+	}
+
+	opts, err := goredis.ParseURL(creds.URI)
+	if err != nil {
+		return nil, fmt.Errorf("Redis failed to parse the uri, Reason: %v", err)
+	}
+
+	db.client = goredis.NewClient(opts)
+
+	if err := db.client.Ping(db.ctx).Err(); err != nil {
+		return nil, fmt.Errorf("Redis failed to ping pong the database, Reason: %v", err)
+	}
+
+	return db, nil
+}
+
+type Store struct {
+	ctx            context.Context
+	l              logger.Logger
+	databaseClient *goredis.Client
+
+	cloudProvider string
+	clusterType   string
+	clusterName   string
+	region        string
+
+	mu *sync.Mutex
+	wg *sync.WaitGroup
+
+	// enc seals the secrets of the state at rest, nil keeps the state in plaintext
+	enc encryption.Encrypter
+
+	// writer is recorded along every revision of the state
+	writer string
+
+	// noUpgrade hands out the documents as stored, set on the copy used while upgrading them in bulk
+	noUpgrade bool
+}
+
+func (conn *RedisConn) NewDatabaseClient(ksctlConfig context.Context, l logger.Logger) (*Store, error) {
+
+	db := &Store{
+		ctx:            conn.ctx,
+		l:              l,
+		mu:             conn.mu,
+		wg:             new(sync.WaitGroup),
+		databaseClient: conn.client,
+		writer:         storage.WriterFromContext(ksctlConfig),
+	}
+
+	return db, nil
+}
+
+// getIndexKey returns the key of the set which holds all the cluster keys for the given cloud and cluster type
+func getIndexKey(cloud, clusterType string) string {
+	return fmt.Sprintf("%s:%s:%s", keyPrefix, cloud, clusterType)
+}
+
+func getClusterKey(db *Store) string {
+	return fmt.Sprintf("%s:%s:%s:%s:%s", keyPrefix, db.cloudProvider, db.clusterType, db.clusterName, db.region)
+}
+
+// getHistoryKey returns the key of the sorted set which holds the revisions of the cluster scored by the revision
+func getHistoryKey(db *Store) string {
+	return fmt.Sprintf("%s:history:%s:%s:%s:%s", keyPrefix, db.cloudProvider, db.clusterType, db.clusterName, db.region)
+}
+
+func (db *Store) SetEncrypter(enc encryption.Encrypter) {
+	db.enc = enc
+}
+
+// WithoutSchemaUpgrade returns a copy of the store handing out the documents as stored,
+// the copy shares the connection with the store and is Setup on its own
+func (db *Store) WithoutSchemaUpgrade() storage.Storage {
+	raw := *db
+	raw.noUpgrade = true
+	return &raw
+}
+
+// upgradeDocument brings the document read to the latest schema version, the upgraded document is stored on the next write
+func (db *Store) upgradeDocument(doc *statefile.StorageDocument) error {
+	if db.noUpgrade {
+		return nil
+	}
+	if _, err := migrations.UpgradeDocument(doc); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidVersion,
+			db.l.NewError(db.ctx, "failed to upgrade the state", "Reason", err),
+		)
+	}
+	return nil
+}
+
+func (db *Store) Connect(_ context.Context) error {
+
+	db.l.Debug(db.ctx, "CONN to Redis")
+
+	return nil
+}
+
+func (db *Store) Read() (*statefile.StorageDocument, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	raw, err := db.isPresent()
+	if err != nil {
+		return nil, err
+	}
+
+	var result *statefile.StorageDocument
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to deserialize the state", "Reason", err),
+		)
+	}
+	if err := encryption.OpenDocument(db.enc, result); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to decrypt the state", "Reason", err),
+		)
+	}
+	if err := db.upgradeDocument(result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (db *Store) Write(data *statefile.StorageDocument) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	migrations.StampDocument(data)
+
+	expectedRevision := data.Revision
+	data.Revision = expectedRevision + 1
+
+	sealed, err := encryption.SealDocument(db.enc, data)
+	if err != nil {
+		data.Revision = expectedRevision
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to encrypt the state", "Reason", err),
+		)
+	}
+
+	raw, err := json.Marshal(sealed)
+	if err != nil {
+		data.Revision = expectedRevision
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to serialize the state", "Reason", err),
+		)
+	}
+
+	history, err := json.Marshal(storage.NewStateRevision(db.writer, sealed))
+	if err != nil {
+		data.Revision = expectedRevision
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to serialize the state revision", "Reason", err),
+		)
+	}
+
+	key := getClusterKey(db)
+	// WATCH makes the transaction fail if the key got modified between the revision check and the update
+	err = db.databaseClient.Watch(db.ctx, func(tx *goredis.Tx) error {
+		prev, err := tx.Get(db.ctx, key).Bytes()
+		if err != nil && !errors.Is(err, goredis.Nil) {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to get cluster", "Reason", err),
+			)
+		}
+		if err == nil {
+			var stored *statefile.StorageDocument
+			if err := json.Unmarshal(prev, &stored); err != nil {
+				return ksctlErrors.WrapError(
+					ksctlErrors.ErrInternal,
+					db.l.NewError(db.ctx, "failed to deserialize the state", "Reason", err),
+				)
+			}
+			if stored.Revision != expectedRevision {
+				return ksctlErrors.WrapError(
+					ksctlErrors.ErrStaleStateWrite,
+					db.l.NewError(db.ctx, "state revision mismatch", "stored", stored.Revision, "got", expectedRevision),
+				)
+			}
+		}
+
+		_, err = tx.TxPipelined(db.ctx, func(pipe goredis.Pipeliner) error {
+			pipe.Set(db.ctx, key, raw, 0)
+			pipe.SAdd(db.ctx, getIndexKey(db.cloudProvider, db.clusterType), key)
+			pipe.ZAdd(db.ctx, getHistoryKey(db), goredis.Z{Score: float64(sealed.Revision), Member: history})
+			pipe.ZRemRangeByScore(db.ctx, getHistoryKey(db), "-inf", strconv.FormatInt(sealed.Revision-int64(consts.CounterMaxStateRevisions), 10))
+			return nil
+		})
+		return err
+	}, key)
+	if err != nil {
+		data.Revision = expectedRevision
+		if errors.Is(err, goredis.TxFailedErr) {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrStaleStateWrite,
+				db.l.NewError(db.ctx, "state got updated by someone else", "revision", expectedRevision),
+			)
+		}
+		if _, ok := err.(ksctlErrors.KsctlError); ok {
+			return err
+		}
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to write state", "Reason", err),
+		)
+	}
+	return nil
+}
+
+func (db *Store) Setup(cloud consts.KsctlCloud, region, clusterName string, clusterType consts.KsctlClusterType) error {
+	switch cloud {
+	case consts.CloudAws, consts.CloudAzure, consts.CloudLocal:
+		db.cloudProvider = string(cloud)
+	default:
+		return ksctlErrors.NewError(ksctlErrors.ErrInvalidCloudProvider)
+	}
+	if clusterType != consts.ClusterTypeSelfMang && clusterType != consts.ClusterTypeMang {
+		return ksctlErrors.NewError(ksctlErrors.ErrInvalidClusterType)
+	}
+
+	db.clusterName = clusterName
+	db.region = region
+	db.clusterType = string(clusterType)
+
+	return nil
+}
+
+func (db *Store) DeleteCluster() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	if _, err := db.isPresent(); err != nil {
+		return err
+	}
+
+	key := getClusterKey(db)
+	if _, err := db.databaseClient.TxPipelined(db.ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Del(db.ctx, key, getHistoryKey(db))
+		pipe.SRem(db.ctx, getIndexKey(db.cloudProvider, db.clusterType), key)
+		return nil
+	}); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to delete the state", "Reason", err),
+		)
+	}
+
+	return nil
+}
+
+func (db *Store) isPresent() ([]byte, error) {
+	raw, err := db.databaseClient.Get(db.ctx, getClusterKey(db)).Bytes()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrNoMatchingRecordsFound,
+				db.l.NewError(db.ctx, "no matching cluster present"),
+			)
+		}
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to get cluster", "Reason", err),
+		)
+	}
+	return raw, nil
+}
+
+func (db *Store) clusterPresent() error {
+	raw, err := db.isPresent()
+	if err != nil {
+		return err
+	}
+
+	var x *statefile.StorageDocument
+	if err := json.Unmarshal(raw, &x); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to deserialize the state", "Reason", err),
+		)
+	}
+	return nil
+}
+
+func (db *Store) AlreadyCreated(cloud consts.KsctlCloud, region, clusterName string, clusterType consts.KsctlClusterType) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	err := db.Setup(cloud, region, clusterName, clusterType)
+	if err != nil {
+		return err
+	}
+
+	return db.clusterPresent()
+}
+
+func (db *Store) GetOneOrMoreClusters(filters map[consts.KsctlSearchFilter]string) (map[consts.KsctlClusterType][]*statefile.StorageDocument, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	if err := storage.ValidateFilters(filters); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			db.l.NewError(db.ctx, "invalid filters", "Reason", err),
+		)
+	}
+
+	clusterType := filters[consts.ClusterType]
+	cloud := filters[consts.Cloud]
+
+	var filterCloudPath, filterClusterType []string
+
+	switch cloud {
+	case string(consts.CloudAll), "":
+		filterCloudPath = append(filterCloudPath, string(consts.CloudAws), string(consts.CloudAzure), string(consts.CloudLocal))
+
+	case string(consts.CloudAzure):
+		filterCloudPath = append(filterCloudPath, string(consts.CloudAzure))
+
+	case string(consts.CloudAws):
+		filterCloudPath = append(filterCloudPath, string(consts.CloudAws))
+
+	case string(consts.CloudLocal):
+		filterCloudPath = append(filterCloudPath, string(consts.CloudLocal))
+	}
+
+	switch clusterType {
+	case string(consts.ClusterTypeSelfMang):
+		filterClusterType = append(filterClusterType, string(consts.ClusterTypeSelfMang))
+
+	case string(consts.ClusterTypeMang):
+		filterClusterType = append(filterClusterType, string(consts.ClusterTypeMang))
+
+	case "":
+		filterClusterType = append(filterClusterType, string(consts.ClusterTypeMang), string(consts.ClusterTypeSelfMang))
+	}
+	db.l.Debug(db.ctx, "storage.external.redis.GetOneOrMoreClusters", "filter", filters, "filterCloudPath", filterCloudPath, "filterClusterType", filterClusterType)
+
+	clustersInfo := make(map[consts.KsctlClusterType][]*statefile.StorageDocument)
+
+	for _, cloud := range filterCloudPath {
+		for _, clusterType := range filterClusterType {
+
+			keys, err := db.databaseClient.SMembers(db.ctx, getIndexKey(cloud, clusterType)).Result()
+			if err != nil && !errors.Is(err, goredis.Nil) {
+				return nil, ksctlErrors.WrapError(
+					ksctlErrors.ErrInternal,
+					db.l.NewError(db.ctx, "failed to find the state", "Reason", err),
+				)
+			}
+			if len(keys) == 0 {
+				continue
+			}
+
+			values, err := db.databaseClient.MGet(db.ctx, keys...).Result()
+			if err != nil {
+				return nil, ksctlErrors.WrapError(
+					ksctlErrors.ErrInternal,
+					db.l.NewError(db.ctx, "failed to find the state", "Reason", err),
+				)
+			}
+
+			var clusters []*statefile.StorageDocument
+			for _, v := range values {
+				raw, ok := v.(string)
+				if !ok {
+					// the key was removed after it got listed in the index
+					continue
+				}
+				var result *statefile.StorageDocument
+				if err := json.Unmarshal([]byte(raw), &result); err != nil {
+					return nil, ksctlErrors.WrapError(
+						ksctlErrors.ErrInternal,
+						db.l.NewError(db.ctx, "failed to deserialize the state", "Reason", err),
+					)
+				}
+				if err := encryption.OpenDocument(db.enc, result); err != nil {
+					return nil, ksctlErrors.WrapError(
+						ksctlErrors.ErrInternal,
+						db.l.NewError(db.ctx, "failed to decrypt the state", "Reason", err),
+					)
+				}
+				if err := db.upgradeDocument(result); err != nil {
+					return nil, err
+				}
+				if storage.MatchesFilters(filters, result) {
+					clusters = append(clusters, result)
+				}
+			}
+
+			clustersInfo[consts.KsctlClusterType(clusterType)] = append(clustersInfo[consts.KsctlClusterType(clusterType)], clusters...)
+		}
+	}
+
+	return clustersInfo, nil
+}
@@ -0,0 +1,329 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage/storagetest"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/gookit/goutil/dump"
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/logger"
+)
+
+var (
+	db           *Store
+	server       *miniredis.Miniredis
+	parentCtx    context.Context
+	ksc                        = context.Background()
+	parentLogger logger.Logger = logger.NewStructuredLogger(-1, os.Stdout)
+)
+
+func TestMain(m *testing.M) {
+	parentCtx = context.WithValue(context.TODO(), consts.KsctlTestFlagKey, "true")
+	ksc = context.WithValue(ksc, consts.KsctlContextUser, "fake")
+
+	uri := os.Getenv("REDIS_URI")
+	if uri == "" {
+		var err error
+		server, err = miniredis.Run()
+		if err != nil {
+			panic(err)
+		}
+		_ = os.Setenv("REDIS_URI", "redis://"+server.Addr())
+	}
+
+	exitVal := m.Run()
+
+	if server != nil {
+		server.Close()
+	}
+
+	os.Exit(exitVal)
+}
+
+func TestInitStorage(t *testing.T) {
+	_db, err := NewDBClient(parentCtx, statefile.CredentialsRedis{
+		URI: os.Getenv("REDIS_URI"),
+	})
+	if err != nil {
+		t.Fatalf("Error should not happen: %v", err)
+	}
+
+	db, err = _db.NewDatabaseClient(ksc, parentLogger)
+	if err != nil {
+		t.Fatalf("Error should not happen: %v", err)
+	}
+
+	err = db.Setup(consts.CloudAzure, "region", "name", consts.ClusterTypeSelfMang)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(ksc); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInvalidURI(t *testing.T) {
+	if _, err := NewDBClient(parentCtx, statefile.CredentialsRedis{URI: "mongodb://localhost"}); err == nil {
+		t.Fatal("Error should happen for a non redis uri")
+	}
+}
+
+func TestStore_RWD(t *testing.T) {
+	if _, err := db.Read(); err == nil {
+		t.Fatal("Error should occur as there is no folder created")
+	}
+	if err := db.DeleteCluster(); err == nil {
+		t.Fatalf("Error should happen on deleting cluster info")
+	}
+
+	if err := db.AlreadyCreated(consts.CloudAzure, "region", "name", consts.ClusterTypeSelfMang); err == nil {
+		t.Fatalf("Error should happen on checking for presence of the cluster")
+	}
+
+	fakeData := &statefile.StorageDocument{
+		Region:        "region",
+		ClusterName:   "name",
+		ClusterType:   "selfmanaged",
+		InfraProvider: consts.CloudAzure,
+	}
+	err := db.Write(fakeData)
+	if err != nil {
+		t.Fatalf("Error shouln't happen: %v", err)
+	}
+
+	if err := db.AlreadyCreated(consts.CloudAzure, "region", "name", consts.ClusterTypeSelfMang); err != nil {
+		t.Fatalf("Error shouldn't happen on checking for presence of the cluster: %v", err)
+	}
+
+	if gotFakeData, err := db.Read(); err != nil {
+		t.Fatalf("Error shouln't happen on reading file: %v", err)
+	} else {
+		if _, err := db.Read(); err != nil {
+			t.Fatalf("Second Read failed")
+		}
+		fmt.Printf("%#+v\n", gotFakeData)
+
+		if !reflect.DeepEqual(gotFakeData, fakeData) {
+			t.Fatalf("Written data doesn't match Reading")
+		}
+	}
+
+	if err := db.DeleteCluster(); err != nil {
+		t.Fatalf("Error shouln't happen on deleting cluster info: %v", err)
+	}
+}
+
+func TestStore_StaleWrite(t *testing.T) {
+	storagetest.StaleWrite(t, db)
+}
+
+func TestStore_Lock(t *testing.T) {
+	if err := db.Setup(consts.CloudAzure, "region", "locked", consts.ClusterTypeSelfMang); err != nil {
+		t.Fatal(err)
+	}
+	locker := db
+
+	lease, err := locker.Lock("alice", time.Minute)
+	assert.NilError(t, err)
+
+	_, err = locker.Lock("bob", time.Minute)
+	assert.Check(t, ksctlErrors.IsClusterLocked(err), fmt.Sprintf("expected cluster locked error, got: %v", err))
+	assert.ErrorContains(t, err, "locked by alice")
+
+	// the lease keeps to its cluster while the store is Setup for another one
+	if err := db.Setup(consts.CloudAws, "region", "other", consts.ClusterTypeMang); err != nil {
+		t.Fatal(err)
+	}
+	assert.NilError(t, locker.Renew(lease, 2*time.Minute))
+	assert.NilError(t, locker.Unlock(lease))
+	if err := db.Setup(consts.CloudAzure, "region", "locked", consts.ClusterTypeSelfMang); err != nil {
+		t.Fatal(err)
+	}
+
+	lease, err = locker.Lock("bob", time.Minute)
+	assert.NilError(t, err)
+	assert.NilError(t, locker.Unlock(lease))
+
+	_, err = locker.Lock("alice", time.Second)
+	assert.NilError(t, err)
+	if server != nil {
+		server.FastForward(2 * time.Second)
+		lease, err = locker.Lock("bob", time.Minute)
+		assert.NilError(t, err, "expired lease should be taken over")
+		assert.NilError(t, locker.Unlock(lease))
+	}
+}
+
+func TestStore_History(t *testing.T) {
+	storagetest.History(t, db)
+}
+func TestStore_Audit(t *testing.T) {
+	storagetest.Audit(t, db)
+}
+
+func TestGetClusterInfo(t *testing.T) {
+
+	t.Run("Setup some demo clusters", func(t *testing.T) {
+
+		func() {
+
+			if err := db.Setup(consts.CloudAzure, "regionAzure", "name_managed", consts.ClusterTypeMang); err != nil {
+				t.Fatal(err)
+			}
+
+			fakeData := &statefile.StorageDocument{
+				Region:        "regionAzure",
+				ClusterName:   "name_managed",
+				InfraProvider: consts.CloudAzure,
+				ClusterType:   "managed",
+				CloudInfra:    &statefile.InfrastructureState{Azure: &statefile.StateConfigurationAzure{}},
+			}
+
+			err := db.Write(fakeData)
+			if err != nil {
+				t.Fatalf("Error shouln't happen: %v", err)
+			}
+
+		}()
+
+		func() {
+
+			if err := db.Setup(consts.CloudAws, "regionAws", "name_managed", consts.ClusterTypeMang); err != nil {
+				t.Fatal(err)
+			}
+
+			fakeData := &statefile.StorageDocument{
+				Region:        "regionAws",
+				ClusterName:   "name_managed",
+				InfraProvider: consts.CloudAws,
+				ClusterType:   "managed",
+				CloudInfra:    &statefile.InfrastructureState{Aws: &statefile.StateConfigurationAws{}},
+			}
+
+			err := db.Write(fakeData)
+			if err != nil {
+				t.Fatalf("Error shouln't happen: %v", err)
+			}
+			err = db.Write(fakeData)
+			if err != nil {
+				t.Fatalf("Error shouln't happen on second Write: %v", err)
+			}
+
+		}()
+
+		func() {
+
+			if err := db.Setup(consts.CloudAws, "regionAws", "name_ha", consts.ClusterTypeSelfMang); err != nil {
+				t.Fatal(err)
+			}
+
+			fakeData := &statefile.StorageDocument{
+				Region:        "regionAws",
+				ClusterName:   "name_ha",
+				InfraProvider: consts.CloudAws,
+				ClusterType:   "selfmanaged",
+				CloudInfra:    &statefile.InfrastructureState{Aws: &statefile.StateConfigurationAws{}},
+				K8sBootstrap:  &statefile.KubernetesBootstrapState{K3s: &statefile.StateConfigurationK3s{}},
+			}
+
+			err := db.Write(fakeData)
+			if err != nil {
+				t.Fatalf("Error shouln't happen: %v", err)
+			}
+
+		}()
+	})
+
+	t.Run("fetch cluster Infos", func(t *testing.T) {
+		func(t *testing.T) {
+			m, err := db.GetOneOrMoreClusters(map[consts.KsctlSearchFilter]string{"cloud": "all", "clusterType": ""})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			dump.Println(m)
+			assert.Check(t, len(m[consts.ClusterTypeSelfMang]) == 1)
+			assert.Check(t, len(m[consts.ClusterTypeMang]) == 2)
+		}(t)
+
+		func(t *testing.T) {
+			m, err := db.GetOneOrMoreClusters(map[consts.KsctlSearchFilter]string{"cloud": "aws", "clusterType": "selfmanaged"})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			dump.Println(m)
+			assert.Check(t, len(m[consts.ClusterTypeSelfMang]) == 1)
+			assert.Check(t, len(m[consts.ClusterTypeMang]) == 0)
+		}(t)
+
+		func(t *testing.T) {
+			m, err := db.GetOneOrMoreClusters(map[consts.KsctlSearchFilter]string{"cloud": "azure", "clusterType": "managed"})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			dump.Println(m)
+			assert.Check(t, len(m[consts.ClusterTypeSelfMang]) == 0)
+			assert.Check(t, len(m[consts.ClusterTypeMang]) == 1)
+		}(t)
+	})
+
+}
+
+func TestDelete(t *testing.T) {
+
+	t.Run("delete all", func(t *testing.T) {
+		func() {
+			if err := db.Setup(consts.CloudAws, "regionAws", "name_ha", consts.ClusterTypeSelfMang); err != nil {
+				t.Fatal(err)
+			}
+			if err := db.DeleteCluster(); err != nil {
+				t.Fatal(err)
+			}
+		}()
+
+		func() {
+			if err := db.Setup(consts.CloudAws, "regionAws", "name_managed", consts.ClusterTypeMang); err != nil {
+				t.Fatal(err)
+			}
+			if err := db.DeleteCluster(); err != nil {
+				t.Fatal(err)
+			}
+		}()
+
+		func() {
+			if err := db.Setup(consts.CloudAzure, "regionAzure", "name_managed", consts.ClusterTypeMang); err != nil {
+				t.Fatal(err)
+			}
+			if err := db.DeleteCluster(); err != nil {
+				t.Fatal(err)
+			}
+		}()
+	})
+}
@@ -0,0 +1,96 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+)
+
+const auditDir = "audit"
+
+// getAuditPrefix returns the prefix of the trail of the cluster, it is kept apart from
+// the objects of the cluster so that the trail outlives DeleteCluster
+func getAuditPrefix(db *Store, cloud, clusterType, clusterName, region string) string {
+	return path.Join(db.prefix, auditDir, cloud, clusterType, clusterName, region) + "/"
+}
+
+func (db *Store) RecordAudit(entry *storage.AuditEntry) error {
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to serialize the audit entry", "Reason", err),
+		)
+	}
+
+	key := getAuditPrefix(db, string(entry.Cloud), string(entry.ClusterType), entry.ClusterName, entry.Region) + entry.ID + ".json"
+	// the entries are never overwritten
+	if err := db.putObject(key, raw, ""); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to put the audit entry", "Reason", err),
+		)
+	}
+	return nil
+}
+
+func (db *Store) QueryAudit(query storage.AuditQuery) ([]*storage.AuditEntry, error) {
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	prefix := path.Join(db.prefix, auditDir) + "/"
+	if query.IsClusterScoped() {
+		prefix = getAuditPrefix(db, string(query.Cloud), string(query.ClusterType), query.ClusterName, query.Region)
+	}
+
+	keys, err := db.listKeys(prefix)
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to list the audit entries", "Reason", err),
+		)
+	}
+
+	var entries []*storage.AuditEntry
+	for _, key := range keys {
+		if !strings.HasSuffix(key, ".json") {
+			continue
+		}
+		raw, _, err := db.getObject(key)
+		if err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to get the audit entry", "key", key, "Reason", err),
+			)
+		}
+		var v *storage.AuditEntry
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to deserialize the audit entry", "key", key, "Reason", err),
+			)
+		}
+		entries = append(entries, v)
+	}
+	return storage.FilterAuditEntries(entries, query), nil
+}
@@ -0,0 +1,176 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"github.com/ksctl/ksctl/v2/pkg/storage/encryption"
+)
+
+func getHistoryPrefix(db *Store) string {
+	return getClusterPrefix(db) + historyDir + "/"
+}
+
+func getRevisionKey(db *Store, revision int64) string {
+	return path.Join(getHistoryPrefix(db), strconv.FormatInt(revision, 10)+".json")
+}
+
+// listRevisionKeys returns the revisions present in the history of the cluster, newest first
+func (db *Store) listRevisionKeys() ([]int64, error) {
+	prefix := getHistoryPrefix(db)
+	keys, err := db.listKeys(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []int64
+	for _, key := range keys {
+		name := strings.TrimPrefix(key, prefix)
+		if !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		rev, err := strconv.ParseInt(strings.TrimSuffix(name, ".json"), 10, 64)
+		if err != nil {
+			continue
+		}
+		revisions = append(revisions, rev)
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i] > revisions[j]
+	})
+	return revisions, nil
+}
+
+// recordRevision keeps a copy of the state just written and removes the revisions which are older than the ones to be kept
+func (db *Store) recordRevision(doc *statefile.StorageDocument) error {
+	raw, err := json.Marshal(storage.NewStateRevision(db.writer, doc))
+	if err != nil {
+		return err
+	}
+	if err := db.putObject(getRevisionKey(db, doc.Revision), raw, ""); err != nil && !isConditionFailed(err) {
+		return err
+	}
+
+	revisions, err := db.listRevisionKeys()
+	if err != nil {
+		return err
+	}
+	for _, rev := range revisions {
+		if rev <= doc.Revision-int64(consts.CounterMaxStateRevisions) {
+			if err := db.deleteObject(getRevisionKey(db, rev)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// removeHistory drops all the revisions of the cluster
+func (db *Store) removeHistory() error {
+	revisions, err := db.listRevisionKeys()
+	if err != nil {
+		return err
+	}
+	for _, rev := range revisions {
+		if err := db.deleteObject(getRevisionKey(db, rev)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *Store) readRevision(revision int64) (*storage.StateRevision, error) {
+	raw, _, err := db.getObject(getRevisionKey(db, revision))
+	if err != nil {
+		if isStatus(err, http.StatusNotFound) {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrNoMatchingRecordsFound,
+				db.l.NewError(db.ctx, "revision not present", "revision", revision),
+			)
+		}
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to get the revision", "Reason", err),
+		)
+	}
+
+	var v *storage.StateRevision
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to deserialize the revision", "Reason", err),
+		)
+	}
+	return v, nil
+}
+
+func (db *Store) ListRevisions() ([]*storage.StateRevision, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	revisions, err := db.listRevisionKeys()
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to list the revisions", "Reason", err),
+		)
+	}
+
+	var out []*storage.StateRevision
+	for _, rev := range revisions {
+		v, err := db.readRevision(rev)
+		if err != nil {
+			if ksctlErrors.IsNoMatchingRecordsFound(err) {
+				// pruned after it got listed
+				continue
+			}
+			return nil, err
+		}
+		v.Document = nil
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func (db *Store) ReadRevision(revision int64) (*storage.StateRevision, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	v, err := db.readRevision(revision)
+	if err != nil {
+		return nil, err
+	}
+	if err := encryption.OpenDocument(db.enc, v.Document); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to decrypt the state", "Reason", err),
+		)
+	}
+	return v, nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+)
+
+func getLockKey(db *Store) string {
+	return getClusterPrefix(db) + lockObject
+}
+
+// getLease returns the lease present along with the etag of its object
+func (db *Store) getLease(key string) (*storage.ClusterLease, string, error) {
+	raw, etag, err := db.getObject(key)
+	if err != nil {
+		return nil, "", err
+	}
+	var lease *storage.ClusterLease
+	if err := json.Unmarshal(raw, &lease); err != nil {
+		return nil, "", err
+	}
+	return lease, etag, nil
+}
+
+// Lock creates the lock object only when it is absent, an expired lease is taken over
+// with a put conditioned on its etag so that only one of the contenders wins
+func (db *Store) Lock(owner string, ttl time.Duration) (*storage.ClusterLease, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	lease, err := storage.NewClusterLease(owner, ttl)
+	if err != nil {
+		return nil, err
+	}
+	lease.Key = getLockKey(db)
+	raw, err := json.Marshal(lease)
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to serialize the lease", "Reason", err),
+		)
+	}
+
+	if err := db.putObject(lease.Key, raw, ""); err == nil {
+		return lease, nil
+	} else if !isConditionFailed(err) {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to acquire the lock", "Reason", err),
+		)
+	}
+
+	holder, etag, err := db.getLease(lease.Key)
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrClusterLocked,
+			db.l.NewError(db.ctx, "cluster is locked by someone else", "Reason", err),
+		)
+	}
+	if !holder.IsExpired(time.Now()) {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrClusterLocked,
+			db.l.NewError(db.ctx, "cluster is "+holder.String()),
+		)
+	}
+
+	if err := db.putObject(lease.Key, raw, etag); err != nil {
+		if isConditionFailed(err) || isStatus(err, http.StatusNotFound) {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrClusterLocked,
+				db.l.NewError(db.ctx, "cluster lock got acquired by someone else", "Reason", err),
+			)
+		}
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to take over the lease", "Reason", err),
+		)
+	}
+	return lease, nil
+}
+
+// ownLease returns the etag of the lock object if the lease is still the one held
+func (db *Store) ownLease(lease *storage.ClusterLease) (string, error) {
+	holder, etag, err := db.getLease(lease.Key)
+	if err != nil {
+		if isStatus(err, http.StatusNotFound) {
+			return "", ksctlErrors.WrapError(
+				ksctlErrors.ErrNoMatchingRecordsFound,
+				db.l.NewError(db.ctx, "lease is not present"),
+			)
+		}
+		return "", ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to read the lease", "Reason", err),
+		)
+	}
+	if holder.ID != lease.ID {
+		return "", ksctlErrors.WrapError(
+			ksctlErrors.ErrClusterLocked,
+			db.l.NewError(db.ctx, "lease got lost, cluster is "+holder.String()),
+		)
+	}
+	return etag, nil
+}
+
+func (db *Store) Renew(lease *storage.ClusterLease, ttl time.Duration) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	etag, err := db.ownLease(lease)
+	if err != nil {
+		if ksctlErrors.IsNoMatchingRecordsFound(err) {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrClusterLocked,
+				db.l.NewError(db.ctx, "lease got expired", "id", lease.ID),
+			)
+		}
+		return err
+	}
+
+	renewed := *lease
+	renewed.ExpiresAt = time.Now().UTC().Add(ttl)
+	raw, err := json.Marshal(renewed)
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to serialize the lease", "Reason", err),
+		)
+	}
+
+	if err := db.putObject(lease.Key, raw, etag); err != nil {
+		if isConditionFailed(err) {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrClusterLocked,
+				db.l.NewError(db.ctx, "lease got updated by someone else"),
+			)
+		}
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to renew the lease", "Reason", err),
+		)
+	}
+	lease.ExpiresAt = renewed.ExpiresAt
+	return nil
+}
+
+func (db *Store) Unlock(lease *storage.ClusterLease) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, err := db.ownLease(lease); err != nil {
+		if ksctlErrors.IsNoMatchingRecordsFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if err := db.deleteObject(lease.Key); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to release the lease", "Reason", err),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,552 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/ksctl/ksctl/v2/migrations"
+	"github.com/ksctl/ksctl/v2/pkg/logger"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"github.com/ksctl/ksctl/v2/pkg/storage/encryption"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+)
+
+const (
+	defaultPrefix = "ksctl-state"
+	stateObject   = "state.json"
+	lockObject    = "lock.json"
+	historyDir    = "history"
+)
+
+type S3Conn struct {
+	ctx    context.Context
+	client *awss3.Client
+	bucket string
+	prefix string
+	mu     *sync.Mutex
+}
+
+func NewDBClient(parentCtx context.Context, creds statefile.CredentialsS3) (*S3Conn, error) {
+	db := &S3Conn{
+		ctx:    context.WithValue(parentCtx, consts.KsctlModuleNameKey, string(consts.StoreExtS3)),
+		bucket: creds.Bucket,
+		prefix: strings.Trim(creds.Prefix, "/"),
+		mu:     &sync.Mutex{},
+	}
+	if len(db.bucket) == 0 {
+		return nil, fmt.Errorf("S3 bucket is not set")
+	}
+	if len(db.prefix) == 0 {
+		db.prefix = defaultPrefix
+	}
+
+	opts := []func(*awsconfig.LoadOptions) error{
+		awsconfig.WithRegion(creds.Region),
+	}
+	if len(creds.AccessKeyID) != 0 {
+		opts = append(opts, awsconfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(creds.AccessKeyID, creds.SecretAccessKey, ""),
+		))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(db.ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("S3 failed to load the config, Reason: %v", err)
+	}
+
+	db.client = awss3.NewFromConfig(cfg, func(o *awss3.Options) {
+		if len(creds.Endpoint) != 0 {
+			o.BaseEndpoint = aws.String(creds.Endpoint)
+		}
+		o.UsePathStyle = creds.UsePathStyle
+		// the S3 compatible services lag behind on the newer checksum algorithms
+		o.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
+		o.ResponseChecksumValidation = aws.ResponseChecksumValidationWhenRequired
+	})
+
+	if _, err := db.client.HeadBucket(db.ctx, &awss3.HeadBucketInput{Bucket: aws.String(db.bucket)}); err != nil {
+		return nil, fmt.Errorf("S3 failed to reach the bucket, Reason: %v", err)
+	}
+
+	return db, nil
+}
+
+type Store struct {
+	ctx    context.Context
+	l      logger.Logger
+	client *awss3.Client
+	bucket string
+	prefix string
+
+	cloudProvider string
+	clusterType   string
+	clusterName   string
+	region        string
+
+	mu *sync.Mutex
+	wg *sync.WaitGroup
+
+	// enc seals the secrets of the state at rest, nil keeps the state in plaintext
+	enc encryption.Encrypter
+
+	// writer is recorded along every revision of the state
+	writer string
+
+	// noUpgrade hands out the documents as stored, set on the copy used while upgrading them in bulk
+	noUpgrade bool
+}
+
+func (conn *S3Conn) NewDatabaseClient(ksctlConfig context.Context, l logger.Logger) (*Store, error) {
+
+	db := &Store{
+		ctx:    conn.ctx,
+		l:      l,
+		mu:     conn.mu,
+		wg:     new(sync.WaitGroup),
+		client: conn.client,
+		bucket: conn.bucket,
+		prefix: conn.prefix,
+		writer: storage.WriterFromContext(ksctlConfig),
+	}
+
+	return db, nil
+}
+
+func (db *Store) SetEncrypter(enc encryption.Encrypter) {
+	db.enc = enc
+}
+
+// WithoutSchemaUpgrade returns a copy of the store handing out the documents as stored,
+// the copy shares the connection with the store and is Setup on its own
+func (db *Store) WithoutSchemaUpgrade() storage.Storage {
+	raw := *db
+	raw.noUpgrade = true
+	return &raw
+}
+
+// upgradeDocument brings the document read to the latest schema version, the upgraded document is stored on the next write
+func (db *Store) upgradeDocument(doc *statefile.StorageDocument) error {
+	if db.noUpgrade {
+		return nil
+	}
+	if _, err := migrations.UpgradeDocument(doc); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidVersion,
+			db.l.NewError(db.ctx, "failed to upgrade the state", "Reason", err),
+		)
+	}
+	return nil
+}
+
+// getListPrefix returns the prefix of all the clusters of the given cloud and cluster type
+func getListPrefix(db *Store, cloud, clusterType string) string {
+	return path.Join(db.prefix, cloud, clusterType) + "/"
+}
+
+// getClusterPrefix returns the prefix of all the objects of the cluster
+func getClusterPrefix(db *Store) string {
+	return path.Join(db.prefix, db.cloudProvider, db.clusterType, db.clusterName, db.region) + "/"
+}
+
+func getStateKey(db *Store) string {
+	return getClusterPrefix(db) + stateObject
+}
+
+func (db *Store) Connect(_ context.Context) error {
+
+	db.l.Debug(db.ctx, "CONN to S3", "bucket", db.bucket, "prefix", db.prefix)
+
+	return nil
+}
+
+// isStatus checks the http status code of a failed request, the S3 compatible
+// services don't agree on the error codes but they do on the status codes
+func isStatus(err error, code int) bool {
+	var re *awshttp.ResponseError
+	return errors.As(err, &re) && re.HTTPStatusCode() == code
+}
+
+// isConditionFailed reports if a conditional request got rejected because the object changed
+func isConditionFailed(err error) bool {
+	return isStatus(err, http.StatusPreconditionFailed) || isStatus(err, http.StatusConflict)
+}
+
+// getObject returns the content of the object along with its etag
+func (db *Store) getObject(key string) ([]byte, string, error) {
+	out, err := db.client.GetObject(db.ctx, &awss3.GetObjectInput{
+		Bucket: aws.String(db.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() { _ = out.Body.Close() }()
+
+	raw, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return raw, aws.ToString(out.ETag), nil
+}
+
+// putObject writes the object only if its etag still matches, an empty etag expects the object to be absent
+func (db *Store) putObject(key string, raw []byte, etag string) error {
+	in := &awss3.PutObjectInput{
+		Bucket:      aws.String(db.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(raw),
+		ContentType: aws.String("application/json"),
+	}
+	if len(etag) != 0 {
+		in.IfMatch = aws.String(etag)
+	} else {
+		in.IfNoneMatch = aws.String("*")
+	}
+	_, err := db.client.PutObject(db.ctx, in)
+	return err
+}
+
+func (db *Store) deleteObject(key string) error {
+	_, err := db.client.DeleteObject(db.ctx, &awss3.DeleteObjectInput{
+		Bucket: aws.String(db.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+// listKeys returns the keys of all the objects under the prefix
+func (db *Store) listKeys(prefix string) ([]string, error) {
+	var keys []string
+	pages := awss3.NewListObjectsV2Paginator(db.client, &awss3.ListObjectsV2Input{
+		Bucket: aws.String(db.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(db.ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range page.Contents {
+			keys = append(keys, aws.ToString(o.Key))
+		}
+	}
+	return keys, nil
+}
+
+func (db *Store) decodeState(raw []byte) (*statefile.StorageDocument, error) {
+	var result *statefile.StorageDocument
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to deserialize the state", "Reason", err),
+		)
+	}
+	if err := encryption.OpenDocument(db.enc, result); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to decrypt the state", "Reason", err),
+		)
+	}
+	if err := db.upgradeDocument(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (db *Store) Read() (*statefile.StorageDocument, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	raw, _, err := db.isPresent()
+	if err != nil {
+		return nil, err
+	}
+
+	return db.decodeState(raw)
+}
+
+func (db *Store) Write(data *statefile.StorageDocument) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	migrations.StampDocument(data)
+
+	key := getStateKey(db)
+	prev, etag, err := db.getObject(key)
+	if err != nil && !isStatus(err, http.StatusNotFound) {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to get cluster", "Reason", err),
+		)
+	}
+
+	expectedRevision := data.Revision
+	if err == nil {
+		var stored *statefile.StorageDocument
+		if err := json.Unmarshal(prev, &stored); err != nil {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to deserialize the state", "Reason", err),
+			)
+		}
+		if stored.Revision != expectedRevision {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrStaleStateWrite,
+				db.l.NewError(db.ctx, "state revision mismatch", "stored", stored.Revision, "got", expectedRevision),
+			)
+		}
+	}
+
+	data.Revision = expectedRevision + 1
+
+	sealed, err := encryption.SealDocument(db.enc, data)
+	if err != nil {
+		data.Revision = expectedRevision
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to encrypt the state", "Reason", err),
+		)
+	}
+
+	raw, err := json.Marshal(sealed)
+	if err != nil {
+		data.Revision = expectedRevision
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to serialize the state", "Reason", err),
+		)
+	}
+
+	// the etag of the object we checked the revision against makes the put fail if someone else wrote in between
+	if err := db.putObject(key, raw, etag); err != nil {
+		data.Revision = expectedRevision
+		if isConditionFailed(err) {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrStaleStateWrite,
+				db.l.NewError(db.ctx, "state got updated by someone else", "revision", expectedRevision),
+			)
+		}
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to write state", "Reason", err),
+		)
+	}
+
+	if err := db.recordRevision(sealed); err != nil {
+		db.l.Warn(db.ctx, "failed to record the state revision", "revision", data.Revision, "Reason", err)
+	}
+	return nil
+}
+
+func (db *Store) Setup(cloud consts.KsctlCloud, region, clusterName string, clusterType consts.KsctlClusterType) error {
+	switch cloud {
+	case consts.CloudAws, consts.CloudAzure, consts.CloudLocal:
+		db.cloudProvider = string(cloud)
+	default:
+		return ksctlErrors.NewError(ksctlErrors.ErrInvalidCloudProvider)
+	}
+	if clusterType != consts.ClusterTypeSelfMang && clusterType != consts.ClusterTypeMang {
+		return ksctlErrors.NewError(ksctlErrors.ErrInvalidClusterType)
+	}
+
+	db.clusterName = clusterName
+	db.region = region
+	db.clusterType = string(clusterType)
+
+	return nil
+}
+
+func (db *Store) DeleteCluster() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	if _, _, err := db.isPresent(); err != nil {
+		return err
+	}
+
+	if err := db.deleteObject(getStateKey(db)); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to delete the state", "Reason", err),
+		)
+	}
+
+	if err := db.removeHistory(); err != nil {
+		db.l.Warn(db.ctx, "failed to remove the state revisions", "Reason", err)
+	}
+	return nil
+}
+
+func (db *Store) isPresent() ([]byte, string, error) {
+	raw, etag, err := db.getObject(getStateKey(db))
+	if err != nil {
+		if isStatus(err, http.StatusNotFound) {
+			return nil, "", ksctlErrors.WrapError(
+				ksctlErrors.ErrNoMatchingRecordsFound,
+				db.l.NewError(db.ctx, "no matching cluster present"),
+			)
+		}
+		return nil, "", ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to get cluster", "Reason", err),
+		)
+	}
+	return raw, etag, nil
+}
+
+func (db *Store) clusterPresent() error {
+	raw, _, err := db.isPresent()
+	if err != nil {
+		return err
+	}
+
+	var x *statefile.StorageDocument
+	if err := json.Unmarshal(raw, &x); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to deserialize the state", "Reason", err),
+		)
+	}
+	return nil
+}
+
+func (db *Store) AlreadyCreated(cloud consts.KsctlCloud, region, clusterName string, clusterType consts.KsctlClusterType) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	err := db.Setup(cloud, region, clusterName, clusterType)
+	if err != nil {
+		return err
+	}
+
+	return db.clusterPresent()
+}
+
+func (db *Store) GetOneOrMoreClusters(filters map[consts.KsctlSearchFilter]string) (map[consts.KsctlClusterType][]*statefile.StorageDocument, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	if err := storage.ValidateFilters(filters); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			db.l.NewError(db.ctx, "invalid filters", "Reason", err),
+		)
+	}
+
+	clusterType := filters[consts.ClusterType]
+	cloud := filters[consts.Cloud]
+
+	var filterCloudPath, filterClusterType []string
+
+	switch cloud {
+	case string(consts.CloudAll), "":
+		filterCloudPath = append(filterCloudPath, string(consts.CloudAws), string(consts.CloudAzure), string(consts.CloudLocal))
+
+	case string(consts.CloudAzure):
+		filterCloudPath = append(filterCloudPath, string(consts.CloudAzure))
+
+	case string(consts.CloudAws):
+		filterCloudPath = append(filterCloudPath, string(consts.CloudAws))
+
+	case string(consts.CloudLocal):
+		filterCloudPath = append(filterCloudPath, string(consts.CloudLocal))
+	}
+
+	switch clusterType {
+	case string(consts.ClusterTypeSelfMang):
+		filterClusterType = append(filterClusterType, string(consts.ClusterTypeSelfMang))
+
+	case string(consts.ClusterTypeMang):
+		filterClusterType = append(filterClusterType, string(consts.ClusterTypeMang))
+
+	case "":
+		filterClusterType = append(filterClusterType, string(consts.ClusterTypeMang), string(consts.ClusterTypeSelfMang))
+	}
+	db.l.Debug(db.ctx, "storage.external.s3.GetOneOrMoreClusters", "filter", filters, "filterCloudPath", filterCloudPath, "filterClusterType", filterClusterType)
+
+	clustersInfo := make(map[consts.KsctlClusterType][]*statefile.StorageDocument)
+
+	for _, cloud := range filterCloudPath {
+		for _, clusterType := range filterClusterType {
+			prefix := getListPrefix(db, cloud, clusterType)
+
+			keys, err := db.listKeys(prefix)
+			if err != nil {
+				return nil, ksctlErrors.WrapError(
+					ksctlErrors.ErrInternal,
+					db.l.NewError(db.ctx, "failed to find the state", "Reason", err),
+				)
+			}
+
+			var clusters []*statefile.StorageDocument
+			for _, key := range keys {
+				// only <name>/<region>/state.json, the revisions and the locks live next to it
+				if parts := strings.Split(strings.TrimPrefix(key, prefix), "/"); len(parts) != 3 || parts[2] != stateObject {
+					continue
+				}
+
+				raw, _, err := db.getObject(key)
+				if err != nil {
+					if isStatus(err, http.StatusNotFound) {
+						// the object was removed after it got listed
+						continue
+					}
+					return nil, ksctlErrors.WrapError(
+						ksctlErrors.ErrInternal,
+						db.l.NewError(db.ctx, "failed to find the state", "Reason", err),
+					)
+				}
+				result, err := db.decodeState(raw)
+				if err != nil {
+					return nil, err
+				}
+				if storage.MatchesFilters(filters, result) {
+					clusters = append(clusters, result)
+				}
+			}
+
+			clustersInfo[consts.KsctlClusterType(clusterType)] = append(clustersInfo[consts.KsctlClusterType(clusterType)], clusters...)
+		}
+	}
+
+	return clustersInfo, nil
+}
@@ -0,0 +1,362 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage/storagetest"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/gookit/goutil/dump"
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/logger"
+)
+
+var (
+	db           *Store
+	server       *httptest.Server
+	parentCtx    context.Context
+	ksc                        = context.Background()
+	parentLogger logger.Logger = logger.NewStructuredLogger(-1, os.Stdout)
+)
+
+func TestMain(m *testing.M) {
+	parentCtx = context.WithValue(context.TODO(), consts.KsctlTestFlagKey, "true")
+	ksc = context.WithValue(ksc, consts.KsctlContextUser, "fake")
+
+	// S3_ENDPOINT points the tests to a real S3 compatible service like MinIO
+	if os.Getenv("S3_ENDPOINT") == "" {
+		server = newFakeS3("ksctl")
+		_ = os.Setenv("S3_ENDPOINT", server.URL)
+		_ = os.Setenv("S3_BUCKET", "ksctl")
+		_ = os.Setenv("S3_ACCESS_KEY_ID", "fake")
+		_ = os.Setenv("S3_SECRET_ACCESS_KEY", "fake")
+	}
+
+	exitVal := m.Run()
+
+	if server != nil {
+		server.Close()
+	}
+
+	os.Exit(exitVal)
+}
+
+func testCreds() statefile.CredentialsS3 {
+	return statefile.CredentialsS3{
+		Endpoint:        os.Getenv("S3_ENDPOINT"),
+		Region:          "us-east-1",
+		Bucket:          os.Getenv("S3_BUCKET"),
+		Prefix:          "ksctl-test",
+		AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		UsePathStyle:    true,
+	}
+}
+
+func TestInitStorage(t *testing.T) {
+	_db, err := NewDBClient(parentCtx, testCreds())
+	if err != nil {
+		t.Fatalf("Error should not happen: %v", err)
+	}
+
+	db, err = _db.NewDatabaseClient(ksc, parentLogger)
+	if err != nil {
+		t.Fatalf("Error should not happen: %v", err)
+	}
+	err = db.Setup(consts.CloudAzure, "region", "name", consts.ClusterTypeSelfMang)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(ksc); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInvalidBucket(t *testing.T) {
+	creds := testCreds()
+	creds.Bucket = ""
+	if _, err := NewDBClient(parentCtx, creds); err == nil {
+		t.Fatal("Error should happen for an empty bucket")
+	}
+
+	creds.Bucket = "ksctl-missing-bucket"
+	if _, err := NewDBClient(parentCtx, creds); err == nil {
+		t.Fatal("Error should happen for a bucket which is not present")
+	}
+}
+
+func TestStore_RWD(t *testing.T) {
+	if _, err := db.Read(); err == nil {
+		t.Fatal("Error should occur as there is no folder created")
+	}
+	if err := db.DeleteCluster(); err == nil {
+		t.Fatalf("Error should happen on deleting cluster info")
+	}
+
+	if err := db.AlreadyCreated(consts.CloudAzure, "region", "name", consts.ClusterTypeSelfMang); err == nil {
+		t.Fatalf("Error should happen on checking for presence of the cluster")
+	}
+
+	fakeData := &statefile.StorageDocument{
+		Region:        "region",
+		ClusterName:   "name",
+		ClusterType:   "selfmanaged",
+		InfraProvider: consts.CloudAzure,
+	}
+	err := db.Write(fakeData)
+	if err != nil {
+		t.Fatalf("Error shouln't happen: %v", err)
+	}
+
+	if err := db.AlreadyCreated(consts.CloudAzure, "region", "name", consts.ClusterTypeSelfMang); err != nil {
+		t.Fatalf("Error shouldn't happen on checking for presence of the cluster: %v", err)
+	}
+
+	if gotFakeData, err := db.Read(); err != nil {
+		t.Fatalf("Error shouln't happen on reading file: %v", err)
+	} else {
+		if _, err := db.Read(); err != nil {
+			t.Fatalf("Second Read failed")
+		}
+		fmt.Printf("%#+v\n", gotFakeData)
+
+		if !reflect.DeepEqual(gotFakeData, fakeData) {
+			t.Fatalf("Written data doesn't match Reading")
+		}
+	}
+
+	if err := db.DeleteCluster(); err != nil {
+		t.Fatalf("Error shouln't happen on deleting cluster info: %v", err)
+	}
+}
+
+func TestStore_StaleWrite(t *testing.T) {
+	storagetest.StaleWrite(t, db)
+}
+
+func TestStore_ConditionalPut(t *testing.T) {
+	if err := db.Setup(consts.CloudAzure, "region", "conditional", consts.ClusterTypeSelfMang); err != nil {
+		t.Fatal(err)
+	}
+	key := getClusterPrefix(db) + "conditional.json"
+
+	assert.NilError(t, db.putObject(key, []byte(`{"v":1}`), ""))
+	err := db.putObject(key, []byte(`{"v":2}`), "")
+	assert.Check(t, isConditionFailed(err), fmt.Sprintf("expected the create to be rejected, got: %v", err))
+
+	_, etag, err := db.getObject(key)
+	assert.NilError(t, err)
+	assert.NilError(t, db.putObject(key, []byte(`{"v":2}`), etag))
+
+	err = db.putObject(key, []byte(`{"v":3}`), etag)
+	assert.Check(t, isConditionFailed(err), fmt.Sprintf("expected the update with an old etag to be rejected, got: %v", err))
+
+	assert.NilError(t, db.deleteObject(key))
+}
+
+func TestStore_Lock(t *testing.T) {
+	if err := db.Setup(consts.CloudAzure, "region", "locked", consts.ClusterTypeSelfMang); err != nil {
+		t.Fatal(err)
+	}
+	locker := db
+
+	lease, err := locker.Lock("alice", time.Minute)
+	assert.NilError(t, err)
+
+	_, err = locker.Lock("bob", time.Minute)
+	assert.Check(t, ksctlErrors.IsClusterLocked(err), fmt.Sprintf("expected cluster locked error, got: %v", err))
+	assert.ErrorContains(t, err, "locked by alice")
+
+	// the lease keeps to its cluster while the store is Setup for another one
+	if err := db.Setup(consts.CloudAws, "region", "other", consts.ClusterTypeMang); err != nil {
+		t.Fatal(err)
+	}
+	assert.NilError(t, locker.Renew(lease, 2*time.Minute))
+	assert.NilError(t, locker.Unlock(lease))
+	if err := db.Setup(consts.CloudAzure, "region", "locked", consts.ClusterTypeSelfMang); err != nil {
+		t.Fatal(err)
+	}
+
+	lease, err = locker.Lock("bob", time.Minute)
+	assert.NilError(t, err)
+	assert.NilError(t, locker.Unlock(lease))
+
+	_, err = locker.Lock("alice", time.Millisecond)
+	assert.NilError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	lease, err = locker.Lock("bob", time.Minute)
+	assert.NilError(t, err, "expired lease should be taken over")
+	assert.NilError(t, locker.Unlock(lease))
+}
+
+func TestStore_History(t *testing.T) {
+	storagetest.History(t, db)
+}
+func TestStore_Audit(t *testing.T) {
+	storagetest.Audit(t, db)
+}
+
+func TestGetClusterInfo(t *testing.T) {
+
+	t.Run("Setup some demo clusters", func(t *testing.T) {
+
+		func() {
+
+			if err := db.Setup(consts.CloudAzure, "regionAzure", "name_managed", consts.ClusterTypeMang); err != nil {
+				t.Fatal(err)
+			}
+
+			fakeData := &statefile.StorageDocument{
+				Region:        "regionAzure",
+				ClusterName:   "name_managed",
+				InfraProvider: consts.CloudAzure,
+				ClusterType:   "managed",
+				CloudInfra:    &statefile.InfrastructureState{Azure: &statefile.StateConfigurationAzure{}},
+			}
+
+			err := db.Write(fakeData)
+			if err != nil {
+				t.Fatalf("Error shouln't happen: %v", err)
+			}
+
+		}()
+
+		func() {
+
+			if err := db.Setup(consts.CloudAws, "regionAws", "name_managed", consts.ClusterTypeMang); err != nil {
+				t.Fatal(err)
+			}
+
+			fakeData := &statefile.StorageDocument{
+				Region:        "regionAws",
+				ClusterName:   "name_managed",
+				InfraProvider: consts.CloudAws,
+				ClusterType:   "managed",
+				CloudInfra:    &statefile.InfrastructureState{Aws: &statefile.StateConfigurationAws{}},
+			}
+
+			err := db.Write(fakeData)
+			if err != nil {
+				t.Fatalf("Error shouln't happen: %v", err)
+			}
+			err = db.Write(fakeData)
+			if err != nil {
+				t.Fatalf("Error shouln't happen on second Write: %v", err)
+			}
+
+		}()
+
+		func() {
+
+			if err := db.Setup(consts.CloudAws, "regionAws", "name_ha", consts.ClusterTypeSelfMang); err != nil {
+				t.Fatal(err)
+			}
+
+			fakeData := &statefile.StorageDocument{
+				Region:        "regionAws",
+				ClusterName:   "name_ha",
+				InfraProvider: consts.CloudAws,
+				ClusterType:   "selfmanaged",
+				CloudInfra:    &statefile.InfrastructureState{Aws: &statefile.StateConfigurationAws{}},
+				K8sBootstrap:  &statefile.KubernetesBootstrapState{K3s: &statefile.StateConfigurationK3s{}},
+			}
+
+			err := db.Write(fakeData)
+			if err != nil {
+				t.Fatalf("Error shouln't happen: %v", err)
+			}
+
+		}()
+	})
+
+	t.Run("fetch cluster Infos", func(t *testing.T) {
+		func(t *testing.T) {
+			m, err := db.GetOneOrMoreClusters(map[consts.KsctlSearchFilter]string{"cloud": "all", "clusterType": ""})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			dump.Println(m)
+			assert.Check(t, len(m[consts.ClusterTypeSelfMang]) == 1)
+			assert.Check(t, len(m[consts.ClusterTypeMang]) == 2)
+		}(t)
+
+		func(t *testing.T) {
+			m, err := db.GetOneOrMoreClusters(map[consts.KsctlSearchFilter]string{"cloud": "aws", "clusterType": "selfmanaged"})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			dump.Println(m)
+			assert.Check(t, len(m[consts.ClusterTypeSelfMang]) == 1)
+			assert.Check(t, len(m[consts.ClusterTypeMang]) == 0)
+		}(t)
+
+		func(t *testing.T) {
+			m, err := db.GetOneOrMoreClusters(map[consts.KsctlSearchFilter]string{"cloud": "azure", "clusterType": "managed"})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			dump.Println(m)
+			assert.Check(t, len(m[consts.ClusterTypeSelfMang]) == 0)
+			assert.Check(t, len(m[consts.ClusterTypeMang]) == 1)
+		}(t)
+	})
+
+}
+
+func TestDelete(t *testing.T) {
+
+	t.Run("delete all", func(t *testing.T) {
+		func() {
+			if err := db.Setup(consts.CloudAws, "regionAws", "name_ha", consts.ClusterTypeSelfMang); err != nil {
+				t.Fatal(err)
+			}
+			if err := db.DeleteCluster(); err != nil {
+				t.Fatal(err)
+			}
+		}()
+
+		func() {
+			if err := db.Setup(consts.CloudAws, "regionAws", "name_managed", consts.ClusterTypeMang); err != nil {
+				t.Fatal(err)
+			}
+			if err := db.DeleteCluster(); err != nil {
+				t.Fatal(err)
+			}
+		}()
+
+		func() {
+			if err := db.Setup(consts.CloudAzure, "regionAzure", "name_managed", consts.ClusterTypeMang); err != nil {
+				t.Fatal(err)
+			}
+			if err := db.DeleteCluster(); err != nil {
+				t.Fatal(err)
+			}
+		}()
+	})
+}
@@ -0,0 +1,130 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+)
+
+// RecordAudit inserts the entry in the ksctl_audit table, DeleteCluster leaves the table as is
+// so that the trail outlives the cluster
+func (db *Store) RecordAudit(entry *storage.AuditEntry) error {
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to serialize the audit entry", "Reason", err),
+		)
+	}
+
+	if _, err := db.db.ExecContext(db.ctx, `
+INSERT INTO ksctl_audit (id, cloud, cluster_type, name, region, actor, actor_group, operation, started_at, data)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		entry.ID, string(entry.Cloud), string(entry.ClusterType), entry.ClusterName, entry.Region,
+		entry.Actor, entry.Group, string(entry.Operation), entry.StartedAt.UnixNano(), string(raw),
+	); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to insert the audit entry", "Reason", err),
+		)
+	}
+	return nil
+}
+
+func (db *Store) QueryAudit(query storage.AuditQuery) ([]*storage.AuditEntry, error) {
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	var (
+		conds []string
+		args  []any
+	)
+	where := func(cond string, v any) {
+		args = append(args, v)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	exact := []struct{ column, value string }{
+		{"cloud", string(query.Cloud)},
+		{"cluster_type", string(query.ClusterType)},
+		{"name", query.ClusterName},
+		{"region", query.Region},
+		{"actor", query.Actor},
+		{"actor_group", query.Group},
+		{"operation", string(query.Operation)},
+	}
+	for _, f := range exact {
+		if len(f.value) != 0 {
+			where(f.column+" = $%d", f.value)
+		}
+	}
+	if !query.Since.IsZero() {
+		where("started_at >= $%d", query.Since.UnixNano())
+	}
+	if !query.Until.IsZero() {
+		where("started_at < $%d", query.Until.UnixNano())
+	}
+
+	stmt := "SELECT data FROM ksctl_audit"
+	if len(conds) != 0 {
+		stmt += " WHERE " + strings.Join(conds, " AND ")
+	}
+	stmt += " ORDER BY started_at DESC, id DESC"
+	if query.Limit > 0 {
+		stmt += fmt.Sprintf(" LIMIT %d", query.Limit)
+	}
+
+	rows, err := db.db.QueryContext(db.ctx, stmt, args...)
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to list the audit entries", "Reason", err),
+		)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var entries []*storage.AuditEntry
+	for rows.Next() {
+		var raw string
+		if err := rows.Scan(&raw); err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to list the audit entries", "Reason", err),
+			)
+		}
+		var v *storage.AuditEntry
+		if err := json.Unmarshal([]byte(raw), &v); err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to deserialize the audit entry", "Reason", err),
+			)
+		}
+		entries = append(entries, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to list the audit entries", "Reason", err),
+		)
+	}
+	return storage.FilterAuditEntries(entries, query), nil
+}
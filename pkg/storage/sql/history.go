@@ -0,0 +1,141 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	dbsql "database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"github.com/ksctl/ksctl/v2/pkg/storage/encryption"
+)
+
+// recordRevision keeps a copy of the state written in the same transaction and removes the revisions which are older than the ones to be kept
+func (db *Store) recordRevision(tx *dbsql.Tx, doc *statefile.StorageDocument) error {
+	raw, err := json.Marshal(storage.NewStateRevision(db.writer, doc))
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(db.ctx, `
+INSERT INTO ksctl_cluster_revisions (cloud, cluster_type, name, region, revision, data)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT DO NOTHING`,
+		append(clusterKey(db), doc.Revision, string(raw))...,
+	); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(db.ctx,
+		"DELETE FROM ksctl_cluster_revisions WHERE "+whereCluster+" AND revision <= $5",
+		append(clusterKey(db), doc.Revision-int64(consts.CounterMaxStateRevisions))...,
+	)
+	return err
+}
+
+func (db *Store) decodeRevision(raw string) (*storage.StateRevision, error) {
+	var v *storage.StateRevision
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to deserialize the revision", "Reason", err),
+		)
+	}
+	return v, nil
+}
+
+func (db *Store) ListRevisions() ([]*storage.StateRevision, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	rows, err := db.db.QueryContext(db.ctx,
+		"SELECT data FROM ksctl_cluster_revisions WHERE "+whereCluster+" ORDER BY revision DESC",
+		clusterKey(db)...,
+	)
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to list the revisions", "Reason", err),
+		)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []*storage.StateRevision
+	for rows.Next() {
+		var raw string
+		if err := rows.Scan(&raw); err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to list the revisions", "Reason", err),
+			)
+		}
+		v, err := db.decodeRevision(raw)
+		if err != nil {
+			return nil, err
+		}
+		v.Document = nil
+		out = append(out, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to list the revisions", "Reason", err),
+		)
+	}
+	return out, nil
+}
+
+func (db *Store) ReadRevision(revision int64) (*storage.StateRevision, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	var raw string
+	err := db.db.QueryRowContext(db.ctx,
+		"SELECT data FROM ksctl_cluster_revisions WHERE "+whereCluster+" AND revision = $5",
+		append(clusterKey(db), revision)...,
+	).Scan(&raw)
+	if err != nil {
+		if errors.Is(err, dbsql.ErrNoRows) {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrNoMatchingRecordsFound,
+				db.l.NewError(db.ctx, "revision not present", "revision", revision),
+			)
+		}
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to get the revision", "Reason", err),
+		)
+	}
+
+	v, err := db.decodeRevision(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := encryption.OpenDocument(db.enc, v.Document); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to decrypt the state", "Reason", err),
+		)
+	}
+	return v, nil
+}
@@ -0,0 +1,217 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	dbsql "database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+)
+
+// leaseKey returns the primary key of the cluster the lease was taken on, Lock keeps it in the key of the lease
+func leaseKey(lease *storage.ClusterLease) ([]any, error) {
+	var key []any
+	if err := json.Unmarshal([]byte(lease.Key), &key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// getLease returns the lease present on the cluster with the key, nil when the cluster is not locked
+func (db *Store) getLease(key []any) (*storage.ClusterLease, error) {
+	var raw string
+	err := db.db.QueryRowContext(db.ctx, "SELECT lease FROM ksctl_cluster_locks WHERE "+whereCluster, key...).Scan(&raw)
+	if err != nil {
+		if errors.Is(err, dbsql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var lease *storage.ClusterLease
+	if err := json.Unmarshal([]byte(raw), &lease); err != nil {
+		return nil, err
+	}
+	return lease, nil
+}
+
+// Lock removes an expired lease and inserts the new one, the primary key lets only one of the contenders win
+func (db *Store) Lock(owner string, ttl time.Duration) (*storage.ClusterLease, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	lease, err := storage.NewClusterLease(owner, ttl)
+	if err != nil {
+		return nil, err
+	}
+	key := clusterKey(db)
+	rawKey, err := json.Marshal(key)
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to serialize the lock key", "Reason", err),
+		)
+	}
+	lease.Key = string(rawKey)
+	raw, err := json.Marshal(lease)
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to serialize the lease", "Reason", err),
+		)
+	}
+
+	if _, err := db.db.ExecContext(db.ctx,
+		"DELETE FROM ksctl_cluster_locks WHERE "+whereCluster+" AND expires_at <= $5",
+		append(key, time.Now().UTC().UnixNano())...,
+	); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to remove the expired lease", "Reason", err),
+		)
+	}
+
+	res, err := db.db.ExecContext(db.ctx, `
+INSERT INTO ksctl_cluster_locks (cloud, cluster_type, name, region, lease_id, lease, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT DO NOTHING`,
+		append(key, lease.ID, string(raw), lease.ExpiresAt.UnixNano())...,
+	)
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to acquire the lock", "Reason", err),
+		)
+	}
+	if inserted, err := res.RowsAffected(); err == nil && inserted == 1 {
+		return lease, nil
+	}
+
+	holder, err := db.getLease(key)
+	if err != nil || holder == nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrClusterLocked,
+			db.l.NewError(db.ctx, "cluster is locked by someone else", "Reason", err),
+		)
+	}
+	return nil, ksctlErrors.WrapError(
+		ksctlErrors.ErrClusterLocked,
+		db.l.NewError(db.ctx, "cluster is "+holder.String()),
+	)
+}
+
+// lostLease explains why a lease could not be found, it is either gone or held by someone else
+func (db *Store) lostLease(key []any, lease *storage.ClusterLease) error {
+	holder, err := db.getLease(key)
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to read the lease", "Reason", err),
+		)
+	}
+	if holder == nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrClusterLocked,
+			db.l.NewError(db.ctx, "lease got expired", "id", lease.ID),
+		)
+	}
+	return ksctlErrors.WrapError(
+		ksctlErrors.ErrClusterLocked,
+		db.l.NewError(db.ctx, "lease got lost, cluster is "+holder.String()),
+	)
+}
+
+func (db *Store) Renew(lease *storage.ClusterLease, ttl time.Duration) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	key, err := leaseKey(lease)
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidOperation,
+			db.l.NewError(db.ctx, "lease was not taken by this store", "id", lease.ID, "Reason", err),
+		)
+	}
+
+	renewed := *lease
+	renewed.ExpiresAt = time.Now().UTC().Add(ttl)
+	raw, err := json.Marshal(renewed)
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to serialize the lease", "Reason", err),
+		)
+	}
+
+	res, err := db.db.ExecContext(db.ctx,
+		"UPDATE ksctl_cluster_locks SET lease = $6, expires_at = $7 WHERE "+whereCluster+" AND lease_id = $5",
+		append(key, lease.ID, string(raw), renewed.ExpiresAt.UnixNano())...,
+	)
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to renew the lease", "Reason", err),
+		)
+	}
+	if updated, err := res.RowsAffected(); err == nil && updated == 0 {
+		return db.lostLease(key, lease)
+	}
+	lease.ExpiresAt = renewed.ExpiresAt
+	return nil
+}
+
+func (db *Store) Unlock(lease *storage.ClusterLease) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	key, err := leaseKey(lease)
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidOperation,
+			db.l.NewError(db.ctx, "lease was not taken by this store", "id", lease.ID, "Reason", err),
+		)
+	}
+
+	res, err := db.db.ExecContext(db.ctx,
+		"DELETE FROM ksctl_cluster_locks WHERE "+whereCluster+" AND lease_id = $5",
+		append(key, lease.ID)...,
+	)
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to release the lease", "Reason", err),
+		)
+	}
+	if deleted, err := res.RowsAffected(); err == nil && deleted == 0 {
+		// releasing a lease which is already gone is fine, one taken over by someone else is not
+		holder, err := db.getLease(key)
+		if err != nil {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to read the lease", "Reason", err),
+			)
+		}
+		if holder != nil {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrClusterLocked,
+				db.l.NewError(db.ctx, "lease got lost, cluster is "+holder.String()),
+			)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	dbsql "database/sql"
+	"fmt"
+	"time"
+)
+
+// schemaMigrations are applied in order, the applied ones are recorded in the ksctl_schema_migrations table.
+// New statements are added as a new entry at the end, an applied entry is never changed
+var schemaMigrations = []string{
+	`
+CREATE TABLE IF NOT EXISTS ksctl_clusters (
+	cloud          TEXT    NOT NULL,
+	cluster_type   TEXT    NOT NULL,
+	name           TEXT    NOT NULL,
+	region         TEXT    NOT NULL,
+	state          TEXT    NOT NULL DEFAULT '',
+	owner          TEXT    NOT NULL DEFAULT '',
+	team           TEXT    NOT NULL DEFAULT '',
+	revision       BIGINT  NOT NULL,
+	schema_version INTEGER NOT NULL DEFAULT 0,
+	document       TEXT    NOT NULL,
+	updated_at     TEXT    NOT NULL,
+	PRIMARY KEY (cloud, cluster_type, name, region)
+);
+CREATE INDEX IF NOT EXISTS ksctl_clusters_region ON ksctl_clusters (region);
+CREATE INDEX IF NOT EXISTS ksctl_clusters_name ON ksctl_clusters (name);
+CREATE INDEX IF NOT EXISTS ksctl_clusters_state ON ksctl_clusters (state);
+CREATE INDEX IF NOT EXISTS ksctl_clusters_owner ON ksctl_clusters (owner);
+CREATE INDEX IF NOT EXISTS ksctl_clusters_team ON ksctl_clusters (team);
+
+CREATE TABLE IF NOT EXISTS ksctl_cluster_revisions (
+	cloud        TEXT   NOT NULL,
+	cluster_type TEXT   NOT NULL,
+	name         TEXT   NOT NULL,
+	region       TEXT   NOT NULL,
+	revision     BIGINT NOT NULL,
+	data         TEXT   NOT NULL,
+	PRIMARY KEY (cloud, cluster_type, name, region, revision)
+);
+
+CREATE TABLE IF NOT EXISTS ksctl_cluster_locks (
+	cloud        TEXT   NOT NULL,
+	cluster_type TEXT   NOT NULL,
+	name         TEXT   NOT NULL,
+	region       TEXT   NOT NULL,
+	lease_id     TEXT   NOT NULL,
+	lease        TEXT   NOT NULL,
+	expires_at   BIGINT NOT NULL,
+	PRIMARY KEY (cloud, cluster_type, name, region)
+);
+`,
+	`
+CREATE TABLE IF NOT EXISTS ksctl_audit (
+	id           TEXT   NOT NULL PRIMARY KEY,
+	cloud        TEXT   NOT NULL,
+	cluster_type TEXT   NOT NULL,
+	name         TEXT   NOT NULL,
+	region       TEXT   NOT NULL,
+	actor        TEXT   NOT NULL,
+	actor_group  TEXT   NOT NULL DEFAULT '',
+	operation    TEXT   NOT NULL,
+	started_at   BIGINT NOT NULL,
+	data         TEXT   NOT NULL
+);
+CREATE INDEX IF NOT EXISTS ksctl_audit_cluster ON ksctl_audit (cloud, cluster_type, name, region);
+CREATE INDEX IF NOT EXISTS ksctl_audit_actor ON ksctl_audit (actor);
+`,
+}
+
+// migrateSchema brings the tables to the latest version, every migration runs in a transaction of its own
+func migrateSchema(db *dbsql.DB) error {
+	if _, err := db.Exec(`
+CREATE TABLE IF NOT EXISTS ksctl_schema_migrations (
+	version    INTEGER NOT NULL PRIMARY KEY,
+	applied_at TEXT    NOT NULL
+)`); err != nil {
+		return err
+	}
+
+	var current int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM ksctl_schema_migrations`).Scan(&current); err != nil {
+		return err
+	}
+	if current > len(schemaMigrations) {
+		return fmt.Errorf("database schema version %d is newer than the supported version %d", current, len(schemaMigrations))
+	}
+
+	for i := current; i < len(schemaMigrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(schemaMigrations[i]); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("schema migration %d failed: %w", i+1, err)
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO ksctl_schema_migrations (version, applied_at) VALUES ($1, $2)`,
+			i+1, time.Now().UTC().Format(time.RFC3339),
+		); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,548 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"context"
+	dbsql "database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ksctl/ksctl/v2/migrations"
+	"github.com/ksctl/ksctl/v2/pkg/logger"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+	"github.com/ksctl/ksctl/v2/pkg/storage/encryption"
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+)
+
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+type SQLConn struct {
+	ctx    context.Context
+	db     *dbsql.DB
+	driver string
+	mu     *sync.Mutex
+}
+
+func NewDBClient(parentCtx context.Context, creds statefile.CredentialsSQL) (*SQLConn, error) {
+	conn := &SQLConn{
+		ctx:    context.WithValue(parentCtx, consts.KsctlModuleNameKey, string(consts.StoreExtSQL)),
+		driver: creds.Driver,
+		mu:     &sync.Mutex{},
+	}
+	if len(conn.driver) == 0 {
+		conn.driver = DriverSQLite
+	}
+	if conn.driver != DriverSQLite && conn.driver != DriverPostgres {
+		return nil, fmt.Errorf("SQL driver %q is not supported, use %s or %s", conn.driver, DriverSQLite, DriverPostgres)
+	}
+	if len(creds.DSN) == 0 {
+		return nil, fmt.Errorf("SQL dsn is not set")
+	}
+
+	db, err := dbsql.Open(conn.driver, creds.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("SQL failed to open the database, Reason: %v", err)
+	}
+	if conn.driver == DriverSQLite {
+		// sqlite allows a single writer, one connection serializes the transactions
+		// instead of failing them with SQLITE_BUSY
+		db.SetMaxOpenConns(1)
+		if _, err := db.ExecContext(conn.ctx, "PRAGMA busy_timeout = 5000"); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("SQL failed to configure the database, Reason: %v", err)
+		}
+	}
+
+	if err := db.PingContext(conn.ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("SQL failed to reach the database, Reason: %v", err)
+	}
+
+	if err := migrateSchema(db); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("SQL failed to migrate the schema, Reason: %v", err)
+	}
+
+	conn.db = db
+	return conn, nil
+}
+
+// Close releases the connections to the database
+func (conn *SQLConn) Close() error {
+	return conn.db.Close()
+}
+
+type Store struct {
+	ctx context.Context
+	l   logger.Logger
+	db  *dbsql.DB
+
+	cloudProvider string
+	clusterType   string
+	clusterName   string
+	region        string
+
+	mu *sync.Mutex
+	wg *sync.WaitGroup
+
+	// enc seals the secrets of the state at rest, nil keeps the state in plaintext
+	enc encryption.Encrypter
+
+	// writer is recorded along every revision of the state
+	writer string
+
+	// noUpgrade hands out the documents as stored, set on the copy used while upgrading them in bulk
+	noUpgrade bool
+}
+
+func (conn *SQLConn) NewDatabaseClient(ksctlConfig context.Context, l logger.Logger) (*Store, error) {
+
+	db := &Store{
+		ctx:    conn.ctx,
+		l:      l,
+		mu:     conn.mu,
+		wg:     new(sync.WaitGroup),
+		db:     conn.db,
+		writer: storage.WriterFromContext(ksctlConfig),
+	}
+
+	return db, nil
+}
+
+func (db *Store) SetEncrypter(enc encryption.Encrypter) {
+	db.enc = enc
+}
+
+// WithoutSchemaUpgrade returns a copy of the store handing out the documents as stored,
+// the copy shares the connection with the store and is Setup on its own
+func (db *Store) WithoutSchemaUpgrade() storage.Storage {
+	raw := *db
+	raw.noUpgrade = true
+	return &raw
+}
+
+// upgradeDocument brings the document read to the latest schema version, the upgraded document is stored on the next write
+func (db *Store) upgradeDocument(doc *statefile.StorageDocument) error {
+	if db.noUpgrade {
+		return nil
+	}
+	if _, err := migrations.UpgradeDocument(doc); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidVersion,
+			db.l.NewError(db.ctx, "failed to upgrade the state", "Reason", err),
+		)
+	}
+	return nil
+}
+
+func (db *Store) Connect(_ context.Context) error {
+
+	db.l.Debug(db.ctx, "CONN to SQL")
+
+	return nil
+}
+
+// clusterKey returns the primary key of the cluster selected through Setup in the order of the table columns
+func clusterKey(db *Store) []any {
+	return []any{db.cloudProvider, db.clusterType, db.clusterName, db.region}
+}
+
+const whereCluster = "cloud = $1 AND cluster_type = $2 AND name = $3 AND region = $4"
+
+func (db *Store) decodeState(raw string) (*statefile.StorageDocument, error) {
+	var result *statefile.StorageDocument
+	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to deserialize the state", "Reason", err),
+		)
+	}
+	if err := encryption.OpenDocument(db.enc, result); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to decrypt the state", "Reason", err),
+		)
+	}
+	if err := db.upgradeDocument(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (db *Store) Read() (*statefile.StorageDocument, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	raw, err := db.isPresent()
+	if err != nil {
+		return nil, err
+	}
+
+	return db.decodeState(raw)
+}
+
+func (db *Store) Write(data *statefile.StorageDocument) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	migrations.StampDocument(data)
+
+	expectedRevision := data.Revision
+	data.Revision = expectedRevision + 1
+
+	sealed, err := encryption.SealDocument(db.enc, data)
+	if err != nil {
+		data.Revision = expectedRevision
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to encrypt the state", "Reason", err),
+		)
+	}
+
+	raw, err := json.Marshal(sealed)
+	if err != nil {
+		data.Revision = expectedRevision
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to serialize the state", "Reason", err),
+		)
+	}
+
+	if err := db.writeState(sealed, string(raw), expectedRevision); err != nil {
+		data.Revision = expectedRevision
+		return err
+	}
+	return nil
+}
+
+// writeState stores the state and its revision in one transaction, the update is conditioned on
+// the revision the caller read so a concurrent writer makes it a stale write instead of a lost update
+func (db *Store) writeState(sealed *statefile.StorageDocument, raw string, expectedRevision int64) error {
+	tx, err := db.db.BeginTx(db.ctx, nil)
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to begin the transaction", "Reason", err),
+		)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	columns := append(clusterKey(db),
+		string(sealed.PlatformSpec.State),
+		sealed.PlatformSpec.Owner,
+		sealed.PlatformSpec.Team,
+		sealed.Revision,
+		sealed.SchemaVersion,
+		raw,
+		time.Now().UTC().Format(time.RFC3339Nano),
+	)
+
+	res, err := tx.ExecContext(db.ctx, `
+UPDATE ksctl_clusters
+SET state = $5, owner = $6, team = $7, revision = $8, schema_version = $9, document = $10, updated_at = $11
+WHERE `+whereCluster+` AND revision = $12`,
+		append(columns, expectedRevision)...,
+	)
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to write state", "Reason", err),
+		)
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to write state", "Reason", err),
+		)
+	}
+
+	if updated == 0 {
+		var storedRevision int64
+		err := tx.QueryRowContext(db.ctx, "SELECT revision FROM ksctl_clusters WHERE "+whereCluster, clusterKey(db)...).Scan(&storedRevision)
+		switch {
+		case err == nil:
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrStaleStateWrite,
+				db.l.NewError(db.ctx, "state revision mismatch", "stored", storedRevision, "got", expectedRevision),
+			)
+		case !errors.Is(err, dbsql.ErrNoRows):
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to get cluster", "Reason", err),
+			)
+		}
+
+		res, err := tx.ExecContext(db.ctx, `
+INSERT INTO ksctl_clusters (cloud, cluster_type, name, region, state, owner, team, revision, schema_version, document, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ON CONFLICT DO NOTHING`,
+			columns...,
+		)
+		if err != nil {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to write state", "Reason", err),
+			)
+		}
+		if inserted, err := res.RowsAffected(); err != nil || inserted == 0 {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrStaleStateWrite,
+				db.l.NewError(db.ctx, "state got created by someone else", "revision", expectedRevision),
+			)
+		}
+	}
+
+	if err := db.recordRevision(tx, sealed); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to record the state revision", "Reason", err),
+		)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to commit the state", "Reason", err),
+		)
+	}
+	return nil
+}
+
+func (db *Store) Setup(cloud consts.KsctlCloud, region, clusterName string, clusterType consts.KsctlClusterType) error {
+	switch cloud {
+	case consts.CloudAws, consts.CloudAzure, consts.CloudLocal:
+		db.cloudProvider = string(cloud)
+	default:
+		return ksctlErrors.NewError(ksctlErrors.ErrInvalidCloudProvider)
+	}
+	if clusterType != consts.ClusterTypeSelfMang && clusterType != consts.ClusterTypeMang {
+		return ksctlErrors.NewError(ksctlErrors.ErrInvalidClusterType)
+	}
+
+	db.clusterName = clusterName
+	db.region = region
+	db.clusterType = string(clusterType)
+
+	return nil
+}
+
+func (db *Store) DeleteCluster() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	tx, err := db.db.BeginTx(db.ctx, nil)
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to begin the transaction", "Reason", err),
+		)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(db.ctx, "DELETE FROM ksctl_clusters WHERE "+whereCluster, clusterKey(db)...)
+	if err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to delete the state", "Reason", err),
+		)
+	}
+	if deleted, err := res.RowsAffected(); err == nil && deleted == 0 {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrNoMatchingRecordsFound,
+			db.l.NewError(db.ctx, "no matching cluster present"),
+		)
+	}
+
+	if _, err := tx.ExecContext(db.ctx, "DELETE FROM ksctl_cluster_revisions WHERE "+whereCluster, clusterKey(db)...); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to remove the state revisions", "Reason", err),
+		)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to commit the deletion", "Reason", err),
+		)
+	}
+	return nil
+}
+
+func (db *Store) isPresent() (string, error) {
+	var raw string
+	err := db.db.QueryRowContext(db.ctx, "SELECT document FROM ksctl_clusters WHERE "+whereCluster, clusterKey(db)...).Scan(&raw)
+	if err != nil {
+		if errors.Is(err, dbsql.ErrNoRows) {
+			return "", ksctlErrors.WrapError(
+				ksctlErrors.ErrNoMatchingRecordsFound,
+				db.l.NewError(db.ctx, "no matching cluster present"),
+			)
+		}
+		return "", ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to get cluster", "Reason", err),
+		)
+	}
+	return raw, nil
+}
+
+func (db *Store) AlreadyCreated(cloud consts.KsctlCloud, region, clusterName string, clusterType consts.KsctlClusterType) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	err := db.Setup(cloud, region, clusterName, clusterType)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.isPresent()
+	return err
+}
+
+// inClause returns the placeholders of a IN clause for the values, numbered after the args already present
+func inClause(args []any, values []string) (string, []any) {
+	placeholders := make([]string, 0, len(values))
+	for _, v := range values {
+		args = append(args, v)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+	}
+	return "(" + strings.Join(placeholders, ", ") + ")", args
+}
+
+func (db *Store) GetOneOrMoreClusters(filters map[consts.KsctlSearchFilter]string) (map[consts.KsctlClusterType][]*statefile.StorageDocument, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.wg.Add(1)
+	defer db.wg.Done()
+
+	if err := storage.ValidateFilters(filters); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			db.l.NewError(db.ctx, "invalid filters", "Reason", err),
+		)
+	}
+
+	clusterType := filters[consts.ClusterType]
+	cloud := filters[consts.Cloud]
+
+	var filterCloudPath, filterClusterType []string
+
+	switch cloud {
+	case string(consts.CloudAll), "":
+		filterCloudPath = append(filterCloudPath, string(consts.CloudAws), string(consts.CloudAzure), string(consts.CloudLocal))
+
+	case string(consts.CloudAzure):
+		filterCloudPath = append(filterCloudPath, string(consts.CloudAzure))
+
+	case string(consts.CloudAws):
+		filterCloudPath = append(filterCloudPath, string(consts.CloudAws))
+
+	case string(consts.CloudLocal):
+		filterCloudPath = append(filterCloudPath, string(consts.CloudLocal))
+	}
+
+	switch clusterType {
+	case string(consts.ClusterTypeSelfMang):
+		filterClusterType = append(filterClusterType, string(consts.ClusterTypeSelfMang))
+
+	case string(consts.ClusterTypeMang):
+		filterClusterType = append(filterClusterType, string(consts.ClusterTypeMang))
+
+	case "":
+		filterClusterType = append(filterClusterType, string(consts.ClusterTypeMang), string(consts.ClusterTypeSelfMang))
+	}
+	db.l.Debug(db.ctx, "storage.external.sql.GetOneOrMoreClusters", "filter", filters, "filterCloudPath", filterCloudPath, "filterClusterType", filterClusterType)
+
+	clustersInfo := make(map[consts.KsctlClusterType][]*statefile.StorageDocument)
+	if len(filterCloudPath) == 0 || len(filterClusterType) == 0 {
+		return clustersInfo, nil
+	}
+
+	var args []any
+	clouds, args := inClause(args, filterCloudPath)
+	types, args := inClause(args, filterClusterType)
+	query := "SELECT cluster_type, document FROM ksctl_clusters WHERE cloud IN " + clouds + " AND cluster_type IN " + types
+	for filter, column := range map[consts.KsctlSearchFilter]string{
+		consts.Region: "region",
+		consts.Name:   "name",
+		consts.State:  "state",
+		consts.Owner:  "owner",
+		consts.Team:   "team",
+	} {
+		if v, ok := filters[filter]; ok && len(v) != 0 {
+			args = append(args, v)
+			query += fmt.Sprintf(" AND %s = $%d", column, len(args))
+		}
+	}
+	query += " ORDER BY cloud, cluster_type, name, region"
+
+	rows, err := db.db.QueryContext(db.ctx, query, args...)
+	if err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to find the state", "Reason", err),
+		)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var clusterType, raw string
+		if err := rows.Scan(&clusterType, &raw); err != nil {
+			return nil, ksctlErrors.WrapError(
+				ksctlErrors.ErrInternal,
+				db.l.NewError(db.ctx, "failed to find the state", "Reason", err),
+			)
+		}
+		result, err := db.decodeState(raw)
+		if err != nil {
+			return nil, err
+		}
+		if !storage.MatchesFilters(filters, result) {
+			continue
+		}
+		clustersInfo[consts.KsctlClusterType(clusterType)] = append(clustersInfo[consts.KsctlClusterType(clusterType)], result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ksctlErrors.WrapError(
+			ksctlErrors.ErrInternal,
+			db.l.NewError(db.ctx, "failed to find the state", "Reason", err),
+		)
+	}
+
+	return clustersInfo, nil
+}
@@ -0,0 +1,395 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage/storagetest"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/gookit/goutil/dump"
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/logger"
+)
+
+var (
+	db           *Store
+	dir          string
+	parentCtx    context.Context
+	ksc                        = context.Background()
+	parentLogger logger.Logger = logger.NewStructuredLogger(-1, os.Stdout)
+)
+
+func TestMain(m *testing.M) {
+	parentCtx = context.WithValue(context.TODO(), consts.KsctlTestFlagKey, "true")
+	ksc = context.WithValue(ksc, consts.KsctlContextUser, "fake")
+
+	// SQL_DRIVER and SQL_DSN point the tests to a real database like PostgreSQL
+	if os.Getenv("SQL_DSN") == "" {
+		var err error
+		dir, err = os.MkdirTemp("", "ksctl-sql-test")
+		if err != nil {
+			panic(err)
+		}
+		_ = os.Setenv("SQL_DRIVER", DriverSQLite)
+		_ = os.Setenv("SQL_DSN", filepath.Join(dir, "ksctl.db"))
+	}
+
+	exitVal := m.Run()
+
+	if len(dir) != 0 {
+		_ = os.RemoveAll(dir)
+	}
+
+	os.Exit(exitVal)
+}
+
+func testCreds() statefile.CredentialsSQL {
+	return statefile.CredentialsSQL{
+		Driver: os.Getenv("SQL_DRIVER"),
+		DSN:    os.Getenv("SQL_DSN"),
+	}
+}
+
+func TestInitStorage(t *testing.T) {
+	_db, err := NewDBClient(parentCtx, testCreds())
+	if err != nil {
+		t.Fatalf("Error should not happen: %v", err)
+	}
+
+	db, err = _db.NewDatabaseClient(ksc, parentLogger)
+	if err != nil {
+		t.Fatalf("Error should not happen: %v", err)
+	}
+	err = db.Setup(consts.CloudAzure, "region", "name", consts.ClusterTypeSelfMang)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Connect(ksc); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInvalidCreds(t *testing.T) {
+	creds := testCreds()
+	creds.Driver = "mysql"
+	if _, err := NewDBClient(parentCtx, creds); err == nil {
+		t.Fatal("Error should happen for a driver which is not supported")
+	}
+
+	creds = testCreds()
+	creds.DSN = ""
+	if _, err := NewDBClient(parentCtx, creds); err == nil {
+		t.Fatal("Error should happen for an empty dsn")
+	}
+}
+
+func TestSchemaMigrations(t *testing.T) {
+	// reconnecting must not apply the migrations again
+	conn, err := NewDBClient(parentCtx, testCreds())
+	assert.NilError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	var applied, latest int
+	assert.NilError(t, conn.db.QueryRow("SELECT COUNT(*), MAX(version) FROM ksctl_schema_migrations").Scan(&applied, &latest))
+	assert.Equal(t, applied, len(schemaMigrations))
+	assert.Equal(t, latest, len(schemaMigrations))
+}
+
+func TestStore_RWD(t *testing.T) {
+	if _, err := db.Read(); err == nil {
+		t.Fatal("Error should occur as there is no folder created")
+	}
+	if err := db.DeleteCluster(); err == nil {
+		t.Fatalf("Error should happen on deleting cluster info")
+	}
+
+	if err := db.AlreadyCreated(consts.CloudAzure, "region", "name", consts.ClusterTypeSelfMang); err == nil {
+		t.Fatalf("Error should happen on checking for presence of the cluster")
+	}
+
+	fakeData := &statefile.StorageDocument{
+		Region:        "region",
+		ClusterName:   "name",
+		ClusterType:   "selfmanaged",
+		InfraProvider: consts.CloudAzure,
+	}
+	err := db.Write(fakeData)
+	if err != nil {
+		t.Fatalf("Error shouln't happen: %v", err)
+	}
+
+	if err := db.AlreadyCreated(consts.CloudAzure, "region", "name", consts.ClusterTypeSelfMang); err != nil {
+		t.Fatalf("Error shouldn't happen on checking for presence of the cluster: %v", err)
+	}
+
+	if gotFakeData, err := db.Read(); err != nil {
+		t.Fatalf("Error shouln't happen on reading file: %v", err)
+	} else {
+		if _, err := db.Read(); err != nil {
+			t.Fatalf("Second Read failed")
+		}
+		fmt.Printf("%#+v\n", gotFakeData)
+
+		if !reflect.DeepEqual(gotFakeData, fakeData) {
+			t.Fatalf("Written data doesn't match Reading")
+		}
+	}
+
+	if err := db.DeleteCluster(); err != nil {
+		t.Fatalf("Error shouln't happen on deleting cluster info: %v", err)
+	}
+}
+
+func TestStore_StaleWrite(t *testing.T) {
+	storagetest.StaleWrite(t, db)
+}
+
+func TestStore_IndexedColumns(t *testing.T) {
+	if err := db.Setup(consts.CloudAws, "region", "indexed", consts.ClusterTypeMang); err != nil {
+		t.Fatal(err)
+	}
+
+	fakeData := &statefile.StorageDocument{
+		Region:        "region",
+		ClusterName:   "indexed",
+		ClusterType:   "managed",
+		InfraProvider: consts.CloudAws,
+		PlatformSpec: statefile.PlatformSpec{
+			State: statefile.Running,
+			Owner: "alice",
+			Team:  "platform",
+		},
+	}
+	assert.NilError(t, db.Write(fakeData))
+
+	var state, owner, team string
+	var revision int64
+	assert.NilError(t, db.db.QueryRow(
+		"SELECT state, owner, team, revision FROM ksctl_clusters WHERE "+whereCluster, clusterKey(db)...,
+	).Scan(&state, &owner, &team, &revision))
+	assert.Equal(t, state, string(statefile.Running))
+	assert.Equal(t, owner, "alice")
+	assert.Equal(t, team, "platform")
+	assert.Equal(t, revision, fakeData.Revision)
+
+	assert.NilError(t, db.DeleteCluster())
+}
+
+func TestStore_Lock(t *testing.T) {
+	if err := db.Setup(consts.CloudAzure, "region", "locked", consts.ClusterTypeSelfMang); err != nil {
+		t.Fatal(err)
+	}
+	locker := db
+
+	lease, err := locker.Lock("alice", time.Minute)
+	assert.NilError(t, err)
+
+	_, err = locker.Lock("bob", time.Minute)
+	assert.Check(t, ksctlErrors.IsClusterLocked(err), fmt.Sprintf("expected cluster locked error, got: %v", err))
+	assert.ErrorContains(t, err, "locked by alice")
+
+	// the lease keeps to its cluster while the store is Setup for another one
+	if err := db.Setup(consts.CloudAws, "region", "other", consts.ClusterTypeMang); err != nil {
+		t.Fatal(err)
+	}
+	assert.NilError(t, locker.Renew(lease, 2*time.Minute))
+	assert.NilError(t, locker.Unlock(lease))
+	if err := db.Setup(consts.CloudAzure, "region", "locked", consts.ClusterTypeSelfMang); err != nil {
+		t.Fatal(err)
+	}
+
+	lease, err = locker.Lock("bob", time.Minute)
+	assert.NilError(t, err)
+	assert.NilError(t, locker.Unlock(lease))
+
+	_, err = locker.Lock("alice", time.Millisecond)
+	assert.NilError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	lease, err = locker.Lock("bob", time.Minute)
+	assert.NilError(t, err, "expired lease should be taken over")
+	assert.NilError(t, locker.Unlock(lease))
+}
+
+func TestStore_History(t *testing.T) {
+	storagetest.History(t, db)
+}
+func TestStore_Audit(t *testing.T) {
+	storagetest.Audit(t, db)
+}
+
+func TestGetClusterInfo(t *testing.T) {
+
+	t.Run("Setup some demo clusters", func(t *testing.T) {
+
+		func() {
+
+			if err := db.Setup(consts.CloudAzure, "regionAzure", "name_managed", consts.ClusterTypeMang); err != nil {
+				t.Fatal(err)
+			}
+
+			fakeData := &statefile.StorageDocument{
+				Region:        "regionAzure",
+				ClusterName:   "name_managed",
+				InfraProvider: consts.CloudAzure,
+				ClusterType:   "managed",
+				CloudInfra:    &statefile.InfrastructureState{Azure: &statefile.StateConfigurationAzure{}},
+			}
+
+			err := db.Write(fakeData)
+			if err != nil {
+				t.Fatalf("Error shouln't happen: %v", err)
+			}
+
+		}()
+
+		func() {
+
+			if err := db.Setup(consts.CloudAws, "regionAws", "name_managed", consts.ClusterTypeMang); err != nil {
+				t.Fatal(err)
+			}
+
+			fakeData := &statefile.StorageDocument{
+				Region:        "regionAws",
+				ClusterName:   "name_managed",
+				InfraProvider: consts.CloudAws,
+				ClusterType:   "managed",
+				CloudInfra:    &statefile.InfrastructureState{Aws: &statefile.StateConfigurationAws{}},
+			}
+
+			err := db.Write(fakeData)
+			if err != nil {
+				t.Fatalf("Error shouln't happen: %v", err)
+			}
+			err = db.Write(fakeData)
+			if err != nil {
+				t.Fatalf("Error shouln't happen on second Write: %v", err)
+			}
+
+		}()
+
+		func() {
+
+			if err := db.Setup(consts.CloudAws, "regionAws", "name_ha", consts.ClusterTypeSelfMang); err != nil {
+				t.Fatal(err)
+			}
+
+			fakeData := &statefile.StorageDocument{
+				Region:        "regionAws",
+				ClusterName:   "name_ha",
+				InfraProvider: consts.CloudAws,
+				ClusterType:   "selfmanaged",
+				CloudInfra:    &statefile.InfrastructureState{Aws: &statefile.StateConfigurationAws{}},
+				K8sBootstrap:  &statefile.KubernetesBootstrapState{K3s: &statefile.StateConfigurationK3s{}},
+			}
+
+			err := db.Write(fakeData)
+			if err != nil {
+				t.Fatalf("Error shouln't happen: %v", err)
+			}
+
+		}()
+	})
+
+	t.Run("fetch cluster Infos", func(t *testing.T) {
+		func(t *testing.T) {
+			m, err := db.GetOneOrMoreClusters(map[consts.KsctlSearchFilter]string{"cloud": "all", "clusterType": ""})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			dump.Println(m)
+			assert.Check(t, len(m[consts.ClusterTypeSelfMang]) == 1)
+			assert.Check(t, len(m[consts.ClusterTypeMang]) == 2)
+		}(t)
+
+		func(t *testing.T) {
+			m, err := db.GetOneOrMoreClusters(map[consts.KsctlSearchFilter]string{"cloud": "aws", "clusterType": "selfmanaged"})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			dump.Println(m)
+			assert.Check(t, len(m[consts.ClusterTypeSelfMang]) == 1)
+			assert.Check(t, len(m[consts.ClusterTypeMang]) == 0)
+		}(t)
+
+		func(t *testing.T) {
+			m, err := db.GetOneOrMoreClusters(map[consts.KsctlSearchFilter]string{"cloud": "azure", "clusterType": "managed"})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			dump.Println(m)
+			assert.Check(t, len(m[consts.ClusterTypeSelfMang]) == 0)
+			assert.Check(t, len(m[consts.ClusterTypeMang]) == 1)
+		}(t)
+
+		func(t *testing.T) {
+			m, err := db.GetOneOrMoreClusters(map[consts.KsctlSearchFilter]string{"cloud": "all", "region": "regionAws", "clusterName": "name_managed"})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			dump.Println(m)
+			assert.Check(t, len(m[consts.ClusterTypeSelfMang]) == 0)
+			assert.Check(t, len(m[consts.ClusterTypeMang]) == 1)
+			assert.Check(t, m[consts.ClusterTypeMang][0].InfraProvider == consts.CloudAws)
+		}(t)
+	})
+
+}
+
+func TestDelete(t *testing.T) {
+
+	t.Run("delete all", func(t *testing.T) {
+		func() {
+			if err := db.Setup(consts.CloudAws, "regionAws", "name_ha", consts.ClusterTypeSelfMang); err != nil {
+				t.Fatal(err)
+			}
+			if err := db.DeleteCluster(); err != nil {
+				t.Fatal(err)
+			}
+		}()
+
+		func() {
+			if err := db.Setup(consts.CloudAws, "regionAws", "name_managed", consts.ClusterTypeMang); err != nil {
+				t.Fatal(err)
+			}
+			if err := db.DeleteCluster(); err != nil {
+				t.Fatal(err)
+			}
+		}()
+
+		func() {
+			if err := db.Setup(consts.CloudAzure, "regionAzure", "name_managed", consts.ClusterTypeMang); err != nil {
+				t.Fatal(err)
+			}
+			if err := db.DeleteCluster(); err != nil {
+				t.Fatal(err)
+			}
+		}()
+	})
+}
@@ -15,8 +15,12 @@
 package storage
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/ksctl/ksctl/v2/pkg/consts"
 	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/utilities"
 )
 
 type Storage interface {
@@ -32,3 +36,48 @@ type Storage interface {
 
 	GetOneOrMoreClusters(filters map[consts.KsctlSearchFilter]string) (map[consts.KsctlClusterType][]*statefile.StorageDocument, error)
 }
+
+// ClusterLease describes the holder of the operation lock of a cluster
+type ClusterLease struct {
+	// ID is unique for every acquisition, it is used to renew and release the lease
+	ID         string    `json:"id" bson:"id"`
+	Owner      string    `json:"owner" bson:"owner"`
+	AcquiredAt time.Time `json:"acquired_at" bson:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at" bson:"expires_at"`
+
+	// Key is the lock of the cluster the lease was taken on, Lock sets it and Renew and Unlock use it
+	// as the store might get Setup for another cluster while the lease is being held
+	Key string `json:"-" bson:"-"`
+}
+
+func NewClusterLease(owner string, ttl time.Duration) (*ClusterLease, error) {
+	id, err := utilities.GenRandomString(16)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	return &ClusterLease{
+		ID:         id,
+		Owner:      owner,
+		AcquiredAt: now,
+		ExpiresAt:  now.Add(ttl),
+	}, nil
+}
+
+func (l ClusterLease) String() string {
+	return fmt.Sprintf("locked by %s since %s", l.Owner, l.AcquiredAt.Format(time.RFC3339))
+}
+
+func (l ClusterLease) IsExpired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
+
+// Locker is implemented by the storage backends which can serialize
+// the mutating operations on the cluster selected through Setup
+type Locker interface {
+	Lock(owner string, ttl time.Duration) (*ClusterLease, error)
+
+	Renew(lease *ClusterLease, ttl time.Duration) error
+
+	Unlock(lease *ClusterLease) error
+}
@@ -0,0 +1,160 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package storagetest holds the behaviour every storage backend has to share,
+// the tests of a backend run it against a store of their own
+package storagetest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+	"github.com/ksctl/ksctl/v2/pkg/storage"
+
+	"gotest.tools/v3/assert"
+)
+
+// StaleWrite checks a write based on an older revision of the state is rejected
+func StaleWrite(t *testing.T, store storage.Storage) {
+	t.Helper()
+	assert.NilError(t, store.Setup(consts.CloudAzure, "region", "stale", consts.ClusterTypeSelfMang))
+
+	fakeData := &statefile.StorageDocument{
+		Region:        "region",
+		ClusterName:   "stale",
+		ClusterType:   "selfmanaged",
+		InfraProvider: consts.CloudAzure,
+	}
+	assert.NilError(t, store.Write(fakeData))
+	assert.Equal(t, fakeData.Revision, int64(1))
+
+	first, err := store.Read()
+	assert.NilError(t, err)
+	second, err := store.Read()
+	assert.NilError(t, err)
+
+	assert.NilError(t, store.Write(first))
+	assert.Equal(t, first.Revision, int64(2))
+
+	err = store.Write(second)
+	assert.Check(t, ksctlErrors.IsStaleStateWrite(err), fmt.Sprintf("expected stale write error, got: %v", err))
+	assert.Equal(t, second.Revision, int64(1))
+
+	assert.NilError(t, store.DeleteCluster())
+}
+
+// History checks the store keeps the last revisions of the state and drops them with the cluster
+func History(t *testing.T, store storage.Storage) {
+	t.Helper()
+	history, ok := store.(storage.History)
+	assert.Assert(t, ok, "store does not keep the history of the state")
+
+	assert.NilError(t, store.Setup(consts.CloudAzure, "region", "history", consts.ClusterTypeSelfMang))
+
+	fakeData := &statefile.StorageDocument{
+		Region:        "region",
+		ClusterName:   "history",
+		ClusterType:   "selfmanaged",
+		InfraProvider: consts.CloudAzure,
+	}
+	limit := int(consts.CounterMaxStateRevisions)
+	for i := 1; i <= limit+2; i++ {
+		fakeData.ClusterKubeConfig = fmt.Sprintf("v%d", i)
+		assert.NilError(t, store.Write(fakeData))
+	}
+
+	revisions, err := history.ListRevisions()
+	assert.NilError(t, err)
+	assert.Equal(t, len(revisions), limit)
+	assert.Equal(t, revisions[0].Revision, fakeData.Revision)
+	assert.Equal(t, revisions[limit-1].Revision, fakeData.Revision-int64(limit)+1)
+	assert.Assert(t, revisions[0].Document == nil)
+	assert.Assert(t, len(revisions[0].WrittenBy) != 0)
+
+	_, err = history.ReadRevision(1)
+	assert.Check(t, ksctlErrors.IsNoMatchingRecordsFound(err), fmt.Sprintf("expected no matching records error, got: %v", err))
+
+	prev, err := history.ReadRevision(fakeData.Revision - 1)
+	assert.NilError(t, err)
+	assert.Equal(t, prev.Document.ClusterKubeConfig, fmt.Sprintf("v%d", limit+1))
+	changes, err := storage.DiffDocuments(prev.Document, fakeData)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, changes, []storage.StateChange{
+		{Path: "cluster_kubeconfig", Old: storage.RedactedValue, New: storage.RedactedValue},
+	})
+
+	assert.NilError(t, store.DeleteCluster())
+
+	revisions, err = history.ListRevisions()
+	assert.NilError(t, err)
+	assert.Equal(t, len(revisions), 0)
+}
+
+// Audit checks the trail outlives the cluster and is queried in the order it got recorded,
+// the store is expected to hold no other entries
+func Audit(t *testing.T, store storage.Storage) {
+	t.Helper()
+	auditor, ok := store.(storage.Auditor)
+	assert.Assert(t, ok, "store does not keep the audit trail")
+
+	record := func(name, actor string, op storage.AuditOperation, opErr error) {
+		e, err := storage.NewAuditEntry(consts.CloudAws, "region", name, consts.ClusterTypeMang, op, actor, "team")
+		assert.NilError(t, err)
+		e.Input, err = storage.RedactInput(map[string]any{"node_type": "t2.micro", "aws_secret_access_key": "s3cr3t"})
+		assert.NilError(t, err)
+		e.Finish(opErr)
+		assert.NilError(t, auditor.RecordAudit(e))
+	}
+	record("audit-1", "alice", storage.AuditOperationCreate, nil)
+	record("audit-2", "bob", storage.AuditOperationCreate, nil)
+	record("audit-1", "bob", storage.AuditOperationDelete, fmt.Errorf("boom"))
+
+	assert.NilError(t, store.Setup(consts.CloudAws, "region", "audit-1", consts.ClusterTypeMang))
+	assert.NilError(t, store.Write(&statefile.StorageDocument{
+		Region:        "region",
+		ClusterName:   "audit-1",
+		ClusterType:   "managed",
+		InfraProvider: consts.CloudAws,
+	}))
+	assert.NilError(t, store.DeleteCluster())
+
+	trail, err := auditor.QueryAudit(storage.AuditQuery{
+		Cloud:       consts.CloudAws,
+		Region:      "region",
+		ClusterName: "audit-1",
+		ClusterType: consts.ClusterTypeMang,
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(trail), 2)
+	assert.Equal(t, trail[0].Operation, storage.AuditOperationCreate)
+	assert.Equal(t, trail[0].Outcome, storage.AuditOutcomeSucceeded)
+	assert.Equal(t, trail[0].Input["node_type"], "t2.micro")
+	assert.Equal(t, trail[0].Input["aws_secret_access_key"], storage.RedactedValue)
+	assert.Equal(t, trail[1].Outcome, storage.AuditOutcomeFailed)
+	assert.Equal(t, trail[1].Error, "boom")
+
+	trail, err = auditor.QueryAudit(storage.AuditQuery{Actor: "bob"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(trail), 2)
+	assert.Equal(t, trail[0].ClusterName, "audit-2")
+	assert.Equal(t, trail[1].ClusterName, "audit-1")
+
+	trail, err = auditor.QueryAudit(storage.AuditQuery{Actor: "bob", Limit: 1})
+	assert.NilError(t, err)
+	assert.Equal(t, len(trail), 1)
+	assert.Equal(t, trail[0].Operation, storage.AuditOperationDelete)
+}
@@ -0,0 +1,113 @@
+// Copyright 2024 Ksctl Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/ksctl/ksctl/v2/pkg/consts"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
+)
+
+type WatchEventType string
+
+const (
+	WatchEventAdded   WatchEventType = "added"
+	WatchEventUpdated WatchEventType = "updated"
+	WatchEventDeleted WatchEventType = "deleted"
+
+	// WatchEventError carries the failure which ended the watch, the channel gets closed right after it
+	WatchEventError WatchEventType = "error"
+)
+
+// WatchEvent is a change of the state of a cluster, a cluster which no longer matches the filters
+// of the watch is reported as deleted. The document of a deleted cluster is the last one seen
+type WatchEvent struct {
+	Type     WatchEventType
+	Document *statefile.StorageDocument
+	Err      error
+}
+
+// Watcher is implemented by the storage backends which can stream the changes of the clusters
+type Watcher interface {
+	// Watch sends the clusters matching the filters present at the start as added events followed
+	// by their changes. The channel is closed once the ctx is done or the watch failed
+	Watch(ctx context.Context, filters map[consts.KsctlSearchFilter]string) (<-chan WatchEvent, error)
+}
+
+// SendWatchEvent hands the event to the watcher, false means the ctx got done and the watch has to stop
+func SendWatchEvent(ctx context.Context, ch chan<- WatchEvent, ev WatchEvent) bool {
+	select {
+	case ch <- ev:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// WatchTracker turns the state seen by a backend into watch events, it remembers the
+// last document of every cluster so that repeated notifications of a revision are dropped
+// and a deletion carries the last document. The key identifies the cluster in the backend
+type WatchTracker struct {
+	filters map[consts.KsctlSearchFilter]string
+	known   map[string]*statefile.StorageDocument
+}
+
+func NewWatchTracker(filters map[consts.KsctlSearchFilter]string) *WatchTracker {
+	return &WatchTracker{
+		filters: filters,
+		known:   make(map[string]*statefile.StorageDocument),
+	}
+}
+
+// Upsert records the document of the cluster, false means there is nothing to report.
+// A reported cluster whose document stopped matching the filters is deleted from the watch
+func (t *WatchTracker) Upsert(key string, doc *statefile.StorageDocument) (WatchEvent, bool) {
+	if !MatchesFilters(t.filters, doc) {
+		if _, ok := t.known[key]; !ok {
+			return WatchEvent{}, false
+		}
+		delete(t.known, key)
+		return WatchEvent{Type: WatchEventDeleted, Document: doc}, true
+	}
+	prev, ok := t.known[key]
+	if ok && prev.Revision == doc.Revision {
+		return WatchEvent{}, false
+	}
+	t.known[key] = doc
+	if ok {
+		return WatchEvent{Type: WatchEventUpdated, Document: doc}, true
+	}
+	return WatchEvent{Type: WatchEventAdded, Document: doc}, true
+}
+
+// Remove forgets the cluster, false means it was never reported
+func (t *WatchTracker) Remove(key string) (WatchEvent, bool) {
+	prev, ok := t.known[key]
+	if !ok {
+		return WatchEvent{}, false
+	}
+	delete(t.known, key)
+	return WatchEvent{Type: WatchEventDeleted, Document: prev}, true
+}
+
+// Keys returns the clusters reported and not deleted yet
+func (t *WatchTracker) Keys() []string {
+	keys := make([]string, 0, len(t.known))
+	for k := range t.known {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -83,3 +83,15 @@ func DeepCopySlice[T any](src []T) (dest []T) {
 
 	return
 }
+
+// RemoveIndexes returns a copy of src without the elements at the given indexes, keeping the order of the rest
+func RemoveIndexes[T any](src []T, indexes []int) (dest []T) {
+	dest = make([]T, 0, len(src))
+	for i, v := range src {
+		if !Contains(indexes, i) {
+			dest = append(dest, v)
+		}
+	}
+
+	return
+}
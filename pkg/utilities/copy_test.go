@@ -114,3 +114,11 @@ func TestDeepCopySlice(t *testing.T) {
 	dest := DeepCopySlice(src)
 	assert.DeepEqual(t, dest, src)
 }
+
+func TestRemoveIndexes(t *testing.T) {
+	src := []string{"a", "b", "c", "d"}
+	assert.DeepEqual(t, RemoveIndexes(src, []int{1, 3}), []string{"a", "c"})
+	assert.DeepEqual(t, RemoveIndexes(src, nil), src)
+	assert.DeepEqual(t, RemoveIndexes(src, []int{7}), src)
+	assert.DeepEqual(t, src, []string{"a", "b", "c", "d"})
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/ksctl/ksctl/v2/pkg/consts"
 	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
 	"github.com/ksctl/ksctl/v2/pkg/logger"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
 	"gotest.tools/v3/assert"
 )
 
@@ -151,6 +152,9 @@ func FuzzValidateStorage(f *testing.F) {
 	testcases := []string{
 		string(consts.StoreLocal),
 		string(consts.StoreExtMongo),
+		string(consts.StoreExtRedis),
+		string(consts.StoreExtS3),
+		string(consts.StoreExtSQL),
 	}
 
 	for _, tc := range testcases {
@@ -161,7 +165,7 @@ func FuzzValidateStorage(f *testing.F) {
 		ok := ValidateStorage(consts.KsctlStore(store))
 		t.Logf("storage: %s and ok: %v", store, ok)
 		switch consts.KsctlStore(store) {
-		case consts.StoreLocal, consts.StoreExtMongo:
+		case consts.StoreLocal, consts.StoreExtMongo, consts.StoreExtRedis, consts.StoreExtS3, consts.StoreExtSQL, consts.StoreK8s:
 			if !ok {
 				t.Errorf("Correct storage is invalid")
 			} else {
@@ -332,3 +336,106 @@ func TestIsValidKsctlClusterAddons(t *testing.T) {
 		t.Logf("✔️ Got(%#v): %v, Got: %v", tc, !tc.expectedError, err == nil)
 	}
 }
+
+func TestIsValidLabels(t *testing.T) {
+	testCases := map[string]struct {
+		labels   map[string]string
+		expected bool
+	}{
+		"no labels":         {nil, true},
+		"valid":             {map[string]string{"env": "dev", "cost-center": "1234", "ticket": "OPS/42"}, true},
+		"empty value":       {map[string]string{"env": ""}, true},
+		"empty key":         {map[string]string{"": "dev"}, false},
+		"comma in key":      {map[string]string{"env,team": "dev"}, false},
+		"equals in key":     {map[string]string{"env=dev": "dev"}, false},
+		"comma in value":    {map[string]string{"env": "dev,prod"}, false},
+		"reserved name":     {map[string]string{"name": "demo"}, false},
+		"reserved prefix":   {map[string]string{"aws:createdBy": "me"}, false},
+		"reserved ksctl":    {map[string]string{"ksctl-owner": "me"}, false},
+		"leading space val": {map[string]string{"env": " dev"}, false},
+	}
+
+	for name, tc := range testCases {
+		err := IsValidLabels(dummyCtx, log, tc.labels)
+		assert.Equal(t, err == nil, tc.expected, name)
+		if err != nil {
+			assert.Check(t, ksctlErrors.IsInvalidUserInput(err), name)
+		}
+	}
+
+	tooMany := make(map[string]string)
+	for i := 0; i <= int(consts.CounterMaxClusterLabels); i++ {
+		tooMany[fmt.Sprintf("key%d", i)] = "v"
+	}
+	assert.Check(t, IsValidLabels(dummyCtx, log, tooMany) != nil, "too many labels should be invalid")
+}
+
+func TestIsValidNodePoolScale(t *testing.T) {
+	testCases := map[string]struct {
+		nodes, min, max int
+		expected        bool
+	}{
+		"fixed size":         {3, 0, 0, true},
+		"autoscaling":        {3, 1, 5, true},
+		"at the bounds":      {5, 5, 5, true},
+		"no nodes":           {0, 0, 0, false},
+		"only min":           {3, 1, 0, false},
+		"only max":           {3, 0, 5, false},
+		"min above max":      {3, 4, 2, false},
+		"desired below min":  {1, 2, 5, false},
+		"desired above max":  {6, 2, 5, false},
+		"negative min bound": {3, -1, 5, false},
+	}
+
+	for name, tc := range testCases {
+		err := IsValidNodePoolScale(dummyCtx, log, tc.nodes, tc.min, tc.max)
+		assert.Equal(t, err == nil, tc.expected, name)
+		if err != nil {
+			assert.Check(t, ksctlErrors.IsInvalidUserInput(err), name)
+		}
+	}
+}
+
+func TestIsValidWorkerPools(t *testing.T) {
+	pool := func(mutate func(*statefile.WorkerPool)) []statefile.WorkerPool {
+		p := statefile.WorkerPool{
+			Name:     "gpu",
+			VMSize:   "fake",
+			Count:    2,
+			DiskSize: 100,
+			Labels:   map[string]string{"accelerator": "nvidia", "example.com/team": "ml"},
+			Taints:   []string{"nvidia.com/gpu=present:NoSchedule", "dedicated:NoExecute"},
+		}
+		mutate(&p)
+		return []statefile.WorkerPool{p}
+	}
+
+	testCases := map[string]struct {
+		pools    []statefile.WorkerPool
+		expected bool
+	}{
+		"no pools":          {nil, true},
+		"valid":             {pool(func(p *statefile.WorkerPool) {}), true},
+		"default disk":      {pool(func(p *statefile.WorkerPool) { p.DiskSize = 0 }), true},
+		"no workerplanes":   {pool(func(p *statefile.WorkerPool) { p.Count = 0 }), true},
+		"invalid name":      {pool(func(p *statefile.WorkerPool) { p.Name = "GPU_pool" }), false},
+		"long name":         {pool(func(p *statefile.WorkerPool) { p.Name = "a-very-long-pool-name" }), false},
+		"no vm size":        {pool(func(p *statefile.WorkerPool) { p.VMSize = "" }), false},
+		"negative count":    {pool(func(p *statefile.WorkerPool) { p.Count = -1 }), false},
+		"small disk":        {pool(func(p *statefile.WorkerPool) { p.DiskSize = 10 }), false},
+		"invalid label":     {pool(func(p *statefile.WorkerPool) { p.Labels = map[string]string{"env": "dev,prod"} }), false},
+		"reserved label":    {pool(func(p *statefile.WorkerPool) { p.Labels = map[string]string{"node-role.kubernetes.io/gpu": ""} }), false},
+		"no taint effect":   {pool(func(p *statefile.WorkerPool) { p.Taints = []string{"dedicated=gpu"} }), false},
+		"bad taint effect":  {pool(func(p *statefile.WorkerPool) { p.Taints = []string{"dedicated=gpu:Never"} }), false},
+		"invalid taint key": {pool(func(p *statefile.WorkerPool) { p.Taints = []string{"dedi cated=gpu:NoSchedule"} }), false},
+		"duplicate pools":   {append(pool(func(p *statefile.WorkerPool) {}), pool(func(p *statefile.WorkerPool) {})...), false},
+	}
+
+	for name, tc := range testCases {
+		err := IsValidWorkerPools(dummyCtx, log, tc.pools)
+		assert.Equal(t, err == nil, tc.expected, name)
+		if err != nil {
+			assert.Check(t, ksctlErrors.IsInvalidUserInput(err), name)
+		}
+	}
+}
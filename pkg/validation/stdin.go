@@ -16,6 +16,7 @@ package validation
 
 import (
 	"context"
+	"fmt"
 	"regexp"
 	"slices"
 	"strings"
@@ -25,6 +26,7 @@ import (
 	"github.com/ksctl/ksctl/v2/pkg/consts"
 	ksctlErrors "github.com/ksctl/ksctl/v2/pkg/errors"
 	"github.com/ksctl/ksctl/v2/pkg/logger"
+	"github.com/ksctl/ksctl/v2/pkg/statefile"
 )
 
 func ValidateDistro(distro consts.KsctlKubernetes) bool {
@@ -59,7 +61,7 @@ func ValidateStorage(storage consts.KsctlStore) bool {
 	}
 
 	switch storage {
-	case consts.StoreExtMongo, consts.StoreLocal, consts.StoreK8s:
+	case consts.StoreExtMongo, consts.StoreExtRedis, consts.StoreExtS3, consts.StoreExtSQL, consts.StoreLocal, consts.StoreK8s:
 		return true
 	default:
 		return false
@@ -274,3 +276,167 @@ func IsValidKsctlClusterAddons(ctx context.Context, log logger.Logger, ca addons
 
 	return nil
 }
+
+var (
+	labelKeyPattern   = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9 _.:@+-]{0,126}[a-zA-Z0-9_.@+-])?$`)
+	labelValuePattern = regexp.MustCompile(`^([a-zA-Z0-9_.:/=+@-]([a-zA-Z0-9 _.:/=+@-]{0,254}[a-zA-Z0-9_.:/=+@-])?)?$`)
+
+	reservedLabelPrefixes = []string{"aws:", "microsoft", "azure", "windows", "ksctl"}
+)
+
+// IsValidLabels checks the user-defined cluster labels can be used as both aws and azure resource tags,
+// keys can't contain `,` or `=` so that they stay usable in the labels search filter
+func IsValidLabels(ctx context.Context, log logger.Logger, labels map[string]string) error {
+	if len(labels) > int(consts.CounterMaxClusterLabels) {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			log.NewError(ctx, "too many labels", "max", consts.CounterMaxClusterLabels, "got", len(labels)),
+		)
+	}
+
+	for k, v := range labels {
+		if !labelKeyPattern.MatchString(k) {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrInvalidUserInput,
+				log.NewError(ctx, "invalid label key", "key", k, "expectedToBePattern", labelKeyPattern.String()),
+			)
+		}
+		if strings.EqualFold(k, "Name") {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrInvalidUserInput,
+				log.NewError(ctx, "label key is reserved", "key", k),
+			)
+		}
+		for _, prefix := range reservedLabelPrefixes {
+			if strings.HasPrefix(strings.ToLower(k), prefix) {
+				return ksctlErrors.WrapError(
+					ksctlErrors.ErrInvalidUserInput,
+					log.NewError(ctx, "label key uses a reserved prefix", "key", k, "prefix", prefix),
+				)
+			}
+		}
+		if !labelValuePattern.MatchString(v) {
+			return ksctlErrors.WrapError(
+				ksctlErrors.ErrInvalidUserInput,
+				log.NewError(ctx, "invalid label value", "key", k, "value", v, "expectedToBePattern", labelValuePattern.String()),
+			)
+		}
+	}
+
+	return nil
+}
+
+// IsValidNodePoolScale checks the desired size of a managed node pool along with its optional autoscaling bounds,
+// min and max of 0 keep the autoscaling disabled otherwise both are needed and the desired size has to fall within them
+func IsValidNodePoolScale(ctx context.Context, log logger.Logger, noOfNodes, minNodes, maxNodes int) error {
+	if noOfNodes < 1 {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			log.NewError(ctx, "node pool needs at least a node", "desired", noOfNodes),
+		)
+	}
+
+	if minNodes == 0 && maxNodes == 0 {
+		return nil
+	}
+
+	if minNodes < 1 || maxNodes < minNodes {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			log.NewError(ctx, "invalid autoscaling bounds", "min", minNodes, "max", maxNodes),
+		)
+	}
+
+	if noOfNodes < minNodes || noOfNodes > maxNodes {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			log.NewError(ctx, "desired nodes outside the autoscaling bounds", "desired", noOfNodes, "min", minNodes, "max", maxNodes),
+		)
+	}
+
+	return nil
+}
+
+var (
+	workerPoolNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,14}[a-z0-9])?$`)
+	nodeLabelKeyPattern   = regexp.MustCompile(`^([a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/)?[A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?$`)
+	nodeLabelValuePattern = regexp.MustCompile(`^([A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?)?$`)
+
+	// the kubelet refuses to register a node with labels of these domains
+	reservedNodeLabelDomains = []string{"kubernetes.io", "k8s.io"}
+
+	taintEffects = []string{"NoSchedule", "PreferNoSchedule", "NoExecute"}
+)
+
+const (
+	minWorkerPoolDiskSize = 30
+	maxWorkerPoolDiskSize = 4095
+)
+
+// IsValidWorkerPools checks the worker pools of a self-managed cluster, the names have to be unique and short
+// enough to be part of the vm names, the labels and the taints have to be accepted by the kubelet
+func IsValidWorkerPools(ctx context.Context, log logger.Logger, pools []statefile.WorkerPool) error {
+	invalid := func(msg string, args ...any) error {
+		return ksctlErrors.WrapError(
+			ksctlErrors.ErrInvalidUserInput,
+			log.NewError(ctx, msg, args...),
+		)
+	}
+
+	names := make(map[string]struct{}, len(pools))
+	for _, pool := range pools {
+		if !workerPoolNamePattern.MatchString(pool.Name) {
+			return invalid("invalid worker pool name", "pool", pool.Name, "expectedToBePattern", workerPoolNamePattern.String())
+		}
+		if _, ok := names[pool.Name]; ok {
+			return invalid("duplicate worker pool", "pool", pool.Name)
+		}
+		names[pool.Name] = struct{}{}
+
+		if len(pool.VMSize) == 0 {
+			return invalid("worker pool needs a vm size", "pool", pool.Name)
+		}
+		if pool.Count < 0 {
+			return invalid("invalid count of worker pool", "pool", pool.Name, "count", pool.Count)
+		}
+		if pool.DiskSize != 0 && (pool.DiskSize < minWorkerPoolDiskSize || pool.DiskSize > maxWorkerPoolDiskSize) {
+			return invalid("invalid disk size of worker pool", "pool", pool.Name, "diskSize", pool.DiskSize, "min", minWorkerPoolDiskSize, "max", maxWorkerPoolDiskSize)
+		}
+
+		for k, v := range pool.Labels {
+			if err := isValidNodeLabel(k, v); err != nil {
+				return invalid("invalid node label of worker pool", "pool", pool.Name, "key", k, "value", v, "reason", err)
+			}
+		}
+
+		for _, taint := range pool.Taints {
+			kv, effect, ok := strings.Cut(taint, ":")
+			if !ok || !slices.Contains(taintEffects, effect) {
+				return invalid("invalid taint effect of worker pool", "pool", pool.Name, "taint", taint, "effects", taintEffects)
+			}
+			k, v, _ := strings.Cut(kv, "=")
+			if err := isValidNodeLabel(k, v); err != nil {
+				return invalid("invalid taint of worker pool", "pool", pool.Name, "taint", taint, "reason", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func isValidNodeLabel(k, v string) error {
+	if !nodeLabelKeyPattern.MatchString(k) {
+		return fmt.Errorf("key is expected to match %s", nodeLabelKeyPattern.String())
+	}
+	if prefix, _, ok := strings.Cut(k, "/"); ok {
+		for _, domain := range reservedNodeLabelDomains {
+			if prefix == domain || strings.HasSuffix(prefix, "."+domain) {
+				return fmt.Errorf("key uses the reserved domain %s", domain)
+			}
+		}
+	}
+	if !nodeLabelValuePattern.MatchString(v) {
+		return fmt.Errorf("value is expected to match %s", nodeLabelValuePattern.String())
+	}
+	return nil
+}
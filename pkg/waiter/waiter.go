@@ -69,13 +69,7 @@ func (b *Waiter) Run(
 		select {
 		case <-ctx.Done():
 			log.Print(ctx, "Operation cancelled during backoff")
-			if ctx.Err() != nil {
-				return ksctlErrors.WrapError(
-					ksctlErrors.ErrContextCancelled,
-					log.NewError(ctx, "backoff termination", "Reason", ctx.Err()),
-				)
-			}
-			return nil
+			return cancelled(ctx, log, "backoff termination")
 		case <-time.After(waitTime):
 			waitTime *= time.Duration(b.factor)
 		}
@@ -93,3 +87,32 @@ func (b *Waiter) Run(
 		log.NewError(ctx, "Max backoff retries reached"),
 	)
 }
+
+// Sleep pauses for d, it returns early with ErrContextCancelled once the ctx is cancelled or past its deadline
+func Sleep(ctx context.Context, log logger.Logger, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return cancelled(ctx, log, "pause termination")
+	case <-t.C:
+		return nil
+	}
+}
+
+// Cancelled returns ErrContextCancelled once the ctx is cancelled or past its deadline, so that
+// the long-running operations stop before their next step instead of starting it
+func Cancelled(ctx context.Context, log logger.Logger) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	return cancelled(ctx, log, "operation termination")
+}
+
+func cancelled(ctx context.Context, log logger.Logger, msg string) error {
+	return ksctlErrors.WrapError(
+		ksctlErrors.ErrContextCancelled,
+		log.NewError(ctx, msg, "Reason", ctx.Err()),
+	)
+}
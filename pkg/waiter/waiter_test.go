@@ -142,3 +142,24 @@ func TestWaiterRun_MaxRetriesExceeded(t *testing.T) {
 	err := backOff.Run(ctx, log, executeFunc, isSuccessful, errorFunc, successFunc, "Waiting message")
 	assert.Assert(t, err != nil && ksctlErrors.IsTimeout(err))
 }
+
+func TestSleep(t *testing.T) {
+	assert.NilError(t, Sleep(context.Background(), log, 10*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := Sleep(ctx, log, time.Minute)
+	assert.Assert(t, err != nil && ksctlErrors.IsContextCancelled(err))
+	assert.Assert(t, time.Since(start) < 10*time.Second, "sleep should end with the deadline")
+}
+
+func TestCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.NilError(t, Cancelled(ctx, log))
+
+	cancel()
+	err := Cancelled(ctx, log)
+	assert.Assert(t, err != nil && ksctlErrors.IsContextCancelled(err))
+}
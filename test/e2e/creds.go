@@ -85,3 +85,49 @@ func CredsMongo(ctx context.Context) statefile.CredentialsMongodb {
 	}
 
 }
+
+func CredsRedis(ctx context.Context) statefile.CredentialsRedis {
+
+	redisHost, ok := os.LookupEnv("REDIS_URI")
+	if !ok {
+		panic("REDIS_URI not set")
+	}
+
+	return statefile.CredentialsRedis{
+		URI: redisHost,
+	}
+
+}
+
+func CredsS3(ctx context.Context) statefile.CredentialsS3 {
+
+	bucket, ok := os.LookupEnv("S3_BUCKET")
+	if !ok {
+		panic("S3_BUCKET not set")
+	}
+
+	return statefile.CredentialsS3{
+		Endpoint:        os.Getenv("S3_ENDPOINT"),
+		Region:          os.Getenv("S3_REGION"),
+		Bucket:          bucket,
+		Prefix:          os.Getenv("S3_PREFIX"),
+		AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		UsePathStyle:    os.Getenv("S3_USE_PATH_STYLE") == "true",
+	}
+
+}
+
+func CredsSQL(ctx context.Context) statefile.CredentialsSQL {
+
+	dsn, ok := os.LookupEnv("SQL_DSN")
+	if !ok {
+		panic("SQL_DSN not set")
+	}
+
+	return statefile.CredentialsSQL{
+		Driver: os.Getenv("SQL_DRIVER"),
+		DSN:    dsn,
+	}
+
+}
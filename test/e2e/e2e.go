@@ -23,8 +23,12 @@ import (
 
 	"github.com/ksctl/ksctl/v2/pkg/cache"
 	"github.com/ksctl/ksctl/v2/pkg/handler/cluster/controller"
+	"github.com/ksctl/ksctl/v2/pkg/storage/encryption"
 	"github.com/ksctl/ksctl/v2/pkg/storage/host"
 	"github.com/ksctl/ksctl/v2/pkg/storage/mongodb"
+	"github.com/ksctl/ksctl/v2/pkg/storage/redis"
+	"github.com/ksctl/ksctl/v2/pkg/storage/s3"
+	"github.com/ksctl/ksctl/v2/pkg/storage/sql"
 
 	"github.com/ksctl/ksctl/v2/pkg/consts"
 	"github.com/ksctl/ksctl/v2/pkg/logger"
@@ -111,10 +115,54 @@ func main() {
 			l.Error("unable to initialize the mongodb client", "Reason", err)
 			os.Exit(1)
 		}
+	} else if meta.StateLocation == consts.StoreExtRedis { // redis storage
+		client, err := redis.NewDBClient(ctx, CredsRedis(ctx))
+		if err != nil {
+			l.Error("unable to initialize the redis client", "Reason", err)
+			os.Exit(1)
+		}
+		kscConfig.Storage, err = client.NewDatabaseClient(ksctlConfig, l)
+		if err != nil {
+			l.Error("unable to initialize the redis client", "Reason", err)
+			os.Exit(1)
+		}
+	} else if meta.StateLocation == consts.StoreExtS3 { // s3 storage
+		client, err := s3.NewDBClient(ctx, CredsS3(ctx))
+		if err != nil {
+			l.Error("unable to initialize the s3 client", "Reason", err)
+			os.Exit(1)
+		}
+		kscConfig.Storage, err = client.NewDatabaseClient(ksctlConfig, l)
+		if err != nil {
+			l.Error("unable to initialize the s3 client", "Reason", err)
+			os.Exit(1)
+		}
+	} else if meta.StateLocation == consts.StoreExtSQL { // sql storage
+		client, err := sql.NewDBClient(ctx, CredsSQL(ctx))
+		if err != nil {
+			l.Error("unable to initialize the sql client", "Reason", err)
+			os.Exit(1)
+		}
+		kscConfig.Storage, err = client.NewDatabaseClient(ksctlConfig, l)
+		if err != nil {
+			l.Error("unable to initialize the sql client", "Reason", err)
+			os.Exit(1)
+		}
 	} else { // local storage
 		kscConfig.Storage = host.NewClient(ksctlConfig, l)
 	}
 
+	if keyfile, ok := os.LookupEnv("KSCTL_STATE_KEYFILE"); ok {
+		keyring, err := encryption.NewLocalKeyring(keyfile)
+		if err != nil {
+			l.Error("unable to load the state keyfile", "Reason", err)
+			os.Exit(1)
+		}
+		if store, ok := kscConfig.Storage.(encryption.Encryptable); ok {
+			store.SetEncrypter(encryption.NewEnvelopeEncrypter(ctx, keyring))
+		}
+	}
+
 	defer cc.Close()
 
 	l.Print(ctx, "Testing starting...")
@@ -166,6 +166,19 @@ func ExecuteManagedRun() error {
 		return err
 	}
 
+	cli.Metadata.NoMP++
+	if cli.Metadata.Provider != consts.CloudLocal {
+		cli.Metadata.NoMPMin, cli.Metadata.NoMPMax = 1, cli.Metadata.NoMP+2
+	}
+	if err := controller.Scale(); err != nil {
+		return err
+	}
+	cli.Metadata.NoMPMin, cli.Metadata.NoMPMax = 0, 0
+
+	if err := ExecuteKsctlSpecificRun(); err != nil {
+		return err
+	}
+
 	if err := controller.Delete(); err != nil {
 		return err
 	}
@@ -215,6 +228,86 @@ func ExecuteHARun() error {
 		return err
 	}
 
+	cli.Metadata.WorkerPools = []statefile.WorkerPool{
+		{
+			Name:     "gpu",
+			VMSize:   "fake",
+			DiskSize: 100,
+			Labels:   map[string]string{"accelerator": "nvidia"},
+			Taints:   []string{"gpu=true:NoSchedule"},
+		},
+	}
+	if err := controller.ScaleWorkerPool("gpu", 2); err != nil {
+		return err
+	}
+
+	if err := ExecuteKsctlSpecificRun(); err != nil {
+		return err
+	}
+
+	// the workerplanes of the gpu pool move up in place of the default one
+	if err := controller.ScaleWorkerPool(statefile.DefaultWorkerPool, 0); err != nil {
+		return err
+	}
+
+	if err := controller.ScaleWorkerPool("gpu", 0); err != nil {
+		return err
+	}
+
+	cli.Metadata.WorkerPools = nil
+	cli.Metadata.NoWP = 1
+	if err := controller.AddWorkerNodes(); err != nil {
+		return err
+	}
+
+	if err := ExecuteKsctlSpecificRun(); err != nil {
+		return err
+	}
+
+	cli.Metadata.NoCP = 5
+	if err := controller.AddControlPlaneNodes(); err != nil {
+		return err
+	}
+
+	if err := ExecuteKsctlSpecificRun(); err != nil {
+		return err
+	}
+
+	cli.Metadata.NoCP = 3
+	if err := controller.DeleteControlPlaneNodes(); err != nil {
+		return err
+	}
+
+	if err := ExecuteKsctlSpecificRun(); err != nil {
+		return err
+	}
+
+	cli.Metadata.NoDS = 5
+	if err := controller.AddDataStoreNodes(); err != nil {
+		return err
+	}
+
+	if err := ExecuteKsctlSpecificRun(); err != nil {
+		return err
+	}
+
+	cli.Metadata.NoDS = 3
+	if err := controller.DeleteDataStoreNodes(); err != nil {
+		return err
+	}
+
+	if err := ExecuteKsctlSpecificRun(); err != nil {
+		return err
+	}
+
+	if err := controller.ReplaceDataStoreNode(1); err != nil {
+		return err
+	}
+
+	if err := ExecuteKsctlSpecificRun(); err != nil {
+		return err
+	}
+
 	if err := controller.Delete(); err != nil {
 		return err
 	}